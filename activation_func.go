@@ -40,12 +40,48 @@ var (
 	}
 )
 
+// RegisterActivation registers a user-defined activation function of the
+// argument name in ActivationSet, and returns it. Once registered, it can be
+// chosen by its name in configurations, e.g., in hiddenActivations, and
+// genomes that use it are restored when they are imported from JSON, in which
+// activation functions are only kept by their names. A function of an existing
+// name is replaced. Since ActivationSet is not guarded, functions should be
+// registered before evolution, e.g., in an init function.
+func RegisterActivation(name string, fn func(x float64) float64) *ActivationFunc {
+	afunc := &ActivationFunc{Name: name, Fn: fn}
+	ActivationSet[name] = afunc
+	return afunc
+}
+
+// lookupActivation returns the activation function of the argument name in
+// ActivationSet, either by its key or by its name, and false if there is none.
+func lookupActivation(name string) (*ActivationFunc, bool) {
+	if afunc, ok := ActivationSet[name]; ok {
+		return afunc, true
+	}
+	for _, afunc := range ActivationSet {
+		if afunc.Name == name {
+			return afunc, true
+		}
+	}
+	return nil, false
+}
+
 // ActivationFunc is a wrapper type for activation functions.
 type ActivationFunc struct {
 	Name string                  `json:"name"` // name of the function
 	Fn   func(x float64) float64 `json:"-"`    // activation function
 }
 
+// name returns the name of this activation function, which is Identity if it
+// is nil, i.e., of a node without an activation function, e.g., an input node.
+func (a *ActivationFunc) name() string {
+	if a == nil {
+		return "Identity"
+	}
+	return a.Name
+}
+
 // Identity returns the identity function as an activation
 // function. This function is only used for sensor nodes.
 func Identity() *ActivationFunc {
@@ -57,6 +93,16 @@ func Identity() *ActivationFunc {
 	}
 }
 
+// Linear returns the linear function as an activation function.
+func Linear() *ActivationFunc {
+	return &ActivationFunc{
+		Name: "Linear",
+		Fn: func(x float64) float64 {
+			return x
+		},
+	}
+}
+
 // Sigmoid returns the sigmoid function as an activation function.
 func Sigmoid() *ActivationFunc {
 	return &ActivationFunc{
@@ -0,0 +1,110 @@
+// activation_registry.go implementation of registries of activation functions.
+//
+// Copyright (C) 2017  Jin Yeom
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package neat
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+// ActivationRegistry is a registry of activation functions of an instance of
+// NEAT, which drives the activation functions of nodes both when initial
+// genomes are created and when genomes are mutated.
+//
+// Hidden nodes are assigned functions from the hidden pool, when they are added
+// or when their activations are mutated. Output nodes are assigned a function
+// from the output pool when initial genomes are created, which is then kept
+// fixed throughout the evolution.
+type ActivationRegistry struct {
+	Hidden []*ActivationFunc // pool of hidden nodes
+	Output []*ActivationFunc // pool of output nodes
+}
+
+// NewActivationRegistry returns a new registry of the activation functions of
+// the argument configuration. Unless configured explicitly, the hidden pool
+// consists of Sigmoid function and the CPPN activation functions, and the
+// output pool consists of Sigmoid function. It returns an error if a name is
+// not in ActivationSet, rather than leaving it out of its pool.
+func NewActivationRegistry(config *Config) (*ActivationRegistry, error) {
+	hiddenActivations := config.HiddenActivations
+	if len(hiddenActivations) == 0 {
+		hiddenActivations = append([]string{"sigmoid"}, config.CPPNActivations...)
+	}
+	outputActivations := config.OutputActivations
+	if len(outputActivations) == 0 {
+		outputActivations = []string{"sigmoid"}
+	}
+	hidden, err := activationPool(hiddenActivations)
+	if err != nil {
+		return nil, err
+	}
+	output, err := activationPool(outputActivations)
+	if err != nil {
+		return nil, err
+	}
+	return &ActivationRegistry{Hidden: hidden, Output: output}, nil
+}
+
+// RandHidden returns a random activation function of the hidden pool.
+func (r *ActivationRegistry) RandHidden(rng *rand.Rand) *ActivationFunc {
+	return r.Hidden[rng.Intn(len(r.Hidden))]
+}
+
+// RandOutput returns a random activation function of the output pool.
+func (r *ActivationRegistry) RandOutput(rng *rand.Rand) *ActivationFunc {
+	return r.Output[rng.Intn(len(r.Output))]
+}
+
+// Initialize assigns activation functions of the pools to the hidden and
+// output nodes of the argument initial genome; output nodes are left as they
+// are if the output pool consists of a single function of the same name, so
+// that the default path stays intact.
+func (r *ActivationRegistry) Initialize(g *Genome, rng *rand.Rand) {
+	for _, node := range g.NodeGenes {
+		switch node.Type {
+		case "hidden":
+			node.Activation = r.RandHidden(rng)
+		case "output":
+			if len(r.Output) == 1 && node.Activation != nil &&
+				node.Activation.Name == r.Output[0].Name {
+				continue
+			}
+			node.Activation = r.RandOutput(rng)
+		}
+	}
+}
+
+// activationPool is a helper function that returns the activation functions
+// of the argument names in ActivationSet, in order and without duplicates. It
+// returns an error if a name is unknown.
+func activationPool(names []string) ([]*ActivationFunc, error) {
+	pool := make([]*ActivationFunc, 0, len(names))
+	added := make(map[string]bool)
+	for _, name := range names {
+		afunc, ok := ActivationSet[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown activation function %q "+
+				"(see RegisterActivation)", name)
+		}
+		if !added[name] {
+			pool = append(pool, afunc)
+			added[name] = true
+		}
+	}
+	return pool, nil
+}
@@ -0,0 +1,271 @@
+// ale.go implementation of an adapter of the Arcade Learning Environment.
+//
+// Copyright (C) 2017  Jin Yeom
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+// Package ale provides an adapter of the Arcade Learning Environment (ALE), in
+// which networks play Atari 2600 games from the screen, e.g., to reproduce
+// HyperNEAT-GGP (Hausknecht et al., 2012) with package hyperneat.
+//
+// The emulator runs as an external process, which is connected via its FIFO
+// interface (see FIFO); other connections can be used by implementing
+// Emulator. An adapter downsamples the screen into the inputs of a network,
+// maps its outputs to the actions of the game, repeats actions with sticky
+// actions (Machado et al., 2018), and limits the frames of each episode.
+//
+// Since an emulator plays one game at a time, it must not be shared between
+// evaluations that run concurrently.
+package ale
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+
+	"github.com/jinyeom/neat"
+	"github.com/jinyeom/neat/hyperneat"
+)
+
+// Actions of ALE for the player A. A game often uses only a subset of them,
+// e.g., Breakout with Noop, Fire, Right, and Left.
+const (
+	Noop = iota
+	Fire
+	Up
+	Right
+	Left
+	Down
+	UpRight
+	UpLeft
+	DownRight
+	DownLeft
+	UpFire
+	RightFire
+	LeftFire
+	DownFire
+	UpRightFire
+	UpLeftFire
+	DownRightFire
+	DownLeftFire
+
+	// NumActions is the number of actions of the player A.
+	NumActions
+
+	// ResetAction is the action that resets the game.
+	ResetAction = 40
+)
+
+// Frame is a screen of the emulator, whose pixels are indices of the NTSC
+// palette in row-major order.
+type Frame struct {
+	Width  int     // width of the screen
+	Height int     // height of the screen
+	Pixels []uint8 // indices of the palette
+}
+
+// Luminance returns the luminance in [0, 1] of the argument index of the NTSC
+// palette, which is encoded in its lower bits.
+func Luminance(p uint8) float64 {
+	return float64(p&0x0E) / 14.0
+}
+
+// Downsample returns the average luminance of each cell of a grid of the
+// argument width and height over the frame, in row-major order.
+func (f Frame) Downsample(width, height int) []float64 {
+	cells := make([]float64, width*height)
+	counts := make([]int, width*height)
+	if f.Width <= 0 || f.Height <= 0 || len(f.Pixels) < f.Width*f.Height {
+		return cells
+	}
+	for y := 0; y < f.Height; y++ {
+		row := y * height / f.Height
+		for x := 0; x < f.Width; x++ {
+			i := row*width + x*width/f.Width
+			cells[i] += Luminance(f.Pixels[y*f.Width+x])
+			counts[i]++
+		}
+	}
+	for i := range cells {
+		if counts[i] > 0 {
+			cells[i] /= float64(counts[i])
+		}
+	}
+	return cells
+}
+
+// Emulator is an interface of a running game of the emulator.
+type Emulator interface {
+	// Reset resets the game.
+	Reset() error
+
+	// Act advances the game by a frame with the argument action, and returns
+	// the reward of the frame.
+	Act(action int) (float64, error)
+
+	// Screen returns the current screen.
+	Screen() Frame
+
+	// GameOver returns true if the game is over.
+	GameOver() bool
+}
+
+// Adapter is an adapter of an emulator for networks that take a bias followed
+// by the downsampled screen, and output a score of each action; the action of
+// the highest score is taken.
+type Adapter struct {
+	Emulator    Emulator   // emulator of the game
+	Width       int        // width of the downsampled screen
+	Height      int        // height of the downsampled screen
+	Actions     []int      // actions of the outputs of a network
+	StickyRate  float64    // probability of repeating the previous action
+	FrameSkip   int        // number of frames of each decision
+	MaxFrames   int        // maximum number of frames of an episode
+	NumEpisodes int        // number of episodes of an evaluation
+	Rand        *rand.Rand // random number generator of sticky actions
+}
+
+// NewAdapter returns a new adapter of the argument emulator, with the screen
+// downsampled to the argument width and height, and the argument actions; it
+// follows the recommendations of Machado et al. (2018), i.e., sticky actions
+// of 0.25, a decision every 4 frames, and episodes of 18000 frames (5 minutes).
+func NewAdapter(emulator Emulator, width, height int, actions []int) *Adapter {
+	return &Adapter{
+		Emulator:    emulator,
+		Width:       width,
+		Height:      height,
+		Actions:     actions,
+		StickyRate:  0.25,
+		FrameSkip:   4,
+		MaxFrames:   18000,
+		NumEpisodes: 1,
+		Rand:        rand.New(rand.NewSource(0)),
+	}
+}
+
+// NumInputs returns the number of inputs of a network, including the bias.
+func (a *Adapter) NumInputs() int {
+	return 1 + a.Width*a.Height
+}
+
+// NumOutputs returns the number of outputs of a network.
+func (a *Adapter) NumOutputs() int {
+	return len(a.Actions)
+}
+
+// NewConfig returns a new default configuration of NEAT for networks that play
+// the game directly.
+func (a *Adapter) NewConfig() *neat.Config {
+	config := neat.NewDefaultConfig(a.NumInputs(), a.NumOutputs())
+	config.ExperimentName = "ALE"
+	return config
+}
+
+// Substrate returns a substrate of HyperNEAT for networks that play the game,
+// following HyperNEAT-GGP: the bias and the downsampled screen are connected to
+// a hidden grid of the same size, which is connected to a line of the actions.
+func (a *Adapter) Substrate() *hyperneat.Substrate {
+	return hyperneat.NewSubstrate().
+		AddPoints("bias", "input", [][]float64{{0.0, 0.0}}, -1.0).
+		AddGrid("screen", "input", a.Width, a.Height, -1.0).
+		AddGrid("hidden", "hidden", a.Width, a.Height, 0.0).
+		AddLine("actions", "output", len(a.Actions), 1.0).
+		Connect("bias", "hidden").Connect("screen", "hidden").
+		Connect("bias", "actions").Connect("hidden", "actions")
+}
+
+// Play plays an episode with the argument network, and returns its score,
+// i.e., the total reward.
+func (a *Adapter) Play(nn *neat.NeuralNetwork) (float64, error) {
+	if len(a.Actions) == 0 {
+		return 0.0, errors.New("ale: no actions")
+	}
+	if err := a.Emulator.Reset(); err != nil {
+		return 0.0, err
+	}
+	nn.Reset()
+
+	score := 0.0
+	previous := Noop
+	inputs := make([]float64, 1, a.NumInputs())
+	for frames := 0; frames < a.MaxFrames && !a.Emulator.GameOver(); {
+		inputs = append(inputs[:1], a.Emulator.Screen().Downsample(a.Width,
+			a.Height)...)
+		inputs[0] = 1.0
+		outputs, err := nn.FeedForward(inputs)
+		if err != nil {
+			return score, err
+		}
+		action := a.Actions[argmax(outputs)]
+
+		for i := 0; i < a.FrameSkip || i == 0; i++ {
+			if a.Rand.Float64() >= a.StickyRate {
+				previous = action
+			}
+			reward, err := a.Emulator.Act(previous)
+			if err != nil {
+				return score, err
+			}
+			score += reward
+			frames++
+			if frames >= a.MaxFrames || a.Emulator.GameOver() {
+				break
+			}
+		}
+	}
+	return score, nil
+}
+
+// FallibleEvaluation returns an evaluation function of the average score of
+// NumEpisodes episodes, which returns the error of the emulator, if any (see
+// neat.WithFallibleEvaluation).
+func (a *Adapter) FallibleEvaluation() neat.FallibleEvaluationFunc {
+	return func(nn *neat.NeuralNetwork) (float64, error) {
+		total := 0.0
+		for i := 0; i < a.NumEpisodes; i++ {
+			score, err := a.Play(nn)
+			if err != nil {
+				return 0.0, err
+			}
+			total += score
+		}
+		return total / float64(a.NumEpisodes), nil
+	}
+}
+
+// Evaluation returns an evaluation function of the average score of
+// NumEpisodes episodes; the fitness is NaN if the emulator fails, such that
+// the genome is recorded as a failure.
+func (a *Adapter) Evaluation() neat.EvaluationFunc {
+	evaluate := a.FallibleEvaluation()
+	return func(nn *neat.NeuralNetwork) float64 {
+		score, err := evaluate(nn)
+		if err != nil {
+			return math.NaN()
+		}
+		return score
+	}
+}
+
+// argmax returns the index of the largest of the argument values, or 0 if
+// there are none.
+func argmax(values []float64) int {
+	best := 0
+	for i := range values {
+		if values[i] > values[best] {
+			best = i
+		}
+	}
+	return best
+}
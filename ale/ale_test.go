@@ -0,0 +1,132 @@
+package ale
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/jinyeom/neat"
+	"github.com/jinyeom/neat/hyperneat"
+)
+
+// counter is an emulator of a game, in which the action Right scores a point,
+// and which is over after 100 frames.
+type counter struct {
+	frames  int
+	actions []int
+}
+
+func (c *counter) Reset() error {
+	c.frames = 0
+	c.actions = nil
+	return nil
+}
+
+func (c *counter) Act(action int) (float64, error) {
+	c.frames++
+	c.actions = append(c.actions, action)
+	if action == Right {
+		return 1.0, nil
+	}
+	return 0.0, nil
+}
+
+func (c *counter) Screen() Frame {
+	return Frame{Width: 4, Height: 2, Pixels: []uint8{0, 14, 0, 14, 2, 2, 4, 4}}
+}
+
+func (c *counter) GameOver() bool {
+	return c.frames >= 100
+}
+
+func TestDownsample(t *testing.T) {
+	cells := (&counter{}).Screen().Downsample(2, 1)
+	// each half averages 2 pixels of each row.
+	want := []float64{(0.0 + 1.0 + 2.0/14.0 + 2.0/14.0) / 4.0,
+		(0.0 + 1.0 + 4.0/14.0 + 4.0/14.0) / 4.0}
+	for i := range want {
+		if math.Abs(cells[i]-want[i]) > 1e-9 {
+			t.Errorf("invalid cells: %v (expected %v)", cells, want)
+		}
+	}
+}
+
+func TestPlay(t *testing.T) {
+	emulator := &counter{}
+	a := NewAdapter(emulator, 2, 1, []int{Noop, Right})
+	a.MaxFrames = 50
+
+	// a network that always prefers the second action, i.e., Right.
+	g := neat.NewGenome(0, a.NumInputs(), a.NumOutputs(), 0.0)
+	g.ConnGenes = append(g.ConnGenes, neat.NewConnGene(0, a.NumInputs()+1, 1.0))
+	nn := neat.NewNeuralNetwork(g)
+
+	score, err := a.Play(nn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(emulator.actions) != 50 {
+		t.Errorf("invalid number of frames: %d", len(emulator.actions))
+	}
+	// only the first frames may stick to Noop, after which Right sticks.
+	if score < 40.0 || score > 50.0 {
+		t.Errorf("invalid score: %f", score)
+	}
+
+	a.StickyRate = 0.0
+	a.MaxFrames = 1000
+	if score := a.Evaluation()(nn); score != 100.0 {
+		t.Errorf("invalid score without sticky actions: %f", score)
+	}
+
+	a.Actions = nil
+	if _, err := a.FallibleEvaluation()(nn); err == nil {
+		t.Error("failure of an episode is not returned")
+	}
+}
+
+func TestSubstrate(t *testing.T) {
+	a := NewAdapter(&counter{}, 8, 10, []int{Noop, Fire, Right, Left})
+	s := a.Substrate()
+	if err := s.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if s.NumInputs() != a.NumInputs() || s.NumOutputs() != a.NumOutputs() {
+		t.Errorf("invalid substrate: %d inputs, %d outputs", s.NumInputs(),
+			s.NumOutputs())
+	}
+	if config := hyperneat.NewDecoder(s).NewConfig(); config.NumOutputs != 1 {
+		t.Errorf("invalid configuration of CPPNs: %d outputs", config.NumOutputs)
+	}
+}
+
+func TestFIFO(t *testing.T) {
+	// a screen of 4x2 pixels, raw and run-length encoded.
+	messages := strings.Join([]string{
+		"4-2",
+		"000E000E02020404:0,0:",
+		"000E000E02020404:0,3:",
+		"0001" + "0E01" + "0001" + "0E01" + "0202" + "0402" + ":1,1:",
+	}, "\n") + "\n"
+	var actions bytes.Buffer
+	f, err := NewFIFO(strings.NewReader(messages), &actions)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reward, err := f.Act(Right); err != nil || reward != 3.0 || f.GameOver() {
+		t.Errorf("invalid frame: %f, %v", reward, err)
+	}
+	if reward, err := f.Act(Fire); err != nil || reward != 1.0 || !f.GameOver() {
+		t.Errorf("invalid frame: %f, %v", reward, err)
+	}
+	if screen := f.Screen(); screen.Pixels[1] != 14 || screen.Pixels[7] != 4 {
+		t.Errorf("invalid screen: %v", screen.Pixels)
+	}
+	if got := actions.String(); got != "1,0,0,1\n3,18\n1,18\n" {
+		t.Errorf("invalid actions sent: %q", got)
+	}
+	if _, err := f.Act(Noop); err == nil {
+		t.Error("end of messages is not an error")
+	}
+}
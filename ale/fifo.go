@@ -0,0 +1,207 @@
+// fifo.go implementation of the FIFO interface of the Arcade Learning
+// Environment.
+//
+// Copyright (C) 2017  Jin Yeom
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package ale
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// noopB is the action of the player B, which is always idle.
+const noopB = 18
+
+// FIFO is an emulator connected via the FIFO interface of ALE, i.e., the
+// process started with "-game_controller fifo", which sends the screen and
+// the reward of each frame, and receives the actions.
+type FIFO struct {
+	r        *bufio.Reader // reader of the messages of ALE
+	w        io.Writer     // writer of the actions
+	screen   Frame         // current screen
+	terminal bool          // true if the game is over
+	cmd      *exec.Cmd     // process of ALE, if started by StartFIFO
+}
+
+// NewFIFO returns a new emulator connected via the FIFO interface of ALE,
+// given the reader of its output and the writer of its input; it completes
+// the handshake, requesting the screen and the reward of each frame.
+func NewFIFO(r io.Reader, w io.Writer) (*FIFO, error) {
+	f := &FIFO{r: bufio.NewReader(r), w: w}
+	line, err := f.readLine()
+	if err != nil {
+		return nil, err
+	}
+	dims := strings.Split(line, "-")
+	if len(dims) != 2 {
+		return nil, fmt.Errorf("ale: invalid handshake %q", line)
+	}
+	if f.screen.Width, err = strconv.Atoi(dims[0]); err != nil {
+		return nil, fmt.Errorf("ale: invalid handshake %q", line)
+	}
+	if f.screen.Height, err = strconv.Atoi(dims[1]); err != nil {
+		return nil, fmt.Errorf("ale: invalid handshake %q", line)
+	}
+	f.screen.Pixels = make([]uint8, f.screen.Width*f.screen.Height)
+
+	// send the screen and the RL data, but not the RAM, without frame skip.
+	if _, err := fmt.Fprint(f.w, "1,0,0,1\n"); err != nil {
+		return nil, err
+	}
+	if _, err := f.read(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// StartFIFO starts ALE of the argument path with the argument arguments, e.g.,
+// the path of a ROM, and returns the emulator connected to it.
+func StartFIFO(path string, args ...string) (*FIFO, error) {
+	cmd := exec.Command(path, append([]string{"-game_controller", "fifo"},
+		args...)...)
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	f, err := NewFIFO(stdout, stdin)
+	if err != nil {
+		cmd.Process.Kill()
+		cmd.Wait()
+		return nil, err
+	}
+	f.cmd = cmd
+	return f, nil
+}
+
+// Close stops the process of ALE if it was started by StartFIFO.
+func (f *FIFO) Close() error {
+	if f.cmd == nil {
+		return nil
+	}
+	f.cmd.Process.Kill()
+	f.cmd.Wait()
+	f.cmd = nil
+	return nil
+}
+
+// Reset resets the game.
+func (f *FIFO) Reset() error {
+	_, err := f.Act(ResetAction)
+	f.terminal = false
+	return err
+}
+
+// Act advances the game by a frame with the argument action, and returns the
+// reward of the frame.
+func (f *FIFO) Act(action int) (float64, error) {
+	if _, err := fmt.Fprintf(f.w, "%d,%d\n", action, noopB); err != nil {
+		return 0.0, err
+	}
+	return f.read()
+}
+
+// Screen returns the current screen.
+func (f *FIFO) Screen() Frame {
+	return f.screen
+}
+
+// GameOver returns true if the game is over.
+func (f *FIFO) GameOver() bool {
+	return f.terminal
+}
+
+// readLine reads a line of a message of ALE, without the newline.
+func (f *FIFO) readLine() (string, error) {
+	line, err := f.r.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// read reads a message of a frame, i.e., "<screen>:<terminal>,<reward>:",
+// updates the screen and the state, and returns the reward.
+func (f *FIFO) read() (float64, error) {
+	line, err := f.readLine()
+	if err != nil {
+		return 0.0, err
+	}
+	parts := strings.Split(line, ":")
+	if len(parts) < 2 {
+		return 0.0, fmt.Errorf("ale: invalid message of %d bytes", len(line))
+	}
+	if err := f.decodeScreen(parts[0]); err != nil {
+		return 0.0, err
+	}
+	rl := strings.Split(parts[1], ",")
+	if len(rl) != 2 {
+		return 0.0, fmt.Errorf("ale: invalid RL data %q", parts[1])
+	}
+	f.terminal = rl[0] == "1"
+	reward, err := strconv.ParseFloat(rl[1], 64)
+	if err != nil {
+		return 0.0, fmt.Errorf("ale: invalid RL data %q", parts[1])
+	}
+	return reward, nil
+}
+
+// decodeScreen decodes the screen of a message, in which each pixel is 2 hex
+// digits, or, if run-length encoded, each run is 2 hex digits of the pixel
+// followed by 2 hex digits of its length.
+func (f *FIFO) decodeScreen(s string) error {
+	n := len(f.screen.Pixels)
+	runLength := len(s) != 2*n
+	if len(s)%2 != 0 || (runLength && len(s)%4 != 0) {
+		return fmt.Errorf("ale: invalid screen of %d bytes", len(s))
+	}
+	i := 0
+	for j := 0; j < len(s); j += 2 {
+		p, err := strconv.ParseUint(s[j:j+2], 16, 8)
+		if err != nil {
+			return fmt.Errorf("ale: invalid screen: %v", err)
+		}
+		length := uint64(1)
+		if runLength {
+			j += 2
+			if length, err = strconv.ParseUint(s[j:j+2], 16, 8); err != nil {
+				return fmt.Errorf("ale: invalid screen: %v", err)
+			}
+		}
+		for ; length > 0; length-- {
+			if i >= n {
+				return fmt.Errorf("ale: screen exceeds %d pixels", n)
+			}
+			f.screen.Pixels[i] = uint8(p)
+			i++
+		}
+	}
+	if i != n {
+		return fmt.Errorf("ale: screen of %d pixels (expected %d)", i, n)
+	}
+	return nil
+}
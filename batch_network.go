@@ -0,0 +1,264 @@
+// batch_network.go implementation of batched evaluation of feedforward
+// networks with matrix products.
+//
+// Copyright (C) 2017  Jin Yeom
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package neat
+
+import (
+	"fmt"
+	"math"
+)
+
+// MatMulFunc is a type of function that computes the matrix product c = a * b,
+// where a is m by k, b is k by n, and c is m by n, all in row-major order. It
+// is the backend of BatchNetwork, which can be replaced, e.g., with an
+// optimized BLAS (gonum's blas64.Gemm) or a GPU binding (cuBLAS).
+type MatMulFunc func(m, n, k int, a, b, c []float64)
+
+// MatMul is the default MatMulFunc in pure Go. Since matrices of layers of an
+// evolved network are mostly zeros, zero elements of a are skipped.
+func MatMul(m, n, k int, a, b, c []float64) {
+	for i := 0; i < m; i++ {
+		ci := c[i*n : (i+1)*n]
+		for j := range ci {
+			ci[j] = 0.0
+		}
+		for p, aip := range a[i*k : (i+1)*k] {
+			if aip == 0.0 {
+				continue
+			}
+			for j, bpj := range b[p*n : (p+1)*n] {
+				ci[j] += aip * bpj
+			}
+		}
+	}
+}
+
+// batchLayer is a layer of a BatchNetwork, i.e., neurons of the same depth,
+// whose signals only depend on neurons of lower depths.
+type batchLayer struct {
+	sources     []int             // rows of the neurons of the synapses
+	targets     []int             // rows of the neurons of this layer
+	weights     []float64         // targets by sources matrix of weights
+	activations []*ActivationFunc // activation function of each target
+}
+
+// BatchNetwork is a feedforward network that is layerized into matrices, such
+// that a whole dataset, e.g., of a supervised task, is propagated at once by a
+// matrix product per layer, instead of recursively per sample. It is decoded
+// from a NeuralNetwork with NewBatchNetwork, and computes the same outputs up
+// to rounding errors. A BatchNetwork is not safe for concurrent use, since its
+// buffers are reused between calls.
+type BatchNetwork struct {
+	MatMul MatMulFunc // matrix product of each layer (MatMul by default)
+
+	numRows    int           // number of neurons that the outputs depend on
+	inputRows  []int         // row of each input neuron (-1 if unused)
+	outputRows []int         // row of each output neuron
+	layers     []*batchLayer // layers in topological order
+
+	signals []float64 // buffer of signals of neurons by samples
+	sources []float64 // buffer of signals of the sources of a layer
+	targets []float64 // buffer of signals of the targets of a layer
+}
+
+// NewBatchNetwork returns a new instance of BatchNetwork decoded from the
+// argument network; it returns an error if the network is recurrent.
+func NewBatchNetwork(n *NeuralNetwork) (*BatchNetwork, error) {
+	nodes, outputs, err := n.graph()
+	if err != nil {
+		return nil, err
+	}
+
+	// each neuron is a row of signals; its depth is 0 if it is an input neuron,
+	// or has no synapses (whose signal is 0), or 1 more than its deepest source.
+	rows := make(map[int]int, len(nodes))
+	depths := make([]int, len(nodes))
+	numLayers := 0
+	b := &BatchNetwork{
+		MatMul:     MatMul,
+		numRows:    len(nodes),
+		inputRows:  make([]int, len(n.inputNeurons)),
+		outputRows: make([]int, len(outputs)),
+	}
+	for i := range b.inputRows {
+		b.inputRows[i] = -1
+	}
+	for row, node := range nodes {
+		rows[node.id] = row
+		if node.input >= 0 {
+			b.inputRows[node.input] = row
+		}
+		for _, source := range node.sources {
+			if depth := depths[rows[source]] + 1; depth > depths[row] {
+				depths[row] = depth
+			}
+		}
+		if depths[row] > numLayers {
+			numLayers = depths[row]
+		}
+	}
+	for i, id := range outputs {
+		b.outputRows[i] = rows[id]
+	}
+
+	// neurons of each depth form a layer, whose sources are the union of their
+	// synapses.
+	b.layers = make([]*batchLayer, numLayers)
+	for i := range b.layers {
+		b.layers[i] = &batchLayer{}
+	}
+	for row, node := range nodes {
+		if depths[row] == 0 {
+			continue
+		}
+		layer := b.layers[depths[row]-1]
+		layer.targets = append(layer.targets, row)
+		layer.activations = append(layer.activations, node.activation)
+	}
+	for _, layer := range b.layers {
+		columns := make(map[int]int) // column of each source row
+		for _, row := range layer.targets {
+			for _, source := range nodes[row].sources {
+				if _, ok := columns[rows[source]]; !ok {
+					columns[rows[source]] = len(layer.sources)
+					layer.sources = append(layer.sources, rows[source])
+				}
+			}
+		}
+		layer.weights = make([]float64, len(layer.targets)*len(layer.sources))
+		for i, row := range layer.targets {
+			node := nodes[row]
+			for j, source := range node.sources {
+				k := i*len(layer.sources) + columns[rows[source]]
+				layer.weights[k] += node.weights[j]
+			}
+		}
+	}
+	return b, nil
+}
+
+// NumLayers returns the number of layers of this network, excluding the input
+// layer.
+func (b *BatchNetwork) NumLayers() int {
+	return len(b.layers)
+}
+
+// FeedForwardBatch propagates each of the argument samples of inputs through
+// the network at once, and returns the outputs of each sample in order.
+func (b *BatchNetwork) FeedForwardBatch(inputs [][]float64) ([][]float64,
+	error) {
+	numSamples := len(inputs)
+	for i, sample := range inputs {
+		if len(sample) != len(b.inputRows) {
+			return nil, fmt.Errorf("Invalid number of inputs of sample %d: %d != %d",
+				i, len(sample), len(b.inputRows))
+		}
+	}
+
+	// signals of neurons without synapses are 0, and never written.
+	if size := b.numRows * numSamples; cap(b.signals) < size {
+		b.signals = make([]float64, size)
+	} else {
+		b.signals = b.signals[:size]
+		for i := range b.signals {
+			b.signals[i] = 0.0
+		}
+	}
+	for i, row := range b.inputRows {
+		if row < 0 {
+			continue
+		}
+		signals := b.signals[row*numSamples : (row+1)*numSamples]
+		for s, sample := range inputs {
+			signals[s] = sample[i]
+		}
+	}
+
+	for _, layer := range b.layers {
+		numSources, numTargets := len(layer.sources), len(layer.targets)
+		b.sources = grow(b.sources, numSources*numSamples)
+		b.targets = grow(b.targets, numTargets*numSamples)
+		for j, row := range layer.sources {
+			copy(b.sources[j*numSamples:(j+1)*numSamples],
+				b.signals[row*numSamples:(row+1)*numSamples])
+		}
+		b.MatMul(numTargets, numSamples, numSources, layer.weights, b.sources,
+			b.targets)
+		for i, row := range layer.targets {
+			signals := b.signals[row*numSamples : (row+1)*numSamples]
+			copy(signals, b.targets[i*numSamples:(i+1)*numSamples])
+			if afunc := layer.activations[i]; afunc != nil {
+				for s, x := range signals {
+					signals[s] = afunc.Fn(x)
+				}
+			}
+		}
+	}
+
+	outputs := make([][]float64, numSamples)
+	values := make([]float64, numSamples*len(b.outputRows))
+	for s := range outputs {
+		outputs[s] = values[s*len(b.outputRows) : (s+1)*len(b.outputRows)]
+		for i, row := range b.outputRows {
+			outputs[s][i] = b.signals[row*numSamples+s]
+		}
+	}
+	return outputs, nil
+}
+
+// Predict is FeedForwardBatch of a single sample, which implements Predictor.
+func (b *BatchNetwork) Predict(inputs []float64) ([]float64, error) {
+	outputs, err := b.FeedForwardBatch([][]float64{inputs})
+	if err != nil {
+		return nil, err
+	}
+	return outputs[0], nil
+}
+
+// grow returns the argument buffer resized to the argument size, which is
+// reallocated only if its capacity is insufficient.
+func grow(buf []float64, size int) []float64 {
+	if cap(buf) < size {
+		return make([]float64, size)
+	}
+	return buf[:size]
+}
+
+// BatchEvaluationFunc is a type of function that evaluates a batched network,
+// e.g., on a whole dataset at once, and returns its fitness score.
+type BatchEvaluationFunc func(b *BatchNetwork) float64
+
+// BatchEvaluation returns an evaluation function that decodes each network
+// into a BatchNetwork, whose matrix products are computed with the argument
+// function (MatMul if nil), and evaluates it with the argument batched
+// evaluation function. Since recurrent networks cannot be batched, they result
+// in NaN, i.e., a failed evaluation; disable Config.SelfConnections to evolve
+// feedforward networks only.
+func BatchEvaluation(evaluate BatchEvaluationFunc,
+	matMul MatMulFunc) EvaluationFunc {
+	return func(n *NeuralNetwork) float64 {
+		b, err := NewBatchNetwork(n)
+		if err != nil {
+			return math.NaN()
+		}
+		if matMul != nil {
+			b.MatMul = matMul
+		}
+		return evaluate(b)
+	}
+}
@@ -0,0 +1,76 @@
+package neat
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestMatMul(t *testing.T) {
+	a := []float64{1, 2, 0, 0, 1, 3} // 2 by 3
+	b := []float64{1, 0, 0, 1, 2, 2} // 3 by 2
+	c := []float64{9, 9, 9, 9}       // 2 by 2, overwritten
+	want := []float64{1, 2, 6, 7}
+	MatMul(2, 2, 3, a, b, c)
+	for i := range want {
+		if c[i] != want[i] {
+			t.Fatalf("invalid product: %v != %v", c, want)
+		}
+	}
+}
+
+func TestBatchNetwork(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	samples := make([][]float64, 50)
+	for i := range samples {
+		samples[i] = []float64{rng.NormFloat64(), rng.NormFloat64(),
+			rng.NormFloat64()}
+	}
+
+	for trial := 0; trial < 20; trial++ {
+		g := newFCGenome(0, 3, 2, 0.0, rng)
+		for i := 0; i < 10; i++ {
+			g.mutateAddNode(0.5, ActivationSet["tanh"], 0, rng)
+			g.mutateAddConn(0.5, 0, false, rng)
+		}
+		// a hidden neuron without synapses outputs 0.
+		g.NodeGenes = append(g.NodeGenes,
+			NewNodeGene(len(g.NodeGenes), "hidden", ActivationSet["sigmoid"]))
+		g.ConnGenes = append(g.ConnGenes,
+			NewConnGene(len(g.NodeGenes)-1, 3, 1.0))
+
+		nn := NewNeuralNetwork(g)
+		b, err := NewBatchNetwork(nn)
+		if err != nil {
+			t.Fatal(err)
+		}
+		outputs, err := b.FeedForwardBatch(samples)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for s, sample := range samples {
+			want, _ := nn.FeedForward(sample)
+			for i := range want {
+				if math.Abs(outputs[s][i]-want[i]) > 1e-9 {
+					t.Fatalf("invalid output %d of sample %d: %f != %f",
+						i, s, outputs[s][i], want[i])
+				}
+			}
+		}
+	}
+
+	if _, err := NewBatchNetwork(NewNeuralNetwork(NewGenome(0, 2, 1, 0.0))); err != nil {
+		t.Errorf("network without synapses is rejected: %v", err)
+	}
+
+	g := NewFCGenome(0, 2, 1, 0.0)
+	g.ConnGenes = append(g.ConnGenes, NewConnGene(2, 2, 1.0))
+	if _, err := NewBatchNetwork(NewNeuralNetwork(g)); err == nil {
+		t.Error("recurrent network is not rejected")
+	}
+	if fitness := BatchEvaluation(func(b *BatchNetwork) float64 {
+		return 1.0
+	}, nil)(NewNeuralNetwork(g)); !math.IsNaN(fitness) {
+		t.Errorf("recurrent network is evaluated: %f", fitness)
+	}
+}
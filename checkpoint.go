@@ -0,0 +1,147 @@
+// checkpoint.go implementation of checkpoints of the state of evolution.
+//
+// Copyright (C) 2017  Jin Yeom
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package neat
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+)
+
+// Checkpoint is a snapshot of the state of evolution, from which a run can be
+// resumed (see NEAT.Restore). It is written either in JSON, which is readable,
+// or in gob, which is much faster and smaller for large populations. The state
+// of the random number generator is not included.
+type Checkpoint struct {
+	Config        *Config    `json:"config"`        // configuration of the run
+	Generation    int        `json:"generation"`    // index of the next generation
+	Population    []*Genome  `json:"population"`    // population of genomes
+	Species       []*Species `json:"species"`       // species without members
+	Best          *Genome    `json:"best"`          // best genome so far
+	NextGenomeID  int        `json:"nextGenomeID"`  // ID of the next new genome
+	NextSpeciesID int        `json:"nextSpeciesID"` // ID of the next new species
+	Innovations   [][2]int   `json:"innovations"`   // connected pairs of nodes
+}
+
+// Checkpoint returns a checkpoint of the current state of evolution, which
+// shares its genomes with this NEAT; it should be written before the evolution
+// continues.
+func (n *NEAT) Checkpoint() *Checkpoint {
+	// members are restored from the species IDs of genomes, since they would
+	// be written as copies of the population.
+	species := make([]*Species, len(n.Species))
+	for i, s := range n.Species {
+		species[i] = &Species{
+			ID:             s.ID,
+			Age:            s.Age,
+			Stagnation:     s.Stagnation,
+			Representative: s.Representative,
+			Best:           s.Best,
+			BestFitness:    s.BestFitness,
+		}
+	}
+
+	innovations := make([][2]int, 0, len(n.innovations))
+	for pair := range n.innovations {
+		innovations = append(innovations, pair)
+	}
+
+	return &Checkpoint{
+		Config:        n.Config,
+		Generation:    n.Generation,
+		Population:    n.Population,
+		Species:       species,
+		Best:          n.Best,
+		NextGenomeID:  n.lastGenomeID + 1,
+		NextSpeciesID: n.lastSpeciesID + 1,
+		Innovations:   innovations,
+	}
+}
+
+// WriteJSON writes this checkpoint to the argument writer in JSON.
+func (c *Checkpoint) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "\t")
+	return encoder.Encode(c)
+}
+
+// ReadCheckpointJSON reads a checkpoint that was written with
+// Checkpoint.WriteJSON from the argument reader.
+func ReadCheckpointJSON(r io.Reader) (*Checkpoint, error) {
+	c := &Checkpoint{}
+	if err := json.NewDecoder(r).Decode(c); err != nil {
+		return nil, err
+	}
+	return c, c.restoreGenomes()
+}
+
+// restoreGenomes validates the genomes of this decoded checkpoint, and restores
+// their activation functions from ActivationSet by their names.
+func (c *Checkpoint) restoreGenomes() error {
+	if len(c.Population) == 0 || c.Best == nil {
+		return errors.New("neat: checkpoint without a population")
+	}
+	genomes := append([]*Genome{c.Best}, c.Population...)
+	for _, s := range c.Species {
+		if s.Representative != nil {
+			genomes = append(genomes, s.Representative)
+		}
+		if s.Best != nil {
+			genomes = append(genomes, s.Best)
+		}
+	}
+	for _, genome := range genomes {
+		if err := RestoreGenome(genome); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Restore restores the state of evolution from the argument checkpoint, such
+// that Run resumes from its generation; the configuration of this NEAT is
+// kept, thus it should be created with that of the checkpoint. The genomes of
+// the population are evaluated again in the next generation.
+func (n *NEAT) Restore(c *Checkpoint) error {
+	if len(c.Population) == 0 || c.Best == nil {
+		return errors.New("neat: checkpoint without a population")
+	}
+
+	bySpecies := make(map[int]*Species)
+	for _, s := range c.Species {
+		s.Members = []*Genome{}
+		bySpecies[s.ID] = s
+	}
+	for _, genome := range c.Population {
+		if s, ok := bySpecies[genome.SpeciesID]; ok {
+			s.Members = append(s.Members, genome)
+		}
+	}
+
+	n.innovations = make(map[[2]int]bool, len(c.Innovations))
+	for _, pair := range c.Innovations {
+		n.innovations[pair] = true
+	}
+	n.Generation = c.Generation
+	n.Population = c.Population
+	n.Species = c.Species
+	n.Best = c.Best
+	n.reserveIDs(c.NextGenomeID-1, c.NextSpeciesID-1)
+	n.publish()
+	return nil
+}
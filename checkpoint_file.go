@@ -0,0 +1,159 @@
+// checkpoint_file.go implementation of checkpoint and population files.
+//
+// Copyright (C) 2017  Jin Yeom
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+//go:build !js
+
+package neat
+
+import (
+	"compress/gzip"
+	"encoding/gob"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// WriteGob writes this checkpoint to the argument writer in gob.
+func (c *Checkpoint) WriteGob(w io.Writer) error {
+	return gob.NewEncoder(w).Encode(c)
+}
+
+// ReadCheckpointGob reads a checkpoint that was written with
+// Checkpoint.WriteGob from the argument reader.
+func ReadCheckpointGob(r io.Reader) (*Checkpoint, error) {
+	c := &Checkpoint{}
+	if err := gob.NewDecoder(r).Decode(c); err != nil {
+		return nil, err
+	}
+	return c, c.restoreGenomes()
+}
+
+// Save writes this checkpoint to a file of the argument name, whose extension
+// determines its format, i.e., ".json" or ".gob", optionally followed by ".gz"
+// for gzip compression, e.g., "checkpoint.gob.gz".
+func (c *Checkpoint) Save(filename string) error {
+	format, _ := fileFormat(filename)
+	var write func(w io.Writer) error
+	switch format {
+	case ".json":
+		write = c.WriteJSON
+	case ".gob":
+		write = c.WriteGob
+	default:
+		return fmt.Errorf("neat: unknown checkpoint format %q", format)
+	}
+	return writeFile(filename, write)
+}
+
+// LoadCheckpoint reads a checkpoint from a file of the argument name that was
+// written with Checkpoint.Save.
+func LoadCheckpoint(filename string) (*Checkpoint, error) {
+	format, _ := fileFormat(filename)
+	var read func(r io.Reader) (*Checkpoint, error)
+	switch format {
+	case ".json":
+		read = ReadCheckpointJSON
+	case ".gob":
+		read = ReadCheckpointGob
+	default:
+		return nil, fmt.Errorf("neat: unknown checkpoint format %q", format)
+	}
+
+	var c *Checkpoint
+	err := readFile(filename, func(r io.Reader) (err error) {
+		c, err = read(r)
+		return err
+	})
+	return c, err
+}
+
+// SavePopulation writes the argument genomes to a JSON file of the argument
+// name as ExportPopulationJSON, which is compressed with gzip if the name ends
+// with ".gz", e.g., "population.json.gz".
+func SavePopulation(filename string, genomes []*Genome) error {
+	return writeFile(filename, func(w io.Writer) error {
+		return ExportPopulationJSON(w, genomes)
+	})
+}
+
+// LoadPopulation reads genomes from a file of the argument name that was
+// written with SavePopulation.
+func LoadPopulation(filename string) ([]*Genome, error) {
+	var genomes []*Genome
+	err := readFile(filename, func(r io.Reader) (err error) {
+		genomes, err = ImportPopulationJSON(r)
+		return err
+	})
+	return genomes, err
+}
+
+// fileFormat returns the extension of the argument file name that precedes
+// ".gz", if any, and true if the file is compressed with gzip.
+func fileFormat(filename string) (string, bool) {
+	compressed := strings.HasSuffix(filename, ".gz")
+	if compressed {
+		filename = strings.TrimSuffix(filename, ".gz")
+	}
+	return filepath.Ext(filename), compressed
+}
+
+// writeFile creates a file of the argument name, which is compressed with gzip
+// if the name ends with ".gz", and writes to it with the argument function.
+func writeFile(filename string, write func(w io.Writer) error) error {
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if _, compressed := fileFormat(filename); !compressed {
+		if err := write(f); err != nil {
+			return err
+		}
+		return f.Close()
+	}
+	zw := gzip.NewWriter(f)
+	if err := write(zw); err != nil {
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+// readFile opens a file of the argument name, which is decompressed with gzip
+// if the name ends with ".gz", and reads from it with the argument function.
+func readFile(filename string, read func(r io.Reader) error) error {
+	f, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if _, compressed := fileFormat(filename); !compressed {
+		return read(f)
+	}
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		return err
+	}
+	defer zr.Close()
+	return read(zr)
+}
@@ -0,0 +1,121 @@
+//go:build !js
+
+package neat
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCheckpoint(t *testing.T) {
+	config := NewDefaultConfig(3, 1)
+	config.NumGenerations = 3
+	config.PopulationSize = 50
+
+	n := New(config, XORTest(), WithSeed(0))
+	n.Run()
+
+	formats := []struct {
+		name  string
+		write func(c *Checkpoint, w io.Writer) error
+		read  func(r io.Reader) (*Checkpoint, error)
+	}{
+		{"json", (*Checkpoint).WriteJSON, ReadCheckpointJSON},
+		{"gob", (*Checkpoint).WriteGob, ReadCheckpointGob},
+	}
+	sizes := make(map[string]int)
+	for _, format := range formats {
+		buf := &bytes.Buffer{}
+		if err := format.write(n.Checkpoint(), buf); err != nil {
+			t.Fatalf("%s: %v", format.name, err)
+		}
+		sizes[format.name] = buf.Len()
+
+		c, err := format.read(buf)
+		if err != nil {
+			t.Fatalf("%s: %v", format.name, err)
+		}
+		if c.Generation != n.Generation || len(c.Population) != len(n.Population) ||
+			len(c.Innovations) != len(n.innovations) {
+			t.Errorf("%s: checkpoint is not read as written", format.name)
+		}
+
+		resumed := *config
+		resumed.NumGenerations = 5
+		m := New(&resumed, XORTest(), WithSeed(1))
+		if err := m.Restore(c); err != nil {
+			t.Fatalf("%s: %v", format.name, err)
+		}
+		members := 0
+		for _, s := range m.Species {
+			members += len(s.Members)
+		}
+		if members != len(m.Population) {
+			t.Errorf("%s: invalid number of species members: %d",
+				format.name, members)
+		}
+		m.Run()
+		if m.Err() != nil || m.Generation != resumed.NumGenerations {
+			t.Errorf("%s: run is not resumed: generation %d, %v",
+				format.name, m.Generation, m.Err())
+		}
+	}
+	if sizes["gob"] >= sizes["json"] {
+		t.Errorf("gob is not smaller than JSON: %v", sizes)
+	}
+
+	if _, err := ReadCheckpointGob(&bytes.Buffer{}); err == nil {
+		t.Error("empty checkpoint is not rejected")
+	}
+}
+
+func TestCheckpointFiles(t *testing.T) {
+	config := NewDefaultConfig(3, 1)
+	config.NumGenerations = 2
+	config.PopulationSize = 50
+
+	n := New(config, XORTest(), WithSeed(0))
+	n.Run()
+
+	dir := t.TempDir()
+	sizes := make(map[string]int64)
+	for _, name := range []string{"c.json", "c.json.gz", "c.gob", "c.gob.gz"} {
+		filename := filepath.Join(dir, name)
+		if err := n.Checkpoint().Save(filename); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		c, err := LoadCheckpoint(filename)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if c.Generation != n.Generation || len(c.Population) != len(n.Population) {
+			t.Errorf("%s: checkpoint is not loaded as saved", name)
+		}
+		info, err := os.Stat(filename)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sizes[name] = info.Size()
+	}
+	if sizes["c.json.gz"] >= sizes["c.json"] || sizes["c.gob.gz"] >= sizes["c.gob"] {
+		t.Errorf("compressed checkpoints are not smaller: %v", sizes)
+	}
+	if err := n.Checkpoint().Save(filepath.Join(dir, "c.txt")); err == nil {
+		t.Error("unknown format is not rejected")
+	}
+
+	filename := filepath.Join(dir, "population.json.gz")
+	if err := SavePopulation(filename, n.Population); err != nil {
+		t.Fatal(err)
+	}
+	genomes, err := LoadPopulation(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(genomes) != len(n.Population) {
+		t.Errorf("invalid number of genomes: %d", len(genomes))
+	}
+}
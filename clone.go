@@ -0,0 +1,132 @@
+// clone.go implementation of branching of a running experiment.
+//
+// Copyright (C) 2017  Jin Yeom
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package neat
+
+import (
+	"math/rand"
+)
+
+// Clone returns a deep copy of the state of the evolution, e.g., its
+// population, species, statistics, and genealogy, such that the experiment can
+// be branched in the middle of a run, e.g., to continue one branch with
+// different rates of mutation in clone.Config, without a checkpoint. Both
+// branches evolve independently of each other afterwards.
+//
+// Functions and interfaces, e.g., the evaluation function, the mutation
+// operators, the callbacks, and the logger, are shared, as well as the
+// registry of activation functions; so is n.IDs, unless it is the default
+// MonotonicIDGenerator, which is copied. Since the state of a random number
+// generator cannot be copied, the generator of the clone is seeded from n.Rand.
+func (n *NEAT) Clone() *NEAT {
+	c := &NEAT{
+		Config:      n.Config.Copy(),
+		Activations: n.Activations,
+		Evaluation:  n.Evaluation,
+		Comparison:  n.Comparison,
+		Selection:   n.Selection,
+		Speciation:  n.Speciation,
+		Distance:    n.Distance,
+		Reproducer:  n.Reproducer,
+		Callbacks:   append([]Callback{}, n.Callbacks...),
+		Mutator:     n.Mutator,
+		Mutators:    append([]Mutator{}, n.Mutators...),
+		Rand:        rand.New(rand.NewSource(n.Rand.Int63())),
+		Logger:      n.Logger,
+		Statistics:  n.Statistics.Copy(),
+		Generation:  n.Generation,
+
+		WorkerRand: n.WorkerRand,
+
+		Validation:     n.Validation,
+		ValidationTopK: n.ValidationTopK,
+
+		PopulationEvaluation: n.PopulationEvaluation,
+		FallibleEvaluation:   n.FallibleEvaluation,
+		ContextEvaluation:    n.ContextEvaluation,
+		ResultEvaluation:     n.ResultEvaluation,
+
+		IDs: n.IDs,
+
+		lastGenomeID:  n.lastGenomeID,
+		lastSpeciesID: n.lastSpeciesID,
+		seed:          n.seed,
+
+		err: n.err,
+	}
+	if ids, ok := n.IDs.(*MonotonicIDGenerator); ok {
+		c.IDs = ids.Copy()
+	}
+	if n.Curriculum != nil {
+		curriculum := *n.Curriculum
+		c.Curriculum = &curriculum
+	}
+	if n.Genealogy != nil {
+		c.Genealogy = n.Genealogy.Copy()
+	}
+
+	// a genome may be referred to from multiple places, e.g., both by the
+	// population and by a species; it is copied once, such that it is still
+	// shared in the clone.
+	copies := make(map[*Genome]*Genome)
+	copyOf := func(g *Genome) *Genome {
+		if g == nil {
+			return nil
+		}
+		if copied, ok := copies[g]; ok {
+			return copied
+		}
+		copied := g.Copy()
+		copies[g] = copied
+		return copied
+	}
+
+	c.Population = make([]*Genome, len(n.Population))
+	for i, genome := range n.Population {
+		c.Population[i] = copyOf(genome)
+	}
+	c.Species = make([]*Species, len(n.Species))
+	for i, s := range n.Species {
+		members := make([]*Genome, len(s.Members))
+		for j, genome := range s.Members {
+			members[j] = copyOf(genome)
+		}
+		c.Species[i] = &Species{
+			ID:             s.ID,
+			Age:            s.Age,
+			Stagnation:     s.Stagnation,
+			Representative: copyOf(s.Representative),
+			Best:           copyOf(s.Best),
+			BestFitness:    s.BestFitness,
+			Members:        members,
+		}
+	}
+	c.Best = copyOf(n.Best)
+	if n.Champions != nil {
+		c.Champions = make([]*Genome, len(n.Champions))
+		for i, genome := range n.Champions {
+			c.Champions[i] = copyOf(genome)
+		}
+	}
+
+	c.innovations = make(map[[2]int]bool, len(n.innovations))
+	for pair := range n.innovations {
+		c.innovations[pair] = true
+	}
+	c.publish()
+	return c
+}
@@ -0,0 +1,80 @@
+package neat
+
+import (
+	"testing"
+)
+
+func TestNEATClone(t *testing.T) {
+	config := NewDefaultConfig(3, 1)
+	config.NumGenerations = 6
+	config.PopulationSize = 20
+	config.TrackLineage = true
+
+	n := New(config, XORTest(), WithSeed(0))
+	for i := 0; i < 3; i++ {
+		n.Step()
+	}
+
+	c := n.Clone()
+	if c.Generation != n.Generation || len(c.Population) != len(n.Population) ||
+		len(c.Species) != len(n.Species) {
+		t.Fatalf("state is not cloned: generation %d, %d genomes, %d species",
+			c.Generation, len(c.Population), len(c.Species))
+	}
+	for i, genome := range c.Population {
+		if genome == n.Population[i] || genome.ID != n.Population[i].ID {
+			t.Fatalf("genome %d is not copied", i)
+		}
+	}
+
+	// genomes shared by the population and the species are still shared.
+	inPopulation := make(map[*Genome]bool)
+	for _, genome := range c.Population {
+		inPopulation[genome] = true
+	}
+	shared := 0
+	for _, s := range c.Species {
+		for _, genome := range s.Members {
+			if inPopulation[genome] {
+				shared++
+			}
+		}
+	}
+	if shared == 0 {
+		t.Error("genomes of species are not shared with the population")
+	}
+
+	// the branches evolve independently of each other.
+	weight := n.Population[0].ConnGenes[0].Weight
+	counts := len(n.Statistics.MutationCounts[n.Generation-1])
+	numRecords := len(n.Genealogy.Records)
+	c.Config.RateAddNode = 1.0
+	c.Population[0].ConnGenes[0].Weight = weight + 1.0
+	for i := 0; i < 3; i++ {
+		c.Step()
+		if c.Err() != nil {
+			t.Fatal(c.Err())
+		}
+	}
+	if n.Config.RateAddNode == 1.0 {
+		t.Error("configuration is shared")
+	}
+	if n.Population[0].ConnGenes[0].Weight != weight {
+		t.Error("genes are shared")
+	}
+	if len(n.Statistics.MutationCounts[n.Generation-1]) != counts ||
+		n.Statistics.NumRecorded() != 3 || n.Statistics.MaxFitness[3] != 0.0 {
+		t.Error("statistics are shared")
+	}
+	if len(n.Genealogy.Records) != numRecords {
+		t.Error("genealogy is shared")
+	}
+	if c.Generation != 6 || n.Generation != 3 {
+		t.Errorf("invalid generations: %d, %d", c.Generation, n.Generation)
+	}
+
+	n.Step()
+	if n.Err() != nil {
+		t.Fatal(n.Err())
+	}
+}
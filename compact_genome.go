@@ -0,0 +1,212 @@
+// compact_genome.go implementation of the structure-of-arrays representation
+// of genomes.
+//
+// Copyright (C) 2017  Jin Yeom
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package neat
+
+import (
+	"fmt"
+	"math"
+)
+
+// Types of nodes of CompactGenome.
+const (
+	CompactInput  uint8 = iota // input node
+	CompactHidden              // hidden node
+	CompactOutput              // output node
+)
+
+// compactTypes are the names of the types of nodes of CompactGenome.
+var compactTypes = []string{"input", "hidden", "output"}
+
+// CompactGenome is an alternative representation of Genome, which stores its
+// genes in flat parallel slices, instead of pointers to a struct of each gene;
+// connections refer to their nodes by indices of the slices of nodes, and
+// activation functions are indices of a table of distinct functions. With
+// fewer pointers and contiguous memory, it is more cache-friendly and lighter
+// on the garbage collector, e.g., to keep very large genomes or populations in
+// memory. Convert with NewCompactGenome and ToGenome; provenance is not kept.
+type CompactGenome struct {
+	ID        int     // genome ID
+	SpeciesID int     // genome's species ID
+	Fitness   float64 // fitness score
+
+	// nodes, in the order of Genome.NodeGenes
+	NodeIDs         []int32  // ID of each node
+	NodeTypes       []uint8  // type of each node, e.g., CompactInput
+	NodeActivations []uint16 // index of the function of each node
+
+	// connections, in the order of Genome.ConnGenes
+	ConnFrom     []int32   // index of the input node of each connection
+	ConnTo       []int32   // index of the output node of each connection
+	ConnWeights  []float64 // weight of each connection
+	ConnDisabled []bool    // true if each connection is disabled
+
+	// distinct activation functions of the nodes (nil for input nodes)
+	Activations []*ActivationFunc
+
+	// results of the evaluation, shared with the original genome
+	Objectives []float64
+	Behavior   []float64
+	Metrics    map[string]float64
+
+	evaluated bool // true if already evaluated
+}
+
+// NewCompactGenome returns the compact representation of the argument genome.
+// It returns an error if a node is of an unknown type, or if a connection
+// refers to a node that doesn't exist.
+func NewCompactGenome(g *Genome) (*CompactGenome, error) {
+	numNodes, numConns := len(g.NodeGenes), len(g.ConnGenes)
+	c := &CompactGenome{
+		ID:              g.ID,
+		SpeciesID:       g.SpeciesID,
+		Fitness:         g.Fitness,
+		NodeIDs:         make([]int32, numNodes),
+		NodeTypes:       make([]uint8, numNodes),
+		NodeActivations: make([]uint16, numNodes),
+		ConnFrom:        make([]int32, numConns),
+		ConnTo:          make([]int32, numConns),
+		ConnWeights:     make([]float64, numConns),
+		ConnDisabled:    make([]bool, numConns),
+		Objectives:      g.Objectives,
+		Behavior:        g.Behavior,
+		Metrics:         g.Metrics,
+		evaluated:       g.evaluated,
+	}
+
+	indices := make(map[int]int32, numNodes) // index of each node ID
+	functions := make(map[*ActivationFunc]uint16)
+	for i, node := range g.NodeGenes {
+		if node.ID > math.MaxInt32 {
+			return nil, fmt.Errorf("neat: node ID %d is too large", node.ID)
+		}
+		nodeType := -1
+		for t, name := range compactTypes {
+			if node.Type == name {
+				nodeType = t
+			}
+		}
+		if nodeType < 0 {
+			return nil, fmt.Errorf("neat: unknown type %q of node %d",
+				node.Type, node.ID)
+		}
+
+		f, ok := functions[node.Activation]
+		if !ok {
+			if len(c.Activations) > math.MaxUint16 {
+				return nil, fmt.Errorf("neat: too many activation functions")
+			}
+			f = uint16(len(c.Activations))
+			functions[node.Activation] = f
+			c.Activations = append(c.Activations, node.Activation)
+		}
+
+		indices[node.ID] = int32(i)
+		c.NodeIDs[i] = int32(node.ID)
+		c.NodeTypes[i] = uint8(nodeType)
+		c.NodeActivations[i] = f
+	}
+
+	for i, conn := range g.ConnGenes {
+		from, ok := indices[conn.From]
+		if !ok {
+			return nil, fmt.Errorf("neat: connection %d refers to node %d, "+
+				"which doesn't exist", i, conn.From)
+		}
+		to, ok := indices[conn.To]
+		if !ok {
+			return nil, fmt.Errorf("neat: connection %d refers to node %d, "+
+				"which doesn't exist", i, conn.To)
+		}
+		c.ConnFrom[i] = from
+		c.ConnTo[i] = to
+		c.ConnWeights[i] = conn.Weight
+		c.ConnDisabled[i] = conn.Disabled
+	}
+	return c, nil
+}
+
+// ToGenome returns the genome that this compact genome represents.
+func (c *CompactGenome) ToGenome() *Genome {
+	nodes := make([]NodeGene, len(c.NodeIDs))
+	nodeGenes := make([]*NodeGene, len(nodes))
+	for i, id := range c.NodeIDs {
+		nodes[i] = NodeGene{
+			ID:         int(id),
+			Type:       compactTypes[c.NodeTypes[i]],
+			Activation: c.Activations[c.NodeActivations[i]],
+		}
+		nodeGenes[i] = &nodes[i]
+	}
+
+	conns := make([]ConnGene, len(c.ConnFrom))
+	connGenes := make([]*ConnGene, len(conns))
+	for i := range conns {
+		conns[i] = ConnGene{
+			From:     int(c.NodeIDs[c.ConnFrom[i]]),
+			To:       int(c.NodeIDs[c.ConnTo[i]]),
+			Weight:   c.ConnWeights[i],
+			Disabled: c.ConnDisabled[i],
+		}
+		connGenes[i] = &conns[i]
+	}
+
+	return &Genome{
+		ID:         c.ID,
+		SpeciesID:  c.SpeciesID,
+		NodeGenes:  nodeGenes,
+		ConnGenes:  connGenes,
+		Fitness:    c.Fitness,
+		Objectives: c.Objectives,
+		Behavior:   c.Behavior,
+		Metrics:    c.Metrics,
+		evaluated:  c.evaluated,
+	}
+}
+
+// NumNodes returns the number of nodes of this genome.
+func (c *CompactGenome) NumNodes() int {
+	return len(c.NodeIDs)
+}
+
+// NumConns returns the number of connections of this genome.
+func (c *CompactGenome) NumConns() int {
+	return len(c.ConnFrom)
+}
+
+// Copy returns a deep copy of this genome; activation functions are shared.
+func (c *CompactGenome) Copy() *CompactGenome {
+	copied := *c
+	copied.NodeIDs = append([]int32(nil), c.NodeIDs...)
+	copied.NodeTypes = append([]uint8(nil), c.NodeTypes...)
+	copied.NodeActivations = append([]uint16(nil), c.NodeActivations...)
+	copied.ConnFrom = append([]int32(nil), c.ConnFrom...)
+	copied.ConnTo = append([]int32(nil), c.ConnTo...)
+	copied.ConnWeights = append([]float64(nil), c.ConnWeights...)
+	copied.ConnDisabled = append([]bool(nil), c.ConnDisabled...)
+	copied.Activations = append([]*ActivationFunc(nil), c.Activations...)
+	copied.Objectives = append([]float64(nil), c.Objectives...)
+	copied.Behavior = append([]float64(nil), c.Behavior...)
+	if c.Metrics != nil {
+		copied.Metrics = make(map[string]float64, len(c.Metrics))
+		for name, value := range c.Metrics {
+			copied.Metrics[name] = value
+		}
+	}
+	return &copied
+}
@@ -0,0 +1,46 @@
+package neat
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestCompactGenome(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	g := newFCGenome(0, 3, 2, 0.0, rng)
+	for i := 0; i < 20; i++ {
+		g.mutateAddNode(0.5, ActivationSet["tanh"], 0, rng)
+		g.mutateAddConn(0.5, 0, false, rng)
+	}
+	g.ConnGenes[0].Disabled = true
+	g.Fitness = 1.5
+
+	c, err := NewCompactGenome(g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.NumNodes() != len(g.NodeGenes) || c.NumConns() != len(g.ConnGenes) {
+		t.Fatalf("invalid number of genes: (%d, %d) != (%d, %d)", c.NumNodes(),
+			c.NumConns(), len(g.NodeGenes), len(g.ConnGenes))
+	}
+
+	h := c.Copy().ToGenome()
+	if h.ID != g.ID || h.Fitness != g.Fitness {
+		t.Errorf("invalid genome: %d, %f", h.ID, h.Fitness)
+	}
+	for i, node := range g.NodeGenes {
+		if *h.NodeGenes[i] != *node {
+			t.Errorf("invalid node %d: %v != %v", i, h.NodeGenes[i], node)
+		}
+	}
+	for i, conn := range g.ConnGenes {
+		if *h.ConnGenes[i] != *conn {
+			t.Errorf("invalid connection %d: %v != %v", i, h.ConnGenes[i], conn)
+		}
+	}
+
+	g.ConnGenes = append(g.ConnGenes, NewConnGene(0, 100, 1.0))
+	if _, err := NewCompactGenome(g); err == nil {
+		t.Error("connection to a missing node is not rejected")
+	}
+}
@@ -20,21 +20,33 @@ package neat
 import (
 	"encoding/json"
 	"fmt"
+	"io"
+	"log"
+	"math"
 	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
 	"text/tabwriter"
+	"unicode"
 )
 
 // Config consists of all hyperparameter settings for NEAT. It can be imported
 // from a JSON file.
 type Config struct {
 	// general settings
+	Version        int    `json:"version"`        // version of the format
 	ExperimentName string `json:"experimentName"` // name of the experiment
 	Verbose        bool   `json:"verbose"`        // verbose mode (terminal)
+	RandomSeed     int64  `json:"randomSeed"`     // seed (0: from time)
 
 	// neural network settings
-	NumInputs      int  `json:"numInputs"`      // number of inputs
-	NumOutputs     int  `json:"numOutputs"`     // number of outputs
-	FullyConnected bool `json:"fullyConnected"` // initially fully connected
+	NumInputs       int  `json:"numInputs"`       // number of inputs
+	NumOutputs      int  `json:"numOutputs"`      // number of outputs
+	FullyConnected  bool `json:"fullyConnected"`  // initially fully connected
+	MaxDepth        int  `json:"maxDepth"`        // maximum depth (0: unlimited)
+	SelfConnections bool `json:"selfConnections"` // allow recurrent self-loops
 
 	// evolution settings
 	NumGenerations  int     `json:"numGenerations"`  // number of generations
@@ -44,11 +56,33 @@ type Config struct {
 	SurvivalRate    float64 `json:"survivalRate"`    // survival rate
 	StagnationLimit int     `json:"stagnationLimit"` // limit of stagnation
 
+	// time limit of evaluating a genome in seconds (0: unlimited)
+	EvaluationTimeout float64 `json:"evaluationTimeout"`
+
+	// number of genomes that are evaluated, or reproduced, concurrently (0 or
+	// 1: sequentially)
+	NumWorkers int `json:"numWorkers"`
+
+	// selection settings
+	SelectionStrategy string `json:"selectionStrategy"` // selection of survivors
+	TournamentSize    int    `json:"tournamentSize"`    // size of a tournament
+
+	// archive settings
+	ArchiveChampions bool   `json:"archiveChampions"` // keep each champion
+	ArchiveDir       string `json:"archiveDir"`       // directory of champions
+	TrackLineage     bool   `json:"trackLineage"`     // record ancestry
+
+	// statistics settings
+	HistogramBins    int `json:"histogramBins"`    // bins of fitness histograms
+	StatisticsWindow int `json:"statisticsWindow"` // recent records (0: all)
+	StatisticsStride int `json:"statisticsStride"` // record every n-th generation
+
 	// mutation rates settings
-	RatePerturb     float64 `json:"ratePerturb"`     // by perturbing weights
-	RateAddNode     float64 `json:"rateAddNode"`     // by adding a node
-	RateAddConn     float64 `json:"rateAddConn"`     // by adding a connection
-	RateMutateChild float64 `json:"rateMutateChild"` // mutation of a child
+	RatePerturb          float64 `json:"ratePerturb"`          // by perturbing weights
+	RateAddNode          float64 `json:"rateAddNode"`          // by adding a node
+	RateAddConn          float64 `json:"rateAddConn"`          // by adding a connection
+	RateMutateActivation float64 `json:"rateMutateActivation"` // of a hidden node
+	RateMutateChild      float64 `json:"rateMutateChild"`      // mutation of a child
 
 	// compatibility distance coefficient settings
 	DistanceThreshold float64 `json:"distanceThreshold"` // distance threshold
@@ -56,41 +90,460 @@ type Config struct {
 	CoeffMatching     float64 `json:"coeffMatching"`     // matching genes
 
 	// CPPN settings
-	CPPNActivations []string `json:"cppnActivations"` // additional activations
+	CPPNActivations   []string `json:"cppnActivations"`   // additional activations
+	HiddenActivations []string `json:"hiddenActivations"` // pool of hidden nodes
+	OutputActivations []string `json:"outputActivations"` // pool of output nodes
+}
+
+// NewDefaultConfig creates a new instance of Config with the argument number of
+// inputs and outputs, and default hyperparameter settings that mostly follow
+// the original NEAT paper (Stanley & Miikkulainen, 2002). Fitness is maximized
+// by default.
+func NewDefaultConfig(numInputs, numOutputs int) *Config {
+	return &Config{
+		Version:        ConfigVersion,
+		ExperimentName: "NEAT",
+		Verbose:        false,
+		RandomSeed:     0,
+
+		NumInputs:       numInputs,
+		NumOutputs:      numOutputs,
+		FullyConnected:  true,
+		MaxDepth:        0,
+		SelfConnections: false,
+
+		NumGenerations:  100,
+		PopulationSize:  150,
+		InitFitness:     0.0,
+		MinimizeFitness: false,
+		SurvivalRate:    0.2,
+		StagnationLimit: 15,
+
+		EvaluationTimeout: 0.0,
+		NumWorkers:        1,
+
+		SelectionStrategy: "truncation",
+		TournamentSize:    2,
+
+		ArchiveChampions: false,
+		ArchiveDir:       "",
+		TrackLineage:     false,
+
+		HistogramBins:    10,
+		StatisticsWindow: 0,
+		StatisticsStride: 1,
+
+		RatePerturb:          0.8,
+		RateAddNode:          0.03,
+		RateAddConn:          0.05,
+		RateMutateActivation: 0.0,
+		RateMutateChild:      0.75,
+
+		DistanceThreshold: 3.0,
+		CoeffUnmatching:   1.0,
+		CoeffMatching:     0.4,
+
+		CPPNActivations:   []string{},
+		HiddenActivations: []string{},
+		OutputActivations: []string{},
+	}
+}
+
+// NewConfig creates a new instance of Config, given a reader of JSON data that
+// consists of the hyperparameter settings, e.g., an embedded asset, a network
+// response, or a string in a test. Unlike NewConfigJSON, environment variables
+// are not applied unless requested with ApplyEnv.
+func NewConfig(r io.Reader) (*Config, error) {
+	return decodeConfig(r, false)
 }
 
-// NewConfigJSON creates a new instance of Config, given the name of a JSON file
-// that consists of the hyperparameter settings.
-func NewConfigJSON(filename string) (*Config, error) {
-	f, err := os.Open(filename)
+// NewConfigStrict is NewConfig, except that it returns an error if the JSON data
+// contains a setting that does not exist in Config, e.g., a misspelled
+// "rateAddNodes", instead of silently ignoring it.
+func NewConfigStrict(r io.Reader) (*Config, error) {
+	return decodeConfig(r, true)
+}
+
+// decodeConfig is a helper function that decodes, migrates, and validates a
+// configuration from the argument reader. Configurations of older versions are
+// upgraded to the current version with warnings printed to the standard logger.
+// If strict is true, unknown settings are reported along with the most similar
+// known setting.
+func decodeConfig(r io.Reader, strict bool) (*Config, error) {
+	settings := make(map[string]json.RawMessage)
+	if err := json.NewDecoder(r).Decode(&settings); err != nil {
+		return nil, err
+	}
+	warnings, err := migrateConfig(settings)
+	if err != nil {
+		return nil, err
+	}
+	for _, warning := range warnings {
+		log.Printf("neat: %s", warning)
+	}
+	if strict {
+		if name, ok := unknownSetting(settings); ok {
+			return nil, fmt.Errorf("unknown setting %q (did you mean %q?)",
+				name, closestSetting(name))
+		}
+	}
+	data, err := json.Marshal(settings)
 	if err != nil {
 		return nil, err
 	}
-	defer f.Close()
 
 	config := &Config{}
-	decoder := json.NewDecoder(f)
-	if err = decoder.Decode(&config); err != nil {
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, err
+	}
+	if err := config.Validate(); err != nil {
 		return nil, err
 	}
 	return config, nil
 }
 
+// unknownSetting is a helper function that returns the first name, in sorted
+// order, of the argument settings that is not the JSON name of any setting in
+// Config, and whether there is such a name.
+func unknownSetting(settings map[string]json.RawMessage) (string, bool) {
+	known := make(map[string]bool)
+	t := reflect.TypeOf(Config{})
+	for i := 0; i < t.NumField(); i++ {
+		known[t.Field(i).Tag.Get("json")] = true
+	}
+
+	names := make([]string, 0, len(settings))
+	for name := range settings {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if !known[name] {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// closestSetting is a helper function that returns the JSON name of the setting
+// in Config that is the most similar to the argument name, in terms of the edit
+// distance.
+func closestSetting(name string) string {
+	closest := ""
+	minDist := math.MaxInt32
+	t := reflect.TypeOf(Config{})
+	for i := 0; i < t.NumField(); i++ {
+		setting := t.Field(i).Tag.Get("json")
+		if dist := editDistance(name, setting); dist < minDist {
+			closest, minDist = setting, dist
+		}
+	}
+	return closest
+}
+
+// editDistance is a helper function that returns the Levenshtein distance
+// between two strings.
+func editDistance(s0, s1 string) int {
+	prev := make([]int, len(s1)+1)
+	curr := make([]int, len(s1)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(s0); i++ {
+		curr[0] = i
+		for j := 1; j <= len(s1); j++ {
+			cost := 1
+			if s0[i-1] == s1[j-1] {
+				cost = 0
+			}
+			curr[j] = prev[j-1] + cost
+			if prev[j]+1 < curr[j] {
+				curr[j] = prev[j] + 1
+			}
+			if curr[j-1]+1 < curr[j] {
+				curr[j] = curr[j-1] + 1
+			}
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(s1)]
+}
+
+// Encode writes this configuration to the argument writer as JSON, formatted
+// with indentations, such that it can be loaded again with NewConfig.
+func (c *Config) Encode(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "\t")
+	return encoder.Encode(c)
+}
+
+// EnvPrefix is the conventional prefix of environment variables that override
+// settings of a configuration (see ApplyEnv).
+const EnvPrefix = "NEAT_"
+
+// ApplyEnv overrides the settings of this configuration with environment
+// variables. Each variable's name is the argument prefix followed by the
+// setting's JSON name in upper snake case, e.g., NEAT_POPULATION_SIZE=500
+// overrides "populationSize" given the prefix "NEAT_". Lists of activation
+// functions are separated by commas. NewConfigJSON applies them with EnvPrefix
+// after loading a file; other constructors do not, so that a configuration
+// from a reader does not depend on the environment. It returns an error if a
+// value cannot be parsed, or if the overridden configuration is invalid.
+func (c *Config) ApplyEnv(prefix string) error {
+	t := reflect.TypeOf(*c)
+	for i := 0; i < t.NumField(); i++ {
+		jsonName := t.Field(i).Tag.Get("json")
+		value, ok := os.LookupEnv(prefix + envName(jsonName))
+		if !ok {
+			continue
+		}
+		if err := c.Set(jsonName, value); err != nil {
+			return err
+		}
+	}
+	return c.Validate()
+}
+
+// Set overrides a setting of this configuration, given the setting's JSON name,
+// e.g., "populationSize", and its new value as a string. Lists of activation
+// functions are separated by commas.
+func (c *Config) Set(name, value string) error {
+	v := reflect.ValueOf(c).Elem()
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		if t.Field(i).Tag.Get("json") != name {
+			continue
+		}
+
+		field := v.Field(i)
+		switch field.Kind() {
+		case reflect.String:
+			field.SetString(value)
+		case reflect.Bool:
+			b, err := strconv.ParseBool(value)
+			if err != nil {
+				return fmt.Errorf("invalid value of %s: %v", name, err)
+			}
+			field.SetBool(b)
+		case reflect.Int, reflect.Int64:
+			n, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return fmt.Errorf("invalid value of %s: %v", name, err)
+			}
+			field.SetInt(n)
+		case reflect.Float64:
+			f, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return fmt.Errorf("invalid value of %s: %v", name, err)
+			}
+			field.SetFloat(f)
+		case reflect.Slice:
+			list := field.Addr().Interface().(*[]string)
+			(*listFlag)(list).Set(value)
+		}
+		return nil
+	}
+	return fmt.Errorf("unknown setting: %s", name)
+}
+
+// Copy returns a deep copy of this configuration.
+func (c *Config) Copy() *Config {
+	copied := *c
+	copied.CPPNActivations = append([]string{}, c.CPPNActivations...)
+	copied.HiddenActivations = append([]string{}, c.HiddenActivations...)
+	copied.OutputActivations = append([]string{}, c.OutputActivations...)
+	return &copied
+}
+
+// envName is a helper function that converts a setting's JSON name in camel
+// case into upper snake case, e.g., "populationSize" into "POPULATION_SIZE".
+func envName(jsonName string) string {
+	name := ""
+	for i, r := range jsonName {
+		if unicode.IsUpper(r) && i > 0 {
+			name += "_"
+		}
+		name += string(unicode.ToUpper(r))
+	}
+	return name
+}
+
+// ValidationError is an error that consists of every violation found during
+// the validation of a configuration.
+type ValidationError []string
+
+// Error returns the string representation of ValidationError.
+func (e ValidationError) Error() string {
+	return fmt.Sprintf("invalid configuration: %s", strings.Join(e, "; "))
+}
+
+// Validate checks whether every hyperparameter setting of this configuration
+// is within its valid range, and returns a ValidationError that reports every
+// violation, or nil if the configuration is valid.
+func (c *Config) Validate() error {
+	var violations ValidationError
+	violate := func(format string, a ...interface{}) {
+		violations = append(violations, fmt.Sprintf(format, a...))
+	}
+
+	// general settings
+	// a configuration built in code may leave its version unset, which is
+	// regarded as the current version.
+	if c.Version < 0 || c.Version > ConfigVersion {
+		violate("version must be between 0 and %d (%d)", ConfigVersion,
+			c.Version)
+	}
+
+	// neural network settings
+	if c.NumInputs <= 0 {
+		violate("numInputs must be positive (%d)", c.NumInputs)
+	}
+	if c.NumOutputs <= 0 {
+		violate("numOutputs must be positive (%d)", c.NumOutputs)
+	}
+	if c.MaxDepth < 0 {
+		violate("maxDepth must not be negative (%d)", c.MaxDepth)
+	}
+
+	// evolution settings
+	if c.NumGenerations <= 0 {
+		violate("numGenerations must be positive (%d)", c.NumGenerations)
+	}
+	if c.PopulationSize <= 0 {
+		violate("populationSize must be positive (%d)", c.PopulationSize)
+	}
+	if c.SurvivalRate <= 0.0 || c.SurvivalRate > 1.0 {
+		violate("survivalRate must be in (0, 1] (%f)", c.SurvivalRate)
+	}
+	if c.StagnationLimit < 0 {
+		violate("stagnationLimit must not be negative (%d)", c.StagnationLimit)
+	}
+	if c.EvaluationTimeout < 0.0 {
+		violate("evaluationTimeout must not be negative (%f)", c.EvaluationTimeout)
+	}
+	if c.NumWorkers < 0 {
+		violate("numWorkers must not be negative (%d)", c.NumWorkers)
+	}
+
+	// selection settings
+	if c.SelectionStrategy != "" {
+		known := false
+		for _, strategy := range SelectionStrategies {
+			known = known || strategy == c.SelectionStrategy
+		}
+		if !known {
+			violate("selectionStrategy must be one of %v (%s)",
+				SelectionStrategies, c.SelectionStrategy)
+		}
+	}
+	if c.TournamentSize < 0 {
+		violate("tournamentSize must not be negative (%d)", c.TournamentSize)
+	}
+
+	// activation settings
+	activations := []struct {
+		name  string
+		names []string
+	}{
+		{"cppnActivations", c.CPPNActivations},
+		{"hiddenActivations", c.HiddenActivations},
+		{"outputActivations", c.OutputActivations},
+	}
+	for _, a := range activations {
+		for _, name := range a.names {
+			if _, ok := ActivationSet[name]; !ok {
+				violate("%s must be in ActivationSet (%s)", a.name, name)
+			}
+		}
+	}
+
+	// statistics settings
+	if c.HistogramBins < 0 {
+		violate("histogramBins must not be negative (%d)", c.HistogramBins)
+	}
+	if c.StatisticsWindow < 0 {
+		violate("statisticsWindow must not be negative (%d)", c.StatisticsWindow)
+	}
+	if c.StatisticsStride < 0 {
+		violate("statisticsStride must not be negative (%d)", c.StatisticsStride)
+	}
+
+	// mutation rates settings
+	rates := []struct {
+		name string
+		rate float64
+	}{
+		{"ratePerturb", c.RatePerturb},
+		{"rateAddNode", c.RateAddNode},
+		{"rateAddConn", c.RateAddConn},
+		{"rateMutateActivation", c.RateMutateActivation},
+		{"rateMutateChild", c.RateMutateChild},
+	}
+	for _, r := range rates {
+		if r.rate < 0.0 || r.rate > 1.0 {
+			violate("%s must be in [0, 1] (%f)", r.name, r.rate)
+		}
+	}
+
+	// compatibility distance coefficient settings
+	if c.DistanceThreshold <= 0.0 {
+		violate("distanceThreshold must be positive (%f)", c.DistanceThreshold)
+	}
+	if c.CoeffUnmatching < 0.0 {
+		violate("coeffUnmatching must not be negative (%f)", c.CoeffUnmatching)
+	}
+	if c.CoeffMatching < 0.0 {
+		violate("coeffMatching must not be negative (%f)", c.CoeffMatching)
+	}
+
+	// CPPN settings
+	pools := []struct {
+		name  string
+		names []string
+	}{
+		{"cppnActivations", c.CPPNActivations},
+		{"hiddenActivations", c.HiddenActivations},
+		{"outputActivations", c.OutputActivations},
+	}
+	for _, pool := range pools {
+		for _, name := range pool.names {
+			if _, ok := ActivationSet[name]; !ok {
+				violate("unknown activation function in %s (%s)", pool.name, name)
+			}
+		}
+	}
+
+	if len(violations) != 0 {
+		return violations
+	}
+	return nil
+}
+
 // Summarize prints the summarized configuration on terminal.
 func (c *Config) Summarize() {
-	w := tabwriter.NewWriter(os.Stdout, 40, 1, 1, ' ', tabwriter.TabIndent)
+	c.SummarizeTo(os.Stdout)
+}
+
+// SummarizeTo writes the summarized configuration to the argument writer, e.g.,
+// a log file or a buffer.
+func (c *Config) SummarizeTo(writer io.Writer) {
+	w := tabwriter.NewWriter(writer, 40, 1, 1, ' ', tabwriter.TabIndent)
 	fmt.Fprintf(w, "============================================\n")
 	fmt.Fprintf(w, "Summary of NEAT hyperparameter configuration\t\n")
 	fmt.Fprintf(w, "============================================\n")
 
 	fmt.Fprintf(w, "General settings\t\n")
+	fmt.Fprintf(w, "+ Version\t%d\t\n", c.Version)
 	fmt.Fprintf(w, "+ Experiment name\t%s\t\n", c.ExperimentName)
-	fmt.Fprintf(w, "+ Verbose mode\t%t\t\n\n", c.Verbose)
+	fmt.Fprintf(w, "+ Verbose mode\t%t\t\n", c.Verbose)
+	fmt.Fprintf(w, "+ Random seed\t%d\t\n\n", c.RandomSeed)
 
 	fmt.Fprintf(w, "Neural network settings\t\n")
 	fmt.Fprintf(w, "+ Number of inputs\t%d\t\n", c.NumInputs)
 	fmt.Fprintf(w, "+ Number of outputs\t%d\t\n", c.NumOutputs)
-	fmt.Fprintf(w, "+ Fully connected\t%t\t\n\n", c.FullyConnected)
+	fmt.Fprintf(w, "+ Fully connected\t%t\t\n", c.FullyConnected)
+	fmt.Fprintf(w, "+ Maximum depth\t%d\t\n", c.MaxDepth)
+	fmt.Fprintf(w, "+ Self-connections allowed\t%t\t\n\n", c.SelfConnections)
 
 	fmt.Fprintf(w, "General evolution settings\t\n")
 	fmt.Fprintf(w, "+ Number of generations\t%d\t\n", c.NumGenerations)
@@ -98,12 +551,32 @@ func (c *Config) Summarize() {
 	fmt.Fprintf(w, "+ Initial fitness score\t%.3f\t\n", c.InitFitness)
 	fmt.Fprintf(w, "+ Fitness is being minimized\t%t\t\n", c.MinimizeFitness)
 	fmt.Fprintf(w, "+ Rate of survival each generation\t%.3f\t\n", c.SurvivalRate)
-	fmt.Fprintf(w, "+ Limit of species' stagnation\t%d\t\n\n", c.StagnationLimit)
+	fmt.Fprintf(w, "+ Limit of species' stagnation\t%d\t\n", c.StagnationLimit)
+	fmt.Fprintf(w, "+ Evaluation timeout (seconds)\t%.3f\t\n",
+		c.EvaluationTimeout)
+	fmt.Fprintf(w, "+ Number of workers\t%d\t\n\n", c.NumWorkers)
+
+	fmt.Fprintf(w, "Selection settings\t\n")
+	fmt.Fprintf(w, "+ Selection strategy\t%s\t\n", c.SelectionStrategy)
+	fmt.Fprintf(w, "+ Tournament size\t%d\t\n\n", c.TournamentSize)
+
+	fmt.Fprintf(w, "Archive settings\t\n")
+	fmt.Fprintf(w, "+ Archive champions\t%t\t\n", c.ArchiveChampions)
+	fmt.Fprintf(w, "+ Archive directory\t%s\t\n", c.ArchiveDir)
+	fmt.Fprintf(w, "+ Track lineage\t%t\t\n\n", c.TrackLineage)
+
+	fmt.Fprintf(w, "Statistics settings\t\n")
+	fmt.Fprintf(w, "+ Bins of fitness histograms\t%d\t\n", c.HistogramBins)
+	fmt.Fprintf(w, "+ Window of recent records\t%d\t\n", c.StatisticsWindow)
+	fmt.Fprintf(w, "+ Stride of recorded generations\t%d\t\n\n",
+		c.StatisticsStride)
 
 	fmt.Fprintf(w, "Mutation settings\t\n")
 	fmt.Fprintf(w, "+ Rate of perturbation of weights\t%.3f\t\n", c.RatePerturb)
 	fmt.Fprintf(w, "+ Rate of adding a node\t%.3f\t\n", c.RateAddNode)
 	fmt.Fprintf(w, "+ Rate of adding a connection\t%.3f\t\n", c.RateAddConn)
+	fmt.Fprintf(w, "+ Rate of mutating an activation\t%.3f\t\n",
+		c.RateMutateActivation)
 	fmt.Fprintf(w, "+ Rate of mutating a child\t%.3f\t\n\n", c.RateMutateChild)
 
 	fmt.Fprintf(w, "Compatibility distance settings\t\n")
@@ -113,6 +586,31 @@ func (c *Config) Summarize() {
 
 	fmt.Fprintf(w, "CPPN settings\t\n")
 	fmt.Fprintf(w, "+ CPPN Activation functions\t%s\t\n", c.CPPNActivations)
+	fmt.Fprintf(w, "+ Hidden activation functions\t%s\t\n", c.HiddenActivations)
+	fmt.Fprintf(w, "+ Output activation functions\t%s\t\n", c.OutputActivations)
 
 	w.Flush()
 }
+
+// listFlag is a flag.Value of a list of strings separated by commas.
+type listFlag []string
+
+// String returns the string representation of the list.
+func (l *listFlag) String() string {
+	if l == nil {
+		return ""
+	}
+	return strings.Join(*l, ",")
+}
+
+// Set replaces the list with the argument value separated by commas.
+func (l *listFlag) Set(value string) error {
+	list := []string{}
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	*l = list
+	return nil
+}
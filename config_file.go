@@ -0,0 +1,79 @@
+// config_file.go implementation of configuration files.
+//
+// Copyright (C) 2017  Jin Yeom
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+//go:build !js
+
+package neat
+
+import "os"
+
+// NewConfigJSON creates a new instance of Config, given the name of a JSON file
+// that consists of the hyperparameter settings. After the file is loaded, its
+// settings are overridden by environment variables with EnvPrefix, e.g.,
+// NEAT_POPULATION_SIZE=500 (see ApplyEnv), so that batch jobs can sweep
+// settings without a file for each of them.
+func NewConfigJSON(filename string) (*Config, error) {
+	return loadConfigJSON(filename, false)
+}
+
+// NewConfigJSONStrict is NewConfigJSON, except that it returns an error if the
+// JSON file contains a setting that does not exist in Config.
+func NewConfigJSONStrict(filename string) (*Config, error) {
+	return loadConfigJSON(filename, true)
+}
+
+// loadConfigJSON is a helper function that decodes a configuration from the
+// JSON file of the argument name, and applies environment overrides to it.
+func loadConfigJSON(filename string, strict bool) (*Config, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	config, err := decodeConfig(f, strict)
+	if err != nil {
+		return nil, err
+	}
+	if err = config.ApplyEnv(EnvPrefix); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// Save writes this configuration to a JSON file of the argument name, such that
+// it can be loaded again with NewConfigJSON.
+func (c *Config) Save(filename string) error {
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return c.Encode(f)
+}
+
+// NewConfigNP creates a new instance of Config from a legacy .np parameter
+// file, given its name and the number of inputs and outputs, which are not
+// always specified in such files. See NewConfigLegacy for the format.
+func NewConfigNP(filename string, numInputs, numOutputs int) (*Config, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return NewConfigLegacy(f, numInputs, numOutputs)
+}
@@ -0,0 +1,56 @@
+// config_flag.go implementation of command-line flags of configuration.
+//
+// Copyright (C) 2017  Jin Yeom
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+//go:build !js
+
+package neat
+
+import (
+	"flag"
+	"fmt"
+	"reflect"
+)
+
+// BindFlags registers every setting of this configuration as a flag on the
+// argument flag set, named after the setting's JSON name, e.g.,
+// -populationSize=500. The current values of the configuration, e.g., loaded
+// from a JSON file, are the flags' defaults; the configuration is overridden
+// when the flag set is parsed. Lists of activation functions are separated by
+// commas.
+func (c *Config) BindFlags(fs *flag.FlagSet) {
+	v := reflect.ValueOf(c).Elem()
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		name := t.Field(i).Tag.Get("json")
+		usage := fmt.Sprintf("override the setting %q of the configuration", name)
+
+		switch ptr := v.Field(i).Addr().Interface().(type) {
+		case *string:
+			fs.StringVar(ptr, name, *ptr, usage)
+		case *bool:
+			fs.BoolVar(ptr, name, *ptr, usage)
+		case *int:
+			fs.IntVar(ptr, name, *ptr, usage)
+		case *int64:
+			fs.Int64Var(ptr, name, *ptr, usage)
+		case *float64:
+			fs.Float64Var(ptr, name, *ptr, usage)
+		case *[]string:
+			fs.Var((*listFlag)(ptr), name, usage)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+// config_legacy.go implementation of legacy parameter files of configuration.
+//
+// Copyright (C) 2017  Jin Yeom
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package neat
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"log"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// NewConfigLegacy creates a new instance of Config, given a reader of a legacy
+// .np parameter file and the number of inputs and outputs. Each line of the
+// file consists of a parameter's name and its value separated by whitespace;
+// blank lines and lines that start with '#' or "//" are ignored. Parameters
+// are mapped to their current settings (e.g., MutAddNodeRate to RateAddNode),
+// starting from NewDefaultConfig; parameters without a current equivalent are
+// ignored with a warning printed to the standard logger.
+func NewConfigLegacy(r io.Reader, numInputs,
+	numOutputs int) (*Config, error) {
+	config := NewDefaultConfig(numInputs, numOutputs)
+
+	ignored := make(map[string]bool)
+	scanner := bufio.NewScanner(r)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") ||
+			strings.HasPrefix(line, "//") {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			return nil, fmt.Errorf("line %d: missing value of %s", lineNum, line)
+		}
+		setting, ok := legacySetting(fields[0])
+		if !ok {
+			ignored[fields[0]] = true
+			continue
+		}
+		value := strings.Join(fields[1:], " ")
+		if err := config.Set(setting, value); err != nil {
+			// integer parameters are often written as real numbers in .np files.
+			var f float64
+			if _, scanErr := fmt.Sscanf(value, "%g", &f); scanErr != nil ||
+				config.Set(setting, fmt.Sprint(int(f))) != nil {
+				return nil, fmt.Errorf("line %d: %v", lineNum, err)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(ignored) != 0 {
+		names := make([]string, 0, len(ignored))
+		for name := range ignored {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		log.Printf("neat: ignored legacy parameters without equivalent "+
+			"settings: %s", strings.Join(names, ", "))
+	}
+
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// legacySetting is a helper function that returns the JSON name of the setting
+// in Config that corresponds to the argument name of a legacy parameter, which
+// is either a current name or a legacy name in legacySettings. Names are
+// compared after normalizeParam, e.g., mutate_add_node_prob is
+// mutateAddNodeProb.
+func legacySetting(name string) (string, bool) {
+	name = normalizeParam(name)
+	t := reflect.TypeOf(Config{})
+	for i := 0; i < t.NumField(); i++ {
+		setting := t.Field(i).Tag.Get("json")
+		if normalizeParam(setting) == name {
+			return setting, true
+		}
+	}
+	for _, setting := range legacySettings {
+		if normalizeParam(setting.legacy) == name {
+			return setting.current, true
+		}
+	}
+	return "", false
+}
+
+// normalizeParam is a helper function that normalizes the name of a legacy
+// parameter, by converting it to lower case and removing underscores.
+func normalizeParam(name string) string {
+	return strings.ToLower(strings.Replace(name, "_", "", -1))
+}
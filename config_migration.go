@@ -0,0 +1,125 @@
+// config_migration.go implementation of migration of older configurations.
+//
+// Copyright (C) 2017  Jin Yeom
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package neat
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// ConfigVersion is the current version of the configuration format. A
+// configuration without a version is regarded as version 0.
+const ConfigVersion = 1
+
+var (
+	// configMigrations is a list of migrations of the configuration format, in
+	// which the i-th migration upgrades the settings of version i to version
+	// i+1, and returns warnings about what has been changed.
+	configMigrations = []func(settings map[string]json.RawMessage) []string{
+		migrateConfigV0,
+	}
+
+	// legacySettings pairs settings' names in the legacy (version 0) format with
+	// their current names. It is ordered, since several legacy names may map to
+	// the same current name, in which case the first one present wins. Names of
+	// the old Param type (e.g., mutAddNodeRate) and of the original C++ NEAT
+	// (e.g., mutateAddNodeProb) are both included, since legacy .np parameter
+	// files are read with the same names (see NewConfigLegacy).
+	legacySettings = []struct {
+		legacy  string // name in the legacy format
+		current string // current name
+	}{
+		{"name", "experimentName"},
+		{"numInput", "numInputs"},
+		{"numOutput", "numOutputs"},
+		{"numGeneration", "numGenerations"},
+		{"popSize", "populationSize"},
+		{"populationsize", "populationSize"},
+		{"survivalThresh", "survivalRate"},
+		{"dropOffAge", "stagnationLimit"},
+		{"mutPerturbRate", "ratePerturb"},
+		{"mutAddNodeRate", "rateAddNode"},
+		{"mutAddLinkRate", "rateAddConn"},
+		{"mutAddConnRate", "rateAddConn"},
+		{"mutateOnlyProb", "rateMutateChild"},
+		{"compatThreshold", "distanceThreshold"},
+		{"disjointCoeff", "coeffUnmatching"},
+		{"mutDiffCoeff", "coeffMatching"},
+		{"cppnActivationFn", "cppnActivations"},
+		{"seed", "randomSeed"},
+		{"numGens", "numGenerations"},
+		{"mutWeightRate", "ratePerturb"},
+		{"mutateLinkWeightsProb", "ratePerturb"},
+		{"mutateAddNodeProb", "rateAddNode"},
+		{"mutateAddLinkProb", "rateAddConn"},
+		{"mutChildRate", "rateMutateChild"},
+		{"compatThresh", "distanceThreshold"},
+		{"excessCoeff", "coeffUnmatching"},
+		{"weightDiffCoeff", "coeffMatching"},
+	}
+)
+
+// migrateConfig upgrades the argument settings of a configuration decoded from
+// JSON to the current version, and returns warnings about every change. It
+// returns an error if the settings are of a version newer than ConfigVersion.
+func migrateConfig(settings map[string]json.RawMessage) ([]string, error) {
+	version := 0
+	if raw, ok := settings["version"]; ok {
+		if err := json.Unmarshal(raw, &version); err != nil {
+			return nil, fmt.Errorf("invalid version: %v", err)
+		}
+	}
+	if version > ConfigVersion {
+		return nil, fmt.Errorf("configuration version %d is newer than the "+
+			"supported version %d", version, ConfigVersion)
+	}
+
+	var warnings []string
+	for v := version; v < ConfigVersion; v++ {
+		warnings = append(warnings, configMigrations[v](settings)...)
+	}
+	if version < ConfigVersion {
+		warnings = append(warnings, fmt.Sprintf("configuration upgraded from "+
+			"version %d to %d", version, ConfigVersion))
+	}
+	settings["version"] = json.RawMessage(fmt.Sprint(ConfigVersion))
+	return warnings, nil
+}
+
+// migrateConfigV0 upgrades settings of version 0 to version 1 by renaming
+// legacy settings to their current names.
+func migrateConfigV0(settings map[string]json.RawMessage) []string {
+	var warnings []string
+	for _, setting := range legacySettings {
+		legacy, current := setting.legacy, setting.current
+		raw, ok := settings[legacy]
+		if !ok {
+			continue
+		}
+		delete(settings, legacy)
+		if _, ok := settings[current]; ok {
+			warnings = append(warnings, fmt.Sprintf("legacy setting %q is "+
+				"ignored in favor of %q", legacy, current))
+			continue
+		}
+		settings[current] = raw
+		warnings = append(warnings, fmt.Sprintf("legacy setting %q is renamed "+
+			"to %q", legacy, current))
+	}
+	return warnings
+}
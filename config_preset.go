@@ -0,0 +1,176 @@
+// config_preset.go implementation of built-in hyperparameter presets.
+//
+// Copyright (C) 2017  Jin Yeom
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package neat
+
+import (
+	"fmt"
+	"sort"
+)
+
+var (
+	// configPresets is a set of functions that create tuned configurations for
+	// the bundled tasks, indexed by the names of the presets.
+	configPresets = map[string]func() *Config{
+		"xor":                   xorPreset,
+		"pole":                  polePreset,
+		"double-pole":           doublePolePreset,
+		"double-pole-nonmarkov": doublePoleNonMarkovPreset,
+		"cppn-image":            cppnImagePreset,
+		"retina":                retinaPreset,
+	}
+)
+
+// ConfigPresets returns the sorted names of the built-in presets.
+func ConfigPresets() []string {
+	names := make([]string, 0, len(configPresets))
+	for name := range configPresets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// NewConfigPreset creates a new instance of Config with the hyperparameter
+// settings tuned for one of the bundled tasks, given the name of the preset:
+// "xor", "pole", "double-pole", "double-pole-nonmarkov", "cppn-image", or
+// "retina". The returned configuration can be modified freely before being
+// passed to New.
+func NewConfigPreset(name string) (*Config, error) {
+	preset, ok := configPresets[name]
+	if !ok {
+		return nil, fmt.Errorf("neat: unknown preset %q (available: %v)",
+			name, ConfigPresets())
+	}
+	return preset(), nil
+}
+
+// xorPreset returns a configuration for XORTest, whose inputs include a bias
+// and whose fitness is the squared error to be minimized.
+func xorPreset() *Config {
+	config := NewDefaultConfig(3, 1)
+	config.ExperimentName = "XOR Test"
+	config.NumGenerations = 50
+	config.PopulationSize = 50
+	config.InitFitness = 9999.0
+	config.MinimizeFitness = true
+	config.SurvivalRate = 0.3
+	config.StagnationLimit = 10
+	config.RatePerturb = 0.1
+	config.RateAddNode = 0.1
+	config.RateAddConn = 0.1
+	config.RateMutateChild = 0.5
+	config.DistanceThreshold = 5.0
+	config.CoeffUnmatching = 1.0
+	config.CoeffMatching = 0.5
+	return config
+}
+
+// polePreset returns a configuration for PoleBalancingTest, which takes the
+// state of the cart and the pole, and pushes the cart either left or right.
+func polePreset() *Config {
+	config := NewDefaultConfig(4, 2)
+	config.ExperimentName = "Pole balancing test"
+	config.FullyConnected = false
+	config.NumGenerations = 30
+	config.PopulationSize = 100
+	config.SurvivalRate = 0.1
+	config.StagnationLimit = 10
+	config.RatePerturb = 0.2
+	config.RateAddNode = 0.2
+	config.RateAddConn = 0.2
+	config.RateMutateChild = 0.4
+	config.DistanceThreshold = 5.0
+	config.CoeffUnmatching = 0.5
+	config.CoeffMatching = 0.5
+	return config
+}
+
+// doublePolePreset returns a configuration for DoublePoleBalancingTest, which
+// takes the state of the cart and both poles, and outputs the force.
+func doublePolePreset() *Config {
+	config := NewDefaultConfig(6, 1)
+	config.ExperimentName = "Double pole balancing test"
+	config.OutputActivations = []string{"tanh"}
+	config.NumGenerations = 100
+	config.PopulationSize = 150
+	config.SurvivalRate = 0.2
+	config.StagnationLimit = 15
+	config.RatePerturb = 0.8
+	config.RateAddNode = 0.05
+	config.RateAddConn = 0.1
+	config.RateMutateChild = 0.75
+	config.DistanceThreshold = 3.0
+	config.CoeffUnmatching = 1.0
+	config.CoeffMatching = 0.4
+	return config
+}
+
+// doublePoleNonMarkovPreset returns a configuration for the non-Markovian
+// DoublePoleBalancingTest, which only takes the position of the cart and the
+// angles of both poles, thus relies on recurrent connections.
+func doublePoleNonMarkovPreset() *Config {
+	config := doublePolePreset()
+	config.ExperimentName = "Non-Markovian double pole balancing test"
+	config.NumInputs = 3
+	config.SelfConnections = true
+	config.NumGenerations = 200
+	config.RateAddConn = 0.2
+	return config
+}
+
+// cppnImagePreset returns a configuration for evolving CPPNs that draw
+// grayscale images, which take the coordinates of a pixel, its distance from
+// the center, and a bias, and output the intensity of the pixel.
+func cppnImagePreset() *Config {
+	config := NewDefaultConfig(4, 1)
+	config.ExperimentName = "CPPN image test"
+	config.NumGenerations = 50
+	config.PopulationSize = 100
+	config.SurvivalRate = 0.2
+	config.StagnationLimit = 20
+	config.RatePerturb = 0.8
+	config.RateAddNode = 0.1
+	config.RateAddConn = 0.2
+	config.RateMutateActivation = 0.1
+	config.RateMutateChild = 0.8
+	config.DistanceThreshold = 3.0
+	config.CoeffUnmatching = 1.0
+	config.CoeffMatching = 0.4
+	config.CPPNActivations = []string{"sin", "cos", "gaussian", "abs", "tanh"}
+	config.OutputActivations = []string{"sigmoid"}
+	return config
+}
+
+// retinaPreset returns a configuration for RetinaTest, which takes a bias and
+// the 8 pixels of the retina, and outputs whether it shows objects.
+func retinaPreset() *Config {
+	config := NewDefaultConfig(9, 1)
+	config.ExperimentName = "Retina test"
+	config.NumGenerations = 200
+	config.PopulationSize = 150
+	config.SurvivalRate = 0.2
+	config.StagnationLimit = 20
+	config.RatePerturb = 0.8
+	config.RateAddNode = 0.05
+	config.RateAddConn = 0.2
+	config.RateMutateChild = 0.75
+	config.DistanceThreshold = 3.0
+	config.CoeffUnmatching = 1.0
+	config.CoeffMatching = 0.4
+	return config
+}
@@ -0,0 +1,275 @@
+//go:build !js
+
+package neat
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestConfigValidate(t *testing.T) {
+	config, err := NewConfigPreset("xor")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	config.PopulationSize = 0
+	config.RateAddNode = 1.5
+	config.DistanceThreshold = 0.0
+	err = config.Validate()
+	violations, ok := err.(ValidationError)
+	if !ok {
+		t.Fatalf("expected ValidationError, got %v", err)
+	}
+	if len(violations) != 3 {
+		t.Errorf("invalid number of violations: %d != 3 (%v)", len(violations), err)
+	}
+}
+
+func TestNewDefaultConfig(t *testing.T) {
+	if err := NewDefaultConfig(3, 1).Validate(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestConfigValidateVersion(t *testing.T) {
+	config := NewDefaultConfig(3, 1)
+	config.Version = 0
+	if err := config.Validate(); err != nil {
+		t.Errorf("unset version is not accepted: %v", err)
+	}
+	config.Version = ConfigVersion + 1
+	if err := config.Validate(); err == nil {
+		t.Error("newer version is not reported")
+	}
+}
+
+func TestNewConfig(t *testing.T) {
+	config, err := NewConfig(strings.NewReader(`{
+		"numInputs": 3,
+		"numOutputs": 1,
+		"numGenerations": 10,
+		"populationSize": 20,
+		"survivalRate": 0.5,
+		"distanceThreshold": 1.0
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.NumInputs != 3 || config.PopulationSize != 20 {
+		t.Errorf("invalid configuration: %+v", config)
+	}
+}
+
+func TestConfigApplyEnv(t *testing.T) {
+	t.Setenv("NEAT_POPULATION_SIZE", "500")
+	t.Setenv("NEAT_RATE_ADD_NODE", "0.25")
+	t.Setenv("NEAT_CPPN_ACTIVATIONS", "sin, gaussian")
+
+	buf := &bytes.Buffer{}
+	if err := NewDefaultConfig(3, 1).Encode(buf); err != nil {
+		t.Fatal(err)
+	}
+	config, err := NewConfig(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.PopulationSize == 500 {
+		t.Error("environment variables are applied without being requested")
+	}
+
+	if err = config.ApplyEnv(EnvPrefix); err != nil {
+		t.Fatal(err)
+	}
+	if config.PopulationSize != 500 {
+		t.Errorf("invalid population size: %d != 500", config.PopulationSize)
+	}
+	if config.RateAddNode != 0.25 {
+		t.Errorf("invalid rate of adding a node: %f != 0.25", config.RateAddNode)
+	}
+	if len(config.CPPNActivations) != 2 || config.CPPNActivations[1] != "gaussian" {
+		t.Errorf("invalid CPPN activations: %v", config.CPPNActivations)
+	}
+
+	// overrides are applied after a file is loaded.
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err = NewDefaultConfig(3, 1).Save(path); err != nil {
+		t.Fatal(err)
+	}
+	if config, err = NewConfigJSON(path); err != nil {
+		t.Fatal(err)
+	}
+	if config.PopulationSize != 500 {
+		t.Errorf("environment variables are not applied after file load: %d",
+			config.PopulationSize)
+	}
+
+	// overridden configurations are validated.
+	t.Setenv("NEAT_POPULATION_SIZE", "0")
+	if err = config.ApplyEnv(EnvPrefix); err == nil {
+		t.Error("invalid override is not reported")
+	}
+	if _, err = NewConfigJSONStrict(path); err == nil {
+		t.Error("invalid override of a file is not reported")
+	}
+}
+
+func TestConfigEncode(t *testing.T) {
+	config := NewDefaultConfig(4, 2)
+	config.CPPNActivations = []string{"sin", "gaussian"}
+
+	buf := &bytes.Buffer{}
+	if err := config.Encode(buf); err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := NewConfig(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(config, decoded) {
+		t.Errorf("configuration changed after round trip: %+v != %+v",
+			decoded, config)
+	}
+}
+
+func TestNewConfigStrict(t *testing.T) {
+	data := `{
+		"numInputs": 3,
+		"numOutputs": 1,
+		"numGenerations": 10,
+		"populationSize": 20,
+		"survivalRate": 0.5,
+		"distanceThreshold": 1.0,
+		"rateAddNodes": 0.1
+	}`
+	if _, err := NewConfig(strings.NewReader(data)); err != nil {
+		t.Errorf("unknown setting is not ignored: %v", err)
+	}
+	_, err := NewConfigStrict(strings.NewReader(data))
+	if err == nil || !strings.Contains(err.Error(), `"rateAddNode"`) {
+		t.Errorf("unknown setting is not reported: %v", err)
+	}
+
+	_, err = NewConfigStrict(strings.NewReader(`{"zeta": 1, "alpha": 2}`))
+	if err == nil || !strings.Contains(err.Error(), `"alpha"`) {
+		t.Errorf("first unknown setting is not reported: %v", err)
+	}
+}
+
+func TestConfigMigration(t *testing.T) {
+	config, err := NewConfigStrict(strings.NewReader(`{
+		"numInputs": 3,
+		"numOutputs": 1,
+		"numGenerations": 10,
+		"popSize": 20,
+		"survivalRate": 0.5,
+		"compatThreshold": 1.0,
+		"mutAddNodeRate": 0.1
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.Version != ConfigVersion {
+		t.Errorf("invalid version: %d != %d", config.Version, ConfigVersion)
+	}
+	if config.PopulationSize != 20 || config.RateAddNode != 0.1 ||
+		config.DistanceThreshold != 1.0 {
+		t.Errorf("legacy settings are not migrated: %+v", config)
+	}
+
+	if _, err = NewConfig(strings.NewReader(`{"version": 99}`)); err == nil {
+		t.Error("newer version is not reported")
+	}
+}
+
+func TestConfigMigrationCollision(t *testing.T) {
+	// both legacy names map to rateAddConn; the first one listed must win, with
+	// the same warnings in the same order every time.
+	var first []string
+	for i := 0; i < 20; i++ {
+		settings := map[string]json.RawMessage{
+			"mutAddConnRate": json.RawMessage("0.2"),
+			"mutAddLinkRate": json.RawMessage("0.1"),
+		}
+		warnings, err := migrateConfig(settings)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(settings["rateAddConn"]) != "0.1" {
+			t.Fatalf("invalid rateAddConn: %s != 0.1", settings["rateAddConn"])
+		}
+		if first == nil {
+			first = warnings
+		} else if !reflect.DeepEqual(warnings, first) {
+			t.Fatalf("warnings are not deterministic: %v != %v", warnings, first)
+		}
+	}
+	if len(first) != 3 || !strings.Contains(first[1], `"mutAddConnRate" is `+
+		`ignored`) {
+		t.Errorf("invalid warnings: %v", first)
+	}
+}
+
+func TestConfigBindFlags(t *testing.T) {
+	config, err := NewConfigPreset("xor")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	config.BindFlags(fs)
+	err = fs.Parse([]string{"-populationSize=500", "-verbose=false",
+		"-cppnActivations=sin,gaussian"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.PopulationSize != 500 || config.Verbose {
+		t.Errorf("flags are not applied: %+v", config)
+	}
+	if config.NumInputs != 3 {
+		t.Errorf("default is not loaded from the file: %d != 3", config.NumInputs)
+	}
+	if len(config.CPPNActivations) != 2 {
+		t.Errorf("invalid CPPN activations: %v", config.CPPNActivations)
+	}
+}
+
+func TestNewConfigLegacy(t *testing.T) {
+	config, err := NewConfigLegacy(strings.NewReader(`
+# legacy parameters
+PopSize 200
+MutAddNodeRate 0.05
+mutate_add_link_prob 0.1
+compat_thresh 4.0
+dropoff_age 20.0
+weigh_mut_power 2.5
+`), 3, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.PopulationSize != 200 || config.RateAddNode != 0.05 ||
+		config.RateAddConn != 0.1 || config.DistanceThreshold != 4.0 ||
+		config.StagnationLimit != 20 {
+		t.Errorf("legacy parameters are not loaded: %+v", config)
+	}
+}
+
+func TestNewConfigPreset(t *testing.T) {
+	for _, name := range ConfigPresets() {
+		config, err := NewConfigPreset(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = config.Validate(); err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+	}
+	if _, err := NewConfigPreset("unknown"); err == nil {
+		t.Error("unknown preset is not reported")
+	}
+}
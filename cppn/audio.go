@@ -0,0 +1,107 @@
+// audio.go implementation of waveforms of CPPNs.
+//
+// Copyright (C) 2017  Jin Yeom
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cppn
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"math"
+	"os"
+)
+
+// SampleWave samples the first output of the CPPN over time, and returns a
+// waveform of the argument duration in seconds, at the argument sample rate.
+// The CPPN is queried with the time scaled to [-1, 1] over the duration,
+// followed by sin(2*pi*f*t) for each of the argument frequencies f in Hz,
+// where t is the time in seconds; thus, a CPPN of n frequencies takes n+1
+// coordinates. Samples are clipped to [-1, 1], and NaN is replaced with 0.
+func (c *CPPN) SampleWave(duration float64, sampleRate int,
+	freqs ...float64) []float64 {
+	numSamples := int(duration * float64(sampleRate))
+	wave := make([]float64, numSamples)
+	coords := make([]float64, 1+len(freqs))
+	for i := range wave {
+		t := float64(i) / float64(sampleRate)
+		coords[0] = gridCoord(i, numSamples)
+		for j, f := range freqs {
+			coords[j+1] = math.Sin(2.0 * math.Pi * f * t)
+		}
+		v := c.Query(coords...)
+		if math.IsNaN(v) {
+			v = 0.0
+		}
+		wave[i] = math.Max(-1.0, math.Min(1.0, v))
+	}
+	return wave
+}
+
+// WriteWAV writes the argument waveform of samples in [-1, 1] to the argument
+// writer as a mono 16-bit PCM WAV file of the argument sample rate.
+func WriteWAV(w io.Writer, wave []float64, sampleRate int) error {
+	const (
+		numChannels   = 1
+		bitsPerSample = 16
+	)
+	blockAlign := numChannels * bitsPerSample / 8
+	dataSize := len(wave) * blockAlign
+
+	bw := bufio.NewWriter(w)
+	header := []interface{}{
+		[4]byte{'R', 'I', 'F', 'F'},
+		uint32(36 + dataSize),
+		[4]byte{'W', 'A', 'V', 'E'},
+		[4]byte{'f', 'm', 't', ' '},
+		uint32(16),                      // size of the format chunk
+		uint16(1),                       // PCM
+		uint16(numChannels),             // number of channels
+		uint32(sampleRate),              // sample rate
+		uint32(sampleRate * blockAlign), // byte rate
+		uint16(blockAlign),              // block align
+		uint16(bitsPerSample),           // bits per sample
+		[4]byte{'d', 'a', 't', 'a'},
+		uint32(dataSize),
+	}
+	for _, field := range header {
+		if err := binary.Write(bw, binary.LittleEndian, field); err != nil {
+			return err
+		}
+	}
+	for _, v := range wave {
+		v = math.Max(-1.0, math.Min(1.0, v))
+		sample := int16(math.Round(v * math.MaxInt16))
+		if err := binary.Write(bw, binary.LittleEndian, sample); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
+
+// SaveWAV writes the argument waveform to a WAV file of the argument path (see
+// WriteWAV).
+func SaveWAV(path string, wave []float64, sampleRate int) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err = WriteWAV(f, wave, sampleRate); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,46 @@
+package cppn
+
+import (
+	"bytes"
+	"encoding/binary"
+	"path/filepath"
+	"testing"
+
+	"github.com/jinyeom/neat"
+)
+
+func TestSampleWave(t *testing.T) {
+	// a linear output of the sine of 100 Hz, i.e., a pure tone.
+	g := neat.NewGenome(0, 3, 1, 0.0)
+	g.NodeGenes[3].Activation = neat.ActivationSet["linear"]
+	g.ConnGenes = append(g.ConnGenes, neat.NewConnGene(2, 3, 2.0))
+	wave := New(g).SampleWave(0.1, 1000, 100.0)
+	if len(wave) != 100 {
+		t.Fatalf("invalid number of samples: %d", len(wave))
+	}
+	for i, v := range wave {
+		if v < -1.0 || v > 1.0 {
+			t.Errorf("sample %d is not clipped: %f", i, v)
+		}
+	}
+	if wave[0] != 0.0 || wave[3] != 1.0 {
+		t.Errorf("invalid samples: %f, %f", wave[0], wave[3])
+	}
+
+	buf := &bytes.Buffer{}
+	if err := WriteWAV(buf, wave, 1000); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+	if len(data) != 44+2*len(wave) || string(data[:4]) != "RIFF" ||
+		string(data[8:12]) != "WAVE" {
+		t.Fatalf("invalid WAV header: %q", data[:12])
+	}
+	if rate := binary.LittleEndian.Uint32(data[24:28]); rate != 1000 {
+		t.Errorf("invalid sample rate: %d", rate)
+	}
+
+	if err := SaveWAV(filepath.Join(t.TempDir(), "wave.wav"), wave, 1000); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,158 @@
+// cppn.go implementation of Compositional Pattern Producing Networks (CPPN).
+//
+// Copyright (C) 2017  Jin Yeom
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+// Package cppn provides Compositional Pattern Producing Networks (CPPN), i.e.,
+// neural networks evolved by NEAT that are queried with coordinates to produce
+// patterns, such as images or the connectivity of HyperNEAT substrates
+// (CPPN-NEAT).
+//
+// The first input of a CPPN is a bias of 1.0, which is followed by the
+// coordinates of a query; thus, a genome of a CPPN that takes d coordinates
+// has d+1 inputs.
+package cppn
+
+import (
+	"math"
+
+	"github.com/jinyeom/neat"
+)
+
+var (
+	// Activations is a set of names of activation functions that are used by
+	// hidden nodes of CPPNs, whose compositions produce regularities such as
+	// symmetry (gaussian, abs), repetition (sin, cos), and smooth variation
+	// (sigmoid, tanh, linear).
+	Activations = []string{"sigmoid", "tanh", "sin", "cos", "gaussian", "abs",
+		"linear"}
+)
+
+// CPPN is a wrapper of a neural network that is queried with coordinates.
+type CPPN struct {
+	Network *neat.NeuralNetwork // network that produces the pattern
+
+	inputs []float64 // buffer of inputs, whose first element is the bias
+}
+
+// New returns a new instance of CPPN decoded from the argument genome.
+func New(g *neat.Genome) *CPPN {
+	return FromNetwork(neat.NewNeuralNetwork(g))
+}
+
+// FromNetwork returns a new instance of CPPN that wraps the argument neural
+// network, e.g., the argument of an evaluation function.
+func FromNetwork(nn *neat.NeuralNetwork) *CPPN {
+	return &CPPN{Network: nn}
+}
+
+// QueryAll returns the outputs of the CPPN at the argument coordinates. Since
+// each query is independent, signals of a recurrent network are reset first.
+func (c *CPPN) QueryAll(coords ...float64) ([]float64, error) {
+	c.inputs = append(append(c.inputs[:0], 1.0), coords...)
+	c.Network.Reset()
+	return c.Network.FeedForward(c.inputs)
+}
+
+// Predict is QueryAll of the argument coordinates, which implements
+// neat.Predictor.
+func (c *CPPN) Predict(coords []float64) ([]float64, error) {
+	return c.QueryAll(coords...)
+}
+
+// QueryBatch returns the outputs of the CPPN at each of the argument tuples of
+// coordinates, in order. It is more efficient than repeated calls of QueryAll,
+// e.g., when decoding a large substrate, since the input buffer is reused, and
+// the outputs of all queries share a single backing array.
+func (c *CPPN) QueryBatch(coords [][]float64) ([][]float64, error) {
+	numOutputs := c.Network.NumOutputs()
+	buf := make([]float64, len(coords)*numOutputs)
+	outputs := make([][]float64, len(coords))
+	for i, query := range coords {
+		c.inputs = append(append(c.inputs[:0], 1.0), query...)
+		c.Network.Reset()
+		out, err := c.Network.FeedForwardTo(buf[i*numOutputs:i*numOutputs],
+			c.inputs)
+		if err != nil {
+			return nil, err
+		}
+		outputs[i] = out[:numOutputs:numOutputs]
+	}
+	return outputs, nil
+}
+
+// Query returns the first output of the CPPN at the argument coordinates, e.g.,
+// Query(x1, y1, x2, y2) for the weight of a connection between two points of a
+// substrate. It returns NaN if the number of coordinates does not match.
+func (c *CPPN) Query(coords ...float64) float64 {
+	outputs, err := c.QueryAll(coords...)
+	if err != nil || len(outputs) == 0 {
+		return math.NaN()
+	}
+	return outputs[0]
+}
+
+// Sample2D samples the first output of the CPPN over a grid of the argument
+// width and height that spans [-1, 1] in both dimensions, and returns the
+// outputs indexed by row, then column. If withDistance is true, the distance
+// from the center is given as an additional coordinate, i.e., the CPPN is
+// queried with (x, y, d).
+func (c *CPPN) Sample2D(width, height int, withDistance bool) [][]float64 {
+	pattern := make([][]float64, height)
+	for row := range pattern {
+		pattern[row] = make([]float64, width)
+		y := gridCoord(row, height)
+		for col := range pattern[row] {
+			x := gridCoord(col, width)
+			if withDistance {
+				pattern[row][col] = c.Query(x, y, math.Hypot(x, y))
+			} else {
+				pattern[row][col] = c.Query(x, y)
+			}
+		}
+	}
+	return pattern
+}
+
+// NewConfig returns a new default configuration of CPPN-NEAT, given the number
+// of coordinates of a query and the number of outputs; the bias is included in
+// the number of inputs, and hidden nodes use the CPPN activation set.
+func NewConfig(numCoords, numOutputs int) *neat.Config {
+	config := neat.NewDefaultConfig(numCoords+1, numOutputs)
+	config.ExperimentName = "CPPN-NEAT"
+	config.CPPNActivations = append([]string{}, Activations...)
+	return config
+}
+
+// EvaluationFunc is a type of function that evaluates a CPPN and returns its
+// fitness score.
+type EvaluationFunc func(c *CPPN) float64
+
+// Evaluation returns a NEAT evaluation function that evaluates each network as
+// a CPPN with the argument function.
+func Evaluation(evaluate EvaluationFunc) neat.EvaluationFunc {
+	return func(nn *neat.NeuralNetwork) float64 {
+		return evaluate(FromNetwork(nn))
+	}
+}
+
+// gridCoord returns the coordinate in [-1, 1] of the i-th of n points that are
+// evenly spaced (0 if there is only one point).
+func gridCoord(i, n int) float64 {
+	if n <= 1 {
+		return 0.0
+	}
+	return -1.0 + 2.0*float64(i)/float64(n-1)
+}
@@ -0,0 +1,47 @@
+package cppn
+
+import (
+	"math"
+	"testing"
+
+	"github.com/jinyeom/neat"
+)
+
+func TestCPPN(t *testing.T) {
+	config := NewConfig(2, 1)
+	if err := config.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	config.NumGenerations = 3
+	config.PopulationSize = 20
+
+	// evolve a pattern that is bright at the center.
+	evaluation := Evaluation(func(c *CPPN) float64 {
+		pattern := c.Sample2D(5, 5, false)
+		return pattern[2][2] - pattern[0][0]
+	})
+	best := neat.New(config, evaluation, neat.WithSeed(1)).Run()
+
+	c := New(best)
+	if v := c.Query(0.0, 0.0); math.IsNaN(v) {
+		t.Errorf("invalid query: %f", v)
+	}
+	if v := c.Query(0.0); !math.IsNaN(v) {
+		t.Errorf("query with invalid coordinates: %f", v)
+	}
+	if pattern := c.Sample2D(4, 3, false); len(pattern) != 3 || len(pattern[0]) != 4 {
+		t.Errorf("invalid size of pattern: %dx%d", len(pattern), len(pattern[0]))
+	}
+
+	batch, err := c.QueryBatch([][]float64{{0.0, 0.0}, {0.5, -0.5}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(batch) != 2 || batch[0][0] != c.Query(0.0, 0.0) ||
+		batch[1][0] != c.Query(0.5, -0.5) {
+		t.Errorf("invalid batch of queries: %v", batch)
+	}
+	if _, err = c.QueryBatch([][]float64{{0.0}}); err == nil {
+		t.Error("batch with invalid coordinates is not reported")
+	}
+}
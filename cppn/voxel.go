@@ -0,0 +1,166 @@
+// voxel.go implementation of 3D patterns of CPPNs.
+//
+// Copyright (C) 2017  Jin Yeom
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cppn
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+)
+
+// Voxels is a 3D pattern sampled from a CPPN over a voxel grid, e.g., the
+// material of a soft robot or a structure.
+type Voxels struct {
+	Width  int           // number of voxels in x
+	Height int           // number of voxels in y
+	Depth  int           // number of voxels in z
+	Values [][][]float64 // values indexed by z, then y, then x
+}
+
+// Sample3D samples the first output of the CPPN over a voxel grid of the
+// argument width, height, and depth that spans [-1, 1] in all dimensions. If
+// withDistance is true, the distance from the center is given as an additional
+// coordinate, i.e., the CPPN is queried with (x, y, z, d).
+func (c *CPPN) Sample3D(width, height, depth int, withDistance bool) *Voxels {
+	v := &Voxels{
+		Width:  width,
+		Height: height,
+		Depth:  depth,
+		Values: make([][][]float64, depth),
+	}
+	for k := range v.Values {
+		v.Values[k] = make([][]float64, height)
+		z := gridCoord(k, depth)
+		for j := range v.Values[k] {
+			v.Values[k][j] = make([]float64, width)
+			y := gridCoord(j, height)
+			for i := range v.Values[k][j] {
+				x := gridCoord(i, width)
+				if withDistance {
+					d := math.Sqrt(x*x + y*y + z*z)
+					v.Values[k][j][i] = c.Query(x, y, z, d)
+				} else {
+					v.Values[k][j][i] = c.Query(x, y, z)
+				}
+			}
+		}
+	}
+	return v
+}
+
+// Filled returns true if the voxel at (i, j, k) is in the grid, and its value
+// exceeds the argument threshold.
+func (v *Voxels) Filled(i, j, k int, threshold float64) bool {
+	if i < 0 || i >= v.Width || j < 0 || j >= v.Height || k < 0 || k >= v.Depth {
+		return false
+	}
+	return v.Values[k][j][i] > threshold
+}
+
+// NumFilled returns the number of voxels whose values exceed the argument
+// threshold.
+func (v *Voxels) NumFilled(threshold float64) int {
+	num := 0
+	for k := 0; k < v.Depth; k++ {
+		for j := 0; j < v.Height; j++ {
+			for i := 0; i < v.Width; i++ {
+				if v.Filled(i, j, k, threshold) {
+					num++
+				}
+			}
+		}
+	}
+	return num
+}
+
+// ExportVoxels writes the voxels whose values exceed the argument threshold to
+// the argument writer, one voxel per line as "i j k value", after a header line
+// of the dimensions of the grid.
+func (v *Voxels) ExportVoxels(w io.Writer, threshold float64) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "%d %d %d\n", v.Width, v.Height, v.Depth)
+	for k := 0; k < v.Depth; k++ {
+		for j := 0; j < v.Height; j++ {
+			for i := 0; i < v.Width; i++ {
+				if v.Filled(i, j, k, threshold) {
+					fmt.Fprintf(bw, "%d %d %d %g\n", i, j, k, v.Values[k][j][i])
+				}
+			}
+		}
+	}
+	return bw.Flush()
+}
+
+// cubeFaces are the faces of a unit cube, each of which is given by the offset
+// of the neighboring voxel it faces, and its corners in counterclockwise order
+// seen from outside.
+var cubeFaces = []struct {
+	neighbor [3]int
+	corners  [4][3]int
+}{
+	{[3]int{-1, 0, 0}, [4][3]int{{0, 0, 0}, {0, 0, 1}, {0, 1, 1}, {0, 1, 0}}},
+	{[3]int{1, 0, 0}, [4][3]int{{1, 0, 0}, {1, 1, 0}, {1, 1, 1}, {1, 0, 1}}},
+	{[3]int{0, -1, 0}, [4][3]int{{0, 0, 0}, {1, 0, 0}, {1, 0, 1}, {0, 0, 1}}},
+	{[3]int{0, 1, 0}, [4][3]int{{0, 1, 0}, {0, 1, 1}, {1, 1, 1}, {1, 1, 0}}},
+	{[3]int{0, 0, -1}, [4][3]int{{0, 0, 0}, {0, 1, 0}, {1, 1, 0}, {1, 0, 0}}},
+	{[3]int{0, 0, 1}, [4][3]int{{0, 0, 1}, {1, 0, 1}, {1, 1, 1}, {0, 1, 1}}},
+}
+
+// ExportOBJ writes a mesh of the voxels whose values exceed the argument
+// threshold to the argument writer in Wavefront OBJ format. Each voxel is a
+// unit cube, and only faces that are not shared by two filled voxels are
+// written, such that the mesh is the surface of the structure.
+func (v *Voxels) ExportOBJ(w io.Writer, threshold float64) error {
+	bw := bufio.NewWriter(w)
+	vertices := make(map[[3]int]int)
+	vertex := func(p [3]int) int {
+		if id, ok := vertices[p]; ok {
+			return id
+		}
+		vertices[p] = len(vertices) + 1
+		fmt.Fprintf(bw, "v %d %d %d\n", p[0], p[1], p[2])
+		return vertices[p]
+	}
+
+	var faces [][4]int
+	for k := 0; k < v.Depth; k++ {
+		for j := 0; j < v.Height; j++ {
+			for i := 0; i < v.Width; i++ {
+				if !v.Filled(i, j, k, threshold) {
+					continue
+				}
+				for _, face := range cubeFaces {
+					n := face.neighbor
+					if v.Filled(i+n[0], j+n[1], k+n[2], threshold) {
+						continue
+					}
+					var f [4]int
+					for c, corner := range face.corners {
+						f[c] = vertex([3]int{i + corner[0], j + corner[1], k + corner[2]})
+					}
+					faces = append(faces, f)
+				}
+			}
+		}
+	}
+	for _, f := range faces {
+		fmt.Fprintf(bw, "f %d %d %d %d\n", f[0], f[1], f[2], f[3])
+	}
+	return bw.Flush()
+}
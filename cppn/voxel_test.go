@@ -0,0 +1,48 @@
+package cppn
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/jinyeom/neat"
+)
+
+func TestVoxels(t *testing.T) {
+	// a sphere around the center, i.e., sigmoid(5 - 10d).
+	g := neat.NewGenome(0, 5, 1, 0.0)
+	g.ConnGenes = append(g.ConnGenes,
+		neat.NewConnGene(0, 5, 5.0), neat.NewConnGene(4, 5, -10.0))
+	v := New(g).Sample3D(3, 3, 3, true)
+	if num := v.NumFilled(0.5); num != 1 || !v.Filled(1, 1, 1, 0.5) {
+		t.Fatalf("invalid number of filled voxels: %d", num)
+	}
+
+	buf := &bytes.Buffer{}
+	if err := v.ExportVoxels(buf, 0.5); err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Split(strings.TrimSpace(buf.String()), "\n"); len(lines) != 2 ||
+		!strings.HasPrefix(lines[1], "1 1 1 ") {
+		t.Errorf("invalid voxels: %q", buf.String())
+	}
+
+	buf.Reset()
+	if err := v.ExportOBJ(buf, 0.5); err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(buf.String(), "v "); n != 8 {
+		t.Errorf("invalid number of vertices: %d", n)
+	}
+	if n := strings.Count(buf.String(), "f "); n != 6 {
+		t.Errorf("invalid number of faces: %d", n)
+	}
+
+	// faces between two filled voxels are not a part of the surface.
+	v.Values[1][1][2] = 1.0
+	buf.Reset()
+	v.ExportOBJ(buf, 0.5)
+	if n := strings.Count(buf.String(), "f "); n != 10 {
+		t.Errorf("invalid number of faces of two voxels: %d", n)
+	}
+}
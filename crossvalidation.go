@@ -0,0 +1,152 @@
+// crossvalidation.go implementation of k-fold and holdout evaluation of
+// supervised tasks.
+//
+// Copyright (C) 2017  Jin Yeom
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package neat
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+)
+
+// DatasetEvaluationFunc is a type of function that evaluates a neural network
+// on the samples of the argument indices of a dataset, e.g., its accuracy on
+// them; it may draw random numbers from the evaluation information of the
+// context (see EvaluationInfoFrom), which is seeded per fold.
+type DatasetEvaluationFunc func(ctx context.Context, n *NeuralNetwork,
+	indices []int) (float64, error)
+
+// Fold is a split of the samples of a dataset into the training samples, on
+// which genomes are evaluated during the evolution, and the validation
+// samples, which are held out.
+type Fold struct {
+	Train      []int // indices of the training samples
+	Validation []int // indices of the validation samples
+}
+
+// KFold returns k folds of the argument number of samples, which are shuffled
+// with the argument seed, such that each sample is validated in exactly one
+// fold and trained on in the others.
+func KFold(numSamples, k int, seed int64) ([]Fold, error) {
+	if k < 2 || k > numSamples {
+		return nil, fmt.Errorf("neat: invalid number of folds %d for %d samples",
+			k, numSamples)
+	}
+	perm := rand.New(rand.NewSource(seed)).Perm(numSamples)
+	folds := make([]Fold, k)
+	for i := range folds {
+		lo, hi := i*numSamples/k, (i+1)*numSamples/k
+		folds[i].Validation = append([]int{}, perm[lo:hi]...)
+		folds[i].Train = append(append([]int{}, perm[:lo]...), perm[hi:]...)
+	}
+	return folds, nil
+}
+
+// Holdout returns a single fold of the argument number of samples, which are
+// shuffled with the argument seed, whose argument fraction of samples is held
+// out for validation.
+func Holdout(numSamples int, fraction float64, seed int64) (Fold, error) {
+	numValidation := int(math.Round(fraction * float64(numSamples)))
+	if fraction <= 0.0 || fraction >= 1.0 || numValidation < 1 ||
+		numValidation >= numSamples {
+		return Fold{}, fmt.Errorf("neat: invalid holdout fraction %f for %d "+
+			"samples", fraction, numSamples)
+	}
+	perm := rand.New(rand.NewSource(seed)).Perm(numSamples)
+	return Fold{
+		Train:      perm[numValidation:],
+		Validation: perm[:numValidation],
+	}, nil
+}
+
+// CrossValidation is an evaluator of supervised tasks, which evaluates each
+// neural network on the training samples of every fold and aggregates the
+// scores into its fitness; the validation samples are only evaluated by its
+// validation function, e.g., with WithValidation, such that overfitting can be
+// detected.
+type CrossValidation struct {
+	Evaluation DatasetEvaluationFunc          // evaluation on samples
+	Folds      []Fold                         // folds of the dataset
+	Aggregate  func(scores []float64) float64 // aggregation of fold scores
+}
+
+// NewCrossValidation returns a new instance of CrossValidation, given an
+// evaluation function on samples and the folds of the dataset, which
+// aggregates the scores of the folds by their mean.
+func NewCrossValidation(evaluation DatasetEvaluationFunc,
+	folds []Fold) *CrossValidation {
+	return &CrossValidation{
+		Evaluation: evaluation,
+		Folds:      folds,
+		Aggregate:  Mean,
+	}
+}
+
+// Mean returns the mean of the argument scores (0 if there is none).
+func Mean(scores []float64) float64 {
+	mean, _ := meanVariance(scores)
+	return mean
+}
+
+// ContextEvaluation returns a context-aware evaluation function of the
+// aggregated score of a neural network on the training samples of the folds;
+// it fails if the evaluation of any fold fails.
+func (c *CrossValidation) ContextEvaluation() ContextEvaluationFunc {
+	return func(ctx context.Context, n *NeuralNetwork) (float64, error) {
+		return c.evaluate(ctx, n, func(f Fold) []int { return f.Train })
+	}
+}
+
+// Validation returns an evaluation function of the aggregated score of a
+// neural network on the validation samples of the folds, which is NaN if the
+// evaluation of any fold fails.
+func (c *CrossValidation) Validation() EvaluationFunc {
+	return func(n *NeuralNetwork) float64 {
+		score, err := c.evaluate(context.Background(), n,
+			func(f Fold) []int { return f.Validation })
+		if err != nil {
+			return math.NaN()
+		}
+		return score
+	}
+}
+
+// evaluate returns the aggregated score of the argument neural network on the
+// samples of the folds chosen by the argument function. Each fold is evaluated
+// with its own evaluation information, whose seeds are derived from those of
+// the argument context and the index of the fold.
+func (c *CrossValidation) evaluate(ctx context.Context, n *NeuralNetwork,
+	samples func(f Fold) []int) (float64, error) {
+	info, _ := EvaluationInfoFrom(ctx)
+	scores := make([]float64, len(c.Folds))
+	for i, fold := range c.Folds {
+		foldInfo := info
+		foldInfo.Fold = i
+		foldInfo.Seed = int64(mixSeed(uint64(info.Seed) ^ uint64(i)))
+		foldInfo.CommonSeed = int64(mixSeed(uint64(info.CommonSeed) ^ uint64(i)))
+
+		score, err := c.Evaluation(context.WithValue(ctx, evaluationInfoKey{},
+			foldInfo), n, samples(fold))
+		if err != nil {
+			return 0.0, fmt.Errorf("fold %d: %v", i, err)
+		}
+		scores[i] = score
+	}
+	return c.Aggregate(scores), nil
+}
@@ -0,0 +1,86 @@
+package neat
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sort"
+	"testing"
+)
+
+func TestKFold(t *testing.T) {
+	folds, err := KFold(10, 3, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	validated := []int{}
+	for i, fold := range folds {
+		if len(fold.Train)+len(fold.Validation) != 10 {
+			t.Errorf("invalid number of samples in fold %d: %+v", i, fold)
+		}
+		validated = append(validated, fold.Validation...)
+	}
+	sort.Ints(validated)
+	for i, index := range validated {
+		if i != index {
+			t.Fatalf("samples are not validated exactly once: %v", validated)
+		}
+	}
+	if _, err := KFold(2, 3, 0); err == nil {
+		t.Error("more folds than samples are not rejected")
+	}
+
+	fold, err := Holdout(10, 0.2, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fold.Train) != 8 || len(fold.Validation) != 2 {
+		t.Errorf("invalid holdout fold: %+v", fold)
+	}
+	if _, err := Holdout(10, 1.0, 0); err == nil {
+		t.Error("invalid holdout fraction is not rejected")
+	}
+}
+
+func TestCrossValidation(t *testing.T) {
+	folds, err := KFold(6, 2, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var seeds []int64
+	evaluation := func(ctx context.Context, n *NeuralNetwork,
+		indices []int) (float64, error) {
+		info, _ := EvaluationInfoFrom(ctx)
+		seeds = append(seeds, info.Seed)
+		return float64(len(indices) + info.Fold), nil
+	}
+	c := NewCrossValidation(evaluation, folds)
+	nn := NewNeuralNetwork(NewGenome(0, 3, 1, 0.0))
+
+	ctx := context.WithValue(context.Background(), evaluationInfoKey{},
+		newEvaluationInfo(0, 1, 2))
+	fitness, err := c.ContextEvaluation()(ctx, nn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fitness != 3.5 {
+		t.Errorf("invalid fitness: %f", fitness)
+	}
+	if len(seeds) != 2 || seeds[0] == seeds[1] {
+		t.Errorf("folds are not seeded separately: %v", seeds)
+	}
+	if validation := c.Validation()(nn); validation != 3.5 {
+		t.Errorf("invalid validation score: %f", validation)
+	}
+
+	c.Evaluation = func(ctx context.Context, n *NeuralNetwork,
+		indices []int) (float64, error) {
+		return 0.0, errors.New("failure")
+	}
+	if _, err := c.ContextEvaluation()(ctx, nn); err == nil {
+		t.Error("failure of a fold is not returned")
+	}
+	if validation := c.Validation()(nn); !math.IsNaN(validation) {
+		t.Errorf("invalid validation score of a failure: %f", validation)
+	}
+}
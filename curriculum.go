@@ -0,0 +1,83 @@
+// curriculum.go implementation of curricula of progressively difficult tasks.
+//
+// Copyright (C) 2017  Jin Yeom
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package neat
+
+// Stage is a single stage of a curriculum, which consists of an evaluation
+// function and the target fitness score that has to be reached by the best
+// genome before advancing to the next stage.
+type Stage struct {
+	Evaluation    EvaluationFunc // evaluation function of this stage
+	TargetFitness float64        // fitness score required to advance
+}
+
+// NewStage returns a new instance of Stage, given an evaluation function and
+// its target fitness score.
+func NewStage(evaluation EvaluationFunc, targetFitness float64) *Stage {
+	return &Stage{
+		Evaluation:    evaluation,
+		TargetFitness: targetFitness,
+	}
+}
+
+// Curriculum is a sequence of stages of increasing difficulty. During the
+// evolution, the population is evaluated with the current stage's evaluation
+// function; once the best genome reaches the stage's target fitness, the
+// curriculum advances to the next stage. The last stage is never left.
+type Curriculum struct {
+	Stages  []*Stage // stages in order of difficulty
+	Current int      // index of the current stage
+}
+
+// NewCurriculum returns a new instance of Curriculum, given its stages in order
+// of difficulty.
+func NewCurriculum(stages ...*Stage) *Curriculum {
+	return &Curriculum{
+		Stages:  stages,
+		Current: 0,
+	}
+}
+
+// Stage returns the current stage of this curriculum.
+func (c *Curriculum) Stage() *Stage {
+	return c.Stages[c.Current]
+}
+
+// Done returns true if the curriculum is at its last stage.
+func (c *Curriculum) Done() bool {
+	return c.Current >= len(c.Stages)-1
+}
+
+// Reached returns true if the argument fitness score reaches the target fitness
+// of the current stage, given an indicator of whether the fitness is better
+// when minimized.
+func (c *Curriculum) Reached(fitness float64, minimize bool) bool {
+	if minimize {
+		return fitness <= c.Stage().TargetFitness
+	}
+	return fitness >= c.Stage().TargetFitness
+}
+
+// Advance moves the curriculum to its next stage and returns true, unless it is
+// already at its last stage.
+func (c *Curriculum) Advance() bool {
+	if c.Done() {
+		return false
+	}
+	c.Current++
+	return true
+}
@@ -0,0 +1,24 @@
+package neat
+
+import (
+	"testing"
+)
+
+func TestCurriculum(t *testing.T) {
+	c := NewCurriculum(
+		NewStage(PoleLengthBalancingTest(false, 1000, 0.5), 500.0),
+		NewStage(PoleLengthBalancingTest(false, 1000, 1.0), 1000.0))
+
+	if c.Reached(499.0, false) {
+		t.Error("stage reached below the target fitness")
+	}
+	if !c.Reached(500.0, false) {
+		t.Error("stage not reached at the target fitness")
+	}
+	if !c.Advance() || c.Current != 1 {
+		t.Error("failed to advance to the next stage")
+	}
+	if !c.Done() || c.Advance() {
+		t.Error("advanced beyond the last stage")
+	}
+}
@@ -0,0 +1,207 @@
+// dashboard.go implementation of a live web dashboard of NEAT.
+//
+// Copyright (C) 2017  Jin Yeom
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+// Package dashboard provides a local web dashboard of NEAT, which shows the
+// fitness curves, the species chart, and the network of the best genome,
+// updated live during Run. Updates are pushed to browsers with server-sent
+// events, so that the package only depends on the standard library.
+package dashboard
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/jinyeom/neat"
+)
+
+// Snapshot is the state of the evolution at the end of a generation, which is
+// sent to the browsers.
+type Snapshot struct {
+	Generation int                  `json:"generation"` // index of the generation
+	NumSpecies int                  `json:"numSpecies"` // number of species
+	MinFitness float64              `json:"minFitness"` // minimum fitness
+	MaxFitness float64              `json:"maxFitness"` // maximum fitness
+	AvgFitness float64              `json:"avgFitness"` // average fitness
+	Species    []neat.SpeciesRecord `json:"species"`    // species compositions
+	Best       *neat.Genome         `json:"best"`       // best genome so far
+}
+
+// Dashboard is a web server that records a snapshot of each generation, and
+// pushes it to the connected browsers. Only the latest snapshot keeps the best
+// genome, and at most Window snapshots are kept, such that the history of a
+// long run stays small.
+type Dashboard struct {
+	Addr   string // address to listen on, e.g., "localhost:8080"
+	Window int    // number of snapshots kept; 0 for the statistics window
+
+	mu        sync.Mutex           // mutex of the snapshots and the clients
+	snapshots []*Snapshot          // snapshots of the generations so far
+	clients   map[chan []byte]bool // channels of the connected browsers
+}
+
+// New returns a new instance of Dashboard, given the address to listen on.
+func New(addr string) *Dashboard {
+	return &Dashboard{
+		Addr:      addr,
+		snapshots: []*Snapshot{},
+		clients:   make(map[chan []byte]bool),
+	}
+}
+
+// Callback returns a callback that records a snapshot of each generation, and
+// pushes it to the connected browsers; it can be added to NEAT with
+// neat.WithCallback. Like the statistics, it only records every
+// Config.StatisticsStride generations, and keeps the last
+// Config.StatisticsWindow snapshots unless d.Window is set.
+func (d *Dashboard) Callback() neat.Callback {
+	return func(n *neat.NEAT, gen int) {
+		stats := n.Statistics.At(gen)
+		if stats == nil {
+			return // not recorded in bounded statistics
+		}
+		window := d.Window
+		if window == 0 {
+			window = n.Config.StatisticsWindow
+		}
+		d.publish(window, &Snapshot{
+			Generation: gen,
+			NumSpecies: stats.NumSpecies,
+			MinFitness: stats.MinFitness,
+			MaxFitness: stats.MaxFitness,
+			AvgFitness: stats.AvgFitness,
+			Species:    stats.Species,
+			Best:       n.Best.Copy(),
+		})
+	}
+}
+
+// Publish records the argument snapshot, and pushes it to the connected
+// browsers. Browsers that are too slow to receive it miss the update. The best
+// genome of the previous snapshot is dropped, and the oldest snapshot is
+// dropped if more than d.Window snapshots are recorded (unless it is 0).
+func (d *Dashboard) Publish(s *Snapshot) {
+	d.publish(d.Window, s)
+}
+
+// publish is Publish, given the maximum number of snapshots to keep.
+func (d *Dashboard) publish(window int, s *Snapshot) {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if last := len(d.snapshots) - 1; last >= 0 && d.snapshots[last].Best != nil {
+		// copied, since the previous snapshot may be held by a caller of
+		// Snapshots.
+		previous := *d.snapshots[last]
+		previous.Best = nil
+		d.snapshots[last] = &previous
+	}
+	d.snapshots = append(d.snapshots, s)
+	if window > 0 && len(d.snapshots) > window {
+		numDropped := len(d.snapshots) - window
+		for i := 0; i < numDropped; i++ {
+			d.snapshots[i] = nil
+		}
+		d.snapshots = d.snapshots[numDropped:]
+	}
+	for client := range d.clients {
+		select {
+		case client <- data:
+		default:
+		}
+	}
+}
+
+// Snapshots returns the snapshots recorded so far, of which only the latest
+// has the best genome.
+func (d *Dashboard) Snapshots() []*Snapshot {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	snapshots := make([]*Snapshot, len(d.snapshots))
+	copy(snapshots, d.snapshots)
+	return snapshots
+}
+
+// Handler returns the HTTP handler of the dashboard, which serves the web UI at
+// "/", the recorded snapshots at "/history", and the live updates at "/events".
+func (d *Dashboard) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", d.serveIndex)
+	mux.HandleFunc("/history", d.serveHistory)
+	mux.HandleFunc("/events", d.serveEvents)
+	return mux
+}
+
+// ListenAndServe serves the dashboard on d.Addr; it blocks, thus it is usually
+// called in a separate goroutine before Run.
+func (d *Dashboard) ListenAndServe() error {
+	return http.ListenAndServe(d.Addr, d.Handler())
+}
+
+// serveIndex serves the web UI.
+func (d *Dashboard) serveIndex(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	fmt.Fprint(w, indexHTML)
+}
+
+// serveHistory serves the snapshots so far as a JSON array.
+func (d *Dashboard) serveHistory(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(d.Snapshots())
+}
+
+// serveEvents pushes each new snapshot as a server-sent event, until the
+// browser disconnects.
+func (d *Dashboard) serveEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming is not supported", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	flusher.Flush()
+
+	client := make(chan []byte, 16)
+	d.mu.Lock()
+	d.clients[client] = true
+	d.mu.Unlock()
+	defer func() {
+		d.mu.Lock()
+		delete(d.clients, client)
+		d.mu.Unlock()
+	}()
+
+	for {
+		select {
+		case data := <-client:
+			fmt.Fprintf(w, "data: %s\n\n", data)
+			flusher.Flush()
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
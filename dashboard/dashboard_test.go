@@ -0,0 +1,83 @@
+package dashboard
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jinyeom/neat"
+)
+
+func TestDashboard(t *testing.T) {
+	config := neat.NewDefaultConfig(3, 1)
+	config.NumGenerations = 3
+	config.PopulationSize = 20
+
+	d := New("localhost:0")
+	neat.New(config, neat.XORTest(), neat.WithSeed(1),
+		neat.WithCallback(d.Callback())).Run()
+
+	server := httptest.NewServer(d.Handler())
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/history")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	var snapshots []*Snapshot
+	if err = json.NewDecoder(resp.Body).Decode(&snapshots); err != nil {
+		t.Fatal(err)
+	}
+	if len(snapshots) != config.NumGenerations {
+		t.Fatalf("invalid number of snapshots: %d != %d",
+			len(snapshots), config.NumGenerations)
+	}
+	if snapshots[2].Generation != 2 || snapshots[2].Best == nil {
+		t.Errorf("invalid snapshot: %+v", snapshots[2])
+	}
+	if snapshots[0].Best != nil || snapshots[1].Best != nil {
+		t.Error("best genome is kept in a previous snapshot")
+	}
+
+	resp, err = http.Get(server.URL + "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("invalid status of the web UI: %d", resp.StatusCode)
+	}
+}
+
+func TestDashboardWindow(t *testing.T) {
+	config := neat.NewDefaultConfig(3, 1)
+	config.NumGenerations = 5
+	config.PopulationSize = 20
+	config.StatisticsWindow = 2
+
+	d := New("localhost:0")
+	var held *Snapshot
+	neat.New(config, neat.XORTest(), neat.WithSeed(1),
+		neat.WithCallback(d.Callback()),
+		neat.WithCallback(func(n *neat.NEAT, gen int) {
+			if gen == 0 {
+				held = d.Snapshots()[0]
+			}
+		})).Run()
+
+	snapshots := d.Snapshots()
+	if len(snapshots) != config.StatisticsWindow {
+		t.Fatalf("invalid number of snapshots: %d != %d", len(snapshots),
+			config.StatisticsWindow)
+	}
+	if snapshots[0].Generation != 3 || snapshots[1].Generation != 4 {
+		t.Errorf("invalid generations: %d, %d", snapshots[0].Generation,
+			snapshots[1].Generation)
+	}
+	if held == nil || held.Best == nil {
+		t.Error("snapshot held by a caller is modified")
+	}
+}
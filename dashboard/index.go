@@ -0,0 +1,140 @@
+// index.go implementation of the web UI of the dashboard.
+//
+// Copyright (C) 2017  Jin Yeom
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package dashboard
+
+// indexHTML is the web UI of the dashboard, which loads the snapshots so far
+// from "/history", then listens to "/events" for new ones.
+const indexHTML = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>NEAT Dashboard</title>
+<style>
+body { font-family: sans-serif; margin: 20px; background: #fafafa; }
+canvas { background: #fff; border: 1px solid #ddd; margin: 5px; }
+#status { color: #666; }
+</style>
+</head>
+<body>
+<h2>NEAT Dashboard</h2>
+<p id="status">Waiting for the first generation...</p>
+<canvas id="fitness" width="600" height="300"></canvas>
+<canvas id="species" width="600" height="300"></canvas>
+<canvas id="network" width="600" height="400"></canvas>
+<script>
+var snapshots = [];
+
+function plot(id, title, series) {
+	var c = document.getElementById(id), ctx = c.getContext("2d");
+	ctx.clearRect(0, 0, c.width, c.height);
+	ctx.fillStyle = "#000";
+	ctx.fillText(title, 10, 15);
+	var lo = Infinity, hi = -Infinity;
+	series.forEach(function(s) {
+		s.values.forEach(function(v) { lo = Math.min(lo, v); hi = Math.max(hi, v); });
+	});
+	if (!isFinite(lo)) return;
+	if (hi === lo) { hi = lo + 1; }
+	var n = Math.max(snapshots.length - 1, 1);
+	series.forEach(function(s, k) {
+		ctx.strokeStyle = s.color;
+		ctx.beginPath();
+		s.values.forEach(function(v, i) {
+			var x = 40 + (c.width - 60) * i / n;
+			var y = c.height - 20 - (c.height - 50) * (v - lo) / (hi - lo);
+			if (i === 0) { ctx.moveTo(x, y); } else { ctx.lineTo(x, y); }
+		});
+		ctx.stroke();
+		ctx.fillStyle = s.color;
+		ctx.fillText(s.name, c.width - 120, 15 + 12 * k);
+	});
+	ctx.fillStyle = "#000";
+	ctx.fillText(hi.toFixed(3), 2, 35);
+	ctx.fillText(lo.toFixed(3), 2, c.height - 20);
+}
+
+function drawNetwork(genome) {
+	var c = document.getElementById("network"), ctx = c.getContext("2d");
+	ctx.clearRect(0, 0, c.width, c.height);
+	ctx.fillStyle = "#000";
+	ctx.fillText("Best genome (fitness " + genome.fitness.toFixed(4) + ")", 10, 15);
+	var columns = { input: [], hidden: [], output: [] }, pos = {};
+	genome.nodeGenes.forEach(function(node) {
+		(columns[node.type] || columns.hidden).push(node);
+	});
+	["input", "hidden", "output"].forEach(function(type, col) {
+		var nodes = columns[type];
+		nodes.forEach(function(node, i) {
+			pos[node.id] = {
+				x: 60 + col * (c.width - 120) / 2,
+				y: 40 + (i + 0.5) * (c.height - 60) / nodes.length
+			};
+		});
+	});
+	genome.connGenes.forEach(function(conn) {
+		if (conn.disabled || !pos[conn.from] || !pos[conn.to]) return;
+		ctx.strokeStyle = conn.weight > 0 ? "#c33" : "#33c";
+		ctx.lineWidth = Math.min(Math.abs(conn.weight), 4) + 0.5;
+		ctx.beginPath();
+		ctx.moveTo(pos[conn.from].x, pos[conn.from].y);
+		ctx.lineTo(pos[conn.to].x, pos[conn.to].y);
+		ctx.stroke();
+	});
+	ctx.lineWidth = 1;
+	genome.nodeGenes.forEach(function(node) {
+		var p = pos[node.id];
+		ctx.fillStyle = "#fff";
+		ctx.strokeStyle = "#000";
+		ctx.beginPath();
+		ctx.arc(p.x, p.y, 8, 0, 2 * Math.PI);
+		ctx.fill();
+		ctx.stroke();
+	});
+}
+
+function render() {
+	var last = snapshots[snapshots.length - 1];
+	document.getElementById("status").textContent =
+		"Generation " + last.generation + " | species: " + last.numSpecies +
+		" | best: " + last.best.fitness.toFixed(4);
+	plot("fitness", "Fitness", [
+		{ name: "max", color: "#c33", values: snapshots.map(function(s) { return s.maxFitness; }) },
+		{ name: "avg", color: "#3a3", values: snapshots.map(function(s) { return s.avgFitness; }) },
+		{ name: "min", color: "#33c", values: snapshots.map(function(s) { return s.minFitness; }) }
+	]);
+	plot("species", "Number of species", [
+		{ name: "species", color: "#a3a", values: snapshots.map(function(s) { return s.numSpecies; }) }
+	]);
+	drawNetwork(last.best);
+}
+
+fetch("/history").then(function(r) { return r.json(); }).then(function(history) {
+	snapshots = history || [];
+	if (snapshots.length > 0) { render(); }
+	var events = new EventSource("/events");
+	events.onmessage = function(e) {
+		// only the latest snapshot keeps the best genome, as in the server.
+		if (snapshots.length > 0) { snapshots[snapshots.length - 1].best = null; }
+		snapshots.push(JSON.parse(e.data));
+		render();
+	};
+});
+</script>
+</body>
+</html>
+`
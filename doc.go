@@ -35,6 +35,7 @@ template is provided below, and an evaluation method of a neural network, and
 run.
 
   {
+  	"version": 1,
   	"experimentName": "XOR Test",
   	"verbose": true,
   	"numInputs": 3,
@@ -0,0 +1,157 @@
+// environment.go implementation of pools of environments for evaluation.
+//
+// Copyright (C) 2017  Jin Yeom
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package neat
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// errPoolClosed is the error of checking out an environment from a closed pool.
+var errPoolClosed = errors.New("neat: environment pool is closed")
+
+// Environment is an interface of simulators that are expensive to construct,
+// e.g., a physics engine or an external process, thus are reused between
+// evaluations instead.
+type Environment interface {
+	// Reset restores the initial state of the environment before an
+	// evaluation.
+	Reset() error
+
+	// Close releases the resources of the environment.
+	Close() error
+}
+
+// EnvironmentFunc is a type of function that constructs a new environment.
+type EnvironmentFunc func() (Environment, error)
+
+// EnvironmentEvaluationFunc is a type of function that evaluates an argument
+// neural network in the argument environment under the argument context, and
+// returns its fitness score, or an error if the evaluation failed.
+type EnvironmentEvaluationFunc func(ctx context.Context, env Environment,
+	n *NeuralNetwork) (float64, error)
+
+// EnvironmentPool is a pool of environments, from which each evaluation checks
+// out an environment, and returns it when it is done. Since environments are
+// only constructed when none of them is idle, there are as many environments
+// as evaluations that run concurrently (see Config.NumWorkers).
+type EnvironmentPool struct {
+	New EnvironmentFunc // constructor of environments
+
+	mutex  sync.Mutex    // mutex of the environments
+	idle   []Environment // environments that are not checked out
+	size   int           // number of environments of the pool
+	closed bool          // true if the pool is closed
+}
+
+// NewEnvironmentPool returns a new empty pool of environments that are
+// constructed with the argument function.
+func NewEnvironmentPool(newEnv EnvironmentFunc) *EnvironmentPool {
+	return &EnvironmentPool{New: newEnv}
+}
+
+// Get checks out an idle environment, or a new one if there is none, and
+// resets it. An environment that fails to reset is closed and discarded.
+func (p *EnvironmentPool) Get() (Environment, error) {
+	p.mutex.Lock()
+	if p.closed {
+		p.mutex.Unlock()
+		return nil, errPoolClosed
+	}
+	var env Environment
+	if len(p.idle) > 0 {
+		env = p.idle[len(p.idle)-1]
+		p.idle = p.idle[:len(p.idle)-1]
+	}
+	p.mutex.Unlock()
+
+	if env == nil {
+		var err error
+		if env, err = p.New(); err != nil {
+			return nil, err
+		}
+		p.mutex.Lock()
+		p.size++
+		p.mutex.Unlock()
+	}
+	if err := env.Reset(); err != nil {
+		p.discard(env)
+		return nil, err
+	}
+	return env, nil
+}
+
+// Put returns the argument environment checked out by Get to the pool; if the
+// pool is already closed, the environment is closed instead.
+func (p *EnvironmentPool) Put(env Environment) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if p.closed {
+		env.Close()
+		return
+	}
+	p.idle = append(p.idle, env)
+}
+
+// Len returns the number of environments constructed by the pool that are not
+// discarded.
+func (p *EnvironmentPool) Len() int {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return p.size
+}
+
+// Close closes every idle environment, and the others when they are returned;
+// it returns the first error of closing them.
+func (p *EnvironmentPool) Close() error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.closed = true
+	var first error
+	for _, env := range p.idle {
+		if err := env.Close(); err != nil && first == nil {
+			first = err
+		}
+	}
+	p.idle = nil
+	return first
+}
+
+// Evaluation returns an evaluation function that evaluates each network in an
+// environment checked out from the pool with the argument function (see
+// WithContextEvaluation).
+func (p *EnvironmentPool) Evaluation(
+	evaluate EnvironmentEvaluationFunc) ContextEvaluationFunc {
+	return func(ctx context.Context, n *NeuralNetwork) (float64, error) {
+		env, err := p.Get()
+		if err != nil {
+			return 0.0, err
+		}
+		defer p.Put(env)
+		return evaluate(ctx, env, n)
+	}
+}
+
+// discard closes the argument environment, and removes it from the pool.
+func (p *EnvironmentPool) discard(env Environment) {
+	env.Close()
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.size--
+}
@@ -0,0 +1,89 @@
+package neat
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+// counterEnv is an environment that counts its resets, and is in use while an
+// evaluation holds it.
+type counterEnv struct {
+	resets int
+	inUse  int32
+	closed bool
+}
+
+func (e *counterEnv) Reset() error {
+	e.resets++
+	return nil
+}
+
+func (e *counterEnv) Close() error {
+	e.closed = true
+	return nil
+}
+
+func TestEnvironmentPool(t *testing.T) {
+	var mutex sync.Mutex
+	var envs []*counterEnv
+	pool := NewEnvironmentPool(func() (Environment, error) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		env := &counterEnv{}
+		envs = append(envs, env)
+		return env, nil
+	})
+
+	config := NewDefaultConfig(3, 1)
+	config.NumGenerations = 3
+	config.PopulationSize = 40
+	config.NumWorkers = 4
+
+	var shared int32
+	evaluate := pool.Evaluation(func(ctx context.Context, env Environment,
+		nn *NeuralNetwork) (float64, error) {
+		e := env.(*counterEnv)
+		if !atomic.CompareAndSwapInt32(&e.inUse, 0, 1) {
+			atomic.AddInt32(&shared, 1)
+		}
+		defer atomic.StoreInt32(&e.inUse, 0)
+		return XORTest()(nn), nil
+	})
+	New(config, nil, WithSeed(0), WithContextEvaluation(evaluate)).Run()
+
+	if shared != 0 {
+		t.Errorf("environments are shared by %d evaluations", shared)
+	}
+	if pool.Len() == 0 || pool.Len() > config.NumWorkers {
+		t.Errorf("invalid number of environments: %d", pool.Len())
+	}
+	resets := 0
+	for _, env := range envs {
+		resets += env.resets
+	}
+	if resets < config.PopulationSize {
+		t.Errorf("environments are not reset: %d", resets)
+	}
+
+	if err := pool.Close(); err != nil {
+		t.Fatal(err)
+	}
+	for _, env := range envs {
+		if !env.closed {
+			t.Error("environment is not closed")
+		}
+	}
+	if _, err := pool.Get(); err == nil {
+		t.Error("closed pool returns an environment")
+	}
+
+	failing := NewEnvironmentPool(func() (Environment, error) {
+		return nil, errors.New("simulator is not installed")
+	})
+	if _, err := failing.Evaluation(nil)(context.Background(), nil); err == nil {
+		t.Error("failure of construction is not returned")
+	}
+}
@@ -18,15 +18,184 @@
 package neat
 
 import (
-	"log"
+	"context"
 	"math"
 	"math/rand"
 )
 
 // EvaluationFunc is a type of function that evaluates an argument neural
-// network and returns a its fitness (performance) score.
+// network and returns a its fitness (performance) score. An evaluation that
+// fails should return NaN, which is recorded as a failure, rather than stop
+// the process; the built-in tasks return NaN if the network does not match the
+// number of inputs.
 type EvaluationFunc func(*NeuralNetwork) float64
 
+// FallibleEvaluationFunc is a type of function that evaluates an argument
+// neural network and returns its fitness score, or an error if the evaluation
+// failed, e.g., if an external simulator crashed (see WithFallibleEvaluation).
+type FallibleEvaluationFunc func(*NeuralNetwork) (float64, error)
+
+// ContextEvaluationFunc is a type of function that evaluates an argument
+// neural network under the argument context, and returns its fitness score, or
+// an error if the evaluation failed (see WithContextEvaluation). The context is
+// canceled when the evaluation exceeds Config.EvaluationTimeout, or the run is
+// canceled; a long simulation should check ctx.Done() regularly, and return
+// early, such that it does not keep running in the background.
+type ContextEvaluationFunc func(ctx context.Context, n *NeuralNetwork) (float64,
+	error)
+
+// EvaluationResult is a result of an evaluation of a network, which may include
+// more than its fitness score.
+type EvaluationResult struct {
+	Fitness    float64   // fitness score
+	Objectives []float64 // values of multiple objectives, maximized (optional)
+	Behavior   []float64 // behavior descriptor (optional)
+
+	// named auxiliary metrics, e.g., the number of steps survived or the
+	// distance traveled, which do not affect the evolution (optional)
+	Metrics map[string]float64
+}
+
+// ResultEvaluationFunc is a type of function that evaluates an argument neural
+// network under the argument context as ContextEvaluationFunc, and returns the
+// result of the evaluation (see WithResultEvaluation); e.g., the values of
+// multiple objectives are recorded in the genome for Pareto-based selection
+// (see ParetoSelection), in which case the fitness may be derived from them,
+// or left unused. Likewise, a behavior descriptor, e.g., the final position of
+// a robot, is recorded in the genome for behavior-based methods, such as
+// novelty search or MAP-Elites, which compare genomes by what they do rather
+// than how well they do (see BehaviorDistance). Auxiliary metrics are recorded
+// in the genome and aggregated in the statistics of the run.
+type ResultEvaluationFunc func(ctx context.Context,
+	n *NeuralNetwork) (EvaluationResult, error)
+
+// EvaluationInfo is information of an evaluation of a genome, which is given
+// to context-aware evaluation functions via the context (see
+// EvaluationInfoFrom), e.g., to seed a stochastic environment reproducibly.
+type EvaluationInfo struct {
+	Generation int   // index of the generation
+	GenomeID   int   // ID of the genome
+	Seed       int64 // seed derived from the run seed, generation, and genome
+	CommonSeed int64 // seed derived from the run seed and generation
+	Fold       int   // index of the fold of a cross-validation
+}
+
+// evaluationInfoKey is the key of the information of an evaluation in its
+// context.
+type evaluationInfoKey struct{}
+
+// newEvaluationInfo returns the information of the evaluation of the argument
+// genome in the argument generation of a run of the argument seed. The seeds
+// are deterministic; if the seed of the run is known, so are the environments
+// of the whole run.
+func newEvaluationInfo(runSeed int64, generation, genomeID int) EvaluationInfo {
+	common := mixSeed(mixSeed(uint64(runSeed)) ^ uint64(generation))
+	return EvaluationInfo{
+		Generation: generation,
+		GenomeID:   genomeID,
+		Seed:       int64(mixSeed(common ^ uint64(genomeID))),
+		CommonSeed: int64(common),
+	}
+}
+
+// mixSeed returns a well-mixed value of the argument value, with the finalizer
+// of SplitMix64, such that similar inputs result in unrelated seeds.
+func mixSeed(x uint64) uint64 {
+	x += 0x9e3779b97f4a7c15
+	x = (x ^ (x >> 30)) * 0xbf58476d1ce4e5b9
+	x = (x ^ (x >> 27)) * 0x94d049bb133111eb
+	return x ^ (x >> 31)
+}
+
+// EvaluationInfoFrom returns the information of the evaluation of the argument
+// context, and false if there is none, e.g., if the context is not given by
+// NEAT.
+func EvaluationInfoFrom(ctx context.Context) (EvaluationInfo, bool) {
+	info, ok := ctx.Value(evaluationInfoKey{}).(EvaluationInfo)
+	return info, ok
+}
+
+// Rand returns a new random number generator seeded with the seed of the
+// genome, which differs between genomes, e.g., for independent trials.
+func (info EvaluationInfo) Rand() *rand.Rand {
+	return rand.New(rand.NewSource(info.Seed))
+}
+
+// CommonRand returns a new random number generator seeded with the common seed
+// of the generation, which is shared by every genome of the generation, such
+// that they are compared fairly under the same conditions (common random
+// numbers).
+func (info EvaluationInfo) CommonRand() *rand.Rand {
+	return rand.New(rand.NewSource(info.CommonSeed))
+}
+
+// BehaviorDistance returns the Euclidean distance between the argument behavior
+// descriptors, or infinity if their dimensions differ, e.g., if either genome
+// is not evaluated with a behavior descriptor.
+func BehaviorDistance(a, b []float64) float64 {
+	if len(a) != len(b) {
+		return math.Inf(1)
+	}
+	sum := 0.0
+	for i := range a {
+		sum += (a[i] - b[i]) * (a[i] - b[i])
+	}
+	return math.Sqrt(sum)
+}
+
+// Fallible returns the evaluation function as a FallibleEvaluationFunc, which
+// never returns an error.
+func (f EvaluationFunc) Fallible() FallibleEvaluationFunc {
+	return func(n *NeuralNetwork) (float64, error) {
+		return f(n), nil
+	}
+}
+
+// Contextual returns the evaluation function as a ContextEvaluationFunc, which
+// ignores the context.
+func (f FallibleEvaluationFunc) Contextual() ContextEvaluationFunc {
+	return func(ctx context.Context, n *NeuralNetwork) (float64, error) {
+		return f(n)
+	}
+}
+
+// Result returns the evaluation function as a ResultEvaluationFunc, whose
+// results only include the fitness.
+func (f ContextEvaluationFunc) Result() ResultEvaluationFunc {
+	return func(ctx context.Context, n *NeuralNetwork) (EvaluationResult, error) {
+		fitness, err := f(ctx, n)
+		return EvaluationResult{Fitness: fitness}, err
+	}
+}
+
+// PopulationEvaluationFunc is a type of function that evaluates the networks of
+// the whole population at once, e.g., by playing them against each other, and
+// returns their fitness scores in the same order.
+type PopulationEvaluationFunc func(nets []*NeuralNetwork) []float64
+
+// PopulationEvaluator is an interface of evaluators of the whole population at
+// once, e.g., for tournaments or shared simulations, in which the fitness of a
+// genome depends on the others, or for batches of queries to an external
+// service. Evaluate is given every genome of the population and its network in
+// the same order, and assigns the fitness score of each genome.
+type PopulationEvaluator interface {
+	Evaluate(genomes []*Genome, nets []*NeuralNetwork)
+}
+
+// Evaluate assigns the scores of the networks to the genomes, which makes
+// PopulationEvaluationFunc a PopulationEvaluator; the fitness of a genome
+// whose score is missing is NaN.
+func (f PopulationEvaluationFunc) Evaluate(genomes []*Genome,
+	nets []*NeuralNetwork) {
+	scores := f(nets)
+	for i, genome := range genomes {
+		genome.Fitness = math.NaN()
+		if i < len(scores) {
+			genome.Fitness = scores[i]
+		}
+	}
+}
+
 // XORTest returns an XOR test as an evaluation function. The fitness is
 // measured with the total error, which should be minimized.
 func XORTest() EvaluationFunc {
@@ -41,7 +210,7 @@ func XORTest() EvaluationFunc {
 		inputs[2] = 0.0
 		output, err := n.FeedForward(inputs)
 		if err != nil {
-			log.Fatal(err)
+			return math.NaN()
 		}
 		score += math.Pow((output[0] - 0.0), 2.0)
 
@@ -50,7 +219,7 @@ func XORTest() EvaluationFunc {
 		inputs[2] = 1.0
 		output, err = n.FeedForward(inputs)
 		if err != nil {
-			log.Fatal(err)
+			return math.NaN()
 		}
 		score += math.Pow((output[0] - 1.0), 2.0)
 
@@ -59,7 +228,7 @@ func XORTest() EvaluationFunc {
 		inputs[2] = 0.0
 		output, err = n.FeedForward(inputs)
 		if err != nil {
-			log.Fatal(err)
+			return math.NaN()
 		}
 		score += math.Pow((output[0] - 1.0), 2.0)
 
@@ -68,7 +237,7 @@ func XORTest() EvaluationFunc {
 		inputs[2] = 1.0
 		output, err = n.FeedForward(inputs)
 		if err != nil {
-			log.Fatal(err)
+			return math.NaN()
 		}
 		score += math.Pow((output[0] - 0.0), 2.0)
 
@@ -80,6 +249,14 @@ func XORTest() EvaluationFunc {
 // The fitness is measured with how long the network can balanced the pole,
 // given a max time. Suggested max time is 120000 ticks.
 func PoleBalancingTest(randomStart bool, maxTime int) EvaluationFunc {
+	return PoleLengthBalancingTest(randomStart, maxTime, 0.5)
+}
+
+// PoleLengthBalancingTest returns the pole balancing task with the argument
+// half length of the pole as an evaluation function. Along with a Curriculum,
+// it can be used to evolve controllers with progressively varying pole lengths.
+func PoleLengthBalancingTest(randomStart bool, maxTime int,
+	length float64) EvaluationFunc {
 	// physics constants
 	xLim := 2.4      // x position limit [-2.4, 2.4]
 	dxLim := 1.0     // x velocity limit [-1.0, 1.0]
@@ -88,7 +265,6 @@ func PoleBalancingTest(randomStart bool, maxTime int) EvaluationFunc {
 	gravity := 9.8   // gravity constant
 	cartMass := 1.0  // mass of the cart
 	poleMass := 0.1  // mass of the pole
-	length := 0.5    // half length of pole
 	forceMag := 10.0 // force applied to the cart
 	tau := 0.02      // seconds between state updates
 
@@ -132,7 +308,7 @@ func PoleBalancingTest(randomStart bool, maxTime int) EvaluationFunc {
 		for i := 0; i < maxTime; i++ {
 			outputs, err := n.FeedForward(inputs)
 			if err != nil {
-				panic(err)
+				return math.NaN()
 			}
 
 			// update the next inputs; if the cart moves out of bound (xLim), or the
@@ -145,3 +321,165 @@ func PoleBalancingTest(randomStart bool, maxTime int) EvaluationFunc {
 		return float64(maxTime)
 	}
 }
+
+// DoublePoleBalancingTest returns the double pole balancing task as an
+// evaluation function, in which two poles of different lengths on a cart are
+// balanced by applying a continuous force to the cart. The fitness is measured
+// with how long the network can balance both poles, given a max time in ticks
+// of 0.01 seconds; 100000 ticks are commonly used as the goal.
+//
+// In the Markovian variant, the network is given the position and velocity of
+// the cart and the angles and angular velocities of both poles (6 inputs). In
+// the non-Markovian variant, it is only given the position of the cart and the
+// angles of the poles (3 inputs), thus it must estimate the velocities itself,
+// which requires recurrent connections (Config.SelfConnections); its signals
+// are reset at the start of each episode. In both variants, the network
+// outputs the force in [-1, 1] (e.g., with tanh), which is scaled to 10N.
+func DoublePoleBalancingTest(markov bool, maxTime int) EvaluationFunc {
+	// physics constants
+	xLim := 2.4          // x position limit [-2.4, 2.4]
+	thLim := 0.628329    // theta limit of both poles (36 degrees)
+	gravity := -9.8      // gravity constant
+	cartMass := 1.0      // mass of the cart
+	poleMass1 := 0.1     // mass of the long pole
+	poleMass2 := 0.01    // mass of the short pole
+	length1 := 0.5       // half length of the long pole
+	length2 := 0.05      // half length of the short pole
+	forceMag := 10.0     // maximum force applied to the cart
+	frictionCart := 5e-4 // coefficient of friction of the cart on the track
+	frictionPole := 2e-6 // coefficient of friction of the poles' hinges
+	tau := 0.01          // seconds between state updates
+
+	// state is (x, dx, th1, dth1, th2, dth2).
+	step := func(force float64, s []float64) {
+		dx := s[1]
+		sign := 0.0
+		if dx > 0.0 {
+			sign = 1.0
+		} else if dx < 0.0 {
+			sign = -1.0
+		}
+
+		// effective forces and masses of the poles
+		forces, masses := 0.0, 0.0
+		temps := [2]float64{}
+		for i, p := range []struct{ m, l, th, dth float64 }{
+			{poleMass1, length1, s[2], s[3]},
+			{poleMass2, length2, s[4], s[5]},
+		} {
+			cosTh, sinTh := math.Cos(p.th), math.Sin(p.th)
+			temps[i] = frictionPole * p.dth / (p.m * p.l)
+			forces += p.m*p.l*p.dth*p.dth*sinTh +
+				0.75*p.m*cosTh*(temps[i]+gravity*sinTh)
+			masses += p.m * (1.0 - 0.75*cosTh*cosTh)
+		}
+
+		// accelerations of the cart and the poles
+		ax := (force - frictionCart*sign + forces) / (cartMass + masses)
+		ath1 := -0.75 * (ax*math.Cos(s[2]) + gravity*math.Sin(s[2]) + temps[0]) /
+			length1
+		ath2 := -0.75 * (ax*math.Cos(s[4]) + gravity*math.Sin(s[4]) + temps[1]) /
+			length2
+
+		s[0] += tau * s[1]
+		s[1] += tau * ax
+		s[2] += tau * s[3]
+		s[3] += tau * ath1
+		s[4] += tau * s[5]
+		s[5] += tau * ath2
+	}
+
+	return func(n *NeuralNetwork) float64 {
+		n.Reset()
+
+		// the long pole starts tilted by 4.5 degrees.
+		state := []float64{0.0, 0.0, 0.07854, 0.0, 0.0, 0.0}
+		var inputs []float64
+		for i := 0; i < maxTime; i++ {
+			// inputs are scaled to roughly [-1, 1].
+			if markov {
+				inputs = []float64{state[0] / 4.8, state[1] / 2.0,
+					state[2] / 0.52, state[3] / 2.0, state[4] / 0.52, state[5] / 2.0}
+			} else {
+				inputs = []float64{state[0] / 4.8, state[2] / 0.52, state[4] / 0.52}
+			}
+			outputs, err := n.FeedForward(inputs)
+			if err != nil {
+				return math.NaN()
+			}
+
+			// update the state; if the cart moves out of bound (xLim), or either
+			// pole falls beyond the limit (thLim), return the time.
+			force := math.Max(-1.0, math.Min(1.0, outputs[0])) * forceMag
+			step(force, state)
+			if math.Abs(state[0]) > xLim || math.Abs(state[2]) > thLim ||
+				math.Abs(state[4]) > thLim || math.IsNaN(force) {
+				return float64(i)
+			}
+		}
+		return float64(maxTime)
+	}
+}
+
+// retinaObjects are the patterns of the left retina that are objects, whose
+// pixels are (top left, top right, bottom left, bottom right); the objects of
+// the right retina are their mirror images.
+var retinaObjects = [8][4]float64{
+	{1, 0, 0, 0}, {0, 0, 1, 0}, {1, 0, 1, 0}, {1, 1, 1, 0},
+	{1, 0, 1, 1}, {1, 1, 0, 0}, {0, 0, 1, 1}, {0, 1, 1, 1},
+}
+
+// RetinaTest returns the retina classification task as an evaluation function,
+// a benchmark of modularity (Kashtan & Alon, 2005). A retina of 8 pixels is
+// split into a left and a right half of 2x2 pixels, each of which may show an
+// object; the network is given a bias, the 4 pixels of the left half, and the
+// 4 pixels of the right half, and must output 1 if both halves show an object
+// (or either of them, if and is false), and 0 otherwise. The fitness is the
+// fraction of the 256 patterns that are classified correctly, i.e., whether
+// the output exceeds 0.5.
+//
+// If the connection cost is positive, it is multiplied by the number of
+// connections of the network and subtracted from the fitness (Clune et al.,
+// 2013); the pressure to reduce connections is what makes modular solutions,
+// which handle each half separately, evolve.
+func RetinaTest(and bool, connectionCost float64) EvaluationFunc {
+	isObject := func(pixels []float64, mirror bool) bool {
+		for _, object := range retinaObjects {
+			if mirror {
+				object[0], object[1] = object[1], object[0]
+				object[2], object[3] = object[3], object[2]
+			}
+			if object[0] == pixels[0] && object[1] == pixels[1] &&
+				object[2] == pixels[2] && object[3] == pixels[3] {
+				return true
+			}
+		}
+		return false
+	}
+
+	return func(n *NeuralNetwork) float64 {
+		correct := 0
+		inputs := make([]float64, 9)
+		inputs[0] = 1.0 // bias
+		for pattern := 0; pattern < 256; pattern++ {
+			for i := 0; i < 8; i++ {
+				inputs[1+i] = float64((pattern >> uint(i)) & 1)
+			}
+			left, right := isObject(inputs[1:5], false), isObject(inputs[5:9], true)
+			target := left || right
+			if and {
+				target = left && right
+			}
+
+			output, err := n.FeedForward(inputs)
+			if err != nil {
+				return math.NaN()
+			}
+			if (output[0] > 0.5) == target {
+				correct++
+			}
+		}
+		return float64(correct)/256.0 -
+			connectionCost*float64(n.NumSynapses())
+	}
+}
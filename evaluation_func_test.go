@@ -0,0 +1,149 @@
+package neat
+
+import (
+	"context"
+	"math"
+	"testing"
+)
+
+func TestDoublePoleBalancingTest(t *testing.T) {
+	for _, markov := range []bool{true, false} {
+		numInputs := 3
+		if markov {
+			numInputs = 6
+		}
+
+		// without any force, the poles fall after a while.
+		g := NewGenome(0, numInputs, 1, 0.0)
+		g.NodeGenes[numInputs].Activation = ActivationSet["tanh"]
+		fitness := DoublePoleBalancingTest(markov, 1000)(NewNeuralNetwork(g))
+		if fitness <= 0.0 || fitness >= 1000.0 {
+			t.Errorf("invalid fitness without force (markov: %t): %f",
+				markov, fitness)
+		}
+
+		// a recurrent network is reset at the start of each episode, thus it is
+		// evaluated consistently.
+		g.ConnGenes = append(g.ConnGenes,
+			NewConnGene(numInputs/3, numInputs, 0.5),
+			NewConnGene(numInputs, numInputs, 0.5))
+		nn := NewNeuralNetwork(g)
+		if !nn.Recurrent {
+			t.Fatal("network is not recurrent")
+		}
+		evaluate := DoublePoleBalancingTest(markov, 1000)
+		if f0, f1 := evaluate(nn), evaluate(nn); f0 != f1 {
+			t.Errorf("inconsistent fitness (markov: %t): %f != %f", markov, f0, f1)
+		}
+	}
+
+	config, err := NewConfigPreset("double-pole-nonmarkov")
+	if err != nil {
+		t.Fatal(err)
+	}
+	config.NumGenerations = 2
+	config.PopulationSize = 20
+	New(config, DoublePoleBalancingTest(false, 1000), WithSeed(0)).Run()
+}
+
+func TestRetinaTest(t *testing.T) {
+	// the objects are 8 distinct patterns, i.e., half of the patterns of a side.
+	count := 0
+	for pattern := 0; pattern < 16; pattern++ {
+		for _, object := range retinaObjects {
+			if float64(pattern&1) == object[0] && float64(pattern>>1&1) == object[1] &&
+				float64(pattern>>2&1) == object[2] && float64(pattern>>3&1) == object[3] {
+				count++
+			}
+		}
+	}
+	if count != 8 {
+		t.Fatalf("objects are not distinct: %d", count)
+	}
+
+	// a network that always outputs 0 is correct whenever the target is false,
+	// i.e., 3/4 of the patterns for AND, and 1/4 for OR.
+	g := NewGenome(0, 9, 1, 0.0)
+	nn := NewNeuralNetwork(g)
+	if fitness := RetinaTest(true, 0.0)(nn); fitness != 0.75 {
+		t.Errorf("invalid fitness of AND: %f", fitness)
+	}
+	if fitness := RetinaTest(false, 0.0)(nn); fitness != 0.25 {
+		t.Errorf("invalid fitness of OR: %f", fitness)
+	}
+
+	// a network that always outputs 1 pays for its connection.
+	g.ConnGenes = append(g.ConnGenes, NewConnGene(0, 9, 5.0))
+	nn = NewNeuralNetwork(g)
+	if fitness := RetinaTest(false, 0.01)(nn); math.Abs(fitness-0.74) > 1e-9 {
+		t.Errorf("invalid fitness with connection cost: %f", fitness)
+	}
+}
+
+func TestBehaviorDistance(t *testing.T) {
+	if d := BehaviorDistance([]float64{0, 0}, []float64{3, 4}); d != 5.0 {
+		t.Errorf("invalid distance: %f", d)
+	}
+	if d := BehaviorDistance(nil, []float64{3, 4}); !math.IsInf(d, 1) {
+		t.Errorf("invalid distance of different dimensions: %f", d)
+	}
+
+	g := NewGenome(0, 3, 1, 0.0)
+	behavior := func(ctx context.Context, n *NeuralNetwork) (EvaluationResult,
+		error) {
+		return EvaluationResult{Fitness: 1.0, Behavior: []float64{0.5, 0.25}}, nil
+	}
+	if err := g.evaluateWithin(context.Background(), behavior, 0, -1.0); err != nil ||
+		g.Fitness != 1.0 || len(g.Behavior) != 2 {
+		t.Fatalf("behavior is not recorded: %v, %v", err, g.Behavior)
+	}
+	if c := g.Copy(); BehaviorDistance(c.Behavior, g.Behavior) != 0.0 {
+		t.Errorf("behavior is not copied: %v", c.Behavior)
+	}
+}
+
+func TestEvaluationInfo(t *testing.T) {
+	config := NewDefaultConfig(3, 1)
+	config.NumGenerations = 2
+	config.PopulationSize = 10
+
+	// record the seeds of each genome in each generation.
+	run := func() map[[2]int]EvaluationInfo {
+		infos := make(map[[2]int]EvaluationInfo)
+		New(config, nil, WithSeed(42), WithContextEvaluation(
+			func(ctx context.Context, nn *NeuralNetwork) (float64, error) {
+				info, ok := EvaluationInfoFrom(ctx)
+				if !ok {
+					t.Fatal("evaluation information is missing")
+				}
+				infos[[2]int{info.Generation, info.GenomeID}] = info
+				return info.Rand().Float64(), nil
+			})).Run()
+		return infos
+	}
+
+	infos := run()
+	if len(infos) == 0 {
+		t.Fatal("no evaluations")
+	}
+	seeds := make(map[int64]bool)
+	for key, info := range run() {
+		if infos[key] != info {
+			t.Errorf("seeds are not reproducible: %+v != %+v", infos[key], info)
+		}
+		if seeds[info.Seed] {
+			t.Errorf("seed of genome %d is not unique", info.GenomeID)
+		}
+		seeds[info.Seed] = true
+		if first := infos[[2]int{info.Generation, 0}]; info.Generation == 0 &&
+			info.CommonSeed != first.CommonSeed {
+			t.Errorf("common seeds differ in generation %d", info.Generation)
+		}
+	}
+	if a, b := newEvaluationInfo(42, 0, 0), newEvaluationInfo(42, 1, 0); a.CommonSeed == b.CommonSeed {
+		t.Error("common seeds do not differ between generations")
+	}
+	if _, ok := EvaluationInfoFrom(context.Background()); ok {
+		t.Error("evaluation information without NEAT")
+	}
+}
@@ -0,0 +1,268 @@
+// experiment.go implementation of repeated runs of NEAT and their analysis.
+//
+// Copyright (C) 2017  Jin Yeom
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package neat
+
+import (
+	"math"
+)
+
+// SolvedFunc is a type of function that determines whether a fitness score
+// solves the task of an experiment.
+type SolvedFunc func(fitness float64) bool
+
+// ExperimentResult is the aggregated result of independent runs of NEAT with
+// the same configuration and different seeds.
+type ExperimentResult struct {
+	Seeds      []int64       `json:"seeds"`      // seed of each run
+	Bests      []*Genome     `json:"bests"`      // best genome of each run
+	Statistics []*Statistics `json:"statistics"` // statistics of each run
+
+	// mean and standard deviation over runs of the best fitness (so far) and
+	// the average fitness in each generation
+	MeanBestFitness []float64 `json:"meanBestFitness"`
+	StdBestFitness  []float64 `json:"stdBestFitness"`
+	MeanAvgFitness  []float64 `json:"meanAvgFitness"`
+	StdAvgFitness   []float64 `json:"stdAvgFitness"`
+
+	// first generation in which each run solved the task (-1 if never), and
+	// the rate of runs that solved it
+	GenerationsToSolve []int   `json:"generationsToSolve"`
+	SuccessRate        float64 `json:"successRate"`
+}
+
+// RunExperiment runs NEAT the argument number of times with the argument
+// configuration and evaluation function, each seeded with Config.RandomSeed
+// (or 1, if it is 0) plus the index of the run, and aggregates the results. A
+// run solves the task in the first generation whose best fitness satisfies the
+// argument function; it may be nil, if there is no such criterion.
+//
+// Each run is given its own copy of the configuration, and the options
+// returned by the argument function for the index of the run (it may be nil),
+// so that stateful options, e.g., a curriculum, callbacks, or a dashboard, are
+// not shared between runs.
+func RunExperiment(config *Config, evaluation EvaluationFunc, numRuns int,
+	solved SolvedFunc, options func(run int) []Option) *ExperimentResult {
+	base := config.RandomSeed
+	if base == 0 {
+		base = 1
+	}
+
+	result := &ExperimentResult{
+		Seeds:              make([]int64, numRuns),
+		Bests:              make([]*Genome, numRuns),
+		Statistics:         make([]*Statistics, numRuns),
+		GenerationsToSolve: make([]int, numRuns),
+	}
+	curves := make([][]float64, numRuns) // best fitness so far in each run
+
+	numSolved := 0
+	for i := 0; i < numRuns; i++ {
+		seed := base + int64(i)
+		var opts []Option
+		if options != nil {
+			opts = options(i)
+		}
+		n := New(config.Copy(), evaluation, append(opts, WithSeed(seed))...)
+		result.Seeds[i] = seed
+		result.Bests[i] = n.Run()
+		result.Statistics[i] = n.Statistics
+		curves[i] = bestSoFar(n.Statistics, config.MinimizeFitness)
+
+		// curves are indexed by recorded entries, which are not generations if
+		// the statistics are bounded.
+		result.GenerationsToSolve[i] = -1
+		for entry, fitness := range curves[i] {
+			if solved != nil && solved(fitness) {
+				result.GenerationsToSolve[i] = n.Statistics.Generations[entry]
+				numSolved++
+				break
+			}
+		}
+	}
+	if numRuns > 0 {
+		result.SuccessRate = float64(numSolved) / float64(numRuns)
+	}
+
+	avgCurves := make([][]float64, numRuns)
+	for i, s := range result.Statistics {
+		avgCurves[i] = s.AvgFitness[:s.NumRecorded()]
+	}
+	result.MeanBestFitness, result.StdBestFitness = meanStdCurves(curves)
+	result.MeanAvgFitness, result.StdAvgFitness = meanStdCurves(avgCurves)
+	return result
+}
+
+// FinalBestFitness returns the fitness of the best genome of each run.
+func (r *ExperimentResult) FinalBestFitness() []float64 {
+	fitness := make([]float64, len(r.Bests))
+	for i, best := range r.Bests {
+		fitness[i] = best.Fitness
+	}
+	return fitness
+}
+
+// CompareExperiments performs Welch's t-test on the final best fitness of the
+// runs of two experiments, and returns the t statistic and the two-tailed
+// p-value; a small p-value (e.g., < 0.05) indicates that the two
+// configurations perform significantly differently.
+func CompareExperiments(a, b *ExperimentResult) (t, p float64) {
+	return WelchTTest(a.FinalBestFitness(), b.FinalBestFitness())
+}
+
+// WelchTTest performs Welch's t-test on two samples, which are not assumed to
+// have equal variances, and returns the t statistic and the two-tailed
+// p-value. Both samples must have at least two values; otherwise, or if both
+// samples have no variance, it returns 0 and 1.
+func WelchTTest(a, b []float64) (t, p float64) {
+	if len(a) < 2 || len(b) < 2 {
+		return 0.0, 1.0
+	}
+	meanA, varA := meanVariance(a)
+	meanB, varB := meanVariance(b)
+	seA, seB := varA/float64(len(a)), varB/float64(len(b))
+	if seA+seB == 0.0 {
+		return 0.0, 1.0
+	}
+
+	t = (meanA - meanB) / math.Sqrt(seA+seB)
+	df := (seA + seB) * (seA + seB) /
+		(seA*seA/float64(len(a)-1) + seB*seB/float64(len(b)-1))
+	p = regIncBeta(df/2.0, 0.5, df/(df+t*t))
+	return t, p
+}
+
+// bestSoFar returns the best fitness found so far in each recorded generation.
+func bestSoFar(s *Statistics, minimize bool) []float64 {
+	curve := make([]float64, s.NumRecorded())
+	for gen := range curve {
+		if minimize {
+			curve[gen] = s.MinFitness[gen]
+			if gen > 0 {
+				curve[gen] = math.Min(curve[gen], curve[gen-1])
+			}
+		} else {
+			curve[gen] = s.MaxFitness[gen]
+			if gen > 0 {
+				curve[gen] = math.Max(curve[gen], curve[gen-1])
+			}
+		}
+	}
+	return curve
+}
+
+// meanStdCurves returns the mean and the standard deviation of the argument
+// curves in each generation, over the curves that reached the generation.
+func meanStdCurves(curves [][]float64) (mean, std []float64) {
+	length := 0
+	for _, curve := range curves {
+		if len(curve) > length {
+			length = len(curve)
+		}
+	}
+	mean = make([]float64, length)
+	std = make([]float64, length)
+	for gen := 0; gen < length; gen++ {
+		var values []float64
+		for _, curve := range curves {
+			if gen < len(curve) {
+				values = append(values, curve[gen])
+			}
+		}
+		m, v := meanVariance(values)
+		mean[gen], std[gen] = m, math.Sqrt(v)
+	}
+	return mean, std
+}
+
+// meanVariance returns the mean and the unbiased sample variance of the
+// argument values (0 if there are less than two values).
+func meanVariance(values []float64) (mean, variance float64) {
+	if len(values) == 0 {
+		return 0.0, 0.0
+	}
+	for _, v := range values {
+		mean += v
+	}
+	mean /= float64(len(values))
+	if len(values) < 2 {
+		return mean, 0.0
+	}
+	for _, v := range values {
+		variance += (v - mean) * (v - mean)
+	}
+	return mean, variance / float64(len(values)-1)
+}
+
+// regIncBeta returns the regularized incomplete beta function I_x(a, b),
+// evaluated with its continued fraction (Numerical Recipes, 6.4).
+func regIncBeta(a, b, x float64) float64 {
+	if x <= 0.0 {
+		return 0.0
+	}
+	if x >= 1.0 {
+		return 1.0
+	}
+	lgab, _ := math.Lgamma(a + b)
+	lga, _ := math.Lgamma(a)
+	lgb, _ := math.Lgamma(b)
+	front := math.Exp(lgab - lga - lgb + a*math.Log(x) + b*math.Log(1.0-x))
+
+	// the continued fraction converges rapidly for x < (a+1)/(a+b+2).
+	if x < (a+1.0)/(a+b+2.0) {
+		return front * betaContinuedFraction(a, b, x) / a
+	}
+	return 1.0 - front*betaContinuedFraction(b, a, 1.0-x)/b
+}
+
+// betaContinuedFraction evaluates the continued fraction of the incomplete
+// beta function with the modified Lentz's method.
+func betaContinuedFraction(a, b, x float64) float64 {
+	const (
+		maxIterations = 200
+		epsilon       = 1e-14
+		tiny          = 1e-300
+	)
+	c, d := 1.0, 1.0-(a+b)*x/(a+1.0)
+	if math.Abs(d) < tiny {
+		d = tiny
+	}
+	d = 1.0 / d
+	h := d
+	for m := 1; m <= maxIterations; m++ {
+		fm := float64(m)
+		for _, coeff := range []float64{
+			fm * (b - fm) * x / ((a + 2.0*fm - 1.0) * (a + 2.0*fm)),
+			-(a + fm) * (a + b + fm) * x / ((a + 2.0*fm) * (a + 2.0*fm + 1.0)),
+		} {
+			d = 1.0 + coeff*d
+			if math.Abs(d) < tiny {
+				d = tiny
+			}
+			c = 1.0 + coeff/c
+			if math.Abs(c) < tiny {
+				c = tiny
+			}
+			d = 1.0 / d
+			h *= d * c
+		}
+		if math.Abs(d*c-1.0) < epsilon {
+			break
+		}
+	}
+	return h
+}
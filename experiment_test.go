@@ -0,0 +1,93 @@
+package neat
+
+import (
+	"math"
+	"testing"
+)
+
+func TestWelchTTest(t *testing.T) {
+	a := []float64{1.0, 2.0, 3.0, 4.0, 5.0}
+	b := []float64{3.0, 4.0, 5.0, 6.0, 7.0}
+
+	// t = -2 with 8 degrees of freedom.
+	tstat, p := WelchTTest(a, b)
+	if math.Abs(tstat+2.0) > 1e-9 {
+		t.Errorf("invalid t statistic: %f != -2", tstat)
+	}
+	if math.Abs(p-0.0805) > 1e-3 {
+		t.Errorf("invalid p-value: %f != 0.0805", p)
+	}
+}
+
+func TestRunExperiment(t *testing.T) {
+	config := NewDefaultConfig(3, 1)
+	config.NumGenerations = 3
+	config.PopulationSize = 20
+
+	solved := func(fitness float64) bool { return fitness > 0.0 }
+	result := RunExperiment(config, XORTest(), 3, solved, nil)
+	if len(result.Bests) != 3 || len(result.MeanBestFitness) != 3 {
+		t.Fatalf("invalid result: %d runs, %d generations",
+			len(result.Bests), len(result.MeanBestFitness))
+	}
+	if result.Seeds[0] == result.Seeds[1] {
+		t.Errorf("runs are not seeded independently: %v", result.Seeds)
+	}
+	if result.SuccessRate != 1.0 {
+		t.Errorf("invalid success rate: %f != 1.0", result.SuccessRate)
+	}
+
+	_, p := CompareExperiments(result, result)
+	if p != 1.0 && math.Abs(p-1.0) > 1e-9 {
+		t.Errorf("identical experiments are significantly different: %f", p)
+	}
+}
+
+func TestRunExperimentStride(t *testing.T) {
+	config := NewDefaultConfig(3, 1)
+	config.NumGenerations = 7
+	config.PopulationSize = 20
+	config.StatisticsStride = 3
+
+	// generations 0, 3 and 6 are recorded; the task is solved in the second
+	// recorded generation, which is generation 3.
+	calls := 0
+	solved := func(fitness float64) bool {
+		calls++
+		return calls == 2
+	}
+	result := RunExperiment(config, XORTest(), 1, solved, nil)
+	if gen := result.GenerationsToSolve[0]; gen != 3 {
+		t.Errorf("invalid generations to solve: %d != 3", gen)
+	}
+}
+
+func TestRunExperimentOptions(t *testing.T) {
+	config := NewDefaultConfig(3, 1)
+	config.NumGenerations = 3
+	config.PopulationSize = 20
+
+	// each run must have its own configuration and curriculum, which starts at
+	// its first stage.
+	var curricula []*Curriculum
+	options := func(run int) []Option {
+		c := NewCurriculum(NewStage(XORTest(), math.Inf(-1)),
+			NewStage(XORTest(), math.Inf(1)))
+		curricula = append(curricula, c)
+		return []Option{func(n *NEAT) {
+			if n.Config == config {
+				t.Errorf("configuration is shared by run %d", run)
+			}
+			n.Curriculum = c
+		}}
+	}
+	RunExperiment(config, XORTest(), 2, nil, options)
+	if len(curricula) != 2 || curricula[0] == curricula[1] {
+		t.Fatalf("options are not created for each run: %d", len(curricula))
+	}
+	for run, c := range curricula {
+		if c.Current != 1 {
+			t.Errorf("invalid stage of run %d: %d != 1", run, c.Current)
+		}
+	}
+}
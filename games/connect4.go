@@ -0,0 +1,100 @@
+// connect4.go implementation of Connect Four.
+//
+// Copyright (C) 2017  Jin Yeom
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package games
+
+const (
+	connect4Rows = 6 // number of rows of Connect Four
+	connect4Cols = 7 // number of columns of Connect Four
+)
+
+// ConnectFour is the game of Connect Four on a board of 6 rows and 7 columns,
+// whose cells are indexed in row-major order from the bottom row, and whose
+// moves are the columns to drop a piece into.
+type ConnectFour struct{}
+
+// Name returns the name of the game.
+func (ConnectFour) Name() string { return "Connect Four" }
+
+// NumCells returns the number of cells of the board.
+func (ConnectFour) NumCells() int { return connect4Rows * connect4Cols }
+
+// NumMoves returns the number of possible moves.
+func (ConnectFour) NumMoves() int { return connect4Cols }
+
+// NewBoard returns a new empty board.
+func (ConnectFour) NewBoard() Board { return &connect4Board{winner: -1} }
+
+// connect4Board is a board of Connect Four.
+type connect4Board struct {
+	cells  [connect4Rows * connect4Cols]int // cells of the board
+	moves  int                              // number of moves played
+	winner int                              // winner so far (-1 if none)
+}
+
+// Cells returns the cells of the board.
+func (b *connect4Board) Cells() []int { return b.cells[:] }
+
+// Turn returns the player to move.
+func (b *connect4Board) Turn() int { return b.moves % 2 }
+
+// Legal returns true if the argument column is not full.
+func (b *connect4Board) Legal(move int) bool {
+	return move >= 0 && move < connect4Cols &&
+		b.cells[(connect4Rows-1)*connect4Cols+move] == 0
+}
+
+// Play drops a piece of the player to move into the argument column.
+func (b *connect4Board) Play(move int) {
+	piece := b.Turn() + 1
+	for row := 0; row < connect4Rows; row++ {
+		if i := row*connect4Cols + move; b.cells[i] == 0 {
+			b.cells[i] = piece
+			b.moves++
+			if b.connects(row, move) {
+				b.winner = piece - 1
+			}
+			return
+		}
+	}
+}
+
+// connects returns true if the piece at the argument cell is a part of four
+// pieces in a row.
+func (b *connect4Board) connects(row, col int) bool {
+	piece := b.cells[row*connect4Cols+col]
+	for _, dir := range [4][2]int{{0, 1}, {1, 0}, {1, 1}, {1, -1}} {
+		count := 1
+		for _, sign := range [2]int{1, -1} {
+			r, c := row+sign*dir[0], col+sign*dir[1]
+			for r >= 0 && r < connect4Rows && c >= 0 && c < connect4Cols &&
+				b.cells[r*connect4Cols+c] == piece {
+				count++
+				r, c = r+sign*dir[0], c+sign*dir[1]
+			}
+		}
+		if count >= 4 {
+			return true
+		}
+	}
+	return false
+}
+
+// Result returns the winner, and true if the game is over.
+func (b *connect4Board) Result() (int, bool) {
+	return b.winner, b.winner >= 0 || b.moves == len(b.cells)
+}
@@ -0,0 +1,206 @@
+// games.go implementation of board games for evaluation by play.
+//
+// Copyright (C) 2017  Jin Yeom
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+// Package games provides two-player board games, tic-tac-toe and Connect Four,
+// in which networks are evaluated by playing against each other (coevolution)
+// or against fixed opponents. It also serves as a template of adversarial
+// domains: implement Game and Board for another game, and the evaluations
+// below work as they are.
+//
+// A network takes a bias followed by the cells of the board from the
+// perspective of the player to move, i.e., 1 for its own pieces, -1 for the
+// opponent's, and 0 for empty cells, and outputs a score of each move; it
+// plays the legal move of the highest score.
+package games
+
+import (
+	"math/rand"
+
+	"github.com/jinyeom/neat"
+)
+
+// Game is a two-player, turn-based board game.
+type Game interface {
+	// Name returns the name of the game.
+	Name() string
+
+	// NumCells returns the number of cells of the board.
+	NumCells() int
+
+	// NumMoves returns the number of possible moves.
+	NumMoves() int
+
+	// NewBoard returns a new empty board, on which player 0 moves first.
+	NewBoard() Board
+}
+
+// Board is a state of a game.
+type Board interface {
+	// Cells returns the cells of the board, i.e., 0 for empty cells, and 1 and
+	// 2 for the pieces of player 0 and player 1.
+	Cells() []int
+
+	// Turn returns the player to move, 0 or 1.
+	Turn() int
+
+	// Legal returns true if the argument move is legal.
+	Legal(move int) bool
+
+	// Play plays the argument legal move of the player to move.
+	Play(move int)
+
+	// Result returns the winner (0 or 1, or -1 for a draw), and true if the
+	// game is over.
+	Result() (int, bool)
+}
+
+// Player is a type of function that returns a legal move on the argument
+// board.
+type Player func(b Board) int
+
+// NetworkPlayer returns a player that plays the legal move of the highest score
+// output by the argument network.
+func NetworkPlayer(g Game, nn *neat.NeuralNetwork) Player {
+	inputs := make([]float64, 1+g.NumCells())
+	return func(b Board) int {
+		Encode(b, inputs)
+		outputs, err := nn.FeedForward(inputs)
+		best := -1
+		for move := 0; move < g.NumMoves(); move++ {
+			if !b.Legal(move) {
+				continue
+			}
+			if best < 0 || (err == nil && move < len(outputs) &&
+				outputs[move] > outputs[best]) {
+				best = move
+			}
+		}
+		return best
+	}
+}
+
+// RandomPlayer returns a player that plays a random legal move.
+func RandomPlayer(g Game, rng *rand.Rand) Player {
+	return func(b Board) int {
+		legal := make([]int, 0, g.NumMoves())
+		for move := 0; move < g.NumMoves(); move++ {
+			if b.Legal(move) {
+				legal = append(legal, move)
+			}
+		}
+		return legal[rng.Intn(len(legal))]
+	}
+}
+
+// Encode writes the bias and the cells of the board from the perspective of
+// the player to move to the argument inputs.
+func Encode(b Board, inputs []float64) {
+	inputs[0] = 1.0
+	own := b.Turn() + 1
+	for i, cell := range b.Cells() {
+		switch cell {
+		case 0:
+			inputs[i+1] = 0.0
+		case own:
+			inputs[i+1] = 1.0
+		default:
+			inputs[i+1] = -1.0
+		}
+	}
+}
+
+// Play plays a game between the two argument players, in which p0 moves first,
+// and returns the winner (0 or 1, or -1 for a draw).
+func Play(g Game, p0, p1 Player) int {
+	b := g.NewBoard()
+	players := [2]Player{p0, p1}
+	for {
+		if winner, over := b.Result(); over {
+			return winner
+		}
+		b.Play(players[b.Turn()](b))
+	}
+}
+
+// NewConfig returns a new default configuration of NEAT for players of the
+// argument game.
+func NewConfig(g Game) *neat.Config {
+	config := neat.NewDefaultConfig(1+g.NumCells(), g.NumMoves())
+	config.ExperimentName = g.Name()
+	config.FullyConnected = true
+	return config
+}
+
+// score returns the score of a player given the winner of a game: 1 for a win,
+// 0.5 for a draw, and 0 for a loss.
+func score(winner, player int) float64 {
+	switch winner {
+	case player:
+		return 1.0
+	case -1:
+		return 0.5
+	}
+	return 0.0
+}
+
+// RoundRobin returns a population evaluation function, with which every pair of
+// networks plays two games, each moving first once; the fitness of a network is
+// its average score, i.e., 1 for a win, 0.5 for a draw, and 0 for a loss.
+func RoundRobin(g Game) neat.PopulationEvaluationFunc {
+	return func(nets []*neat.NeuralNetwork) []float64 {
+		players := make([]Player, len(nets))
+		for i, nn := range nets {
+			players[i] = NetworkPlayer(g, nn)
+		}
+		scores := make([]float64, len(nets))
+		if len(nets) < 2 {
+			return scores
+		}
+		for i := range players {
+			for j := range players {
+				if i == j {
+					continue
+				}
+				winner := Play(g, players[i], players[j])
+				scores[i] += score(winner, 0)
+				scores[j] += score(winner, 1)
+			}
+		}
+		for i := range scores {
+			scores[i] /= float64(2 * (len(nets) - 1))
+		}
+		return scores
+	}
+}
+
+// Versus returns an evaluation function, with which a network plays the
+// argument number of games against the fixed opponent, moving first in every
+// other game; the fitness is its average score.
+func Versus(g Game, opponent Player, numGames int) neat.EvaluationFunc {
+	return func(nn *neat.NeuralNetwork) float64 {
+		player := NetworkPlayer(g, nn)
+		total := 0.0
+		for i := 0; i < numGames; i++ {
+			if i%2 == 0 {
+				total += score(Play(g, player, opponent), 0)
+			} else {
+				total += score(Play(g, opponent, player), 1)
+			}
+		}
+		return total / float64(numGames)
+	}
+}
@@ -0,0 +1,86 @@
+package games
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/jinyeom/neat"
+)
+
+func TestTicTacToe(t *testing.T) {
+	b := TicTacToe{}.NewBoard()
+	for _, move := range []int{0, 3, 1, 4} {
+		b.Play(move)
+	}
+	if _, over := b.Result(); over {
+		t.Fatal("game is over too early")
+	}
+	if b.Legal(0) || !b.Legal(2) {
+		t.Error("invalid legal moves")
+	}
+	b.Play(2)
+	if winner, over := b.Result(); !over || winner != 0 {
+		t.Errorf("invalid result: %d, %t", winner, over)
+	}
+
+	inputs := make([]float64, 10)
+	Encode(b, inputs)
+	if inputs[0] != 1.0 || inputs[1] != -1.0 || inputs[4] != 1.0 {
+		t.Errorf("invalid encoding for player 1: %v", inputs)
+	}
+}
+
+func TestConnectFour(t *testing.T) {
+	b := ConnectFour{}.NewBoard()
+	for _, move := range []int{3, 4, 3, 4, 3, 4} {
+		b.Play(move)
+	}
+	if _, over := b.Result(); over {
+		t.Fatal("game is over too early")
+	}
+	b.Play(3)
+	if winner, over := b.Result(); !over || winner != 0 {
+		t.Errorf("invalid result: %d, %t", winner, over)
+	}
+
+	// a full column is illegal.
+	b = ConnectFour{}.NewBoard()
+	for i := 0; i < connect4Rows; i++ {
+		b.Play(0)
+	}
+	if b.Legal(0) || !b.Legal(1) {
+		t.Error("invalid legal moves")
+	}
+}
+
+func TestPlay(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	for _, g := range []Game{TicTacToe{}, ConnectFour{}} {
+		for i := 0; i < 20; i++ {
+			if winner := Play(g, RandomPlayer(g, rng), RandomPlayer(g, rng)); winner < -1 ||
+				winner > 1 {
+				t.Errorf("%s: invalid winner: %d", g.Name(), winner)
+			}
+		}
+	}
+}
+
+func TestCoevolution(t *testing.T) {
+	g := TicTacToe{}
+	config := NewConfig(g)
+	config.NumGenerations = 2
+	config.PopulationSize = 10
+	n := neat.New(config, nil, neat.WithSeed(0),
+		neat.WithPopulationEvaluation(RoundRobin(g)))
+	best := n.Run()
+	for _, genome := range n.Population {
+		if genome.Fitness < 0.0 || genome.Fitness > 1.0 {
+			t.Errorf("invalid fitness: %f", genome.Fitness)
+		}
+	}
+
+	versus := Versus(g, RandomPlayer(g, rand.New(rand.NewSource(0))), 10)
+	if fitness := versus(neat.NewNeuralNetwork(best)); fitness < 0.0 || fitness > 1.0 {
+		t.Errorf("invalid fitness against a random player: %f", fitness)
+	}
+}
@@ -0,0 +1,77 @@
+// tictactoe.go implementation of tic-tac-toe.
+//
+// Copyright (C) 2017  Jin Yeom
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package games
+
+var (
+	// ticTacToeLines are the lines of three cells of tic-tac-toe.
+	ticTacToeLines = [8][3]int{
+		{0, 1, 2}, {3, 4, 5}, {6, 7, 8},
+		{0, 3, 6}, {1, 4, 7}, {2, 5, 8},
+		{0, 4, 8}, {2, 4, 6},
+	}
+)
+
+// TicTacToe is the game of tic-tac-toe on a 3x3 board, whose cells and moves
+// are indexed in row-major order.
+type TicTacToe struct{}
+
+// Name returns the name of the game.
+func (TicTacToe) Name() string { return "Tic-tac-toe" }
+
+// NumCells returns the number of cells of the board.
+func (TicTacToe) NumCells() int { return 9 }
+
+// NumMoves returns the number of possible moves.
+func (TicTacToe) NumMoves() int { return 9 }
+
+// NewBoard returns a new empty board.
+func (TicTacToe) NewBoard() Board { return &ticTacToeBoard{} }
+
+// ticTacToeBoard is a board of tic-tac-toe.
+type ticTacToeBoard struct {
+	cells [9]int // cells of the board
+	moves int    // number of moves played
+}
+
+// Cells returns the cells of the board.
+func (b *ticTacToeBoard) Cells() []int { return b.cells[:] }
+
+// Turn returns the player to move.
+func (b *ticTacToeBoard) Turn() int { return b.moves % 2 }
+
+// Legal returns true if the argument cell is empty.
+func (b *ticTacToeBoard) Legal(move int) bool {
+	return move >= 0 && move < len(b.cells) && b.cells[move] == 0
+}
+
+// Play places a piece of the player to move at the argument cell.
+func (b *ticTacToeBoard) Play(move int) {
+	b.cells[move] = b.Turn() + 1
+	b.moves++
+}
+
+// Result returns the winner, and true if the game is over.
+func (b *ticTacToeBoard) Result() (int, bool) {
+	for _, line := range ticTacToeLines {
+		c := b.cells[line[0]]
+		if c != 0 && c == b.cells[line[1]] && c == b.cells[line[2]] {
+			return c - 1, true
+		}
+	}
+	return -1, b.moves == len(b.cells)
+}
@@ -0,0 +1,127 @@
+// genealogy.go implementation of lineage recording of genomes.
+//
+// Copyright (C) 2017  Jin Yeom
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package neat
+
+import (
+	"encoding/json"
+	"io"
+	"sort"
+)
+
+// Mutation is a record of a mutation applied to a genome.
+type Mutation struct {
+	Generation int    `json:"generation"` // generation of the mutated genome
+	Name       string `json:"name"`       // "perturb", "addNode", or "addConn"
+}
+
+// Ancestry is a record of the origin of a genome, i.e., its parents and the
+// mutations applied to it since its birth.
+type Ancestry struct {
+	ID         int        `json:"id"`         // genome ID
+	Generation int        `json:"generation"` // generation of its birth
+	Parents    []int      `json:"parents"`    // IDs of its parents
+	Mutations  []Mutation `json:"mutations"`  // mutations applied to it
+}
+
+// Genealogy is a record of the ancestry of every genome created during the
+// evolution, from which the evolutionary history of any genome, e.g., the final
+// champion, can be reconstructed.
+type Genealogy struct {
+	Records map[int]*Ancestry // ancestry of each genome, by genome ID
+}
+
+// NewGenealogy returns a new empty instance of Genealogy.
+func NewGenealogy() *Genealogy {
+	return &Genealogy{
+		Records: make(map[int]*Ancestry),
+	}
+}
+
+// Copy returns a deep copy of this genealogy.
+func (g *Genealogy) Copy() *Genealogy {
+	c := &Genealogy{
+		Records: make(map[int]*Ancestry, len(g.Records)),
+	}
+	for id, record := range g.Records {
+		c.Records[id] = &Ancestry{
+			ID:         record.ID,
+			Generation: record.Generation,
+			Parents:    append([]int(nil), record.Parents...),
+			Mutations:  append([]Mutation(nil), record.Mutations...),
+		}
+	}
+	return c
+}
+
+// Record records the birth of a genome in the argument generation, given its
+// ID and the IDs of its parents (none for the initial population).
+func (gl *Genealogy) Record(id, gen int, parents ...int) {
+	gl.Records[id] = &Ancestry{
+		ID:         id,
+		Generation: gen,
+		Parents:    parents,
+		Mutations:  []Mutation{},
+	}
+}
+
+// AddMutations records the mutations of the argument names applied to the
+// genome of the argument ID in the argument generation.
+func (gl *Genealogy) AddMutations(id, gen int, names ...string) {
+	ancestry, ok := gl.Records[id]
+	if !ok {
+		return
+	}
+	for _, name := range names {
+		ancestry.Mutations = append(ancestry.Mutations, Mutation{gen, name})
+	}
+}
+
+// Lineage returns the ancestry of the genome of the argument ID and all of its
+// ancestors, sorted by their IDs. Since a genome has two parents, ancestors can
+// be shared; each of them is included only once, and the tree can be rebuilt
+// by following Parents.
+func (gl *Genealogy) Lineage(id int) []*Ancestry {
+	visited := make(map[int]bool)
+	lineage := []*Ancestry{}
+	stack := []int{id}
+	for len(stack) > 0 {
+		curr := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if visited[curr] {
+			continue
+		}
+		visited[curr] = true
+		if ancestry, ok := gl.Records[curr]; ok {
+			lineage = append(lineage, ancestry)
+			stack = append(stack, ancestry.Parents...)
+		}
+	}
+	sort.Slice(lineage, func(i, j int) bool {
+		return lineage[i].ID < lineage[j].ID
+	})
+	return lineage
+}
+
+// ExportLineage writes the lineage of the genome of the argument ID to the
+// argument writer as a JSON array of Ancestry, i.e., a tree of ancestors in
+// which each node refers to its parents by their IDs.
+func (gl *Genealogy) ExportLineage(w io.Writer, id int) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "\t")
+	return encoder.Encode(gl.Lineage(id))
+}
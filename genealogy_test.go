@@ -0,0 +1,43 @@
+package neat
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func TestGenealogy(t *testing.T) {
+	config := NewDefaultConfig(3, 1)
+	config.NumGenerations = 5
+	config.PopulationSize = 30
+	config.TrackLineage = true
+
+	n := New(config, XORTest(), WithSeed(1))
+	best := n.Run()
+
+	lineage := n.Genealogy.Lineage(best.ID)
+	if len(lineage) == 0 || lineage[len(lineage)-1].ID != best.ID {
+		t.Fatalf("the lineage does not include the best genome: %v", lineage)
+	}
+	for _, ancestry := range lineage {
+		for _, parent := range ancestry.Parents {
+			if n.Genealogy.Records[parent].Generation > ancestry.Generation {
+				t.Errorf("genome %d is older than its parent %d",
+					ancestry.ID, parent)
+			}
+		}
+	}
+
+	buf := &bytes.Buffer{}
+	if err := n.Genealogy.ExportLineage(buf, best.ID); err != nil {
+		t.Fatal(err)
+	}
+	var exported []*Ancestry
+	if err := json.Unmarshal(buf.Bytes(), &exported); err != nil {
+		t.Fatal(err)
+	}
+	if len(exported) != len(lineage) {
+		t.Errorf("invalid number of exported ancestors: %d != %d",
+			len(exported), len(lineage))
+	}
+}
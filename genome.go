@@ -18,11 +18,13 @@
 package neat
 
 import (
-	"encoding/json"
+	"context"
+	"errors"
 	"fmt"
 	"math"
 	"math/rand"
-	"os"
+	"sort"
+	"sync"
 	"time"
 )
 
@@ -47,7 +49,7 @@ func (n *NodeGene) Copy() *NodeGene {
 
 // String returns a string representation of the node.
 func (n *NodeGene) String() string {
-	return fmt.Sprintf("[%s(%d, %s)]", n.Type, n.ID, n.Activation.Name)
+	return fmt.Sprintf("[%s(%d, %s)]", n.Type, n.ID, n.Activation.name())
 }
 
 // ConnGene is an implementation of a connection between two nodes in the graph
@@ -95,12 +97,30 @@ type Genome struct {
 	ConnGenes []*ConnGene `json:"connGenes"` // connections in the genome
 	Fitness   float64     `json:"fitness"`   // fitness score
 
+	// values of multiple objectives, and the behavior descriptor, if evaluated
+	// with them
+	Objectives []float64 `json:"objectives,omitempty"`
+	Behavior   []float64 `json:"behavior,omitempty"`
+
+	// named auxiliary metrics of the evaluation, if any
+	Metrics map[string]float64 `json:"metrics,omitempty"`
+
+	// origin of the genome, if exported with it (see NEAT.Provenance)
+	Provenance *Provenance `json:"provenance,omitempty"`
+
 	evaluated bool // true if already evaluated
 }
 
 // NewFCGenome returns an instance of initial Genome with fully connected input
 // and output layers.
 func NewFCGenome(id, numInputs, numOutputs int, initFitness float64) *Genome {
+	return newFCGenome(id, numInputs, numOutputs, initFitness, globalRand)
+}
+
+// newFCGenome is NewFCGenome with weights drawn from the argument random number
+// generator.
+func newFCGenome(id, numInputs, numOutputs int, initFitness float64,
+	rng *rand.Rand) *Genome {
 	nodeGenes := make([]*NodeGene, 0, numInputs+numOutputs)
 	connGenes := make([]*ConnGene, 0, numInputs*numOutputs)
 
@@ -111,7 +131,7 @@ func NewFCGenome(id, numInputs, numOutputs int, initFitness float64) *Genome {
 	for i := numInputs; i < numInputs+numOutputs; i++ {
 		outputNode := NewNodeGene(i, "output", ActivationSet["sigmoid"])
 		for j := 0; j < numInputs; j++ {
-			c := NewConnGene(j, i, rand.NormFloat64()*6.0)
+			c := NewConnGene(j, i, rng.NormFloat64()*6.0)
 			connGenes = append(connGenes, c)
 		}
 		nodeGenes = append(nodeGenes, outputNode)
@@ -169,8 +189,21 @@ func (g *Genome) Copy() *Genome {
 			}
 			return copies
 		}(),
-		Fitness:   g.Fitness,
-		evaluated: g.evaluated,
+		Fitness:    g.Fitness,
+		Objectives: append([]float64(nil), g.Objectives...),
+		Behavior:   append([]float64(nil), g.Behavior...),
+		Metrics: func() map[string]float64 {
+			if g.Metrics == nil {
+				return nil
+			}
+			metrics := make(map[string]float64, len(g.Metrics))
+			for name, v := range g.Metrics {
+				metrics[name] = v
+			}
+			return metrics
+		}(),
+		Provenance: g.Provenance,
+		evaluated:  g.evaluated,
 	}
 }
 
@@ -202,69 +235,147 @@ func (g *Genome) Evaluate(evaluate EvaluationFunc) {
 	g.evaluated = true
 }
 
-// ExportJSON exports a JSON file that contains this genome's information. If
-// the argument format indicator is true, the exported JSON file will be
-// formatted with indentations.
-func (g *Genome) ExportJSON(format bool) error {
-	// create a new json file
-	filename := fmt.Sprintf("genome_%d_%d.json", g.ID, time.Now().UnixNano())
-	f, err := os.Create(filename)
-	if err != nil {
-		return err
+// errEvaluationTimeout is the error of an evaluation that did not finish in
+// time.
+var errEvaluationTimeout = errors.New("neat: evaluation timed out")
+
+// evaluateWithin evaluates the genome with the argument evaluation function
+// under the argument context, and the argument time limit (unlimited if not
+// positive), and records the result. If the evaluation fails, or does not
+// finish in time, the genome is assigned the argument fallback fitness score,
+// and the error is returned. When the time limit passes, or the context is
+// canceled, the context of the evaluation is canceled; if the evaluation
+// function ignores it, it keeps running in the background until it returns,
+// but its result is discarded.
+func (g *Genome) evaluateWithin(ctx context.Context,
+	evaluate ResultEvaluationFunc, timeout time.Duration,
+	fallback float64) error {
+	if g.evaluated {
+		return nil
 	}
 
-	encoder := json.NewEncoder(f)
-	if format {
-		encoder.SetIndent("", "\t")
+	nn := NewNeuralNetwork(g)
+	g.evaluated = true
+	result, err := EvaluationResult{}, error(nil)
+	if timeout <= 0 && ctx.Done() == nil {
+		result, err = evaluate(ctx, nn)
+	} else {
+		if timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
+
+		type outcome struct {
+			result EvaluationResult
+			err    error
+		}
+		done := make(chan outcome, 1)
+		go func() {
+			result, err := evaluate(ctx, nn)
+			done <- outcome{result, err}
+		}()
+
+		select {
+		case o := <-done:
+			result, err = o.result, o.err
+		case <-ctx.Done():
+			err = ctx.Err()
+		}
 	}
-	if err = encoder.Encode(g); err != nil {
+
+	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			err = errEvaluationTimeout
+		}
+		g.Fitness = fallback
+		g.Objectives = nil
+		g.Behavior = nil
+		g.Metrics = nil
 		return err
 	}
-
+	g.Fitness = result.Fitness
+	g.Objectives = result.Objectives
+	g.Behavior = result.Behavior
+	g.Metrics = result.Metrics
 	return nil
 }
 
 // MutatePerturb mutates the genome by perturbation of its weights by the
 // argument rate.
 func (g *Genome) MutatePerturb(rate float64) {
+	g.mutatePerturb(rate, globalRand)
+}
+
+// mutatePerturb is MutatePerturb with the argument random number generator; it
+// returns true if any weight is perturbed.
+func (g *Genome) mutatePerturb(rate float64, rng *rand.Rand) bool {
 	// perturb connection weights
+	perturbed := false
 	for _, conn := range g.ConnGenes {
-		if rand.Float64() < rate {
+		if rng.Float64() < rate {
 			g.evaluated = false
-			conn.Weight += rand.NormFloat64()
+			conn.Weight += rng.NormFloat64()
+			perturbed = true
 		}
 	}
+	return perturbed
 }
 
 // MutateAddNode mutates the genome by adding a node with the argument
 // activation function.
 func (g *Genome) MutateAddNode(rate float64, activation *ActivationFunc) {
+	g.mutateAddNode(rate, activation, 0, globalRand)
+}
+
+// mutateAddNode is MutateAddNode with the argument maximum depth of the network
+// (unlimited if 0), and the argument random number generator. A mutation that
+// would make the network deeper than the maximum depth is rejected.
+func (g *Genome) mutateAddNode(rate float64, activation *ActivationFunc,
+	maxDepth int, rng *rand.Rand) {
 	// add node between two connected nodes, by randomly selecting a connection;
 	// only applied if there are connections in the genome
-	if rand.Float64() < rate && len(g.ConnGenes) != 0 {
+	if rng.Float64() < rate && len(g.ConnGenes) != 0 {
 		g.evaluated = false
 
-		selected := g.ConnGenes[rand.Intn(len(g.ConnGenes))]
-		newNode := NewNodeGene(len(g.NodeGenes), "hidden", ActivationSet["sigmoid"])
+		selected := g.ConnGenes[rng.Intn(len(g.ConnGenes))]
+		newNode := NewNodeGene(len(g.NodeGenes), "hidden", activation)
 
+		disabled := selected.Disabled
 		g.NodeGenes = append(g.NodeGenes, newNode)
 		g.ConnGenes = append(g.ConnGenes,
 			NewConnGene(selected.From, newNode.ID, 1.0),
 			NewConnGene(newNode.ID, selected.To, selected.Weight))
 		selected.Disabled = true
+
+		// revert the mutation if the network became too deep.
+		if maxDepth > 0 && g.Depth() > maxDepth {
+			g.NodeGenes = g.NodeGenes[:len(g.NodeGenes)-1]
+			g.ConnGenes = g.ConnGenes[:len(g.ConnGenes)-2]
+			selected.Disabled = disabled
+		}
 	}
 }
 
 // MutateAddConn mutates the genome by adding a connection.
 func (g *Genome) MutateAddConn(rate float64) {
+	g.mutateAddConn(rate, 0, false, globalRand)
+}
+
+// mutateAddConn is MutateAddConn with the argument maximum depth of the network
+// (unlimited if 0), an indicator of whether a node may be connected to itself,
+// and the argument random number generator. A mutation that would make the
+// network deeper than the maximum depth is rejected.
+func (g *Genome) mutateAddConn(rate float64, maxDepth int, selfConn bool,
+	rng *rand.Rand) {
 	// add connection between two disconnected nodes; only applied if the selected
 	// nodes are not connected yet, and the resulting connection doesn't make the
 	// phenotype network recurrent
-	if rand.Float64() < rate {
+	if rng.Float64() < rate {
 		g.evaluated = false
 
-		selectedNode0 := g.NodeGenes[rand.Intn(len(g.NodeGenes))].ID
-		selectedNode1 := g.NodeGenes[rand.Intn(len(g.NodeGenes))].ID
+		selectedNode0 := g.NodeGenes[rng.Intn(len(g.NodeGenes))].ID
+		selectedNode1 := g.NodeGenes[rng.Intn(len(g.NodeGenes))].ID
 
 		for _, conn := range g.ConnGenes {
 			if conn.From == selectedNode0 && conn.To == selectedNode1 {
@@ -272,6 +383,16 @@ func (g *Genome) MutateAddConn(rate float64) {
 			}
 		}
 
+		// a self-connection (recurrence of a single neuron) is only added if it
+		// is allowed, and never to an input node.
+		if selectedNode0 == selectedNode1 {
+			if selfConn && g.NodeGenes[selectedNode0].Type != "input" {
+				g.ConnGenes = append(g.ConnGenes, NewConnGene(selectedNode0,
+					selectedNode1, rng.NormFloat64()*6.0))
+			}
+			return
+		}
+
 		if g.NodeGenes[selectedNode1].Type == "input" ||
 			g.NodeGenes[selectedNode0].Type == "output" {
 			return
@@ -279,27 +400,128 @@ func (g *Genome) MutateAddConn(rate float64) {
 
 		if !g.pathExists(selectedNode1, selectedNode0) {
 			g.ConnGenes = append(g.ConnGenes, NewConnGene(selectedNode0,
-				selectedNode1, rand.NormFloat64()*6.0))
+				selectedNode1, rng.NormFloat64()*6.0))
+
+			// revert the mutation if the network became too deep.
+			if maxDepth > 0 && g.Depth() > maxDepth {
+				g.ConnGenes = g.ConnGenes[:len(g.ConnGenes)-1]
+			}
 		}
 
 	}
 }
 
+// MutateActivation mutates the genome by replacing the activation function of a
+// randomly selected hidden node with another one in the argument pool.
+func (g *Genome) MutateActivation(rate float64, pool []*ActivationFunc) {
+	g.mutateActivation(rate, pool, globalRand)
+}
+
+// mutateActivation is MutateActivation with the argument random number
+// generator; it returns true if an activation function is replaced. Input and
+// output nodes keep their activation functions.
+func (g *Genome) mutateActivation(rate float64, pool []*ActivationFunc,
+	rng *rand.Rand) bool {
+	if rng.Float64() >= rate || len(pool) < 2 {
+		return false
+	}
+	hidden := make([]*NodeGene, 0, len(g.NodeGenes))
+	for _, node := range g.NodeGenes {
+		if node.Type == "hidden" {
+			hidden = append(hidden, node)
+		}
+	}
+	if len(hidden) == 0 {
+		return false
+	}
+
+	// select a different function than the current one.
+	selected := hidden[rng.Intn(len(hidden))]
+	candidates := make([]*ActivationFunc, 0, len(pool))
+	for _, afunc := range pool {
+		if afunc.Name != selected.Activation.Name {
+			candidates = append(candidates, afunc)
+		}
+	}
+	if len(candidates) == 0 {
+		return false
+	}
+	selected.Activation = candidates[rng.Intn(len(candidates))]
+	g.evaluated = false
+	return true
+}
+
 // pathExists returns true if there is a path from the source to the
-// destination. Helper method of MutateAddConn.
+// destination. Helper method of MutateAddConn. Self-connections are ignored.
+// Each node is visited at most once, since crossover may produce cycles.
 func (g *Genome) pathExists(src, dst int) bool {
-	if src == dst {
-		return true
+	visited := make(map[int]bool)
+	stack := []int{src}
+	for len(stack) > 0 {
+		id := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if id == dst {
+			return true
+		}
+		if visited[id] {
+			continue
+		}
+		visited[id] = true
+		for _, edge := range g.ConnGenes {
+			if edge.From == id && edge.To != id && !visited[edge.To] {
+				stack = append(stack, edge.To)
+			}
+		}
 	}
+	return false
+}
 
-	for _, edge := range g.ConnGenes {
-		if edge.From == src {
-			if g.pathExists(edge.To, dst) {
-				return true
+// Depth returns the depth of the phenotype network of this genome, i.e., the
+// number of enabled connections in the longest path from an input node to
+// any other node. A genome without connections has a depth of 0.
+// Self-connections do not contribute to the depth.
+func (g *Genome) Depth() int {
+	incoming := make(map[int][]int) // node ID to IDs of its input nodes
+	for _, conn := range g.ConnGenes {
+		if !conn.Disabled && conn.From != conn.To {
+			incoming[conn.To] = append(incoming[conn.To], conn.From)
+		}
+	}
+
+	depths := make(map[int]int) // memoized depth of each node
+	var depthOf func(id int) int
+	depthOf = func(id int) int {
+		if d, ok := depths[id]; ok {
+			return d
+		}
+		depths[id] = 0 // stop at cycles, which crossover may produce
+		d := 0
+		for _, from := range incoming[id] {
+			if fd := depthOf(from) + 1; fd > d {
+				d = fd
 			}
 		}
+		depths[id] = d
+		return d
+	}
+
+	depth := 0
+	for _, node := range g.NodeGenes {
+		if d := depthOf(node.ID); d > depth {
+			depth = d
+		}
 	}
+	return depth
+}
 
+// SelfConnected returns true if this genome has an enabled connection from a
+// node to itself, i.e., its phenotype network is recurrent.
+func (g *Genome) SelfConnected() bool {
+	for _, conn := range g.ConnGenes {
+		if !conn.Disabled && conn.From == conn.To {
+			return true
+		}
+	}
 	return false
 }
 
@@ -315,35 +537,58 @@ func (g *Genome) pathExists(src, dst int) bool {
 // checks if each connection already exists; if it does, swap with the other
 // parent's connection by 50% chance. Otherwise, append the new connection.
 func Crossover(id int, g0, g1 *Genome, initFitness float64) *Genome {
-	innovations := make(map[[2]int]*ConnGene)
+	return crossover(id, g0, g1, initFitness, globalRand)
+}
+
+// crossover is Crossover with the argument random number generator. Genes of
+// the child are allocated at once, and its scratch space is reused from
+// geneScratchPool, since crossover dominates allocations of a run otherwise.
+func crossover(id int, g0, g1 *Genome, initFitness float64,
+	rng *rand.Rand) *Genome {
+	scratch := geneScratchPool.Get().(*geneScratch)
+	defer scratch.release()
+
+	innovations := scratch.innovations
+	order := scratch.order[:0]
 	for _, conn := range g0.ConnGenes {
-		innovations[[2]int{conn.From, conn.To}] = conn
+		innov := [2]int{conn.From, conn.To}
+		if innovations[innov] == nil {
+			order = append(order, innov)
+		}
+		innovations[innov] = conn
 	}
 	for _, conn := range g1.ConnGenes {
 		innov := [2]int{conn.From, conn.To}
 		if innovations[innov] != nil {
-			if rand.Float64() < 0.5 {
+			if rng.Float64() < 0.5 {
 				innovations[innov] = conn
 			}
 		} else {
+			order = append(order, innov)
 			innovations[innov] = conn
 		}
 	}
+	scratch.order = order
 
 	// copy node genes
 	largerParent := g0
 	if len(g0.NodeGenes) < len(g1.NodeGenes) {
 		largerParent = g1
 	}
-	nodeGenes := make([]*NodeGene, len(largerParent.NodeGenes))
-	for i := range largerParent.NodeGenes {
-		nodeGenes[i] = largerParent.NodeGenes[i].Copy()
+	nodes := make([]NodeGene, len(largerParent.NodeGenes))
+	nodeGenes := make([]*NodeGene, len(nodes))
+	for i, node := range largerParent.NodeGenes {
+		nodes[i] = *node
+		nodeGenes[i] = &nodes[i]
 	}
 
-	// copy connection genes
-	connGenes := make([]*ConnGene, 0, len(innovations))
-	for _, conn := range innovations {
-		connGenes = append(connGenes, conn.Copy())
+	// copy connection genes, in the order they appear in the parents, so that
+	// crossover is reproducible given the random number generator.
+	conns := make([]ConnGene, len(order))
+	connGenes := make([]*ConnGene, len(order))
+	for i, innov := range order {
+		conns[i] = *innovations[innov]
+		connGenes[i] = &conns[i]
 	}
 
 	return &Genome{
@@ -354,6 +599,63 @@ func Crossover(id int, g0, g1 *Genome, initFitness float64) *Genome {
 	}
 }
 
+// geneScratch is scratch space of crossover and Compatibility.
+type geneScratch struct {
+	innovations map[[2]int]*ConnGene // connection of each innovation
+	order       [][2]int             // innovations in order of appearance
+	conns0      innovationOrder      // connections of the first genome
+	conns1      innovationOrder      // connections of the second genome
+}
+
+// geneScratchPool is a pool of scratch space, which is safe for concurrent
+// use, e.g., by reproduction workers.
+var geneScratchPool = sync.Pool{
+	New: func() interface{} {
+		return &geneScratch{innovations: make(map[[2]int]*ConnGene)}
+	},
+}
+
+// release clears this scratch space, without freeing its memory, and returns
+// it to geneScratchPool.
+func (s *geneScratch) release() {
+	for innov := range s.innovations {
+		delete(s.innovations, innov)
+	}
+	for i := range s.conns0 {
+		s.conns0[i] = nil
+	}
+	for i := range s.conns1 {
+		s.conns1[i] = nil
+	}
+	geneScratchPool.Put(s)
+}
+
+// innovationOrder is a list of connections sorted by their innovations, i.e.,
+// pairs of nodes, which implements sort.Interface.
+type innovationOrder []*ConnGene
+
+func (o innovationOrder) Len() int      { return len(o) }
+func (o innovationOrder) Swap(i, j int) { o[i], o[j] = o[j], o[i] }
+func (o innovationOrder) Less(i, j int) bool {
+	return innovationLess(o[i], o[j])
+}
+
+// innovationLess returns true if the innovation of the first argument
+// connection precedes that of the second one.
+func innovationLess(c0, c1 *ConnGene) bool {
+	if c0.From != c1.From {
+		return c0.From < c1.From
+	}
+	return c0.To < c1.To
+}
+
+// sortInnovations sets this list to the argument connections, stably sorted by
+// their innovations.
+func (o *innovationOrder) sortInnovations(conns []*ConnGene) {
+	*o = append((*o)[:0], conns...)
+	sort.Stable(o)
+}
+
 // Compatibility computes the compatibility distance between two argument
 // genomes.
 //
@@ -367,46 +669,46 @@ func Crossover(id int, g0, g1 *Genome, initFitness float64) *Genome {
 // approach is a slightly modified version of Dr. Kenneth Stanley's original
 // approach in which unmatching genes are separated into excess and disjoint
 // genes.
+//
+// Connections of both genomes are sorted by their innovations into scratch
+// space, and matched by a merge, without building maps.
 func Compatibility(g0, g1 *Genome, c0, c1 float64) float64 {
-	innov0 := make(map[[2]int]*ConnGene) // innovations in g0
-	innov1 := make(map[[2]int]*ConnGene) // innovations in g1
-
-	for _, conn := range g0.ConnGenes {
-		innov0[[2]int{conn.From, conn.To}] = conn
-	}
-
-	for _, conn := range g1.ConnGenes {
-		innov1[[2]int{conn.From, conn.To}] = conn
-	}
-
-	matching := make(map[*ConnGene]*ConnGene) // pairs of matching genes
-	unmatchingCount := 0                      // unmatching gene counter
-
-	// look for matching/unmatching genes from g1's innovations; if a connection
-	// in g0 is not one of g1's innovations, increment unmatching counter.
-	// Otherwise, add the connection to matching
-	for _, conn := range g0.ConnGenes {
-		innov := innov1[[2]int{conn.From, conn.To}]
-		if innov == nil {
+	scratch := geneScratchPool.Get().(*geneScratch)
+	defer scratch.release()
+
+	scratch.conns0.sortInnovations(g0.ConnGenes)
+	scratch.conns1.sortInnovations(g1.ConnGenes)
+	conns0, conns1 := scratch.conns0, scratch.conns1
+
+	diffSum := 0.0       // sum of weight differences of matching genes
+	matchingCount := 0   // matching gene counter
+	unmatchingCount := 0 // unmatching gene counter
+	for i, j := 0, 0; i < len(conns0) || j < len(conns1); {
+		switch {
+		case j == len(conns1) || i < len(conns0) &&
+			innovationLess(conns0[i], conns1[j]):
 			unmatchingCount++
-		} else {
-			matching[innov] = conn
-		}
-	}
-
-	// repeat for g0's innovations, to count unmatching connection genes for g1.
-	for _, conn := range g1.ConnGenes {
-		if innov0[[2]int{conn.From, conn.To}] == nil {
+			i++
+		case i == len(conns0) || innovationLess(conns1[j], conns0[i]):
 			unmatchingCount++
+			j++
+		default:
+			// a duplicate innovation of a genome matches once, by its last
+			// connection.
+			for i+1 < len(conns0) && !innovationLess(conns0[i], conns0[i+1]) {
+				i++
+			}
+			for j+1 < len(conns1) && !innovationLess(conns1[j], conns1[j+1]) {
+				j++
+			}
+			diffSum += math.Abs(conns0[i].Weight - conns1[j].Weight)
+			matchingCount++
+			i++
+			j++
 		}
 	}
 
 	// compute average weight differences of matching genes
-	diffSum := 0.0
-	matchingCount := len(matching)
-	for conn0, conn1 := range matching {
-		diffSum += math.Abs(conn0.Weight - conn1.Weight)
-	}
 	avgDiff := diffSum / float64(matchingCount)
 	if matchingCount == 0 {
 		avgDiff = 0.0
@@ -414,9 +716,26 @@ func Compatibility(g0, g1 *Genome, c0, c1 float64) float64 {
 	return c0*float64(unmatchingCount) + c1*avgDiff
 }
 
+// DistanceFunc is a type of function that returns the distance between two
+// genomes, by which they are differentiated into species during speciation;
+// e.g., it may also measure differences of nodes and their activation
+// functions, or of the behaviors of their networks.
+type DistanceFunc func(g0, g1 *Genome) float64
+
+// NewDistanceFunc returns a new distance function that is the compatibility
+// distance of genomes with the argument coefficients of unmatching genes and
+// matching genes (see Compatibility).
+func NewDistanceFunc(c0, c1 float64) DistanceFunc {
+	return func(g0, g1 *Genome) float64 {
+		return Compatibility(g0, g1, c0, c1)
+	}
+}
+
 // ComparisonFunc is a type of function that returns a boolean value that
 // indicates whether the first argument genome is better than the second one
-// in terms of its fitness.
+// in terms of its fitness. Since genomes are sorted with it, e.g., by
+// selection, it must be a strict weak ordering, i.e., no genome is better than
+// itself, and being better is transitive.
 type ComparisonFunc func(g0, g1 *Genome) bool
 
 // NewComparisonFunc returns a new comparison function, given an indicator of
@@ -431,3 +750,30 @@ func NewComparisonFunc(minimize bool) ComparisonFunc {
 		return g0.Fitness > g1.Fitness
 	}
 }
+
+// NewLexicographicComparisonFunc returns a new comparison function that
+// compares genomes with each of the argument comparison functions in order,
+// and falls through to the next one only if neither genome is better than the
+// other, e.g., for multiple metrics in the order of priority.
+func NewLexicographicComparisonFunc(
+	comparisons ...ComparisonFunc) ComparisonFunc {
+	return func(g0, g1 *Genome) bool {
+		for _, better := range comparisons {
+			if better(g0, g1) {
+				return true
+			}
+			if better(g1, g0) {
+				return false
+			}
+		}
+		return false
+	}
+}
+
+// SmallerGenome is a comparison function by which a genome is better than
+// another, if it has fewer genes; it is meant to break ties of fitness with
+// NewLexicographicComparisonFunc, in favor of parsimonious networks.
+func SmallerGenome(g0, g1 *Genome) bool {
+	return len(g0.NodeGenes)+len(g0.ConnGenes) <
+		len(g1.NodeGenes)+len(g1.ConnGenes)
+}
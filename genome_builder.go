@@ -0,0 +1,132 @@
+// genome_builder.go implementation of the construction of genomes by hand.
+//
+// Copyright (C) 2017  Jin Yeom
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package neat
+
+import (
+	"fmt"
+	"math"
+)
+
+// GenomeBuilder constructs a genome by hand, e.g., a seed genome of a
+// population or a fixture of a test, node by node and connection by
+// connection, instead of filling the slices of genes directly. Each node is
+// assigned the next ID, i.e., its index, in the order in which it is added;
+// since genomes of a population share the IDs of their input and output nodes,
+// these are conventionally added first. Every mistake is reported by Build.
+//
+//	g, err := NewGenomeBuilder(0, 0.0).
+//		AddInput().AddInput().
+//		AddOutput(ActivationSet["sigmoid"]).
+//		AddHidden(ActivationSet["relu"]).
+//		Connect(0, 3, 1.0).Connect(1, 3, -1.0).Connect(3, 2, 0.5).
+//		Build()
+type GenomeBuilder struct {
+	genome     *Genome         // genome under construction
+	conns      map[[2]int]bool // existing connections
+	violations []string        // mistakes reported by Build
+}
+
+// NewGenomeBuilder returns a new instance of GenomeBuilder of a genome, given
+// its ID and its initial fitness.
+func NewGenomeBuilder(id int, initFitness float64) *GenomeBuilder {
+	return &GenomeBuilder{
+		genome: &Genome{
+			ID:        id,
+			SpeciesID: -1,
+			NodeGenes: make([]*NodeGene, 0),
+			ConnGenes: make([]*ConnGene, 0),
+			Fitness:   initFitness,
+			evaluated: false,
+		},
+		conns: make(map[[2]int]bool),
+	}
+}
+
+// violate records a mistake of construction, which is reported by Build.
+func (b *GenomeBuilder) violate(format string, a ...interface{}) {
+	b.violations = append(b.violations, fmt.Sprintf(format, a...))
+}
+
+// addNode adds a new node of the argument type and activation function.
+func (b *GenomeBuilder) addNode(ntype string,
+	activation *ActivationFunc) *GenomeBuilder {
+	id := len(b.genome.NodeGenes)
+	if activation == nil && ntype != "input" {
+		b.violate("%s node %d has no activation function", ntype, id)
+	}
+	b.genome.NodeGenes = append(b.genome.NodeGenes,
+		NewNodeGene(id, ntype, activation))
+	return b
+}
+
+// AddInput adds a new input node, which has no activation function.
+func (b *GenomeBuilder) AddInput() *GenomeBuilder {
+	return b.addNode("input", nil)
+}
+
+// AddHidden adds a new hidden node with the argument activation function.
+func (b *GenomeBuilder) AddHidden(activation *ActivationFunc) *GenomeBuilder {
+	return b.addNode("hidden", activation)
+}
+
+// AddOutput adds a new output node with the argument activation function.
+func (b *GenomeBuilder) AddOutput(activation *ActivationFunc) *GenomeBuilder {
+	return b.addNode("output", activation)
+}
+
+// LastID returns the ID of the last added node, or -1 if there is none.
+func (b *GenomeBuilder) LastID() int {
+	return len(b.genome.NodeGenes) - 1
+}
+
+// Connect adds a new connection between two existing nodes, given their IDs,
+// with the argument weight. A connection that leads to an input node, or that
+// already exists, is a mistake.
+func (b *GenomeBuilder) Connect(from, to int, weight float64) *GenomeBuilder {
+	numNodes := len(b.genome.NodeGenes)
+	switch {
+	case from < 0 || from >= numNodes || to < 0 || to >= numNodes:
+		b.violate("connection %d->%d refers to a missing node", from, to)
+	case b.genome.NodeGenes[to].Type == "input":
+		b.violate("connection %d->%d leads to an input node", from, to)
+	case b.conns[[2]int{from, to}]:
+		b.violate("connection %d->%d is duplicate", from, to)
+	case math.IsNaN(weight) || math.IsInf(weight, 0):
+		b.violate("connection %d->%d has an invalid weight", from, to)
+	default:
+		b.conns[[2]int{from, to}] = true
+		b.genome.ConnGenes = append(b.genome.ConnGenes,
+			NewConnGene(from, to, weight))
+	}
+	return b
+}
+
+// Build returns a copy of the constructed genome, or a GenomeError that reports
+// every mistake of construction, as well as every violation of
+// Genome.Validate, e.g., if there is no input or output node.
+func (b *GenomeBuilder) Build() (*Genome, error) {
+	if len(b.violations) != 0 {
+		violations := make([]string, len(b.violations))
+		copy(violations, b.violations)
+		return nil, &GenomeError{ID: b.genome.ID, Violations: violations}
+	}
+	if err := b.genome.Validate(); err != nil {
+		return nil, err
+	}
+	return b.genome.Copy(), nil
+}
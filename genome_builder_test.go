@@ -0,0 +1,66 @@
+package neat
+
+import (
+	"math"
+	"testing"
+)
+
+func TestGenomeBuilder(t *testing.T) {
+	sigmoid := ActivationSet["sigmoid"]
+	b := NewGenomeBuilder(7, 1.0).
+		AddInput().AddInput().
+		AddOutput(sigmoid).
+		AddHidden(ActivationSet["relu"])
+	hidden := b.LastID()
+	g, err := b.Connect(0, hidden, 1.0).Connect(1, hidden, 1.0).
+		Connect(hidden, 2, 2.0).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g.ID != 7 || g.Fitness != 1.0 || g.SpeciesID != -1 {
+		t.Errorf("invalid genome %d with fitness %f", g.ID, g.Fitness)
+	}
+	if hidden != 3 || len(g.NodeGenes) != 4 || len(g.ConnGenes) != 3 {
+		t.Fatalf("invalid genome: %s", g)
+	}
+
+	outputs, err := NewNeuralNetwork(g).FeedForward([]float64{0.25, 0.5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := sigmoid.Fn(1.5); math.Abs(outputs[0]-want) > 1e-9 {
+		t.Errorf("invalid output: %f != %f", outputs[0], want)
+	}
+
+	// the built genome must not be shared with the builder.
+	g.ConnGenes[0].Weight = 100.0
+	if h, _ := b.Build(); h.ConnGenes[0].Weight != 1.0 {
+		t.Error("genes are shared with the builder")
+	}
+}
+
+func TestGenomeBuilderMistakes(t *testing.T) {
+	_, err := NewGenomeBuilder(3, 0.0).
+		AddInput().
+		AddOutput(nil).
+		AddHidden(ActivationSet["relu"]).
+		Connect(0, 1, 1.0).
+		Connect(0, 1, 1.0).
+		Connect(1, 0, 1.0).
+		Connect(0, 5, 1.0).
+		Connect(0, 2, math.NaN()).
+		Build()
+	genomeErr, ok := err.(*GenomeError)
+	if !ok {
+		t.Fatalf("invalid error: %v", err)
+	}
+	if genomeErr.ID != 3 || len(genomeErr.Violations) != 5 {
+		t.Errorf("invalid violations: %v", genomeErr)
+	}
+
+	_, err = NewGenomeBuilder(0, 0.0).AddInput().Build()
+	if _, ok := err.(*GenomeError); !ok {
+		t.Errorf("genome without an output node is not rejected: %v", err)
+	}
+}
@@ -0,0 +1,86 @@
+// genome_file.go implementation of genome files.
+//
+// Copyright (C) 2017  Jin Yeom
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+//go:build !js
+
+package neat
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// NewGenomeJSON creates a new instance of Genome, given the name of a JSON file
+// that was exported with Genome.ExportJSON. Since activation functions are
+// exported only by their names, they are restored from ActivationSet; an
+// unknown user-defined function must be registered with RegisterActivation
+// beforehand. The genome is validated (see Genome.Validate).
+func NewGenomeJSON(filename string) (*Genome, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ReadGenomeJSON(f)
+}
+
+// ExportJSON exports a JSON file that contains this genome's information. If
+// the argument format indicator is true, the exported JSON file will be
+// formatted with indentations.
+func (g *Genome) ExportJSON(format bool) error {
+	// create a new json file
+	filename := fmt.Sprintf("genome_%d_%d.json", g.ID, time.Now().UnixNano())
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	encoder := json.NewEncoder(f)
+	if format {
+		encoder.SetIndent("", "\t")
+	}
+	if err = encoder.Encode(g); err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+// saveGenomeJSON writes the argument genome to a JSON file of the argument
+// name, which can be imported with NewGenomeJSON.
+func saveGenomeJSON(g *Genome, filename string) error {
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	encoder := json.NewEncoder(f)
+	encoder.SetIndent("", "\t")
+	return encoder.Encode(g)
+}
+
+// exportChampion writes the argument champion of the argument generation to a
+// JSON file in the archive directory.
+func (n *NEAT) exportChampion(champion *Genome, gen int) error {
+	filename := filepath.Join(n.Config.ArchiveDir,
+		fmt.Sprintf("champion_%04d.json", gen))
+	return saveGenomeJSON(champion, filename)
+}
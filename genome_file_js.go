@@ -0,0 +1,29 @@
+// genome_file_js.go implementation of genome files in JavaScript.
+//
+// Copyright (C) 2017  Jin Yeom
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+//go:build js
+
+package neat
+
+import "errors"
+
+// exportChampion returns an error, since there is no file system to archive
+// champions in when running in JavaScript, e.g., in a browser (see package
+// wasm); champions can still be archived in memory (see NEAT.Champions).
+func (n *NEAT) exportChampion(champion *Genome, gen int) error {
+	return errors.New("neat: no file system to archive champions in")
+}
@@ -0,0 +1,117 @@
+// genome_schema.go implementation of the validation of genome files.
+//
+// Copyright (C) 2017  Jin Yeom
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package neat
+
+import (
+	_ "embed" // for the JSON schemas
+	"fmt"
+	"math"
+	"strings"
+)
+
+var (
+	// GenomeSchema is the JSON Schema of genomes exported in JSON, e.g., with
+	// Genome.ExportJSON, for tools that read or write them.
+	//
+	//go:embed genome.schema.json
+	GenomeSchema string
+
+	// PopulationSchema is the JSON Schema of populations exported with
+	// ExportPopulationJSON, which refers to GenomeSchema.
+	//
+	//go:embed population.schema.json
+	PopulationSchema string
+)
+
+// GenomeError is an error that consists of every violation found during the
+// validation of a genome.
+type GenomeError struct {
+	ID         int      // genome ID
+	Violations []string // violations of the genome
+}
+
+// Error returns the string representation of GenomeError.
+func (e *GenomeError) Error() string {
+	return fmt.Sprintf("neat: invalid genome %d: %s", e.ID,
+		strings.Join(e.Violations, "; "))
+}
+
+// Validate checks whether this genome is well-formed, as described by
+// GenomeSchema, e.g., after it is decoded from a hand-edited file, and returns
+// a GenomeError that reports every violation, or nil if it is valid. Node IDs
+// must be 0 to n-1 for n nodes, since new nodes are assigned the next ID.
+func (g *Genome) Validate() error {
+	var violations []string
+	violate := func(format string, a ...interface{}) {
+		violations = append(violations, fmt.Sprintf(format, a...))
+	}
+
+	types := make(map[int]string)
+	numInputs, numOutputs := 0, 0
+	for i, node := range g.NodeGenes {
+		if node == nil {
+			violate("node gene %d is null", i)
+			continue
+		}
+		if node.ID < 0 || node.ID >= len(g.NodeGenes) {
+			violate("node ID %d is not within [0, %d)", node.ID, len(g.NodeGenes))
+		} else if _, ok := types[node.ID]; ok {
+			violate("node ID %d is duplicate", node.ID)
+		}
+		types[node.ID] = node.Type
+
+		switch node.Type {
+		case "input":
+			numInputs++
+		case "output", "hidden":
+			if node.Type == "output" {
+				numOutputs++
+			}
+			if node.Activation == nil || node.Activation.Name == "" {
+				violate("node %d has no activation function", node.ID)
+			}
+		default:
+			violate("node %d has an unknown type %q", node.ID, node.Type)
+		}
+	}
+	if numInputs == 0 || numOutputs == 0 {
+		violate("genome must have input and output nodes")
+	}
+
+	for i, conn := range g.ConnGenes {
+		if conn == nil {
+			violate("connection gene %d is null", i)
+			continue
+		}
+		_, okFrom := types[conn.From]
+		to, okTo := types[conn.To]
+		if !okFrom || !okTo {
+			violate("connection %d->%d refers to a missing node", conn.From, conn.To)
+		} else if to == "input" {
+			violate("connection %d->%d leads to an input node", conn.From, conn.To)
+		}
+		if math.IsNaN(conn.Weight) || math.IsInf(conn.Weight, 0) {
+			violate("connection %d->%d has an invalid weight", conn.From, conn.To)
+		}
+	}
+
+	if len(violations) != 0 {
+		return &GenomeError{ID: g.ID, Violations: violations}
+	}
+	return nil
+}
@@ -1,10 +1,15 @@
+//go:build !js
+
 package neat
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log"
 	"math/rand"
 	"testing"
+	"time"
 )
 
 func GenomeUnitTest() {
@@ -119,3 +124,159 @@ func TestGenome(t *testing.T) {
 	rand.Seed(0)
 	GenomeUnitTest()
 }
+
+func TestGenomeMaxDepth(t *testing.T) {
+	rand.Seed(0)
+	g := NewFCGenome(0, 3, 1, 0.0)
+	if g.Depth() != 1 {
+		t.Errorf("invalid depth: %d != 1", g.Depth())
+	}
+	for i := 0; i < 100; i++ {
+		g.mutateAddNode(1.0, ActivationSet["sigmoid"], 3, globalRand)
+		g.mutateAddConn(1.0, 3, false, globalRand)
+	}
+	if g.Depth() > 3 {
+		t.Errorf("depth exceeds the maximum: %d > 3", g.Depth())
+	}
+}
+
+func TestGenomeEvaluationTimeout(t *testing.T) {
+	ctx := context.Background()
+	g := NewFCGenome(0, 3, 1, 0.0)
+	slow := ContextEvaluationFunc(func(ctx context.Context,
+		n *NeuralNetwork) (float64, error) {
+		time.Sleep(time.Second)
+		return 1.0, nil
+	}).Result()
+	if err := g.evaluateWithin(ctx, slow, 10*time.Millisecond,
+		-1.0); err != errEvaluationTimeout {
+		t.Errorf("evaluation did not time out: %v", err)
+	}
+	if g.Fitness != -1.0 {
+		t.Errorf("invalid fallback fitness: %f != -1.0", g.Fitness)
+	}
+
+	g = NewFCGenome(1, 3, 1, 0.0)
+	fast := EvaluationFunc(func(n *NeuralNetwork) float64 {
+		return 1.0
+	}).Fallible().Contextual().Result()
+	if err := g.evaluateWithin(ctx, fast, time.Second, -1.0); err != nil ||
+		g.Fitness != 1.0 {
+		t.Errorf("evaluation failed within the time limit: %f", g.Fitness)
+	}
+
+	g = NewFCGenome(2, 3, 1, 0.0)
+	failing := ContextEvaluationFunc(func(ctx context.Context,
+		n *NeuralNetwork) (float64, error) {
+		return 1.0, errors.New("simulator crashed")
+	}).Result()
+	if err := g.evaluateWithin(ctx, failing, 0, -1.0); err == nil ||
+		g.Fitness != -1.0 {
+		t.Errorf("failed evaluation is not reported: %v, %f", err, g.Fitness)
+	}
+
+	// a runaway evaluation that respects its context is canceled.
+	g = NewFCGenome(3, 3, 1, 0.0)
+	canceled := make(chan bool, 1)
+	runaway := ContextEvaluationFunc(func(ctx context.Context,
+		n *NeuralNetwork) (float64, error) {
+		<-ctx.Done()
+		canceled <- true
+		return 0.0, ctx.Err()
+	}).Result()
+	if err := g.evaluateWithin(ctx, runaway, 10*time.Millisecond,
+		-1.0); err != errEvaluationTimeout || g.Fitness != -1.0 {
+		t.Errorf("runaway evaluation did not time out: %v, %f", err, g.Fitness)
+	}
+	select {
+	case <-canceled:
+	case <-time.After(time.Second):
+		t.Error("context of the runaway evaluation is not canceled")
+	}
+}
+
+func TestGenomeMutateActivation(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	g := NewGenome(0, 2, 1, 0.0)
+	pool := []*ActivationFunc{ActivationSet["relu"], ActivationSet["sin"]}
+	if g.mutateActivation(1.0, pool, rng) {
+		t.Error("genome without hidden nodes is mutated")
+	}
+
+	g.ConnGenes = append(g.ConnGenes, NewConnGene(0, 2, 1.0))
+	g.mutateAddNode(1.0, ActivationSet["relu"], 0, rng)
+	for i := 0; i < 4; i++ {
+		prev := g.NodeGenes[3].Activation.Name
+		if !g.mutateActivation(1.0, pool, rng) || g.NodeGenes[3].Activation.Name == prev {
+			t.Errorf("activation is not replaced: %s", prev)
+		}
+	}
+	if g.NodeGenes[2].Activation != ActivationSet["sigmoid"] {
+		t.Error("activation of the output node is mutated")
+	}
+}
+
+func TestCompatibility(t *testing.T) {
+	g0 := NewGenome(0, 2, 1, 0.0)
+	g1 := NewGenome(1, 2, 1, 0.0)
+	g0.ConnGenes = []*ConnGene{
+		NewConnGene(1, 2, 0.5),
+		NewConnGene(0, 2, 1.0),
+		NewConnGene(2, 2, 0.0),
+	}
+	g1.ConnGenes = []*ConnGene{
+		NewConnGene(0, 2, 0.0),
+		NewConnGene(1, 2, 1.0),
+		NewConnGene(0, 1, 0.0),
+	}
+	// 2 unmatching genes, and an average weight difference of 0.75.
+	if dist := Compatibility(g0, g1, 1.0, 2.0); dist != 2.0+1.5 {
+		t.Errorf("invalid distance: %f != %f", dist, 3.5)
+	}
+	if dist := Compatibility(g1, g0, 1.0, 2.0); dist != 2.0+1.5 {
+		t.Errorf("distance is not symmetric: %f != %f", dist, 3.5)
+	}
+	if dist := Compatibility(g0, g0, 1.0, 2.0); dist != 0.0 {
+		t.Errorf("invalid distance to itself: %f", dist)
+	}
+}
+
+func TestCrossoverCopiesGenes(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	g0 := newFCGenome(0, 3, 2, 0.0, rng)
+	g1 := newFCGenome(1, 3, 2, 0.0, rng)
+	child := crossover(2, g0, g1, 0.0, rng)
+	if len(child.ConnGenes) != len(g0.ConnGenes) {
+		t.Fatalf("invalid number of connections: %d != %d",
+			len(child.ConnGenes), len(g0.ConnGenes))
+	}
+
+	// genes of the child must not be shared with its parents.
+	child.ConnGenes[0].Weight = 100.0
+	child.NodeGenes[0].Type = "hidden"
+	if g0.ConnGenes[0].Weight == 100.0 || g1.ConnGenes[0].Weight == 100.0 {
+		t.Error("connection genes are shared with the parents")
+	}
+	if g0.NodeGenes[0].Type != "input" || g1.NodeGenes[0].Type != "input" {
+		t.Error("node genes are shared with the parents")
+	}
+}
+
+func TestLexicographicComparison(t *testing.T) {
+	g0 := NewGenome(0, 3, 1, 1.0)
+	g1 := NewGenome(1, 3, 1, 1.0)
+	g1.ConnGenes = append(g1.ConnGenes, NewConnGene(0, 3, 1.0))
+	g2 := NewGenome(2, 3, 1, 2.0)
+
+	better := NewLexicographicComparisonFunc(NewComparisonFunc(false),
+		SmallerGenome)
+	if !better(g0, g1) || better(g1, g0) {
+		t.Error("ties of fitness are not broken by size")
+	}
+	if !better(g2, g0) || better(g0, g2) {
+		t.Error("fitness is not compared first")
+	}
+	if better(g0, g0) {
+		t.Error("a genome is better than itself")
+	}
+}
@@ -0,0 +1,143 @@
+// adaptive.go implementation of adaptive HyperNEAT.
+//
+// Copyright (C) 2017  Jin Yeom
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package hyperneat
+
+import (
+	"math"
+
+	"github.com/jinyeom/neat"
+	"github.com/jinyeom/neat/cppn"
+)
+
+// numRuleOutputs is the number of CPPN outputs of a plasticity rule.
+const numRuleOutputs = 5
+
+// Rule is a generalized Hebbian plasticity rule of a connection, which changes
+// its weight after each step by
+//
+//	LearningRate * (A*pre*post + B*pre + C*post + D)
+//
+// given the signals of the pre- and post-synaptic neurons. Each parameter is
+// an output of the CPPN, whose range is that of its output activation.
+type Rule struct {
+	LearningRate float64 // learning rate
+	A            float64 // coefficient of correlation
+	B            float64 // coefficient of the pre-synaptic signal
+	C            float64 // coefficient of the post-synaptic signal
+	D            float64 // constant
+}
+
+// Delta returns the change of the weight given the argument signals of the
+// pre- and post-synaptic neurons.
+func (r Rule) Delta(pre, post float64) float64 {
+	return r.LearningRate * (r.A*pre*post + r.B*pre + r.C*post + r.D)
+}
+
+// rule returns the plasticity rule of the argument CPPN outputs, whose rule
+// parameters follow the weight and the link expression output.
+func (d *Decoder) rule(outputs []float64) Rule {
+	offset := 1
+	if d.LEO {
+		offset++
+	}
+	p := outputs[offset : offset+numRuleOutputs]
+	return Rule{LearningRate: p[0], A: p[1], B: p[2], C: p[3], D: p[4]}
+}
+
+// plasticSynapse is a connection of an adaptive network and its rule.
+type plasticSynapse struct {
+	pre  *neat.Neuron // pre-synaptic neuron
+	post *neat.Neuron // post-synaptic neuron
+	rule Rule         // plasticity rule
+}
+
+// AdaptiveNetwork is a phenotype network of adaptive HyperNEAT, whose weights
+// change during its lifetime by the plasticity rules of its connections.
+type AdaptiveNetwork struct {
+	Network   *neat.NeuralNetwork // network of the current weights
+	MaxWeight float64             // maximum magnitude of a weight
+
+	synapses []*plasticSynapse // plastic connections
+}
+
+// DecodeAdaptive returns the adaptive phenotype network that the argument CPPN
+// produces on the substrate. The decoder must be adaptive.
+func (d *Decoder) DecodeAdaptive(c *cppn.CPPN) *AdaptiveNetwork {
+	g, rules := d.decode(c)
+	nn := neat.NewNeuralNetwork(g)
+
+	// signals are kept after each step for the plasticity rules; since the
+	// substrate is acyclic, they don't affect the next step.
+	nn.Recurrent = true
+
+	// node IDs of the decoded genome are indices of the neurons.
+	synapses := make([]*plasticSynapse, 0, len(rules))
+	for i, conn := range g.ConnGenes {
+		synapses = append(synapses, &plasticSynapse{
+			pre:  nn.Neurons[conn.From],
+			post: nn.Neurons[conn.To],
+			rule: rules[i],
+		})
+	}
+	return &AdaptiveNetwork{
+		Network:   nn,
+		MaxWeight: d.MaxWeight,
+		synapses:  synapses,
+	}
+}
+
+// FeedForward propagates the argument inputs through the network like
+// (*neat.NeuralNetwork).FeedForward, then updates its weights by the
+// plasticity rules, clipped to [-MaxWeight, MaxWeight].
+func (a *AdaptiveNetwork) FeedForward(inputs []float64) ([]float64, error) {
+	outputs, err := a.Network.FeedForward(inputs)
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range a.synapses {
+		w := s.post.Synapses[s.pre] + s.rule.Delta(s.pre.Signal, s.post.Signal)
+		if !math.IsNaN(w) {
+			s.post.Synapses[s.pre] = math.Max(-a.MaxWeight, math.Min(a.MaxWeight, w))
+		}
+	}
+	return outputs, nil
+}
+
+// Predict is FeedForward, which implements neat.Predictor; hence, weights are
+// updated on each prediction.
+func (a *AdaptiveNetwork) Predict(inputs []float64) ([]float64, error) {
+	return a.FeedForward(inputs)
+}
+
+// Reset clears the signals of all neurons; weights learned so far are kept.
+func (a *AdaptiveNetwork) Reset() {
+	a.Network.Reset()
+}
+
+// AdaptiveEvaluationFunc is a type of function that evaluates an adaptive
+// network during its lifetime, and returns its fitness score.
+type AdaptiveEvaluationFunc func(a *AdaptiveNetwork) float64
+
+// AdaptiveEvaluation returns a NEAT evaluation function that decodes each CPPN
+// into an adaptive phenotype network on the substrate, and evaluates it with
+// the argument evaluation function.
+func (d *Decoder) AdaptiveEvaluation(evaluate AdaptiveEvaluationFunc) neat.EvaluationFunc {
+	return func(nn *neat.NeuralNetwork) float64 {
+		return evaluate(d.DecodeAdaptive(cppn.FromNetwork(nn)))
+	}
+}
@@ -0,0 +1,82 @@
+package hyperneat
+
+import (
+	"math"
+	"testing"
+
+	"github.com/jinyeom/neat"
+	"github.com/jinyeom/neat/cppn"
+)
+
+func TestRule(t *testing.T) {
+	r := Rule{LearningRate: 0.5, A: 1.0, B: 2.0, C: 3.0, D: 4.0}
+	if delta := r.Delta(1.0, 2.0); delta != 0.5*(2.0+2.0+6.0+4.0) {
+		t.Errorf("invalid delta: %f", delta)
+	}
+}
+
+func TestDecodeAdaptive(t *testing.T) {
+	s := NewSubstrate().AddLine("in", "input", 2, -1).
+		AddLine("out", "output", 1, 1).Connect("in", "out")
+	d := NewAdaptiveDecoder(s)
+	config := d.NewConfig()
+	if config.NumOutputs != 1+numRuleOutputs {
+		t.Fatalf("invalid number of outputs: %d", config.NumOutputs)
+	}
+
+	// a CPPN of a constant weight, and a rule of a positive constant D.
+	g := neat.NewGenome(0, NumCoords+1, config.NumOutputs, 0.0)
+	weight, rate, d0 := NumCoords+1, NumCoords+2, NumCoords+6
+	g.ConnGenes = append(g.ConnGenes,
+		neat.NewConnGene(0, weight, 5.0),
+		neat.NewConnGene(0, rate, 5.0),
+		neat.NewConnGene(0, d0, 5.0))
+	a := d.DecodeAdaptive(cppn.New(g))
+
+	first, err := a.FeedForward([]float64{1.0, 1.0})
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, _ := a.FeedForward([]float64{1.0, 1.0})
+	if !(second[0] > first[0]) {
+		t.Errorf("weights are not updated: %f -> %f", first[0], second[0])
+	}
+	for _, syn := range a.synapses {
+		if w := syn.post.Synapses[syn.pre]; w > a.MaxWeight {
+			t.Errorf("weight is not clipped: %f", w)
+		}
+	}
+}
+
+func TestPredictor(t *testing.T) {
+	s := NewSubstrate().AddLine("in", "input", 2, -1).
+		AddLine("out", "output", 1, 1).Connect("in", "out")
+	d := NewDecoder(s)
+	g := neat.NewFCGenome(0, NumCoords+1, d.NewConfig().NumOutputs, 0.5)
+	c := cppn.New(g)
+
+	inputs := []float64{0.5, -0.5}
+	predictors := []neat.Predictor{
+		d.DecodeNetwork(c),
+		NewAdaptiveDecoder(s).DecodeAdaptive(c),
+	}
+	for i, p := range predictors {
+		outputs, err := p.Predict(inputs)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(outputs) != 1 {
+			t.Errorf("invalid number of outputs of predictor %d: %d",
+				i, len(outputs))
+		}
+	}
+
+	var p neat.Predictor = c
+	outputs, err := p.Predict([]float64{0.1, 0.2, 0.3, 0.4, 0.5, 0.6})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := c.Query(0.1, 0.2, 0.3, 0.4, 0.5, 0.6); math.Abs(outputs[0]-want) > 1e-12 {
+		t.Errorf("invalid prediction of CPPN: %f != %f", outputs[0], want)
+	}
+}
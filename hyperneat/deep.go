@@ -0,0 +1,189 @@
+// deep.go implementation of Deep HyperNEAT.
+//
+// Copyright (C) 2017  Jin Yeom
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package hyperneat
+
+import (
+	"fmt"
+	"math/rand"
+
+	"github.com/jinyeom/neat"
+	"github.com/jinyeom/neat/cppn"
+)
+
+// DeepDecoder decodes CPPNs into phenotype networks whose depth is discovered
+// by evolution, in the style of Deep HyperNEAT: each set of CPPN outputs maps
+// to a layer of the phenotype network, and CPPNs grow new sets of outputs by
+// the mutation of adding a layer, thus new hidden layers of the substrate.
+//
+// The first set of outputs determines the connections into the output layers,
+// and the j-th set thereafter determines the connections into the j-th hidden
+// layer, which is inserted next to the input layers when it is added; thus, a
+// CPPN of k sets of outputs produces the network of
+//
+//	inputs -> hidden k-1 -> ... -> hidden 1 -> outputs
+//
+// where each hidden layer is of the shape of the template, and the depths of
+// the layers are evenly spaced between the input and output layers.
+type DeepDecoder struct {
+	*Decoder         // decoder of each pair of layers
+	Hidden    *Layer // template of hidden layers
+	MaxLayers int    // maximum number of hidden layers
+}
+
+// NewDeepDecoder returns a new deep decoder on the input and output layers of
+// the argument substrate (other layers and connections are ignored), given the
+// template of hidden layers, with at most 3 hidden layers.
+func NewDeepDecoder(s *Substrate, hidden *Layer) *DeepDecoder {
+	return &DeepDecoder{
+		Decoder:   NewDecoder(s),
+		Hidden:    hidden,
+		MaxLayers: 3,
+	}
+}
+
+// NewConfig returns a new default configuration of NEAT that evolves CPPNs for
+// this decoder, which initially have no hidden layers.
+func (d *DeepDecoder) NewConfig() *neat.Config {
+	config := cppn.NewConfig(d.numCoords(), d.numConnOutputs())
+	config.ExperimentName = "Deep HyperNEAT"
+	return config
+}
+
+// NumLayers returns the number of hidden layers that the argument CPPN genome
+// encodes, which is bounded by MaxLayers.
+func (d *DeepDecoder) NumLayers(g *neat.Genome) int {
+	numOutputs := 0
+	for _, node := range g.NodeGenes {
+		if node.Type == "output" {
+			numOutputs++
+		}
+	}
+	return d.numLayers(numOutputs)
+}
+
+// numLayers returns the number of hidden layers given the number of CPPN
+// outputs.
+func (d *DeepDecoder) numLayers(numOutputs int) int {
+	numLayers := numOutputs/d.numConnOutputs() - 1
+	if numLayers > d.MaxLayers {
+		numLayers = d.MaxLayers
+	}
+	if numLayers < 0 {
+		numLayers = 0
+	}
+	return numLayers
+}
+
+// Mutation returns a mutation that adds a hidden layer to a CPPN genome by the
+// argument rate, i.e., a new set of outputs of the same activation functions as
+// the first set, unless it already has MaxLayers hidden layers; it can be added
+// to NEAT by neat.WithMutation.
+func (d *DeepDecoder) Mutation(rate float64) neat.MutationFunc {
+	return func(g *neat.Genome, rng *rand.Rand) bool {
+		if rng.Float64() >= rate || d.NumLayers(g) >= d.MaxLayers {
+			return false
+		}
+		var outputs []*neat.NodeGene
+		for _, node := range g.NodeGenes {
+			if node.Type == "output" {
+				outputs = append(outputs, node)
+			}
+		}
+		if len(outputs) < d.numConnOutputs() {
+			return false
+		}
+		for _, output := range outputs[:d.numConnOutputs()] {
+			g.NodeGenes = append(g.NodeGenes,
+				neat.NewNodeGene(len(g.NodeGenes), "output", output.Activation))
+		}
+		return true
+	}
+}
+
+// substrate returns the substrate of the argument number of hidden layers, and
+// the index of the set of CPPN outputs of each pair of connected layers.
+func (d *DeepDecoder) substrate(numLayers int) (*Substrate, []int) {
+	s := NewSubstrate()
+	var inputs, outputs []*Layer
+	for _, l := range d.Substrate.Layers {
+		switch l.Type {
+		case "input":
+			inputs = append(inputs, l)
+			s.AddLayer(l)
+		case "output":
+			outputs = append(outputs, l)
+			s.AddLayer(l)
+		}
+	}
+	if len(inputs) == 0 || len(outputs) == 0 {
+		return s, nil
+	}
+
+	// hidden layers from the input side, i.e., hidden numLayers to hidden 1.
+	zIn, zOut := inputs[0].Z, outputs[0].Z
+	prev := make([]string, 0, len(inputs))
+	for _, l := range inputs {
+		prev = append(prev, l.Name)
+	}
+	var sets []int
+	for j := numLayers; j >= 1; j-- {
+		hidden := *d.Hidden
+		hidden.Name = fmt.Sprintf("deep-%d", j)
+		hidden.Type = "hidden"
+		pos := float64(numLayers-j+1) / float64(numLayers+1)
+		hidden.Z = zIn + (zOut-zIn)*pos
+		s.AddLayer(&hidden)
+		for _, name := range prev {
+			s.Connect(name, hidden.Name)
+			sets = append(sets, j)
+		}
+		prev = []string{hidden.Name}
+	}
+	for _, name := range prev {
+		for _, l := range outputs {
+			s.Connect(name, l.Name)
+			sets = append(sets, 0)
+		}
+	}
+	return s, sets
+}
+
+// Decode returns the genome of the phenotype network that the argument CPPN
+// produces, whose number of hidden layers is determined by the number of its
+// outputs. Node IDs are assigned as in (*Decoder).Decode.
+func (d *DeepDecoder) Decode(c *cppn.CPPN) *neat.Genome {
+	s, sets := d.substrate(d.numLayers(c.Network.NumOutputs()))
+	g, _ := d.decodeOn(c, s, func(k int) int {
+		return sets[k] * d.numConnOutputs()
+	})
+	return g
+}
+
+// DecodeNetwork returns the phenotype network that the argument CPPN produces.
+func (d *DeepDecoder) DecodeNetwork(c *cppn.CPPN) *neat.NeuralNetwork {
+	return neat.NewNeuralNetwork(d.Decode(c))
+}
+
+// Evaluation returns a NEAT evaluation function that decodes each CPPN into a
+// phenotype network, and evaluates the phenotype network with the argument
+// evaluation function.
+func (d *DeepDecoder) Evaluation(evaluate neat.EvaluationFunc) neat.EvaluationFunc {
+	return func(nn *neat.NeuralNetwork) float64 {
+		return evaluate(d.DecodeNetwork(cppn.FromNetwork(nn)))
+	}
+}
@@ -0,0 +1,78 @@
+package hyperneat
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/jinyeom/neat"
+	"github.com/jinyeom/neat/cppn"
+)
+
+func TestDeepDecoder(t *testing.T) {
+	s := NewSubstrate().AddLine("in", "input", 2, -1).
+		AddLine("out", "output", 1, 1)
+	d := NewDeepDecoder(s, &Layer{Shape: "line", Dims: []int{3}})
+	d.MaxLayers = 2
+
+	// every connection is expressed by a large weight output.
+	g := neat.NewGenome(0, NumCoords+1, 1, 0.0)
+	g.ConnGenes = append(g.ConnGenes, neat.NewConnGene(0, NumCoords+1, 5.0))
+	if pheno := d.Decode(cppn.New(g)); len(pheno.NodeGenes) != 3 ||
+		len(pheno.ConnGenes) != 2 {
+		t.Fatalf("invalid shallow phenotype: %d nodes, %d connections",
+			len(pheno.NodeGenes), len(pheno.ConnGenes))
+	}
+
+	rng := rand.New(rand.NewSource(0))
+	mutate := d.Mutation(1.0)
+	for i := 0; i < 3; i++ {
+		mutated := mutate(g, rng)
+		if mutated != (i < d.MaxLayers) {
+			t.Errorf("invalid mutation %d: %t", i, mutated)
+		}
+	}
+	if n := d.NumLayers(g); n != 2 {
+		t.Fatalf("invalid number of layers: %d", n)
+	}
+
+	// connect the new outputs for every connection to be expressed.
+	for _, node := range g.NodeGenes[NumCoords+2:] {
+		g.ConnGenes = append(g.ConnGenes, neat.NewConnGene(0, node.ID, 5.0))
+	}
+	pheno := d.Decode(cppn.New(g))
+	if len(pheno.NodeGenes) != 9 || len(pheno.ConnGenes) != 2*3+3*3+3*1 {
+		t.Errorf("invalid deep phenotype: %d nodes, %d connections",
+			len(pheno.NodeGenes), len(pheno.ConnGenes))
+	}
+	if depth := pheno.Depth(); depth != 3 {
+		t.Errorf("invalid depth: %d", depth)
+	}
+}
+
+func TestDeepDecoderEvolution(t *testing.T) {
+	s := NewSubstrate().AddLine("in", "input", 2, -1).
+		AddLine("out", "output", 1, 1)
+	d := NewDeepDecoder(s, &Layer{Shape: "line", Dims: []int{2}})
+
+	config := d.NewConfig()
+	config.NumGenerations = 3
+	config.PopulationSize = 20
+	n := neat.New(config, d.Evaluation(func(nn *neat.NeuralNetwork) float64 {
+		outputs, err := nn.FeedForward([]float64{1.0, 0.0})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return outputs[0]
+	}), neat.WithSeed(1), neat.WithMutation("addLayer", d.Mutation(0.5)))
+	n.Run()
+
+	deep := 0
+	for _, genome := range n.Population {
+		if d.NumLayers(genome) > 0 {
+			deep++
+		}
+	}
+	if deep == 0 {
+		t.Error("no hidden layers are added")
+	}
+}
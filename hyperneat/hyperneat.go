@@ -0,0 +1,343 @@
+// hyperneat.go implementation of HyperNEAT.
+//
+// Copyright (C) 2017  Jin Yeom
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+// Package hyperneat provides HyperNEAT, in which NEAT evolves CPPNs that
+// determine the connection weights of a network with a fixed geometry, i.e., a
+// substrate, by being queried with the coordinates of each pair of its nodes.
+package hyperneat
+
+import (
+	"math"
+
+	"github.com/jinyeom/neat"
+	"github.com/jinyeom/neat/cppn"
+)
+
+const (
+	// NumCoords is the number of coordinates of a query of a CPPN, i.e., the
+	// (x, y, z) coordinates of the source node followed by those of the target.
+	NumCoords = 6
+
+	// NumGeometryCoords is the number of additional coordinates of a query of a
+	// CPPN of geometry, i.e., delta-x, delta-y, delta-z, and the distance
+	// between the two nodes.
+	NumGeometryCoords = 4
+)
+
+// Decoder decodes CPPNs into phenotype networks on a substrate. A connection is
+// expressed if the magnitude of the CPPN output exceeds the threshold, and its
+// weight is scaled from (threshold, 1] to (0, MaxWeight].
+//
+// With LEO (Link Expression Output), CPPNs have a second output that decides
+// whether each connection is expressed, by exceeding LEOThreshold, instead of
+// the magnitude of the weight; the weight is then scaled from [-1, 1] to
+// [-MaxWeight, MaxWeight].
+//
+// If the decoder is adaptive, CPPNs also output the parameters of a plasticity
+// rule of each connection, following the weight and the link expression output
+// (see Rule and DecodeAdaptive).
+//
+// If the decoder is of geometry, CPPNs are also given delta-x, delta-y,
+// delta-z, and the distance between the two nodes, after their coordinates;
+// the geometry is often easier to exploit than raw coordinates.
+//
+// If the decoder is multi-spatial, CPPNs have a separate set of the outputs
+// above for each pair of connected layers, in the order of the connections of
+// the substrate, such that the pattern of each pair can be evolved separately,
+// e.g., a convolution-like locality between layers of a vision task.
+type Decoder struct {
+	Substrate    *Substrate           // substrate of the phenotype networks
+	Threshold    float64              // threshold of expression of a connection
+	MaxWeight    float64              // maximum magnitude of a weight
+	Activation   *neat.ActivationFunc // activation of hidden and output nodes
+	LEO          bool                 // true if CPPNs have a link expression output
+	LEOThreshold float64              // threshold of the link expression output
+	Adaptive     bool                 // true if CPPNs output plasticity rules
+	Geometry     bool                 // true if CPPNs are given geometry coordinates
+	MultiSpatial bool                 // true if CPPNs have outputs per layer pair
+}
+
+// NewDecoder returns a new decoder on the argument substrate, with the
+// threshold of 0.2, the maximum weight of 3.0, and sigmoid activations.
+func NewDecoder(s *Substrate) *Decoder {
+	return &Decoder{
+		Substrate:    s,
+		Threshold:    0.2,
+		MaxWeight:    3.0,
+		Activation:   neat.ActivationSet["sigmoid"],
+		LEO:          false,
+		LEOThreshold: 0.5,
+		Adaptive:     false,
+		Geometry:     false,
+		MultiSpatial: false,
+	}
+}
+
+// NewLEODecoder returns a new decoder on the argument substrate like
+// NewDecoder, but with LEO.
+func NewLEODecoder(s *Substrate) *Decoder {
+	d := NewDecoder(s)
+	d.LEO = true
+	return d
+}
+
+// NewConfig returns a new default configuration of NEAT that evolves CPPNs for
+// this decoder.
+func (d *Decoder) NewConfig() *neat.Config {
+	config := cppn.NewConfig(d.numCoords(), d.numOutputs())
+	config.ExperimentName = "HyperNEAT"
+	return config
+}
+
+// NewAdaptiveDecoder returns a new decoder on the argument substrate like
+// NewDecoder, but adaptive.
+func NewAdaptiveDecoder(s *Substrate) *Decoder {
+	d := NewDecoder(s)
+	d.Adaptive = true
+	return d
+}
+
+// Seed seeds the initial population of the argument NEAT with the geometry
+// bias of locality, instead of starting from blank minimal genomes: CPPNs of
+// geometry are seeded by SeedGeometry, and CPPNs of LEO are seeded by SeedLEO.
+// It should be called right after creating the NEAT.
+func (d *Decoder) Seed(n *neat.NEAT) {
+	for _, genome := range n.Population {
+		if d.Geometry {
+			d.SeedGeometry(genome)
+		}
+		if d.LEO {
+			d.SeedLEO(genome)
+		}
+	}
+}
+
+// SeedGeometry seeds the argument CPPN genome of geometry with the bias of
+// locality in weights: a hidden node of Gaussian activation is added, which is
+// given the distance between the two nodes, and is connected to the weight
+// output along with the bias. It does nothing if the decoder is not geometry.
+func (d *Decoder) SeedGeometry(g *neat.Genome) {
+	if !d.Geometry {
+		return
+	}
+	bias, distance := 0, d.numCoords()
+	hidden := neat.NewNodeGene(len(g.NodeGenes), "hidden",
+		neat.ActivationSet["gaussian"])
+	g.NodeGenes = append(g.NodeGenes, hidden)
+	g.ConnGenes = append(g.ConnGenes,
+		neat.NewConnGene(distance, hidden.ID, 1.0))
+	for k := 0; k < d.numOutputSets(); k++ {
+		weight := d.numCoords() + 1 + d.outputOffset(k)
+		g.ConnGenes = append(g.ConnGenes,
+			neat.NewConnGene(hidden.ID, weight, 5.0),
+			neat.NewConnGene(bias, weight, -2.5))
+	}
+}
+
+// SeedLEO seeds the argument CPPN genome of LEO with the geometry bias of
+// locality, such that connections between nodes close in x are expressed
+// initially: a hidden node of Gaussian activation is added, which is given
+// x1 - x2 (or delta-x of geometry) and is connected to the link expression
+// output along with the bias. It does nothing if the decoder doesn't use LEO.
+func (d *Decoder) SeedLEO(g *neat.Genome) {
+	if !d.LEO {
+		return
+	}
+	bias := 0
+	hidden := neat.NewNodeGene(len(g.NodeGenes), "hidden",
+		neat.ActivationSet["gaussian"])
+	g.NodeGenes = append(g.NodeGenes, hidden)
+	if d.Geometry {
+		g.ConnGenes = append(g.ConnGenes,
+			neat.NewConnGene(NumCoords+1, hidden.ID, 1.0))
+	} else {
+		g.ConnGenes = append(g.ConnGenes,
+			neat.NewConnGene(1, hidden.ID, 1.0),
+			neat.NewConnGene(4, hidden.ID, -1.0))
+	}
+	for k := 0; k < d.numOutputSets(); k++ {
+		leo := d.numCoords() + 2 + d.outputOffset(k)
+		g.ConnGenes = append(g.ConnGenes,
+			neat.NewConnGene(hidden.ID, leo, 10.0),
+			neat.NewConnGene(bias, leo, -3.0))
+	}
+}
+
+// Decode returns the genome of the phenotype network that the argument CPPN
+// produces on the substrate. Node IDs are assigned in the order of input,
+// output, and hidden nodes, and in the order of the layers, thus the inputs of
+// the network are given in the order of the input layers.
+func (d *Decoder) Decode(c *cppn.CPPN) *neat.Genome {
+	g, _ := d.decode(c)
+	return g
+}
+
+// decode returns the genome of the phenotype network that the argument CPPN
+// produces on the substrate, and the plasticity rules of its connections if
+// this decoder is adaptive.
+func (d *Decoder) decode(c *cppn.CPPN) (*neat.Genome, []Rule) {
+	return d.decodeOn(c, d.Substrate, d.outputOffset)
+}
+
+// decodeOn is decode on the argument substrate, given the function that returns
+// the index of the first CPPN output for the k-th pair of connected layers.
+func (d *Decoder) decodeOn(c *cppn.CPPN, s *Substrate,
+	outputOffset func(k int) int) (*neat.Genome, []Rule) {
+	g := neat.NewGenome(0, 0, 0, 0.0)
+	nodes := s.nodes()
+	for _, l := range s.layersByType() {
+		activation := d.Activation
+		if l.Type == "input" {
+			activation = neat.ActivationSet["identity"]
+		}
+		for _, n := range nodes[l.Name] {
+			g.NodeGenes = append(g.NodeGenes,
+				neat.NewNodeGene(n.id, l.Type, activation))
+		}
+	}
+
+	// the CPPN is queried in a batch for each pair of connected layers.
+	var rules []Rule
+	for k, conn := range s.Connections {
+		srcs, dsts := nodes[conn[0]], nodes[conn[1]]
+		queries := make([][]float64, 0, len(srcs)*len(dsts))
+		for _, src := range srcs {
+			for _, dst := range dsts {
+				queries = append(queries, d.query(src.coords, dst.coords))
+			}
+		}
+		outputs, err := c.QueryBatch(queries)
+		if err != nil {
+			continue
+		}
+		offset, numOutputs := outputOffset(k), d.numConnOutputs()
+		for i, out := range outputs {
+			if len(out) < offset+numOutputs {
+				break
+			}
+			out = out[offset : offset+numOutputs]
+			weight, ok := d.express(out)
+			if !ok {
+				continue
+			}
+			src, dst := srcs[i/len(dsts)], dsts[i%len(dsts)]
+			g.ConnGenes = append(g.ConnGenes,
+				neat.NewConnGene(src.id, dst.id, weight))
+			if d.Adaptive {
+				rules = append(rules, d.rule(out))
+			}
+		}
+	}
+	return g, rules
+}
+
+// DecodeNetwork returns the phenotype network that the argument CPPN produces
+// on the substrate.
+func (d *Decoder) DecodeNetwork(c *cppn.CPPN) *neat.NeuralNetwork {
+	return neat.NewNeuralNetwork(d.Decode(c))
+}
+
+// numCoords returns the number of coordinates of a query of CPPNs of this
+// decoder, excluding the bias.
+func (d *Decoder) numCoords() int {
+	if d.Geometry {
+		return NumCoords + NumGeometryCoords
+	}
+	return NumCoords
+}
+
+// numOutputs returns the number of outputs of CPPNs of this decoder.
+func (d *Decoder) numOutputs() int {
+	if d.MultiSpatial {
+		return len(d.Substrate.Connections) * d.numConnOutputs()
+	}
+	return d.numConnOutputs()
+}
+
+// numConnOutputs returns the number of outputs of CPPNs of this decoder for a
+// pair of connected layers.
+func (d *Decoder) numConnOutputs() int {
+	numOutputs := 1
+	if d.LEO {
+		numOutputs++
+	}
+	if d.Adaptive {
+		numOutputs += numRuleOutputs
+	}
+	return numOutputs
+}
+
+// outputOffset returns the index of the first CPPN output for the k-th pair of
+// connected layers of the substrate.
+func (d *Decoder) outputOffset(k int) int {
+	if d.MultiSpatial {
+		return k * d.numConnOutputs()
+	}
+	return 0
+}
+
+// numOutputSets returns the number of sets of CPPN outputs, i.e., the number
+// of pairs of connected layers if the decoder is multi-spatial, or 1.
+func (d *Decoder) numOutputSets() int {
+	if d.MultiSpatial {
+		return len(d.Substrate.Connections)
+	}
+	return 1
+}
+
+// query returns the coordinates of a query of the CPPN for a connection between
+// two nodes of the argument coordinates.
+func (d *Decoder) query(src, dst [3]float64) []float64 {
+	coords := []float64{src[0], src[1], src[2], dst[0], dst[1], dst[2]}
+	if d.Geometry {
+		dx, dy, dz := dst[0]-src[0], dst[1]-src[1], dst[2]-src[2]
+		coords = append(coords, dx, dy, dz, math.Sqrt(dx*dx+dy*dy+dz*dz))
+	}
+	return coords
+}
+
+// express returns the weight of a connection given the argument outputs of the
+// CPPN, and true if the connection is expressed.
+func (d *Decoder) express(outputs []float64) (float64, bool) {
+	if len(outputs) < d.numConnOutputs() {
+		return 0.0, false
+	}
+
+	w := outputs[0]
+	if d.LEO {
+		if !(outputs[1] > d.LEOThreshold) {
+			return 0.0, false
+		}
+		w = math.Max(-1.0, math.Min(1.0, w))
+		return w * d.MaxWeight, !math.IsNaN(w) && w != 0.0
+	}
+
+	if math.IsNaN(w) || math.Abs(w) <= d.Threshold {
+		return 0.0, false
+	}
+	scaled := (math.Abs(w) - d.Threshold) / (1.0 - d.Threshold) * d.MaxWeight
+	return math.Copysign(math.Min(scaled, d.MaxWeight), w), true
+}
+
+// Evaluation returns a NEAT evaluation function that decodes each CPPN into a
+// phenotype network on the substrate, and evaluates the phenotype network with
+// the argument evaluation function.
+func (d *Decoder) Evaluation(evaluate neat.EvaluationFunc) neat.EvaluationFunc {
+	return func(nn *neat.NeuralNetwork) float64 {
+		return evaluate(d.DecodeNetwork(cppn.FromNetwork(nn)))
+	}
+}
@@ -0,0 +1,177 @@
+package hyperneat
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/jinyeom/neat"
+	"github.com/jinyeom/neat/cppn"
+)
+
+func TestReadSubstrate(t *testing.T) {
+	s, err := ReadSubstrate(strings.NewReader(`{
+		"layers": [
+			{"name": "in", "type": "input", "shape": "grid", "dims": [3, 3], "z": -1},
+			{"name": "hid", "type": "hidden", "shape": "ring", "dims": [4], "radius": 0.5, "z": 0},
+			{"name": "out", "type": "output", "shape": "line", "dims": [2], "z": 1}
+		],
+		"connections": [["in", "hid"], ["hid", "out"]]
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.NumInputs() != 9 || s.NumOutputs() != 2 {
+		t.Errorf("invalid number of inputs and outputs: %d, %d",
+			s.NumInputs(), s.NumOutputs())
+	}
+
+	buf := &bytes.Buffer{}
+	if err = s.Encode(buf); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = ReadSubstrate(buf); err != nil {
+		t.Errorf("encoded substrate cannot be read: %v", err)
+	}
+}
+
+func TestSubstrateValidate(t *testing.T) {
+	s := NewSubstrate().AddGrid("in", "input", 0, 3, -1).
+		AddLine("out", "output", 2, 1).AddLine("out", "hidden", 2, 0).
+		Connect("in", "unknown").Connect("out", "in")
+	errs, ok := s.Validate().(SubstrateError)
+	if !ok || len(errs) != 5 {
+		t.Errorf("invalid errors: %v", errs)
+	}
+
+	s = NewSubstrate().AddLine("in", "input", 2, -1).
+		AddLine("a", "hidden", 2, 0).AddLine("b", "hidden", 2, 0.5).
+		AddLine("out", "output", 1, 1).
+		Connect("in", "a").Connect("a", "b").Connect("b", "a").Connect("b", "out")
+	if err := s.Validate(); err == nil {
+		t.Error("cycle is not reported")
+	}
+}
+
+func TestDecoder(t *testing.T) {
+	s := NewSubstrate().AddGrid("in", "input", 2, 2, -1).
+		AddLine("out", "output", 1, 1).Connect("in", "out")
+	d := NewDecoder(s)
+
+	config := d.NewConfig()
+	config.NumGenerations = 2
+	config.PopulationSize = 20
+	best := neat.New(config, d.Evaluation(func(nn *neat.NeuralNetwork) float64 {
+		outputs, err := nn.FeedForward([]float64{1.0, 0.0, 0.0, 1.0})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return outputs[0]
+	}), neat.WithSeed(1)).Run()
+
+	g := d.Decode(cppn.New(best))
+	if len(g.NodeGenes) != 5 || len(g.ConnGenes) > 4 {
+		t.Errorf("invalid phenotype: %d nodes, %d connections",
+			len(g.NodeGenes), len(g.ConnGenes))
+	}
+	for _, conn := range g.ConnGenes {
+		if conn.Weight == 0.0 || conn.Weight > d.MaxWeight || conn.Weight < -d.MaxWeight {
+			t.Errorf("invalid weight: %f", conn.Weight)
+		}
+	}
+}
+
+func TestSeedLEO(t *testing.T) {
+	s := NewSubstrate().AddLine("in", "input", 5, -1).
+		AddLine("out", "output", 5, 1).Connect("in", "out")
+	d := NewLEODecoder(s)
+
+	config := d.NewConfig()
+	config.PopulationSize = 10
+	config.FullyConnected = false
+	n := neat.New(config, d.Evaluation(func(nn *neat.NeuralNetwork) float64 {
+		return 0.0
+	}), neat.WithSeed(1))
+	d.Seed(n)
+
+	// the seeded CPPN has no connection to the weight output yet, thus connect
+	// the bias to it to express weights.
+	g := n.Population[0]
+	g.ConnGenes = append(g.ConnGenes, neat.NewConnGene(0, NumCoords+1, 1.0))
+	pheno := d.Decode(cppn.New(g))
+
+	// only connections between nodes close in x are expressed.
+	if len(pheno.ConnGenes) == 0 || len(pheno.ConnGenes) >= 25 {
+		t.Fatalf("invalid number of connections: %d", len(pheno.ConnGenes))
+	}
+	for _, conn := range pheno.ConnGenes {
+		if dx := conn.From - (conn.To - 5); dx > 1 || dx < -1 {
+			t.Errorf("non-local connection: %d -> %d", conn.From, conn.To)
+		}
+	}
+}
+
+func TestSeedGeometry(t *testing.T) {
+	s := NewSubstrate().AddLine("in", "input", 5, 0).
+		AddLine("out", "output", 5, 0).Connect("in", "out")
+	d := NewDecoder(s)
+	d.Geometry = true
+
+	config := d.NewConfig()
+	if config.NumInputs != NumCoords+NumGeometryCoords+1 {
+		t.Fatalf("invalid number of inputs: %d", config.NumInputs)
+	}
+	config.PopulationSize = 10
+	config.FullyConnected = false
+	n := neat.New(config, d.Evaluation(func(nn *neat.NeuralNetwork) float64 {
+		return 0.0
+	}), neat.WithSeed(1))
+	d.Seed(n)
+
+	// connections are expressed only between nearby nodes, and their weights
+	// decrease with the distance.
+	pheno := d.Decode(cppn.New(n.Population[0]))
+	if len(pheno.ConnGenes) == 0 || len(pheno.ConnGenes) >= 25 {
+		t.Fatalf("invalid number of connections: %d", len(pheno.ConnGenes))
+	}
+	weights := make(map[int]float64)
+	for _, conn := range pheno.ConnGenes {
+		dx := conn.From - (conn.To - 5)
+		if dx < 0 {
+			dx = -dx
+		}
+		if w, ok := weights[dx]; ok && w != conn.Weight {
+			t.Errorf("weights differ at the same distance: %f, %f", w, conn.Weight)
+		}
+		weights[dx] = conn.Weight
+	}
+	if !(weights[0] > weights[1]) {
+		t.Errorf("weights don't decrease with the distance: %v", weights)
+	}
+}
+
+func TestMultiSpatial(t *testing.T) {
+	s := NewSubstrate().AddLine("in", "input", 2, -1).
+		AddLine("hid", "hidden", 2, 0).AddLine("out", "output", 1, 1).
+		Connect("in", "hid").Connect("hid", "out")
+	d := NewDecoder(s)
+	d.MultiSpatial = true
+	if config := d.NewConfig(); config.NumOutputs != 2 {
+		t.Fatalf("invalid number of outputs: %d", config.NumOutputs)
+	}
+
+	// the first pair of layers is connected, but the second isn't.
+	g := neat.NewGenome(0, NumCoords+1, 2, 0.0)
+	g.ConnGenes = append(g.ConnGenes,
+		neat.NewConnGene(0, NumCoords+1, 5.0),
+		neat.NewConnGene(0, NumCoords+2, -5.0))
+	pheno := d.Decode(cppn.New(g))
+	if len(pheno.ConnGenes) != 4 {
+		t.Fatalf("invalid number of connections: %d", len(pheno.ConnGenes))
+	}
+	for _, conn := range pheno.ConnGenes {
+		if pheno.NodeGenes[conn.From].Type != "input" {
+			t.Errorf("invalid connection: %d -> %d", conn.From, conn.To)
+		}
+	}
+}
@@ -0,0 +1,362 @@
+// substrate.go implementation of substrates of HyperNEAT.
+//
+// Copyright (C) 2017  Jin Yeom
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package hyperneat
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strings"
+)
+
+// Layer is a sheet of nodes of a substrate, whose geometry is declared by its
+// shape and dimensions. Each node is located at (x, y, z), where z is the depth
+// of the layer, and x and y depend on the shape:
+//
+//	"grid":   Dims[0] x Dims[1] points that span [-1, 1] in x and y
+//	"line":   Dims[0] points that span [-1, 1] in x, at y = 0
+//	"ring":   Dims[0] points evenly spaced on a circle of the radius
+//	"points": explicit (x, y) points
+type Layer struct {
+	Name   string      `json:"name"`             // unique name of the layer
+	Type   string      `json:"type"`             // "input", "hidden", or "output"
+	Shape  string      `json:"shape"`            // shape of the layer
+	Dims   []int       `json:"dims,omitempty"`   // dimensions of the shape
+	Z      float64     `json:"z"`                // depth of the layer
+	Radius float64     `json:"radius,omitempty"` // radius of a ring
+	Points [][]float64 `json:"points,omitempty"` // explicit (x, y) points
+}
+
+// Coords returns the (x, y, z) coordinates of the nodes of this layer, in
+// row-major order for a grid, or nil if its dimensions are invalid.
+func (l *Layer) Coords() [][3]float64 {
+	var coords [][3]float64
+	switch l.Shape {
+	case "grid":
+		if len(l.Dims) != 2 {
+			return nil
+		}
+		for row := 0; row < l.Dims[1]; row++ {
+			for col := 0; col < l.Dims[0]; col++ {
+				coords = append(coords, [3]float64{
+					span(col, l.Dims[0]), span(row, l.Dims[1]), l.Z})
+			}
+		}
+	case "line":
+		if len(l.Dims) != 1 {
+			return nil
+		}
+		for i := 0; i < l.Dims[0]; i++ {
+			coords = append(coords, [3]float64{span(i, l.Dims[0]), 0.0, l.Z})
+		}
+	case "ring":
+		if len(l.Dims) != 1 {
+			return nil
+		}
+		for i := 0; i < l.Dims[0]; i++ {
+			angle := 2.0 * math.Pi * float64(i) / float64(l.Dims[0])
+			coords = append(coords, [3]float64{
+				l.Radius * math.Cos(angle), l.Radius * math.Sin(angle), l.Z})
+		}
+	case "points":
+		for _, p := range l.Points {
+			if len(p) != 2 {
+				return nil
+			}
+			coords = append(coords, [3]float64{p[0], p[1], l.Z})
+		}
+	}
+	return coords
+}
+
+// Substrate is the geometry of the phenotype networks of HyperNEAT, which
+// consists of layers of nodes and the pairs of layers to connect. It can be
+// declared in JSON, e.g.,
+//
+//	{
+//		"layers": [
+//			{"name": "in", "type": "input", "shape": "grid", "dims": [3, 3], "z": -1},
+//			{"name": "out", "type": "output", "shape": "line", "dims": [2], "z": 1}
+//		],
+//		"connections": [["in", "out"]]
+//	}
+//
+// or with the builder methods, e.g.,
+//
+//	NewSubstrate().AddGrid("in", "input", 3, 3, -1).
+//		AddLine("out", "output", 2, 1).Connect("in", "out")
+type Substrate struct {
+	Layers      []*Layer    `json:"layers"`      // layers of nodes
+	Connections [][2]string `json:"connections"` // names of connected layers
+}
+
+// NewSubstrate returns a new empty substrate to build.
+func NewSubstrate() *Substrate {
+	return &Substrate{
+		Layers:      []*Layer{},
+		Connections: [][2]string{},
+	}
+}
+
+// ReadSubstrate reads a substrate from the argument reader of JSON data, and
+// validates it.
+func ReadSubstrate(r io.Reader) (*Substrate, error) {
+	s := NewSubstrate()
+	decoder := json.NewDecoder(r)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(s); err != nil {
+		return nil, err
+	}
+	if err := s.Validate(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// NewSubstrateJSON reads a substrate from a JSON file of the argument name.
+func NewSubstrateJSON(filename string) (*Substrate, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ReadSubstrate(f)
+}
+
+// Encode writes this substrate to the argument writer in JSON.
+func (s *Substrate) Encode(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "\t")
+	return encoder.Encode(s)
+}
+
+// AddGrid adds a layer of a grid of the argument width and height.
+func (s *Substrate) AddGrid(name, ltype string, width, height int,
+	z float64) *Substrate {
+	return s.AddLayer(&Layer{Name: name, Type: ltype, Shape: "grid",
+		Dims: []int{width, height}, Z: z})
+}
+
+// AddLine adds a layer of the argument number of nodes on a line.
+func (s *Substrate) AddLine(name, ltype string, size int, z float64) *Substrate {
+	return s.AddLayer(&Layer{Name: name, Type: ltype, Shape: "line",
+		Dims: []int{size}, Z: z})
+}
+
+// AddRing adds a layer of the argument number of nodes on a circle.
+func (s *Substrate) AddRing(name, ltype string, size int, radius,
+	z float64) *Substrate {
+	return s.AddLayer(&Layer{Name: name, Type: ltype, Shape: "ring",
+		Dims: []int{size}, Z: z, Radius: radius})
+}
+
+// AddPoints adds a layer of nodes at the argument (x, y) points.
+func (s *Substrate) AddPoints(name, ltype string, points [][]float64,
+	z float64) *Substrate {
+	return s.AddLayer(&Layer{Name: name, Type: ltype, Shape: "points",
+		Points: points, Z: z})
+}
+
+// AddLayer adds the argument layer.
+func (s *Substrate) AddLayer(l *Layer) *Substrate {
+	s.Layers = append(s.Layers, l)
+	return s
+}
+
+// Connect connects every node of the layer of the first argument name to
+// every node of the layer of the second argument name.
+func (s *Substrate) Connect(from, to string) *Substrate {
+	s.Connections = append(s.Connections, [2]string{from, to})
+	return s
+}
+
+// Layer returns the layer of the argument name, or nil if there is none.
+func (s *Substrate) Layer(name string) *Layer {
+	for _, l := range s.Layers {
+		if l.Name == name {
+			return l
+		}
+	}
+	return nil
+}
+
+// NumInputs returns the number of nodes in the input layers.
+func (s *Substrate) NumInputs() int {
+	return s.numNodes("input")
+}
+
+// NumOutputs returns the number of nodes in the output layers.
+func (s *Substrate) NumOutputs() int {
+	return s.numNodes("output")
+}
+
+// numNodes returns the number of nodes in the layers of the argument type.
+func (s *Substrate) numNodes(ltype string) int {
+	num := 0
+	for _, l := range s.Layers {
+		if l.Type == ltype {
+			num += len(l.Coords())
+		}
+	}
+	return num
+}
+
+// node is a node of the substrate with its ID in the decoded genome.
+type node struct {
+	id     int        // node ID
+	coords [3]float64 // coordinates of the node
+}
+
+// layersByType returns the layers in the order of input, output, and hidden
+// layers, and in the order of declaration within each type.
+func (s *Substrate) layersByType() []*Layer {
+	layers := make([]*Layer, 0, len(s.Layers))
+	for _, ltype := range []string{"input", "output", "hidden"} {
+		for _, l := range s.Layers {
+			if l.Type == ltype {
+				layers = append(layers, l)
+			}
+		}
+	}
+	return layers
+}
+
+// nodes returns the nodes of each layer by its name, whose IDs are assigned in
+// the order of layersByType.
+func (s *Substrate) nodes() map[string][]node {
+	nodes := make(map[string][]node)
+	id := 0
+	for _, l := range s.layersByType() {
+		for _, coords := range l.Coords() {
+			nodes[l.Name] = append(nodes[l.Name], node{id, coords})
+			id++
+		}
+	}
+	return nodes
+}
+
+// SubstrateError is a list of invalid declarations of a substrate.
+type SubstrateError []string
+
+// Error returns the invalid declarations, separated by semicolons.
+func (e SubstrateError) Error() string {
+	return "hyperneat: invalid substrate: " + strings.Join(e, "; ")
+}
+
+// Validate returns a SubstrateError if the substrate is invalid, e.g., if a
+// layer has an unknown shape, or if connections are not acyclic.
+func (s *Substrate) Validate() error {
+	var errs SubstrateError
+	violate := func(format string, args ...interface{}) {
+		errs = append(errs, fmt.Sprintf(format, args...))
+	}
+
+	names := make(map[string]bool)
+	for i, l := range s.Layers {
+		if l.Name == "" || names[l.Name] {
+			violate("layer %d must have a unique name (%q)", i, l.Name)
+		}
+		names[l.Name] = true
+
+		if l.Type != "input" && l.Type != "hidden" && l.Type != "output" {
+			violate("layer %s has an unknown type (%q)", l.Name, l.Type)
+		}
+		switch l.Shape {
+		case "grid":
+			if len(l.Dims) != 2 || l.Dims[0] <= 0 || l.Dims[1] <= 0 {
+				violate("grid %s must have two positive dims (%v)", l.Name, l.Dims)
+			}
+		case "line", "ring":
+			if len(l.Dims) != 1 || l.Dims[0] <= 0 {
+				violate("%s %s must have a positive dim (%v)", l.Shape, l.Name, l.Dims)
+			}
+		case "points":
+			for _, p := range l.Points {
+				if len(p) != 2 {
+					violate("points of %s must be (x, y) (%v)", l.Name, p)
+					break
+				}
+			}
+		default:
+			violate("layer %s has an unknown shape (%q)", l.Name, l.Shape)
+		}
+	}
+	if s.numNodes("input") == 0 || s.numNodes("output") == 0 {
+		violate("substrate must have input and output nodes")
+	}
+
+	for _, conn := range s.Connections {
+		if !names[conn[0]] || !names[conn[1]] {
+			violate("connection %v refers to an unknown layer", conn)
+		} else if s.Layer(conn[1]).Type == "input" {
+			violate("connection %v must not lead to an input layer", conn)
+		}
+	}
+	if s.cyclic() {
+		violate("connections between layers must be acyclic")
+	}
+
+	if len(errs) != 0 {
+		return errs
+	}
+	return nil
+}
+
+// cyclic returns true if there is a cycle of connected layers.
+func (s *Substrate) cyclic() bool {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make(map[string]int)
+	var visit func(name string) bool
+	visit = func(name string) bool {
+		switch state[name] {
+		case visiting:
+			return true
+		case visited:
+			return false
+		}
+		state[name] = visiting
+		for _, conn := range s.Connections {
+			if conn[0] == name && visit(conn[1]) {
+				return true
+			}
+		}
+		state[name] = visited
+		return false
+	}
+	for _, l := range s.Layers {
+		if visit(l.Name) {
+			return true
+		}
+	}
+	return false
+}
+
+// span returns the coordinate in [-1, 1] of the i-th of n points that are
+// evenly spaced (0 if there is only one point).
+func span(i, n int) float64 {
+	if n <= 1 {
+		return 0.0
+	}
+	return -1.0 + 2.0*float64(i)/float64(n-1)
+}
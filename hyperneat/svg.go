@@ -0,0 +1,143 @@
+// svg.go implementation of visualization of substrates in SVG.
+//
+// Copyright (C) 2017  Jin Yeom
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package hyperneat
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"os"
+
+	"github.com/jinyeom/neat"
+	"github.com/jinyeom/neat/cppn"
+)
+
+const (
+	svgSize    = 600  // width and height of an image in pixels
+	svgMargin  = 40   // margin around the substrate in pixels
+	svgRadius  = 4    // radius of a node in pixels
+	svgOblique = 0.35 // factor of y in the oblique projection
+)
+
+var (
+	// svgNodeColors are colors of nodes by their types.
+	svgNodeColors = map[string]string{
+		"input":  "#3366cc",
+		"hidden": "#999999",
+		"output": "#cc3333",
+	}
+)
+
+// WriteSVG writes an image of the layout of this substrate to the argument
+// writer in SVG. If the argument genome, decoded on this substrate, is not
+// nil, its enabled connections are drawn as well, colored by their weights:
+// blue for positive and red for negative weights, whose opacity is
+// proportional to the magnitude relative to the largest one.
+//
+// Nodes are drawn in an oblique projection, in which z is vertical with the
+// inputs at the bottom, x is horizontal, and y is shifted diagonally.
+func (s *Substrate) WriteSVG(w io.Writer, g *neat.Genome) error {
+	nodes := s.nodes()
+	points := make(map[int][2]float64)
+	types := make(map[int]string)
+	minX, minY := math.Inf(1), math.Inf(1)
+	maxX, maxY := math.Inf(-1), math.Inf(-1)
+	for _, l := range s.layersByType() {
+		for _, n := range nodes[l.Name] {
+			x := n.coords[0] + svgOblique*n.coords[1]
+			y := -n.coords[2] - svgOblique*n.coords[1]
+			points[n.id] = [2]float64{x, y}
+			types[n.id] = l.Type
+			minX, maxX = math.Min(minX, x), math.Max(maxX, x)
+			minY, maxY = math.Min(minY, y), math.Max(maxY, y)
+		}
+	}
+
+	// scale the projected points to the image, keeping the aspect ratio.
+	span := math.Max(maxX-minX, maxY-minY)
+	if span == 0.0 || math.IsInf(span, 0) {
+		span = 1.0
+	}
+	scale := float64(svgSize-2*svgMargin) / span
+	pixel := func(id int) (float64, float64) {
+		p := points[id]
+		return svgMargin + (p[0]-minX)*scale, svgMargin + (p[1]-minY)*scale
+	}
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "<svg xmlns=\"http://www.w3.org/2000/svg\" "+
+		"width=\"%d\" height=\"%d\" viewBox=\"0 0 %d %d\">\n",
+		svgSize, svgSize, svgSize, svgSize)
+	fmt.Fprintf(bw, "<rect width=\"100%%\" height=\"100%%\" fill=\"white\"/>\n")
+
+	if g != nil {
+		maxWeight := 0.0
+		for _, conn := range g.ConnGenes {
+			if !conn.Disabled {
+				maxWeight = math.Max(maxWeight, math.Abs(conn.Weight))
+			}
+		}
+		for _, conn := range g.ConnGenes {
+			_, okFrom := points[conn.From]
+			_, okTo := points[conn.To]
+			if conn.Disabled || !okFrom || !okTo || maxWeight == 0.0 {
+				continue
+			}
+			color := "#3366cc"
+			if conn.Weight < 0.0 {
+				color = "#cc3333"
+			}
+			x1, y1 := pixel(conn.From)
+			x2, y2 := pixel(conn.To)
+			fmt.Fprintf(bw, "<line x1=\"%.1f\" y1=\"%.1f\" x2=\"%.1f\" "+
+				"y2=\"%.1f\" stroke=\"%s\" stroke-opacity=\"%.3f\"/>\n",
+				x1, y1, x2, y2, color, math.Abs(conn.Weight)/maxWeight)
+		}
+	}
+
+	for id := 0; id < len(points); id++ {
+		x, y := pixel(id)
+		fmt.Fprintf(bw, "<circle cx=\"%.1f\" cy=\"%.1f\" r=\"%d\" fill=\"%s\"/>\n",
+			x, y, svgRadius, svgNodeColors[types[id]])
+	}
+	fmt.Fprintf(bw, "</svg>\n")
+	return bw.Flush()
+}
+
+// WriteSVG writes an image of the substrate and the connections of the
+// phenotype network that the argument CPPN produces to the argument writer in
+// SVG (see (*Substrate).WriteSVG).
+func (d *Decoder) WriteSVG(w io.Writer, c *cppn.CPPN) error {
+	return d.Substrate.WriteSVG(w, d.Decode(c))
+}
+
+// SaveSVG writes an image of the substrate and the connections of the
+// phenotype network that the argument CPPN produces to an SVG file of the
+// argument path.
+func (d *Decoder) SaveSVG(path string, c *cppn.CPPN) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err = d.WriteSVG(f, c); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
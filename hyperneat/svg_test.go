@@ -0,0 +1,43 @@
+package hyperneat
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jinyeom/neat"
+	"github.com/jinyeom/neat/cppn"
+)
+
+func TestWriteSVG(t *testing.T) {
+	s := NewSubstrate().AddGrid("in", "input", 2, 2, -1).
+		AddRing("hid", "hidden", 3, 0.5, 0).AddLine("out", "output", 1, 1).
+		Connect("in", "hid").Connect("hid", "out")
+	buf := &bytes.Buffer{}
+	if err := s.WriteSVG(buf, nil); err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(buf.String(), "<circle"); n != 8 {
+		t.Errorf("invalid number of nodes: %d", n)
+	}
+	if strings.Contains(buf.String(), "<line") {
+		t.Error("connections are drawn without a genome")
+	}
+
+	g := neat.NewGenome(0, NumCoords+1, 1, 0.0)
+	g.ConnGenes = append(g.ConnGenes, neat.NewConnGene(0, NumCoords+1, 5.0))
+	d := NewDecoder(s)
+	buf.Reset()
+	if err := d.WriteSVG(buf, cppn.New(g)); err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(buf.String(), "<line"); n != 4*3+3*1 {
+		t.Errorf("invalid number of connections: %d", n)
+	}
+
+	if err := d.SaveSVG(filepath.Join(t.TempDir(), "substrate.svg"),
+		cppn.New(g)); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,115 @@
+// id_generator.go implementation of the generation of IDs of genomes and species.
+//
+// Copyright (C) 2017  Jin Yeom
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package neat
+
+import (
+	"fmt"
+	"sync"
+)
+
+// IDGenerator is an interface of generators of IDs of genomes and species,
+// which must be unique within a run. NewGenomeID and NewSpeciesID return a new
+// ID each; Reserve makes sure that the argument IDs, and every smaller one, are
+// never returned afterwards, e.g., since they belong to imported genomes.
+type IDGenerator interface {
+	NewGenomeID() int
+	NewSpeciesID() int
+	Reserve(genomeID, speciesID int)
+}
+
+// MonotonicIDGenerator is the default IDGenerator of NEAT, which generates
+// increasing IDs. It can also generate IDs of a partition, i.e., the IDs that
+// are congruent to the partition modulo the number of partitions, such that
+// multiple instances of NEAT, e.g., islands or processes of a distributed run,
+// create genomes concurrently without collisions. It is safe for concurrent
+// use.
+type MonotonicIDGenerator struct {
+	mu            sync.Mutex
+	numPartitions int // number of partitions of IDs
+	nextGenomeID  int // genome ID that is assigned to a newly created genome
+	nextSpeciesID int // species ID that is assigned to a newly created species
+}
+
+// NewMonotonicIDGenerator returns a new instance of MonotonicIDGenerator, whose
+// IDs start from 0.
+func NewMonotonicIDGenerator() *MonotonicIDGenerator {
+	return &MonotonicIDGenerator{numPartitions: 1}
+}
+
+// NewPartitionedIDGenerator returns a new instance of MonotonicIDGenerator of
+// the argument partition of IDs, given the number of partitions; each instance
+// of NEAT that shares the space of IDs should be given a distinct partition.
+func NewPartitionedIDGenerator(partition,
+	numPartitions int) (*MonotonicIDGenerator, error) {
+	if numPartitions < 1 || partition < 0 || partition >= numPartitions {
+		return nil, fmt.Errorf("neat: invalid partition %d of %d partitions",
+			partition, numPartitions)
+	}
+	return &MonotonicIDGenerator{
+		numPartitions: numPartitions,
+		nextGenomeID:  partition,
+		nextSpeciesID: partition,
+	}, nil
+}
+
+// Copy returns a copy of this generator, which continues from the same IDs.
+func (g *MonotonicIDGenerator) Copy() *MonotonicIDGenerator {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return &MonotonicIDGenerator{
+		numPartitions: g.numPartitions,
+		nextGenomeID:  g.nextGenomeID,
+		nextSpeciesID: g.nextSpeciesID,
+	}
+}
+
+// NewGenomeID returns a new genome ID.
+func (g *MonotonicIDGenerator) NewGenomeID() int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	id := g.nextGenomeID
+	g.nextGenomeID += g.numPartitions
+	return id
+}
+
+// NewSpeciesID returns a new species ID.
+func (g *MonotonicIDGenerator) NewSpeciesID() int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	id := g.nextSpeciesID
+	g.nextSpeciesID += g.numPartitions
+	return id
+}
+
+// Reserve skips the IDs up to the argument genome and species IDs, while
+// keeping the partition.
+func (g *MonotonicIDGenerator) Reserve(genomeID, speciesID int) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.nextGenomeID = g.skip(g.nextGenomeID, genomeID)
+	g.nextSpeciesID = g.skip(g.nextSpeciesID, speciesID)
+}
+
+// skip returns the smallest ID of the partition of the argument next ID that
+// is greater than the argument reserved ID, or the next ID if it already is.
+func (g *MonotonicIDGenerator) skip(next, reserved int) int {
+	if next > reserved {
+		return next
+	}
+	return next + ((reserved-next)/g.numPartitions+1)*g.numPartitions
+}
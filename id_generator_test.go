@@ -0,0 +1,62 @@
+package neat
+
+import (
+	"testing"
+)
+
+func TestMonotonicIDGenerator(t *testing.T) {
+	g := NewMonotonicIDGenerator()
+	for i := 0; i < 3; i++ {
+		if id := g.NewGenomeID(); id != i {
+			t.Errorf("invalid genome ID: %d != %d", id, i)
+		}
+	}
+	g.Reserve(9, -1)
+	if id := g.NewGenomeID(); id != 10 {
+		t.Errorf("reserved genome ID is not skipped: %d", id)
+	}
+	if id := g.NewSpeciesID(); id != 0 {
+		t.Errorf("invalid species ID: %d", id)
+	}
+	g.Reserve(5, 0)
+	if id := g.NewGenomeID(); id != 11 {
+		t.Errorf("genome ID went back: %d", id)
+	}
+
+	if _, err := NewPartitionedIDGenerator(2, 2); err == nil {
+		t.Error("invalid partition is not rejected")
+	}
+}
+
+func TestNEATPartitionedIDs(t *testing.T) {
+	config := NewDefaultConfig(3, 1)
+	config.NumGenerations = 3
+	config.PopulationSize = 20
+
+	// islands of a distributed run never assign the same IDs.
+	seen := make(map[int]int)
+	for partition := 0; partition < 3; partition++ {
+		ids, err := NewPartitionedIDGenerator(partition, 3)
+		if err != nil {
+			t.Fatal(err)
+		}
+		n := New(config, XORTest(), WithSeed(int64(partition)),
+			WithIDGenerator(ids))
+		n.Run()
+		for _, genome := range n.Population {
+			if genome.ID%3 != partition {
+				t.Fatalf("genome ID %d is not of partition %d", genome.ID,
+					partition)
+			}
+			if p, ok := seen[genome.ID]; ok && p != partition {
+				t.Fatalf("genome ID %d collides", genome.ID)
+			}
+			seen[genome.ID] = partition
+		}
+		for _, s := range n.Species {
+			if s.ID%3 != partition {
+				t.Errorf("species ID %d is not of partition %d", s.ID, partition)
+			}
+		}
+	}
+}
@@ -0,0 +1,338 @@
+// maze.go implementation of the maze navigation environment.
+//
+// Copyright (C) 2017  Jin Yeom
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+// Package maze provides the 2D maze navigation environment of Lehman and
+// Stanley, the canonical testbed of novelty search, in which a robot with
+// rangefinder and radar sensors navigates from the start to the goal of a
+// deceptive maze.
+//
+// The robot has 6 rangefinders, at -90, -45, 0, 45, 90, and 180 degrees from
+// its heading, which sense the distance to the nearest wall up to a range, and
+// 4 pie-slice radars, which fire if the goal is in front, left, behind, or
+// right of the robot. Thus, its network takes 11 inputs, i.e., a bias followed
+// by the rangefinders and the radars, all in [0, 1], and outputs 2 values in
+// [0, 1], which change the angular velocity and the speed of the robot.
+package maze
+
+import (
+	"context"
+	"math"
+
+	"github.com/jinyeom/neat"
+)
+
+const (
+	// NumInputs is the number of inputs of a network of a robot, including the
+	// bias.
+	NumInputs = 1 + numRangefinders + numRadars
+
+	// NumOutputs is the number of outputs of a network of a robot.
+	NumOutputs = 2
+
+	numRangefinders = 6     // number of rangefinders
+	numRadars       = 4     // number of pie-slice radars
+	sensorRange     = 100.0 // maximum range of rangefinders
+	robotRadius     = 8.0   // radius of the robot
+	maxSpeed        = 3.0   // maximum speed of the robot
+	maxAngularVel   = 3.0   // maximum angular velocity in degrees per step
+)
+
+var (
+	// rangefinderAngles are the angles of rangefinders from the heading in
+	// degrees.
+	rangefinderAngles = [numRangefinders]float64{-90.0, -45.0, 0.0, 45.0, 90.0,
+		180.0}
+)
+
+// Point is a point in a maze.
+type Point struct {
+	X float64 // x coordinate
+	Y float64 // y coordinate
+}
+
+// Wall is a line segment of a wall of a maze.
+type Wall struct {
+	A Point // one end of the wall
+	B Point // the other end of the wall
+}
+
+// Maze is a map of the maze navigation environment.
+type Maze struct {
+	Walls      []Wall  // walls of the maze
+	Start      Point   // starting position of the robot
+	Heading    float64 // starting heading of the robot in degrees
+	Goal       Point   // position of the goal
+	GoalRadius float64 // distance from the goal within which it is reached
+}
+
+// MediumMaze returns the medium map of Lehman and Stanley, whose goal is
+// reached by most methods, though fitness is deceptive.
+func MediumMaze() *Maze {
+	return &Maze{
+		Walls: []Wall{
+			{Point{293, 7}, Point{289, 130}},
+			{Point{289, 130}, Point{6, 134}},
+			{Point{6, 134}, Point{8, 5}},
+			{Point{8, 5}, Point{292, 7}},
+			{Point{241, 130}, Point{58, 65}},
+			{Point{114, 7}, Point{73, 42}},
+			{Point{130, 91}, Point{107, 46}},
+			{Point{196, 8}, Point{139, 51}},
+			{Point{219, 122}, Point{182, 63}},
+			{Point{267, 9}, Point{214, 63}},
+			{Point{271, 129}, Point{237, 88}},
+		},
+		Start:      Point{30, 22},
+		Heading:    0.0,
+		Goal:       Point{270, 100},
+		GoalRadius: 5.0,
+	}
+}
+
+// HardMaze returns a hard map in the spirit of that of Lehman and Stanley, in
+// which the goal is just above the start behind a long wall, such that the
+// fitness of the distance to the goal leads robots into a dead end; it is
+// rarely solved without novelty.
+func HardMaze() *Maze {
+	return &Maze{
+		Walls: []Wall{
+			// boundary
+			{Point{5, 5}, Point{295, 5}},
+			{Point{295, 5}, Point{295, 195}},
+			{Point{295, 195}, Point{5, 195}},
+			{Point{5, 195}, Point{5, 5}},
+			// long wall between the start and the goal, open at the right
+			{Point{5, 150}, Point{250, 150}},
+			// dead end below the goal
+			{Point{5, 100}, Point{200, 100}},
+			{Point{200, 100}, Point{200, 130}},
+			// corridor at the top, open at the left
+			{Point{60, 50}, Point{295, 50}},
+			{Point{60, 50}, Point{60, 80}},
+		},
+		Start:      Point{36, 184},
+		Heading:    0.0,
+		Goal:       Point{31, 20},
+		GoalRadius: 5.0,
+	}
+}
+
+// NewConfig returns a new default configuration of NEAT for robots of the maze
+// navigation environment.
+func NewConfig() *neat.Config {
+	config := neat.NewDefaultConfig(NumInputs, NumOutputs)
+	config.ExperimentName = "Maze navigation"
+	config.FullyConnected = false
+	config.NumGenerations = 200
+	config.PopulationSize = 250
+	return config
+}
+
+// Result is the result of a simulation of a robot in a maze.
+type Result struct {
+	Fitness  float64   // fitness in [0, 1], 1 if the goal is reached
+	Behavior []float64 // behavior descriptor, i.e., the final position
+	Solved   bool      // true if the goal is reached
+	Steps    int       // number of steps taken
+}
+
+// robot is the state of a robot in a maze.
+type robot struct {
+	pos        Point   // position
+	heading    float64 // heading in degrees
+	speed      float64 // speed
+	angularVel float64 // angular velocity in degrees per step
+}
+
+// Simulate runs the robot controlled by the argument network in this maze for
+// up to the argument number of steps, and returns the result; the simulation
+// ends early if the goal is reached. Its fitness is 1 minus the distance to
+// the goal at the end, relative to the size of the maze, and its behavior is
+// its final position, which is what novelty search compares.
+func (m *Maze) Simulate(nn *neat.NeuralNetwork, maxSteps int) Result {
+	nn.Reset()
+	r := &robot{pos: m.Start, heading: m.Heading}
+	inputs := make([]float64, NumInputs)
+	steps := 0
+	for ; steps < maxSteps && !m.reached(r.pos); steps++ {
+		m.sense(r, inputs)
+		outputs, err := nn.FeedForward(inputs)
+		if err != nil || len(outputs) < NumOutputs {
+			break
+		}
+		m.move(r, outputs)
+	}
+
+	solved := m.reached(r.pos)
+	fitness := 1.0
+	if !solved {
+		fitness = math.Max(0.0, 1.0-distance(r.pos, m.Goal)/m.diameter())
+	}
+	return Result{
+		Fitness:  fitness,
+		Behavior: []float64{r.pos.X, r.pos.Y},
+		Solved:   solved,
+		Steps:    steps,
+	}
+}
+
+// Evaluation returns an evaluation function of the fitness of robots in this
+// maze, simulated for the argument number of steps, e.g., 400.
+func (m *Maze) Evaluation(maxSteps int) neat.EvaluationFunc {
+	return func(nn *neat.NeuralNetwork) float64 {
+		return m.Simulate(nn, maxSteps).Fitness
+	}
+}
+
+// Behavior returns a function that returns the behavior descriptor of robots
+// in this maze, i.e., their final positions after the argument number of
+// steps, for novelty search.
+func (m *Maze) Behavior(maxSteps int) func(nn *neat.NeuralNetwork) []float64 {
+	return func(nn *neat.NeuralNetwork) []float64 {
+		return m.Simulate(nn, maxSteps).Behavior
+	}
+}
+
+// ResultEvaluation returns an evaluation function of both the fitness and the
+// behavior descriptor of robots in this maze, simulated for the argument
+// number of steps, which are recorded in each genome (see
+// neat.WithResultEvaluation), along with the metrics "steps" and "solved" (1
+// if the goal is reached, 0 otherwise).
+func (m *Maze) ResultEvaluation(maxSteps int) neat.ResultEvaluationFunc {
+	return func(ctx context.Context, nn *neat.NeuralNetwork) (neat.EvaluationResult,
+		error) {
+		result := m.Simulate(nn, maxSteps)
+		solved := 0.0
+		if result.Solved {
+			solved = 1.0
+		}
+		return neat.EvaluationResult{
+			Fitness:  result.Fitness,
+			Behavior: result.Behavior,
+			Metrics: map[string]float64{
+				"steps":  float64(result.Steps),
+				"solved": solved,
+			},
+		}, nil
+	}
+}
+
+// reached returns true if the argument position is within the goal radius.
+func (m *Maze) reached(p Point) bool {
+	return distance(p, m.Goal) <= m.GoalRadius
+}
+
+// diameter returns the length of the diagonal of the bounding box of the walls.
+func (m *Maze) diameter() float64 {
+	minX, minY := math.Inf(1), math.Inf(1)
+	maxX, maxY := math.Inf(-1), math.Inf(-1)
+	for _, w := range m.Walls {
+		for _, p := range []Point{w.A, w.B} {
+			minX, maxX = math.Min(minX, p.X), math.Max(maxX, p.X)
+			minY, maxY = math.Min(minY, p.Y), math.Max(maxY, p.Y)
+		}
+	}
+	if len(m.Walls) == 0 {
+		return 1.0
+	}
+	return math.Hypot(maxX-minX, maxY-minY)
+}
+
+// sense writes the bias, the rangefinders, and the radars of the robot to the
+// argument inputs.
+func (m *Maze) sense(r *robot, inputs []float64) {
+	inputs[0] = 1.0
+	for i, angle := range rangefinderAngles {
+		rad := (r.heading + angle) * math.Pi / 180.0
+		end := Point{r.pos.X + sensorRange*math.Cos(rad),
+			r.pos.Y + sensorRange*math.Sin(rad)}
+		nearest := sensorRange
+		for _, w := range m.Walls {
+			if p, ok := intersect(r.pos, end, w.A, w.B); ok {
+				nearest = math.Min(nearest, distance(r.pos, p))
+			}
+		}
+		inputs[1+i] = nearest / sensorRange
+	}
+
+	// radars of the goal: front, left, behind, and right of the robot.
+	angle := math.Atan2(m.Goal.Y-r.pos.Y, m.Goal.X-r.pos.X) * 180.0 / math.Pi
+	angle = math.Mod(angle-r.heading+360.0+45.0, 360.0)
+	for i := 0; i < numRadars; i++ {
+		inputs[1+numRangefinders+i] = 0.0
+	}
+	inputs[1+numRangefinders+int(angle/90.0)%numRadars] = 1.0
+}
+
+// move updates the robot given the argument outputs of its network; the robot
+// stays in place if it would collide with a wall.
+func (m *Maze) move(r *robot, outputs []float64) {
+	r.angularVel = clip(r.angularVel+(outputs[0]-0.5), maxAngularVel)
+	r.speed = clip(r.speed+(outputs[1]-0.5), maxSpeed)
+	r.heading = math.Mod(r.heading+r.angularVel+360.0, 360.0)
+
+	rad := r.heading * math.Pi / 180.0
+	next := Point{r.pos.X + r.speed*math.Cos(rad), r.pos.Y + r.speed*math.Sin(rad)}
+	for _, w := range m.Walls {
+		if segmentDistance(next, w.A, w.B) < robotRadius {
+			r.speed = 0.0
+			return
+		}
+	}
+	r.pos = next
+}
+
+// clip returns the argument value clipped to [-limit, limit].
+func clip(v, limit float64) float64 {
+	if math.IsNaN(v) {
+		return 0.0
+	}
+	return math.Max(-limit, math.Min(limit, v))
+}
+
+// distance returns the Euclidean distance between two points.
+func distance(p, q Point) float64 {
+	return math.Hypot(p.X-q.X, p.Y-q.Y)
+}
+
+// segmentDistance returns the distance from the point p to the segment ab.
+func segmentDistance(p, a, b Point) float64 {
+	dx, dy := b.X-a.X, b.Y-a.Y
+	lengthSq := dx*dx + dy*dy
+	if lengthSq == 0.0 {
+		return distance(p, a)
+	}
+	t := math.Max(0.0, math.Min(1.0, ((p.X-a.X)*dx+(p.Y-a.Y)*dy)/lengthSq))
+	return distance(p, Point{a.X + t*dx, a.Y + t*dy})
+}
+
+// intersect returns the intersection of the segments pq and ab, and true if
+// they intersect.
+func intersect(p, q, a, b Point) (Point, bool) {
+	rx, ry := q.X-p.X, q.Y-p.Y
+	sx, sy := b.X-a.X, b.Y-a.Y
+	denom := rx*sy - ry*sx
+	if denom == 0.0 {
+		return Point{}, false
+	}
+	t := ((a.X-p.X)*sy - (a.Y-p.Y)*sx) / denom
+	u := ((a.X-p.X)*ry - (a.Y-p.Y)*rx) / denom
+	if t < 0.0 || t > 1.0 || u < 0.0 || u > 1.0 {
+		return Point{}, false
+	}
+	return Point{p.X + t*rx, p.Y + t*ry}, true
+}
@@ -0,0 +1,74 @@
+package maze
+
+import (
+	"testing"
+
+	"github.com/jinyeom/neat"
+)
+
+func TestSense(t *testing.T) {
+	m := MediumMaze()
+	r := &robot{pos: m.Start, heading: m.Heading}
+	inputs := make([]float64, NumInputs)
+	m.sense(r, inputs)
+
+	// the left wall is about 23 behind the robot, and the goal is in front.
+	if behind := inputs[6] * sensorRange; behind < 20.0 || behind > 25.0 {
+		t.Errorf("invalid rangefinder behind: %f", behind)
+	}
+	if inputs[7] != 1.0 || inputs[8]+inputs[9]+inputs[10] != 0.0 {
+		t.Errorf("invalid radars: %v", inputs[7:])
+	}
+}
+
+func TestSimulate(t *testing.T) {
+	m := MediumMaze()
+
+	// a robot that speeds up straight ahead stops at the first wall.
+	g := neat.NewGenome(0, NumInputs, NumOutputs, 0.0)
+	g.ConnGenes = append(g.ConnGenes,
+		neat.NewConnGene(0, NumInputs, 0.0),
+		neat.NewConnGene(0, NumInputs+1, 10.0))
+	result := m.Simulate(neat.NewNeuralNetwork(g), 400)
+	if result.Solved || result.Steps != 400 {
+		t.Errorf("invalid result: %+v", result)
+	}
+	if x, y := result.Behavior[0], result.Behavior[1]; x < 60.0 || x > 100.0 ||
+		y != m.Start.Y {
+		t.Errorf("invalid final position: (%f, %f)", x, y)
+	}
+	if result.Fitness <= 0.0 || result.Fitness >= 1.0 {
+		t.Errorf("invalid fitness: %f", result.Fitness)
+	}
+
+	m.Start = m.Goal
+	if result = m.Simulate(neat.NewNeuralNetwork(g), 400); !result.Solved ||
+		result.Fitness != 1.0 || result.Steps != 0 {
+		t.Errorf("goal is not reached: %+v", result)
+	}
+}
+
+func TestMazeEvolution(t *testing.T) {
+	m := HardMaze()
+	config := NewConfig()
+	config.NumGenerations = 3
+	config.PopulationSize = 20
+	best := neat.New(config, m.Evaluation(100), neat.WithSeed(0)).Run()
+	if behavior := m.Behavior(100)(neat.NewNeuralNetwork(best)); len(behavior) != 2 {
+		t.Errorf("invalid behavior: %v", behavior)
+	}
+
+	// behavior descriptors are recorded in the genomes.
+	n := neat.New(config, nil, neat.WithSeed(0),
+		neat.WithResultEvaluation(m.ResultEvaluation(100)))
+	best = n.Run()
+	if len(best.Behavior) != 2 {
+		t.Errorf("behavior of the best genome is not recorded: %v", best.Behavior)
+	}
+	if best.Metrics["steps"] <= 0.0 {
+		t.Errorf("metrics of the best genome are not recorded: %v", best.Metrics)
+	}
+	if _, ok := n.Statistics.AvgMetrics[0]["solved"]; !ok {
+		t.Errorf("metrics are not aggregated: %v", n.Statistics.AvgMetrics[0])
+	}
+}
@@ -0,0 +1,382 @@
+// mnist.go implementation of the MNIST classification evaluator.
+//
+// Copyright (C) 2017  Jin Yeom
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+// Package mnist provides an evaluator of networks that classify handwritten
+// digits of MNIST, which is loaded from files of the IDX format, e.g.,
+// train-images-idx3-ubyte and train-labels-idx1-ubyte, optionally gzipped.
+//
+// A network takes the pixels of an image in [0, 1], in row-major order, and
+// outputs a score of each of the 10 digits; the digit of the highest score is
+// its prediction. Images can be downsampled to reduce the number of inputs,
+// which suits direct NEAT; for HyperNEAT, the pixels can be laid out as a grid
+// layer of a substrate.
+package mnist
+
+import (
+	"compress/gzip"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"os"
+	"strings"
+
+	"github.com/jinyeom/neat"
+)
+
+// NumClasses is the number of classes of digits.
+const NumClasses = 10
+
+// Dataset is a set of images of digits and their labels.
+type Dataset struct {
+	Images [][]float64 // pixels of each image in [0, 1], in row-major order
+	Labels []int       // digit of each image
+	Width  int         // width of the images
+	Height int         // height of the images
+}
+
+// Load returns a new dataset loaded from the argument files of images and
+// labels in the IDX format; files whose names end with ".gz" are decompressed.
+func Load(imagesFile, labelsFile string) (*Dataset, error) {
+	f, err := open(imagesFile)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	images, width, height, err := ReadImages(f)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err = open(labelsFile)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	labels, err := ReadLabels(f)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(images) != len(labels) {
+		return nil, fmt.Errorf("mnist: %d images != %d labels",
+			len(images), len(labels))
+	}
+	return &Dataset{
+		Images: images,
+		Labels: labels,
+		Width:  width,
+		Height: height,
+	}, nil
+}
+
+// gzipFile is a gzipped file, which closes both the decompressor and the file.
+type gzipFile struct {
+	*gzip.Reader
+	f *os.File
+}
+
+// Close closes the decompressor and the file.
+func (g *gzipFile) Close() error {
+	g.Reader.Close()
+	return g.f.Close()
+}
+
+// open opens the argument file, which is decompressed if its name ends with
+// ".gz".
+func open(filename string) (io.ReadCloser, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasSuffix(filename, ".gz") {
+		return f, nil
+	}
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return &gzipFile{gz, f}, nil
+}
+
+// readHeader reads the magic number and dimensions of an IDX file, and checks
+// that its data are unsigned bytes of the argument number of dimensions.
+func readHeader(r io.Reader, numDims int) ([]int, error) {
+	var magic [4]byte
+	if _, err := io.ReadFull(r, magic[:]); err != nil {
+		return nil, err
+	}
+	if magic[0] != 0 || magic[1] != 0 || magic[2] != 0x08 ||
+		int(magic[3]) != numDims {
+		return nil, fmt.Errorf("mnist: invalid IDX header %v", magic)
+	}
+	dims := make([]int, numDims)
+	for i := range dims {
+		var dim uint32
+		if err := binary.Read(r, binary.BigEndian, &dim); err != nil {
+			return nil, err
+		}
+		dims[i] = int(dim)
+	}
+	return dims, nil
+}
+
+// ReadImages reads images of the IDX format of 3 dimensions from the argument
+// reader, and returns their pixels scaled to [0, 1], their width, and their
+// height.
+func ReadImages(r io.Reader) ([][]float64, int, int, error) {
+	dims, err := readHeader(r, 3)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	num, height, width := dims[0], dims[1], dims[2]
+	images := make([][]float64, num)
+	buf := make([]byte, width*height)
+	for i := range images {
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return nil, 0, 0, err
+		}
+		images[i] = make([]float64, len(buf))
+		for j, b := range buf {
+			images[i][j] = float64(b) / 255.0
+		}
+	}
+	return images, width, height, nil
+}
+
+// ReadLabels reads labels of the IDX format of 1 dimension from the argument
+// reader.
+func ReadLabels(r io.Reader) ([]int, error) {
+	dims, err := readHeader(r, 1)
+	if err != nil {
+		return nil, err
+	}
+	buf := make([]byte, dims[0])
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return nil, err
+	}
+	labels := make([]int, len(buf))
+	for i, b := range buf {
+		if b >= NumClasses {
+			return nil, fmt.Errorf("mnist: invalid label %d", b)
+		}
+		labels[i] = int(b)
+	}
+	return labels, nil
+}
+
+// Downsample returns a new dataset of images downsampled by the argument
+// factor, in which each pixel is the average of a block of factor x factor
+// pixels; e.g., a factor of 4 turns 28x28 images into 7x7 images.
+func (d *Dataset) Downsample(factor int) *Dataset {
+	if factor <= 1 {
+		return d
+	}
+	width, height := d.Width/factor, d.Height/factor
+	images := make([][]float64, len(d.Images))
+	for i, img := range d.Images {
+		images[i] = make([]float64, width*height)
+		for y := 0; y < height; y++ {
+			for x := 0; x < width; x++ {
+				sum := 0.0
+				for dy := 0; dy < factor; dy++ {
+					for dx := 0; dx < factor; dx++ {
+						sum += img[(y*factor+dy)*d.Width+x*factor+dx]
+					}
+				}
+				images[i][y*width+x] = sum / float64(factor*factor)
+			}
+		}
+	}
+	return &Dataset{
+		Images: images,
+		Labels: d.Labels,
+		Width:  width,
+		Height: height,
+	}
+}
+
+// Subset returns a new dataset of the first n images of this dataset.
+func (d *Dataset) Subset(n int) *Dataset {
+	if n > len(d.Images) {
+		n = len(d.Images)
+	}
+	return &Dataset{
+		Images: d.Images[:n],
+		Labels: d.Labels[:n],
+		Width:  d.Width,
+		Height: d.Height,
+	}
+}
+
+// NewConfig returns a new default configuration of NEAT for classifiers of
+// this dataset, whose outputs are scores of the digits.
+func (d *Dataset) NewConfig() *neat.Config {
+	config := neat.NewDefaultConfig(d.Width*d.Height, NumClasses)
+	config.ExperimentName = "MNIST"
+	return config
+}
+
+// Score is the performance of a classifier on a set of images.
+type Score struct {
+	Accuracy     float64 // fraction of correctly classified images
+	CrossEntropy float64 // mean cross-entropy of the softmax of the outputs
+}
+
+// Evaluate returns the score of the argument network on the images of the
+// argument indices, e.g., a mini-batch; all images are used if indices is nil.
+func (d *Dataset) Evaluate(nn *neat.NeuralNetwork, indices []int) (Score, error) {
+	indices = d.indices(indices)
+	if len(indices) == 0 {
+		return Score{}, nil
+	}
+
+	outputs := make([][]float64, len(indices))
+	for j, i := range indices {
+		var err error
+		if outputs[j], err = nn.FeedForward(d.Images[i]); err != nil {
+			return Score{}, err
+		}
+	}
+	return d.score(outputs, indices)
+}
+
+// EvaluateBatch is Evaluate of a batched network, which propagates all the
+// images at once; it is much faster than Evaluate on large batches.
+func (d *Dataset) EvaluateBatch(b *neat.BatchNetwork, indices []int) (Score,
+	error) {
+	indices = d.indices(indices)
+	if len(indices) == 0 {
+		return Score{}, nil
+	}
+
+	images := make([][]float64, len(indices))
+	for j, i := range indices {
+		images[j] = d.Images[i]
+	}
+	outputs, err := b.FeedForwardBatch(images)
+	if err != nil {
+		return Score{}, err
+	}
+	return d.score(outputs, indices)
+}
+
+// indices returns the argument indices of images, or those of all images if
+// it is nil.
+func (d *Dataset) indices(indices []int) []int {
+	if indices != nil {
+		return indices
+	}
+	indices = make([]int, len(d.Images))
+	for i := range indices {
+		indices[i] = i
+	}
+	return indices
+}
+
+// score returns the score of the argument outputs of the images of the
+// argument indices.
+func (d *Dataset) score(outputs [][]float64, indices []int) (Score, error) {
+	correct, entropy := 0, 0.0
+	for j, i := range indices {
+		if len(outputs[j]) != NumClasses {
+			return Score{}, fmt.Errorf("mnist: %d outputs != %d classes",
+				len(outputs[j]), NumClasses)
+		}
+		if argmax(outputs[j]) == d.Labels[i] {
+			correct++
+		}
+		entropy += crossEntropy(outputs[j], d.Labels[i])
+	}
+	return Score{
+		Accuracy:     float64(correct) / float64(len(indices)),
+		CrossEntropy: entropy / float64(len(indices)),
+	}, nil
+}
+
+// Evaluation returns an evaluation function of the accuracy of networks on a
+// random mini-batch of the argument size, drawn anew for each network with the
+// argument random number generator; the whole dataset is used if batchSize is
+// 0. Networks that fail to classify get the accuracy of 0.
+func (d *Dataset) Evaluation(batchSize int, rng *rand.Rand) neat.EvaluationFunc {
+	return func(nn *neat.NeuralNetwork) float64 {
+		score, err := d.Evaluate(nn, d.batch(batchSize, rng))
+		if err != nil {
+			return 0.0
+		}
+		return score.Accuracy
+	}
+}
+
+// BatchEvaluation is Evaluation with batched networks, whose matrix products
+// are computed with the argument function (neat.MatMul if nil), e.g., of a
+// BLAS. Since recurrent networks cannot be batched, they fail to classify;
+// networks should be evolved without self-connections.
+func (d *Dataset) BatchEvaluation(batchSize int, rng *rand.Rand,
+	matMul neat.MatMulFunc) neat.EvaluationFunc {
+	return func(nn *neat.NeuralNetwork) float64 {
+		b, err := neat.NewBatchNetwork(nn)
+		if err != nil {
+			return 0.0
+		}
+		if matMul != nil {
+			b.MatMul = matMul
+		}
+		score, err := d.EvaluateBatch(b, d.batch(batchSize, rng))
+		if err != nil {
+			return 0.0
+		}
+		return score.Accuracy
+	}
+}
+
+// batch returns the indices of a random mini-batch of the argument size, or
+// nil, i.e., all images, if batchSize is 0.
+func (d *Dataset) batch(batchSize int, rng *rand.Rand) []int {
+	if batchSize <= 0 {
+		return nil
+	}
+	indices := make([]int, batchSize)
+	for i := range indices {
+		indices[i] = rng.Intn(len(d.Images))
+	}
+	return indices
+}
+
+// argmax returns the index of the largest value.
+func argmax(values []float64) int {
+	best := 0
+	for i, v := range values {
+		if v > values[best] {
+			best = i
+		}
+	}
+	return best
+}
+
+// crossEntropy returns the cross-entropy of the softmax of the argument
+// outputs, given the label.
+func crossEntropy(outputs []float64, label int) float64 {
+	largest := outputs[argmax(outputs)]
+	sum := 0.0
+	for _, v := range outputs {
+		sum += math.Exp(v - largest)
+	}
+	return math.Log(sum) - (outputs[label] - largest)
+}
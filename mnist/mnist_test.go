@@ -0,0 +1,106 @@
+package mnist
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"math"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jinyeom/neat"
+)
+
+// writeIDX writes an IDX file of unsigned bytes of the argument dimensions.
+func writeIDX(t *testing.T, filename string, dims []int, data []byte) {
+	buf := &bytes.Buffer{}
+	buf.Write([]byte{0, 0, 0x08, byte(len(dims))})
+	for _, dim := range dims {
+		binary.Write(buf, binary.BigEndian, uint32(dim))
+	}
+	buf.Write(data)
+
+	raw := buf.Bytes()
+	if filepath.Ext(filename) == ".gz" {
+		gzbuf := &bytes.Buffer{}
+		gz := gzip.NewWriter(gzbuf)
+		gz.Write(raw)
+		gz.Close()
+		raw = gzbuf.Bytes()
+	}
+	if err := os.WriteFile(filename, raw, 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDataset(t *testing.T) {
+	dir := t.TempDir()
+	images := make([]byte, 4*4*4)
+	for i := range images {
+		images[i] = byte(i * 4)
+	}
+	writeIDX(t, filepath.Join(dir, "images.gz"), []int{4, 4, 4}, images)
+	writeIDX(t, filepath.Join(dir, "labels"), []int{4}, []byte{0, 0, 1, 2})
+
+	d, err := Load(filepath.Join(dir, "images.gz"), filepath.Join(dir, "labels"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(d.Images) != 4 || d.Width != 4 || d.Height != 4 || d.Labels[3] != 2 {
+		t.Fatalf("invalid dataset: %d images of %dx%d", len(d.Images),
+			d.Width, d.Height)
+	}
+	if d.Images[0][1] != 4.0/255.0 {
+		t.Errorf("invalid pixel: %f", d.Images[0][1])
+	}
+
+	small := d.Downsample(2)
+	if small.Width != 2 || len(small.Images[0]) != 4 {
+		t.Fatalf("invalid downsampled size: %d", small.Width)
+	}
+	if want := (0.0 + 4.0 + 16.0 + 20.0) / 4.0 / 255.0; small.Images[0][0] != want {
+		t.Errorf("invalid downsampled pixel: %f != %f", small.Images[0][0], want)
+	}
+
+	// a network that always predicts 0.
+	g := neat.NewGenome(0, 4, NumClasses, 0.0)
+	g.ConnGenes = append(g.ConnGenes, neat.NewConnGene(0, 4, 10.0))
+	score, err := small.Evaluate(neat.NewNeuralNetwork(g), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if score.Accuracy != 0.5 || score.CrossEntropy <= 0.0 {
+		t.Errorf("invalid score: %+v", score)
+	}
+	if _, err = d.Evaluate(neat.NewNeuralNetwork(g), nil); err == nil {
+		t.Error("invalid number of inputs is not reported")
+	}
+
+	b, err := neat.NewBatchNetwork(neat.NewNeuralNetwork(g))
+	if err != nil {
+		t.Fatal(err)
+	}
+	batchScore, err := small.EvaluateBatch(b, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(batchScore.CrossEntropy-score.CrossEntropy) > 1e-9 ||
+		batchScore.Accuracy != score.Accuracy {
+		t.Errorf("invalid batched score: %+v != %+v", batchScore, score)
+	}
+
+	config := small.NewConfig()
+	config.NumGenerations = 2
+	config.PopulationSize = 10
+	neat.New(config, small.Evaluation(2, rand.New(rand.NewSource(0))),
+		neat.WithSeed(0)).Run()
+}
+
+func TestReadLabels(t *testing.T) {
+	buf := bytes.NewReader([]byte{0, 0, 0x08, 1, 0, 0, 0, 1, 10})
+	if _, err := ReadLabels(buf); err == nil {
+		t.Error("invalid label is not reported")
+	}
+}
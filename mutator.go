@@ -0,0 +1,91 @@
+// mutator.go implementation of mutation operators of genomes.
+//
+// Copyright (C) 2017  Jin Yeom
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package neat
+
+import (
+	"math/rand"
+)
+
+// Mutator is an interface of mutation operators of genomes, which are applied
+// to each genome that mutates during reproduction. Mutate mutates the argument
+// genome given the configuration of the run and the random number generator,
+// which must be used for the evolution to be reproducible, and returns the
+// names of the mutations applied (none if the genome is intact), which are
+// recorded in the statistics and the genealogy. Since genomes are mutated
+// concurrently if Config.NumWorkers is greater than 1, Mutate must be safe for
+// concurrent use on different genomes.
+type Mutator interface {
+	Mutate(g *Genome, config *Config, rng *rand.Rand) []string
+}
+
+// DefaultMutator is the Mutator of the built-in mutation operators, which
+// perturbs the weights of a genome, adds a node, adds a connection, and
+// replaces an activation function, given the rates of the configuration.
+type DefaultMutator struct {
+	Activations *ActivationRegistry // activation functions of new nodes
+}
+
+// Mutate applies the built-in mutation operators to the argument genome, and
+// returns the names of those that mutated it: "perturb", "addNode", "addConn",
+// and "activation".
+func (m *DefaultMutator) Mutate(g *Genome, config *Config,
+	rng *rand.Rand) []string {
+	var applied []string
+	numNodes, numConns := len(g.NodeGenes), len(g.ConnGenes)
+
+	if g.mutatePerturb(config.RatePerturb, rng) {
+		applied = append(applied, "perturb")
+	}
+	g.mutateAddNode(config.RateAddNode, m.Activations.RandHidden(rng),
+		config.MaxDepth, rng)
+	if len(g.NodeGenes) > numNodes {
+		applied = append(applied, "addNode")
+		numConns = len(g.ConnGenes)
+	}
+	g.mutateAddConn(config.RateAddConn, config.MaxDepth,
+		config.SelfConnections, rng)
+	if len(g.ConnGenes) > numConns {
+		applied = append(applied, "addConn")
+	}
+	if g.mutateActivation(config.RateMutateActivation, m.Activations.Hidden,
+		rng) {
+		applied = append(applied, "activation")
+	}
+	return applied
+}
+
+// MutationFunc is a type of function that mutates the argument genome given the
+// random number generator, and returns true if the genome is mutated.
+type MutationFunc func(g *Genome, rng *rand.Rand) bool
+
+// namedMutation is a Mutator of a mutation function of a name (see
+// WithMutation).
+type namedMutation struct {
+	name   string       // name of the mutation
+	mutate MutationFunc // mutation function
+}
+
+// Mutate applies the mutation function to the argument genome, and returns
+// its name if the genome is mutated.
+func (m *namedMutation) Mutate(g *Genome, config *Config,
+	rng *rand.Rand) []string {
+	if m.mutate(g, rng) {
+		return []string{m.name}
+	}
+	return nil
+}
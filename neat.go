@@ -18,112 +18,471 @@
 package neat
 
 import (
+	"context"
 	"fmt"
+	"io"
+	"log"
 	"math"
 	"math/rand"
+	"os"
 	"sort"
+	"strings"
+	"sync"
+	"time"
 )
 
 // NEAT is the implementation of NeuroEvolution of Augmenting Topology (NEAT).
 type NEAT struct {
-	Config      *Config           // configuration
-	Population  []*Genome         // population of genome
-	Species     []*Species        // species of subpopulation of genomes
-	Activations []*ActivationFunc // set of activation functions
-	Evaluation  EvaluationFunc    // evaluation function
-	Comparison  ComparisonFunc    // comparison function
-	Best        *Genome           // best genome
-	Statistics  *Statistics       // statistics
-
-	nextGenomeID  int // genome ID that is assigned to a newly created genome
-	nextSpeciesID int // species ID that is assigned to a newly created species
+	Config      *Config             // configuration
+	Population  []*Genome           // population of genome
+	Species     []*Species          // species of subpopulation of genomes
+	Activations *ActivationRegistry // registry of activation functions
+	Evaluation  EvaluationFunc      // evaluation function
+	Comparison  ComparisonFunc      // comparison function
+	Selection   SelectionFunc       // selection function of survivors
+	Speciation  SpeciationFunc      // speciation function
+	Distance    DistanceFunc        // compatibility distance of genomes
+	Reproducer  Reproducer          // reproduction policy
+	Callbacks   []Callback          // callbacks at the end of each generation
+	Mutator     Mutator             // mutation operator of genomes
+	Mutators    []Mutator           // custom mutation operators after Mutator
+	Rand        *rand.Rand          // random number generator
+	Logger      *log.Logger         // logger of verbose messages
+	Best        *Genome             // best genome
+	Statistics  *Statistics         // statistics
+	Curriculum  *Curriculum         // curriculum of evaluation stages (optional)
+	Generation  int                 // index of the current generation
+
+	Champions []*Genome  // champion of each generation (archive)
+	Genealogy *Genealogy // ancestry of genomes (optional)
+
+	// constructor of the random number generator of each reproduction worker
+	WorkerRand func(seed int64) *rand.Rand
+
+	// held-out evaluation of the top genomes of each generation (optional)
+	Validation     EvaluationFunc
+	ValidationTopK int // number of top genomes that are validated
+
+	// evaluation of the whole population at once (optional)
+	PopulationEvaluation PopulationEvaluator
+
+	// evaluation function that may fail, used instead of Evaluation (optional)
+	FallibleEvaluation FallibleEvaluationFunc
+
+	// evaluation function under a context, used instead of both of the above
+	// (optional)
+	ContextEvaluation ContextEvaluationFunc
+
+	// evaluation function of detailed results, e.g., multiple objectives or a
+	// behavior descriptor, used instead of all of the above (optional)
+	ResultEvaluation ResultEvaluationFunc
+
+	// generator of IDs of genomes and species
+	IDs IDGenerator
+
+	lastGenomeID  int   // largest genome ID in use
+	lastSpeciesID int   // largest species ID in use
+	seed          int64 // seed of the random number generator (0 if unknown)
+
+	innovations map[[2]int]bool // pairs of nodes that have been connected
+
+	err error // error of the evaluations of the last generation
+
+	// copies of the best genome and of the progress, published at the end of
+	// each generation for other goroutines (see BestSnapshot)
+	mu       sync.RWMutex
+	snapshot *Genome
+	progress Progress
 }
 
 // New creates a new instance of NEAT with provided argument configuration and
-// an evaluation function.
-func New(config *Config, evaluation EvaluationFunc) *NEAT {
-	nextGenomeID := 0
-	nextSpeciesID := 0
-
-	// in order to prevent containing multiple of the same activation function
-	// in the set of activation functions, they will temporarily be added to a
-	// map first, which contains Sigmoid function as a default, then be
-	// transferred to a slice of ActivationFunc.
-	temp := map[string]*ActivationFunc{
-		"sigmoid": Sigmoid(),
+// an evaluation function. Default behaviors, e.g., the comparison function or
+// the random number generator, can be overridden with options.
+//
+// By default, the random number generator is seeded with Config.RandomSeed, or
+// with the current time if it is 0; either way, the seed is recorded in the
+// metadata of the run, so that the run can be reproduced.
+//
+// The configuration is expected to be valid (see Config.Validate); New panics
+// if its selection strategy or one of its activation functions is unknown,
+// rather than running with another one.
+func New(config *Config, evaluation EvaluationFunc, opts ...Option) *NEAT {
+	seed := config.RandomSeed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	selection, err := NewSelectionFunc(config.SelectionStrategy,
+		config.TournamentSize)
+	if err != nil {
+		panic("neat: " + err.Error())
 	}
 
-	// if more additional activation functions are needed,
-	for _, name := range config.CPPNActivations {
-		temp[name] = ActivationSet[name]
+	n := &NEAT{
+		Config:     config,
+		Evaluation: evaluation,
+		Comparison: NewComparisonFunc(config.MinimizeFitness),
+		Selection:  selection,
+		Speciation: (*NEAT).Speciate,
+		Distance: NewDistanceFunc(config.CoeffUnmatching,
+			config.CoeffMatching),
+		Reproducer: DefaultReproducer{},
+		Callbacks:  []Callback{},
+		Mutators:   []Mutator{},
+		Rand:       rand.New(rand.NewSource(seed)),
+		WorkerRand: newWorkerRand,
+		Logger:     log.New(os.Stdout, "", 0),
+		Statistics: newStatistics(config),
+		IDs:        NewMonotonicIDGenerator(),
+		seed:       seed,
+
+		lastGenomeID:  -1,
+		lastSpeciesID: -1,
+	}
+	for _, opt := range opts {
+		opt(n)
 	}
+	n.Statistics.RunInfo = NewRunInfo(config, n.seed)
 
-	activations := make([]*ActivationFunc, 0, len(temp))
-	for _, afunc := range temp {
-		activations = append(activations, afunc)
+	if n.Activations, err = NewActivationRegistry(config); err != nil {
+		panic("neat: " + err.Error())
+	}
+	if n.Mutator == nil {
+		n.Mutator = &DefaultMutator{Activations: n.Activations}
 	}
 
 	population := make([]*Genome, config.PopulationSize)
 	if config.FullyConnected {
 		for i := 0; i < config.PopulationSize; i++ {
-			population[i] = NewFCGenome(nextGenomeID, config.NumInputs,
-				config.NumOutputs, config.InitFitness)
-			nextGenomeID++
+			population[i] = newFCGenome(n.newGenomeID(), config.NumInputs,
+				config.NumOutputs, config.InitFitness, n.Rand)
 		}
 	} else {
 		for i := 0; i < config.PopulationSize; i++ {
-			population[i] = NewGenome(nextGenomeID, config.NumInputs,
+			population[i] = NewGenome(n.newGenomeID(), config.NumInputs,
 				config.NumOutputs, config.InitFitness)
-			nextGenomeID++
 		}
 	}
 
+	// nodes of initial genomes are assigned activation functions from the
+	// registry; output nodes keep them throughout the evolution.
+	for _, genome := range population {
+		n.Activations.Initialize(genome, n.Rand)
+	}
+
 	// initialize the first species with a randomly selected genome
-	s := NewSpecies(nextSpeciesID, population[rand.Intn(len(population))])
+	s := NewSpecies(n.newSpeciesID(), population[n.Rand.Intn(len(population))])
 	species := []*Species{s}
-	nextSpeciesID++
 
-	return &NEAT{
-		Config:        config,
-		Population:    population,
-		Species:       species,
-		Activations:   activations,
-		Evaluation:    evaluation,
-		Comparison:    NewComparisonFunc(config.MinimizeFitness),
-		Best:          population[rand.Intn(config.PopulationSize)].Copy(),
-		Statistics:    NewStatistics(config.NumGenerations),
-		nextGenomeID:  nextGenomeID,
-		nextSpeciesID: nextSpeciesID,
+	n.Population = population
+	n.Species = species
+	n.Best = population[n.Rand.Intn(config.PopulationSize)].Copy()
+
+	n.innovations = make(map[[2]int]bool)
+	for _, genome := range population {
+		n.countInnovations(genome.ConnGenes)
+	}
+
+	if config.TrackLineage {
+		n.Genealogy = NewGenealogy()
+		for _, genome := range population {
+			n.Genealogy.Record(genome.ID, 0)
+		}
+	}
+	n.publish()
+	return n
+}
+
+// newStatistics returns new statistics of a run with the argument
+// configuration, which are bounded if either Config.StatisticsWindow or
+// Config.StatisticsStride is set.
+func newStatistics(config *Config) *Statistics {
+	if config.StatisticsWindow > 0 || config.StatisticsStride > 1 {
+		return NewBoundedStatistics(config.StatisticsWindow,
+			config.StatisticsStride)
+	}
+	return NewStatistics(config.NumGenerations)
+}
+
+// SetEvaluation replaces the evaluation function of this NEAT. Since fitness
+// scores measured with the previous evaluation function are no longer
+// comparable, every genome in the population, as well as the best genome, is
+// marked to be evaluated again. It also replaces n.FallibleEvaluation,
+// n.ContextEvaluation, and n.ResultEvaluation, if any.
+func (n *NEAT) SetEvaluation(evaluation EvaluationFunc) {
+	n.Evaluation = evaluation
+	n.FallibleEvaluation = nil
+	n.ContextEvaluation = nil
+	n.ResultEvaluation = nil
+	for _, genome := range n.Population {
+		genome.evaluated = false
 	}
+	n.Best.evaluated = false
+	n.Best.Evaluate(evaluation)
 }
 
-// Summarize summarizes current state of evolution process.
+// SetCurriculum sets the curriculum of this NEAT, and starts evaluating the
+// population with the evaluation function of its current stage.
+func (n *NEAT) SetCurriculum(c *Curriculum) {
+	n.Curriculum = c
+	n.SetEvaluation(c.Stage().Evaluation)
+}
+
+// Summarize summarizes current state of evolution process with n.Logger.
 func (n *NEAT) Summarize(gen int) {
+	n.SummarizeTo(n.Logger.Writer(), gen)
+}
+
+// SummarizeTo writes the summary of current state of evolution process to the
+// argument writer, e.g., a log file or a buffer.
+func (n *NEAT) SummarizeTo(w io.Writer, gen int) {
 	// summary template
 	tmpl := "Gen. %4d | Num. Species: %4d | Best Fitness: %.4f | " +
 		"Avg. Fitness: %.4f"
 
+	// average fitness of the population, which may not be recorded in
+	// n.Statistics if it is bounded
+	avg := 0.0
+	for _, genome := range n.Population {
+		avg += genome.Fitness
+	}
+	avg /= float64(len(n.Population))
+
 	// compose each line of summary and the spacing of separating line
-	str := fmt.Sprintf(tmpl, gen, len(n.Species),
-		n.Best.Fitness, n.Statistics.AvgFitness[gen])
+	str := fmt.Sprintf(tmpl, gen, len(n.Species), n.Best.Fitness, avg)
 	spacing := int(math.Max(float64(len(str)), 80.0))
 
-	for i := 0; i < spacing; i++ {
-		fmt.Printf("-")
+	separator := strings.Repeat("-", spacing)
+	fmt.Fprintf(w, "%s\n%s\n%s\n", separator, str, separator)
+
+	// summary of each species
+	for _, s := range n.SpeciesSummaries() {
+		fmt.Fprintf(w, "  Species %4d | Size: %4d | Best: %.4f | Avg: %.4f | "+
+			"Age: %3d | Stag.: %3d | Nodes: %3d | Conns: %3d\n", s.ID, s.Size,
+			s.BestFitness, s.AvgFitness, s.Age, s.Stagnation, s.NumNodes,
+			s.NumConns)
 	}
-	fmt.Printf("\n%s\n", str)
-	for i := 0; i < spacing; i++ {
-		fmt.Printf("-")
+}
+
+// SpeciesSummaries returns the summaries of the current species, whose members
+// are the genomes assigned during the last speciation.
+func (n *NEAT) SpeciesSummaries() []*SpeciesSummary {
+	summaries := make([]*SpeciesSummary, len(n.Species))
+	for i, s := range n.Species {
+		summaries[i] = s.Summary()
 	}
-	fmt.Println()
+	return summaries
 }
 
 // Evaluate evaluates fitness of every genome in the population. After the
-// evaluation, their fitness scores are recored in each genome.
-func (n *NEAT) Evaluate() {
+// evaluation, their fitness scores are recored in each genome. If an
+// evaluation takes longer than n.Config.EvaluationTimeout, results in NaN or
+// infinity, or returns an error (with n.FallibleEvaluation or
+// n.ContextEvaluation), the genome is assigned the initial fitness score
+// instead, and the failure is recorded in n.Statistics. The errors returned by
+// the evaluation function are also returned as an EvaluationError.
+//
+// If n.Config.NumWorkers is greater than 1, as many genomes are evaluated
+// concurrently, thus the evaluation function must be safe for concurrent use;
+// an EnvironmentPool gives each of them its own simulator.
+//
+// If n.PopulationEvaluation is set, the whole population is evaluated with it
+// at once instead, without the timeout.
+func (n *NEAT) Evaluate() error {
+	return n.EvaluateContext(context.Background())
+}
+
+// EvaluateContext is Evaluate under the argument context, which is passed to
+// n.ContextEvaluation along with the information of each evaluation, including
+// its deterministic seeds (see EvaluationInfoFrom); the context of each
+// evaluation is canceled when it exceeds n.Config.EvaluationTimeout, such that
+// a runaway genome does not stall the generation. If the argument context is
+// canceled, the remaining genomes are assigned the initial fitness score, and
+// the error of the context is returned.
+func (n *NEAT) EvaluateContext(ctx context.Context) error {
+	if n.PopulationEvaluation != nil {
+		n.evaluatePopulation()
+		return nil
+	}
+
+	evaluate := n.ResultEvaluation
+	if evaluate == nil {
+		contextual := n.ContextEvaluation
+		if contextual == nil {
+			fallible := n.FallibleEvaluation
+			if fallible == nil {
+				fallible = n.Evaluation.Fallible()
+			}
+			contextual = fallible.Contextual()
+		}
+		evaluate = contextual.Result()
+	}
+	timeout := time.Duration(n.Config.EvaluationTimeout * float64(time.Second))
+	pending := make([]*Genome, 0, len(n.Population))
+	for _, genome := range n.Population {
+		if !genome.evaluated {
+			pending = append(pending, genome)
+		}
+	}
+
+	// genomes that are not evaluated before the context is canceled are
+	// skipped, and assigned the initial fitness score.
+	errs := make([]error, len(pending))
+	skipped := make([]bool, len(pending))
+	n.parallel(len(pending), func(i int) {
+		genome := pending[i]
+		if ctx.Err() != nil {
+			genome.Fitness = n.Config.InitFitness
+			skipped[i] = true
+			return
+		}
+		info := newEvaluationInfo(n.seed, n.Generation, genome.ID)
+		errs[i] = genome.evaluateWithin(context.WithValue(ctx,
+			evaluationInfoKey{}, info), evaluate, timeout, n.Config.InitFitness)
+	})
+
+	var evalErr *EvaluationError
+	for i, genome := range pending {
+		if skipped[i] {
+			continue
+		}
+		reason := ""
+		err := errs[i]
+		if err == errEvaluationTimeout {
+			reason = "timeout"
+		} else if err != nil {
+			reason = "error"
+			n.Statistics.RecordError(n.Generation, genome.ID, err)
+			if evalErr == nil {
+				evalErr = &EvaluationError{Generation: n.Generation,
+					Errors: make(map[int]error)}
+			}
+			evalErr.Errors[genome.ID] = err
+		} else if reason = nonFiniteReason(genome.Fitness); reason != "" {
+			genome.Fitness = n.Config.InitFitness
+		}
+
+		if reason != "" {
+			if reason != "error" {
+				n.Statistics.RecordFailure(n.Generation, genome.ID, reason)
+			}
+			if n.Config.Verbose {
+				n.Logger.Printf("Evaluation of genome %d failed (%s)\n",
+					genome.ID, reason)
+			}
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if evalErr == nil {
+		return nil
+	}
+	return evalErr
+}
+
+// parallel calls the argument function with each index in [0, num), with at
+// most n.Config.NumWorkers calls at once (sequentially if it is 0 or 1).
+func (n *NEAT) parallel(num int, fn func(i int)) {
+	if n.Config.NumWorkers <= 1 {
+		for i := 0; i < num; i++ {
+			fn(i)
+		}
+		return
+	}
+
+	indices := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < n.Config.NumWorkers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indices {
+				fn(i)
+			}
+		}()
+	}
+	for i := 0; i < num; i++ {
+		indices <- i
+	}
+	close(indices)
+	wg.Wait()
+}
+
+// parallelRand is parallel, in which each call is also given a random number
+// generator of n.WorkerRand, seeded with the argument seed of its index. Each
+// worker reuses its own generator, while the results don't depend on the order
+// of the calls, given the seeds.
+func (n *NEAT) parallelRand(seeds []int64, fn func(i int, rng *rand.Rand)) {
+	numWorkers := n.Config.NumWorkers
+	if numWorkers < 1 {
+		numWorkers = 1
+	}
+	rngs := make(chan *rand.Rand, numWorkers)
+	for w := 0; w < numWorkers; w++ {
+		rngs <- n.WorkerRand(0)
+	}
+	n.parallel(len(seeds), func(i int) {
+		rng := <-rngs
+		rng.Seed(seeds[i])
+		fn(i, rng)
+		rngs <- rng
+	})
+}
+
+// EvaluationError is an error of the evaluations of a generation, whose
+// evaluation functions returned errors, indexed by the IDs of the genomes.
+type EvaluationError struct {
+	Generation int           // index of the generation
+	Errors     map[int]error // error of each failed genome
+}
+
+// Error returns the number of failed evaluations, and the error of the genome
+// of the smallest ID.
+func (e *EvaluationError) Error() string {
+	ids := make([]int, 0, len(e.Errors))
+	for id := range e.Errors {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	return fmt.Sprintf("neat: %d evaluations failed in generation %d "+
+		"(genome %d: %v)", len(ids), e.Generation, ids[0], e.Errors[ids[0]])
+}
+
+// Err returns the error of the evaluations of the last generation, or nil if
+// there has been none.
+func (n *NEAT) Err() error {
+	return n.err
+}
+
+// evaluatePopulation evaluates every genome in the population with
+// n.PopulationEvaluation; a genome that results in NaN or infinity, e.g., whose
+// score is missing, is assigned the initial fitness score instead.
+func (n *NEAT) evaluatePopulation() {
+	nets := make([]*NeuralNetwork, len(n.Population))
+	for i, genome := range n.Population {
+		nets[i] = NewNeuralNetwork(genome)
+	}
+	n.PopulationEvaluation.Evaluate(n.Population, nets)
 	for _, genome := range n.Population {
-		genome.Evaluate(n.Evaluation)
+		genome.evaluated = true
+		if reason := nonFiniteReason(genome.Fitness); reason != "" {
+			genome.Fitness = n.Config.InitFitness
+			n.Statistics.RecordFailure(n.Generation, genome.ID, reason)
+		}
+	}
+}
+
+// nonFiniteReason returns the reason of a failed evaluation whose fitness is
+// the argument value: "nan" if it is NaN, "inf" if it is infinite, or an empty
+// string if it is finite.
+func nonFiniteReason(fitness float64) string {
+	switch {
+	case math.IsNaN(fitness):
+		return "nan"
+	case math.IsInf(fitness, 0):
+		return "inf"
 	}
+	return ""
 }
 
 // Speciate performs speciation of each genome. The speciation mechanism is as
@@ -144,148 +503,415 @@ func (n *NEAT) Evaluate() {
 //			Genome Loop
 //		Else STOP
 //
+// Members of the previous generation are removed from each species first,
+// while its representative is kept.
+//
+// Since representatives of the existing species don't change, genomes are
+// compared with them in chunks first, concurrently if n.Config.NumWorkers is
+// greater than 1 (thus, n.Distance must be safe for concurrent use); only
+// genomes that fit none of them are compared with the species created during
+// this speciation, in order.
 func (n *NEAT) Speciate() {
-	for _, genome := range n.Population {
-		registered := false
-		for i := 0; i < len(n.Species) && !registered; i++ {
-			dist := Compatibility(n.Species[i].Representative, genome,
-				n.Config.CoeffUnmatching, n.Config.CoeffMatching)
-
-			if dist <= n.Config.DistanceThreshold {
-				n.Species[i].Register(genome, n.Config.MinimizeFitness)
-				registered = true
-			}
+	for _, s := range n.Species {
+		s.Flush()
+	}
+
+	existing := n.Species
+	matches := make([]int, len(n.Population)) // index of the existing species
+	numChunks := (len(n.Population) + speciationChunk - 1) / speciationChunk
+	n.parallel(numChunks, func(c int) {
+		end := (c + 1) * speciationChunk
+		if end > len(n.Population) {
+			end = len(n.Population)
+		}
+		for i := c * speciationChunk; i < end; i++ {
+			matches[i] = n.compatibleSpecies(existing, n.Population[i])
 		}
+	})
+
+	for i, genome := range n.Population {
+		if j := matches[i]; j >= 0 {
+			existing[j].Register(genome, n.Comparison)
+			continue
+		}
+		created := n.Species[len(existing):]
+		if j := n.compatibleSpecies(created, genome); j >= 0 {
+			created[j].Register(genome, n.Comparison)
+			continue
+		}
+		n.Species = append(n.Species, NewSpecies(n.newSpeciesID(), genome))
+	}
+}
 
-		if !registered {
-			n.Species = append(n.Species, NewSpecies(n.nextSpeciesID, genome))
-			n.nextSpeciesID++
+// speciationChunk is the number of genomes that are compared with the species
+// at once during speciation.
+const speciationChunk = 256
+
+// compatibleSpecies returns the index of the first of the argument species
+// whose representative is compatible with the argument genome, i.e., within
+// n.Config.DistanceThreshold by n.Distance, or -1 if there is none.
+func (n *NEAT) compatibleSpecies(species []*Species, g *Genome) int {
+	for i, s := range species {
+		if n.Distance(s.Representative, g) <= n.Config.DistanceThreshold {
+			return i
 		}
 	}
+	return -1
 }
 
-// Reproduce performs reproduction of genomes in each species. Reproduction is
-// performed under the assumption of speciation being already executed. The
-// number of eliminated genomes in each species is determined by rate of
-// elimination specified in n.Config; after some number of genomes are
-// eliminated, the empty space is filled with resulting genomes of crossover
-// among surviving genomes. If the number of eliminated genomes is 0 or less
-// then 2 genomes survive, every member survives and mutates.
+// newGenomeID returns a new genome ID of n.IDs.
+func (n *NEAT) newGenomeID() int {
+	id := n.IDs.NewGenomeID()
+	if id > n.lastGenomeID {
+		n.lastGenomeID = id
+	}
+	return id
+}
+
+// newSpeciesID returns a new species ID of n.IDs.
+func (n *NEAT) newSpeciesID() int {
+	id := n.IDs.NewSpeciesID()
+	if id > n.lastSpeciesID {
+		n.lastSpeciesID = id
+	}
+	return id
+}
+
+// reserveIDs reserves the argument genome and species IDs in n.IDs, since they
+// are already in use, e.g., by imported genomes.
+func (n *NEAT) reserveIDs(genomeID, speciesID int) {
+	n.IDs.Reserve(genomeID, speciesID)
+	if genomeID > n.lastGenomeID {
+		n.lastGenomeID = genomeID
+	}
+	if speciesID > n.lastSpeciesID {
+		n.lastSpeciesID = speciesID
+	}
+}
+
+// Reproduce replaces the population with the next generation of n.Reproducer,
+// under the assumption of speciation being already executed (see
+// DefaultReproducer for the default policy).
 func (n *NEAT) Reproduce() {
-	nextGeneration := make([]*Genome, 0, n.Config.PopulationSize)
-	for _, s := range n.Species {
-		// genomes in this species can inherit to the next generation, if two or
-		// more genomes survive in this species, and there is room for more
-		// children, i.e., at least one genome must be eliminated.
-		numSurvived := int(math.Ceil(float64(len(s.Members)) *
-			n.Config.SurvivalRate))
-		numEliminated := len(s.Members) - numSurvived
-
-		// reproduction of this species is only executed, if there is enough room.
-		if numSurvived > 2 && numEliminated > 0 {
-			// adjust the fitness of each member genome of this species.
-			//s.ExplicitFitnessSharing()
-
-			sort.Slice(s.Members, func(i, j int) bool {
-				return n.Comparison(s.Members[i], s.Members[j])
-			})
-			s.Members = s.Members[:numSurvived]
-
-			// fill the spaces that are made by eliminated genomes, by creating
-			// children.
-			for i := 0; i < numEliminated; i++ {
-				perm := rand.Perm(numSurvived)
-				p0 := s.Members[perm[0]] // parent 0
-				p1 := s.Members[perm[1]] // parent 1
-
-				// create a child from two chosen parents as a result of crossover;
-				// mutate the child given the rate of mutation of children.
-				child := Crossover(n.nextGenomeID, p0, p1, n.Config.InitFitness)
-				if rand.Float64() < n.Config.RateMutateChild {
-					child.MutatePerturb(n.Config.RatePerturb)
-					child.MutateAddNode(n.Config.RateAddNode, n.randActivationFunc())
-					child.MutateAddConn(n.Config.RateAddConn)
-				} else {
-					// if the two parents are identical, definitely mutate the child.
-					if p0.ID == p1.ID {
-						child.MutatePerturb(n.Config.RatePerturb)
-						child.MutateAddNode(n.Config.RateAddNode, n.randActivationFunc())
-						child.MutateAddConn(n.Config.RateAddConn)
-					}
-				}
-				n.nextGenomeID++
-
-				nextGeneration = append(nextGeneration, child)
-			}
+	n.Population = n.Reproducer.Reproduce(n)
+}
 
-			// mutate all the genomes that survived.
-			for _, genome := range s.Members {
-				genome.MutatePerturb(n.Config.RatePerturb)
-				genome.MutateAddNode(n.Config.RateAddNode, n.randActivationFunc())
-				genome.MutateAddConn(n.Config.RateAddConn)
-				nextGeneration = append(nextGeneration, genome)
-			}
-		} else {
-			// otherwise, they all survive, and mutate.
-			for _, genome := range s.Members {
-				genome.MutatePerturb(n.Config.RatePerturb)
-				genome.MutateAddNode(n.Config.RateAddNode, n.randActivationFunc())
-				genome.MutateAddConn(n.Config.RateAddConn)
-				nextGeneration = append(nextGeneration, genome)
-			}
-		}
+// Offspring returns a new child of the argument parents by crossover, which is
+// mutated given n.Config.RateMutateChild, or definitely if the parents are
+// identical, and recorded in n.Genealogy, if tracked. It is a building block
+// of Reproducer.
+func (n *NEAT) Offspring(p0, p1 *Genome) *Genome {
+	child := crossover(n.newGenomeID(), p0, p1, n.Config.InitFitness, n.Rand)
+	if n.Genealogy != nil {
+		n.Genealogy.Record(child.ID, n.Generation+1, p0.ID, p1.ID)
+	}
+	if n.Rand.Float64() < n.Config.RateMutateChild || p0.ID == p1.ID {
+		n.mutate(child)
+	}
+	return child
+}
 
-		s.Flush()
+// Mutate mutates the argument genome with n.Mutator and the custom mutation
+// operators, and records the mutations. It is a building block of Reproducer.
+func (n *NEAT) Mutate(g *Genome) {
+	n.mutate(g)
+}
+
+// mutation is a record of the mutations applied to a genome.
+type mutation struct {
+	genome   *Genome  // mutated genome
+	applied  []string // names of the mutations applied
+	numConns int      // number of connections before the mutations
+}
+
+// mutate is a helper function that mutates the argument genome with n.Mutator,
+// by default, by perturbing its weights, adding a node, adding a connection,
+// and replacing an activation function, given the rates specified in n.Config,
+// followed by the custom mutation operators.
+func (n *NEAT) mutate(g *Genome) {
+	n.recordMutation(n.mutateWith(g, n.Rand))
+}
+
+// mutateWith is mutate with the argument random number generator, which only
+// modifies the argument genome; the returned mutations are to be recorded
+// with recordMutation.
+func (n *NEAT) mutateWith(g *Genome, rng *rand.Rand) *mutation {
+	m := &mutation{genome: g, numConns: len(g.ConnGenes)}
+	m.applied = n.Mutator.Mutate(g, n.Config, rng)
+	for _, mutator := range n.Mutators {
+		m.applied = append(m.applied, mutator.Mutate(g, n.Config, rng)...)
+	}
+	if len(m.applied) > 0 {
+		g.evaluated = false
+	}
+	return m
+}
+
+// recordMutation records the argument mutations in n.Statistics, in the
+// innovations of this run, and in n.Genealogy, if tracked.
+func (n *NEAT) recordMutation(m *mutation) {
+	n.Statistics.CountMutations(n.Generation, m.applied...)
+	n.Statistics.CountInnovations(n.Generation,
+		n.countInnovations(m.genome.ConnGenes[m.numConns:]))
+
+	// the mutated genome is evaluated in the next generation.
+	if n.Genealogy != nil {
+		n.Genealogy.AddMutations(m.genome.ID, n.Generation+1, m.applied...)
 	}
+}
 
-	// update the population with the new generation
-	n.Population = nextGeneration
+// countInnovations registers the pairs of nodes connected by the argument
+// connections, and returns the number of pairs that were never connected
+// before in this run.
+func (n *NEAT) countInnovations(conns []*ConnGene) int {
+	count := 0
+	for _, conn := range conns {
+		pair := [2]int{conn.From, conn.To}
+		if !n.innovations[pair] {
+			n.innovations[pair] = true
+			count++
+		}
+	}
+	return count
 }
 
-// randActivationFunc is a helper function that returns a random activation
-// function.
-func (n *NEAT) randActivationFunc() *ActivationFunc {
-	return n.Activations[rand.Intn(len(n.Activations))]
+// Step executes a single generation of evolution and returns the best genome
+// so far. It allows the caller to drive the evolution, e.g., to interleave
+// multiple instances of NEAT, or to stop early; Run simply calls Step until
+// the number of generations specified in n.Config is reached.
+func (n *NEAT) Step() *Genome {
+	return n.StepContext(context.Background())
 }
 
-// Run executes evolution and return the best genome.
-func (n *NEAT) Run() *Genome {
-	if n.Config.Verbose {
-		n.Config.Summarize()
+// StepContext is Step whose evaluation is under the argument context (see
+// EvaluateContext).
+func (n *NEAT) StepContext(ctx context.Context) *Genome {
+	i := n.Generation
+	start := time.Now()
+	n.err = n.EvaluateContext(ctx)
+	evaluationTime := time.Since(start)
+
+	// update the best genome; it is copied once, rather than each time a
+	// better genome is found.
+	if champion := n.Champion(); n.Comparison(champion, n.Best) {
+		n.Best = champion.Copy()
 	}
 
-	// for each generation
-	for i := 0; i < n.Config.NumGenerations; i++ {
-		n.Evaluate()
+	n.Statistics.Update(i, n)
+	if n.Validation != nil {
+		n.Statistics.RecordValidation(i, n.validate())
+	}
+	if n.Config.ArchiveChampions || n.Config.ArchiveDir != "" {
+		n.archive(i)
+	}
 
-		// update the best genome
-		for _, genome := range n.Population {
-			if n.Comparison(genome, n.Best) {
-				n.Best = genome.Copy()
+	// advance the curriculum if the best genome reached the target fitness of
+	// the current stage; the new stage is applied from the next generation.
+	if n.Curriculum != nil &&
+		n.Curriculum.Reached(n.Best.Fitness, n.Config.MinimizeFitness) &&
+		n.Curriculum.Advance() {
+		if n.Config.Verbose {
+			n.Logger.Printf("Advanced to curriculum stage %d\n", n.Curriculum.Current)
+		}
+		n.SetEvaluation(n.Curriculum.Stage().Evaluation)
+	}
+
+	// speciate genomes and reproduce children genomes
+	speciationStart := time.Now()
+	n.Speciation(n)
+	speciationTime := time.Since(speciationStart)
+	n.Statistics.UpdateSpecies(i, n.Species)
+	if n.Config.Verbose {
+		n.Summarize(i)
+	}
+
+	reproductionStart := time.Now()
+	n.Reproduce()
+	reproductionTime := time.Since(reproductionStart)
+
+	// eliminate stagnant species
+	if len(n.Species) > 1 {
+		var survived []*Species
+		for j := range n.Species {
+			if n.Species[j].Stagnation <= n.Config.StagnationLimit {
+				n.Species[j].Stagnation++
+				n.Species[j].Age++
+				survived = append(survived, n.Species[j])
 			}
 		}
+		n.Species = survived
+	}
+
+	n.Statistics.UpdateTiming(i, evaluationTime, speciationTime,
+		reproductionTime, time.Since(start))
+	n.Statistics.publish(i)
+	n.Statistics.RunInfo.EndTime = time.Now()
+	for _, callback := range n.Callbacks {
+		callback(n, i)
+	}
+
+	n.Generation++
+	n.publish()
+	return n.Best
+}
+
+// publish updates the copies of the best genome and of the progress that are
+// read by BestSnapshot and Progress, which may be called concurrently.
+func (n *NEAT) publish() {
+	best := n.Best.Copy()
+	progress := Progress{
+		Generation:     n.Generation,
+		NumGenerations: n.Config.NumGenerations,
+		BestFitness:    best.Fitness,
+		NumSpecies:     len(n.Species),
+		Elapsed:        time.Since(n.Statistics.RunInfo.StartTime),
+	}
+	n.mu.Lock()
+	n.snapshot, n.progress = best, progress
+	n.mu.Unlock()
+}
+
+// BestSnapshot returns a copy of the best genome as of the end of the last
+// generation. Unlike Best, it is safe to call from another goroutine while
+// Run executes, e.g., to serve the current champion as the evolution
+// continues.
+func (n *NEAT) BestSnapshot() *Genome {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	return n.snapshot.Copy()
+}
+
+// Progress returns the progress of the evolution as of the end of the last
+// generation. It is safe to call from another goroutine while Run executes.
+func (n *NEAT) Progress() Progress {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	return n.progress
+}
+
+// validate evaluates the top n.ValidationTopK genomes (at least the champion)
+// of the current population by fitness with n.Validation, and returns the best
+// of their validation scores by n.Comparison, as if each score were the fitness
+// of its genome, ignoring NaN (0 if all of them are NaN). Their fitness scores
+// are not affected.
+func (n *NEAT) validate() float64 {
+	topK := n.ValidationTopK
+	if topK < 1 {
+		topK = 1
+	}
+
+	var best *Genome
+	for _, genome := range n.TopK(topK) {
+		score := n.Validation(NewNeuralNetwork(genome))
+		if math.IsNaN(score) || math.IsInf(score, 0) {
+			continue
+		}
+		scored := *genome
+		scored.Fitness = score
+		if best == nil || n.Comparison(&scored, best) {
+			best = &scored
+		}
+	}
+	if best == nil {
+		return 0.0
+	}
+	return best.Fitness
+}
 
-		n.Statistics.Update(i, n)
-		if n.Config.Verbose {
-			n.Summarize(i)
+// Champion returns the best genome of the current population, unlike Best,
+// which is the best genome found so far.
+func (n *NEAT) Champion() *Genome {
+	champion := n.Population[0]
+	for _, genome := range n.Population {
+		if n.Comparison(genome, champion) {
+			champion = genome
 		}
+	}
+	return champion
+}
 
-		// speciate genomes and reproduce children genomes
-		n.Speciate()
-		n.Reproduce()
-
-		// eliminate stagnant species
-		if len(n.Species) > 1 {
-			var survived []*Species
-			for j := range n.Species {
-				if n.Species[j].Stagnation <= n.Config.StagnationLimit {
-					n.Species[j].Stagnation++
-					survived = append(survived, n.Species[j])
-				}
+// TopK returns the best k genomes of the current population, from the best to
+// the worst by n.Comparison, or every genome if there are fewer than k; genomes
+// of equal rank keep their order in the population. Like Champion, they are
+// not copies.
+func (n *NEAT) TopK(k int) []*Genome {
+	ranked := make([]*Genome, len(n.Population))
+	copy(ranked, n.Population)
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return n.Comparison(ranked[i], ranked[j])
+	})
+	if k < 0 {
+		k = 0
+	}
+	if k > len(ranked) {
+		k = len(ranked)
+	}
+	return ranked[:k]
+}
+
+// SpeciesChampions returns the best member of each species by n.Comparison,
+// in the order of n.Species; species without members are skipped. Like
+// Champion, they are not copies.
+func (n *NEAT) SpeciesChampions() []*Genome {
+	champions := make([]*Genome, 0, len(n.Species))
+	for _, s := range n.Species {
+		if len(s.Members) == 0 {
+			continue
+		}
+		champion := s.Members[0]
+		for _, genome := range s.Members {
+			if n.Comparison(genome, champion) {
+				champion = genome
 			}
-			n.Species = survived
+		}
+		champions = append(champions, champion)
+	}
+	return champions
+}
+
+// archive stores a copy of the champion of the argument generation in
+// n.Champions, if enabled in n.Config, and exports it as a JSON file in the
+// archive directory, if specified, along with its provenance; a failed export
+// is logged, and does not stop the evolution.
+func (n *NEAT) archive(gen int) {
+	champion := n.Champion().Copy()
+	if n.Config.ArchiveChampions {
+		n.Champions = append(n.Champions, champion)
+	}
+	if n.Config.ArchiveDir == "" {
+		return
+	}
+
+	exported := champion.Copy()
+	exported.Provenance = n.provenance(champion, gen)
+	if err := n.exportChampion(exported, gen); err != nil {
+		n.Logger.Printf("neat: failed to archive champion of generation %d: %v\n",
+			gen, err)
+	}
+}
+
+// Run executes evolution and return the best genome. If an evaluation returns
+// an error, it stops at the end of the generation, and the error is available
+// via Err; the evolution can be resumed with Step or Run.
+func (n *NEAT) Run() *Genome {
+	return n.RunContext(context.Background())
+}
+
+// RunContext is Run whose evaluations are under the argument context (see
+// EvaluateContext); if the context is canceled, it stops at the end of the
+// current generation, and Err returns the error of the context.
+func (n *NEAT) RunContext(ctx context.Context) *Genome {
+	if n.Config.Verbose {
+		n.Config.SummarizeTo(n.Logger.Writer())
+	}
+
+	// for each generation
+	for n.Generation < n.Config.NumGenerations {
+		if n.StepContext(ctx); n.err != nil {
+			break
 		}
 	}
+	n.Statistics.CloseStream()
 
 	return n.Best
 }
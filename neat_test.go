@@ -1,16 +1,28 @@
+//go:build !js
+
 package neat
 
 import (
+	"bytes"
+	"context"
 	"fmt"
+	"log"
+	"math"
 	"math/rand"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 )
 
 func NEATUnitTest() {
 	fmt.Println("===== NEAT Unit Test =====")
 
-	fmt.Println("\x1b[32m=Testing config JSON file import...\x1b[0m")
-	configXOR, err := NewConfigJSON("config_xor.json")
+	fmt.Println("\x1b[32m=Testing config preset...\x1b[0m")
+	configXOR, err := NewConfigPreset("xor")
 	if err != nil {
 		fmt.Println("\x1b[31mFAIL\x1b[0m")
 	}
@@ -32,7 +44,7 @@ func NEATUnitTest() {
 
 	/*
 		fmt.Println("\x1b[32m=Testing NEAT with pole balancing test...\x1b[0m")
-		configPole, err := NewConfigJSON("config_pole_balancing.json")
+		configPole, err := NewConfigPreset("pole")
 		if err != nil {
 			fmt.Println("\x1b[31mFAIL\x1b[0m")
 		}
@@ -52,3 +64,823 @@ func TestNEAT(t *testing.T) {
 	rand.Seed(0)
 	NEATUnitTest()
 }
+
+func TestNEATOptions(t *testing.T) {
+	config := NewDefaultConfig(3, 1)
+	config.NumGenerations = 3
+	config.PopulationSize = 20
+
+	generations := 0
+	n := New(config, XORTest(),
+		WithRand(rand.New(rand.NewSource(0))),
+		WithComparison(NewComparisonFunc(true)),
+		WithCallback(func(n *NEAT, gen int) {
+			generations++
+		}))
+	n.Run()
+
+	buf := &bytes.Buffer{}
+	n.SummarizeTo(buf, config.NumGenerations-1)
+	if buf.Len() == 0 {
+		t.Error("summary is not written")
+	}
+
+	if generations != config.NumGenerations {
+		t.Errorf("invalid number of callbacks: %d != %d",
+			generations, config.NumGenerations)
+	}
+}
+
+func TestNEATLogger(t *testing.T) {
+	config := NewDefaultConfig(3, 1)
+	config.NumGenerations = 2
+	config.PopulationSize = 20
+	config.Verbose = true
+
+	buf := &bytes.Buffer{}
+	New(config, XORTest(), WithLogger(log.New(buf, "", 0))).Run()
+	if !bytes.Contains(buf.Bytes(), []byte("Population size")) ||
+		!bytes.Contains(buf.Bytes(), []byte("Gen.    1")) {
+		t.Errorf("verbose output is not written to the logger:\n%s", buf)
+	}
+}
+
+func TestNEATRunInfo(t *testing.T) {
+	config := NewDefaultConfig(3, 1)
+	config.NumGenerations = 2
+	config.PopulationSize = 20
+
+	n := New(config, XORTest(), WithSeed(42))
+	n.Run()
+
+	info := n.Statistics.RunInfo
+	if info.Seed != 42 {
+		t.Errorf("invalid seed: %d != 42", info.Seed)
+	}
+	if info.ConfigHash != ConfigHash(config) || info.ConfigHash == "" {
+		t.Errorf("invalid config hash: %s", info.ConfigHash)
+	}
+	if info.Duration() < 0 {
+		t.Errorf("invalid duration: %s", info.Duration())
+	}
+}
+
+func TestNEATActivationPools(t *testing.T) {
+	config := NewDefaultConfig(3, 2)
+	config.PopulationSize = 20
+	config.RateAddNode = 1.0
+	config.RateMutateActivation = 1.0
+	config.HiddenActivations = []string{"relu", "sin"}
+	config.OutputActivations = []string{"tanh"}
+
+	n := New(config, XORTest(), WithSeed(0))
+	for _, genome := range n.Population {
+		n.mutate(genome)
+		n.mutate(genome)
+		for _, node := range genome.NodeGenes {
+			if node.Type == "input" {
+				continue
+			}
+			name := node.Activation.Name
+			if node.Type == "output" && name != "Tanh" {
+				t.Errorf("output node with %s activation", name)
+			}
+			if node.Type == "hidden" && name != "ReLU" && name != "Sine" {
+				t.Errorf("hidden node with %s activation", name)
+			}
+		}
+	}
+}
+
+func TestNEATUnknownActivation(t *testing.T) {
+	config := NewDefaultConfig(3, 1)
+	config.HiddenActivations = []string{"sigmod"}
+	if _, err := NewActivationRegistry(config); err == nil {
+		t.Error("unknown activation function is not reported")
+	}
+	if err := config.Validate(); err == nil ||
+		!strings.Contains(err.Error(), "sigmod") {
+		t.Errorf("unknown activation function is not a violation: %v", err)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("New does not panic on an unknown activation function")
+		}
+	}()
+	New(config, XORTest(), WithSeed(0))
+}
+
+func TestNEATRandomSeed(t *testing.T) {
+	config := NewDefaultConfig(3, 1)
+	config.NumGenerations = 10
+	config.PopulationSize = 50
+	config.RateAddNode = 0.2
+	config.RateAddConn = 0.2
+	config.RandomSeed = 7
+
+	// the same seed must reproduce the same evolution.
+	best0 := New(config, XORTest()).Run()
+	best1 := New(config, XORTest()).Run()
+	if best0.Fitness != best1.Fitness || len(best0.ConnGenes) != len(best1.ConnGenes) {
+		t.Errorf("runs with the same seed differ: %f != %f",
+			best0.Fitness, best1.Fitness)
+	}
+
+	config.RandomSeed = 0
+	if n := New(config, XORTest()); n.Statistics.RunInfo.Seed == 0 {
+		t.Error("seed from time is not recorded")
+	}
+}
+
+func TestNEATParallelReproduction(t *testing.T) {
+	config := NewDefaultConfig(3, 1)
+	config.NumGenerations = 10
+	config.PopulationSize = 50
+	config.RateAddNode = 0.2
+	config.RateAddConn = 0.2
+	config.RandomSeed = 7
+	config.TrackLineage = true
+
+	// the same seed must reproduce the same genomes, regardless of the number
+	// of workers; fitness scores may differ by rounding errors, since synapses
+	// of a neuron are summed in no particular order.
+	var runs []*NEAT
+	for _, numWorkers := range []int{2, 4} {
+		config.NumWorkers = numWorkers
+		n := New(config, XORTest())
+		n.Run()
+		runs = append(runs, n)
+	}
+	for i, g := range runs[0].Population {
+		h := runs[1].Population[i]
+		if g.ID != h.ID || len(g.ConnGenes) != len(h.ConnGenes) {
+			t.Fatalf("runs with the same seed differ at genome %d", i)
+		}
+		for j, conn := range g.ConnGenes {
+			if *conn != *h.ConnGenes[j] {
+				t.Fatalf("runs with the same seed differ at genome %d", i)
+			}
+		}
+	}
+
+	n := runs[0]
+	if len(n.Population) != config.PopulationSize {
+		t.Errorf("invalid population size: %d != %d",
+			len(n.Population), config.PopulationSize)
+	}
+	ids := make(map[int]bool)
+	for _, g := range n.Population {
+		if ids[g.ID] {
+			t.Errorf("duplicate genome ID: %d", g.ID)
+		}
+		ids[g.ID] = true
+		if n.Genealogy.Records[g.ID] == nil {
+			t.Errorf("genome %d is not recorded in the genealogy", g.ID)
+		}
+	}
+}
+
+func TestNEATDistance(t *testing.T) {
+	config := NewDefaultConfig(3, 1)
+	config.PopulationSize = 20
+	config.DistanceThreshold = 0.5
+
+	// genomes are only compatible if they have the same number of nodes.
+	nodes := func(g0, g1 *Genome) float64 {
+		return math.Abs(float64(len(g0.NodeGenes) - len(g1.NodeGenes)))
+	}
+	n := New(config, XORTest(), WithSeed(0), WithDistance(nodes))
+	sigmoid := ActivationSet["sigmoid"]
+	for i, genome := range n.Population {
+		for j := 0; j < i%3; j++ {
+			id := len(genome.NodeGenes)
+			genome.NodeGenes = append(genome.NodeGenes,
+				NewNodeGene(id, "hidden", sigmoid))
+		}
+	}
+	n.Speciate()
+	if len(n.Species) != 3 {
+		t.Fatalf("invalid number of species: %d != 3", len(n.Species))
+	}
+	for _, s := range n.Species {
+		for _, genome := range s.Members {
+			if nodes(genome, s.Representative) != 0.0 {
+				t.Errorf("genome %d is not compatible with species %d", genome.ID,
+					s.ID)
+			}
+		}
+	}
+}
+
+func TestNEATSpeciateChunks(t *testing.T) {
+	config := NewDefaultConfig(3, 1)
+	config.PopulationSize = 3*speciationChunk + 1
+	config.DistanceThreshold = 0.5
+	config.RandomSeed = 1
+
+	// speciation in concurrent chunks must be identical to the sequential one.
+	var speciesIDs [][]int
+	for _, numWorkers := range []int{1, 4} {
+		config.NumWorkers = numWorkers
+		n := New(config, XORTest())
+		for _, genome := range n.Population {
+			genome.mutatePerturb(1.0, n.Rand)
+		}
+		n.Speciate()
+		ids := make([]int, len(n.Population))
+		for i, genome := range n.Population {
+			ids[i] = genome.SpeciesID
+		}
+		speciesIDs = append(speciesIDs, ids)
+		if len(n.Species) < 2 {
+			t.Fatalf("too few species: %d", len(n.Species))
+		}
+	}
+	for i, id := range speciesIDs[0] {
+		if speciesIDs[1][i] != id {
+			t.Fatalf("species of genome %d differ: %d != %d",
+				i, speciesIDs[1][i], id)
+		}
+	}
+}
+
+func TestRandomPair(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	counts := make([]int, 3)
+	for i := 0; i < 3000; i++ {
+		i0, i1 := randomPair(3, rng)
+		if i0 == i1 || i0 < 0 || i1 < 0 || i0 >= 3 || i1 >= 3 {
+			t.Fatalf("invalid pair: (%d, %d)", i0, i1)
+		}
+		counts[i0]++
+		counts[i1]++
+	}
+	for i, count := range counts {
+		if count < 1800 || count > 2200 {
+			t.Errorf("index %d is chosen %d times out of 6000", i, count)
+		}
+	}
+}
+
+func TestNEATArchive(t *testing.T) {
+	config := NewDefaultConfig(3, 1)
+	config.NumGenerations = 3
+	config.PopulationSize = 20
+	config.ArchiveChampions = true
+	config.ArchiveDir = t.TempDir()
+
+	n := New(config, XORTest(), WithRand(rand.New(rand.NewSource(0))))
+	n.Run()
+
+	if len(n.Champions) != config.NumGenerations {
+		t.Fatalf("invalid number of champions: %d != %d",
+			len(n.Champions), config.NumGenerations)
+	}
+	for gen, champion := range n.Champions {
+		if champion.Fitness != n.Statistics.MaxFitness[gen] {
+			t.Errorf("invalid champion of generation %d: %f != %f",
+				gen, champion.Fitness, n.Statistics.MaxFitness[gen])
+		}
+	}
+
+	g, err := NewGenomeJSON(filepath.Join(config.ArchiveDir,
+		"champion_0002.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g.ID != n.Champions[2].ID {
+		t.Errorf("invalid archived champion: %d != %d", g.ID, n.Champions[2].ID)
+	}
+	if g.Provenance == nil {
+		t.Fatal("provenance of archived champion is missing")
+	}
+	if g.Provenance.Generation != 2 {
+		t.Errorf("invalid generation: %d != 2", g.Provenance.Generation)
+	}
+	if g.Provenance.RunInfo.ID != n.Statistics.RunInfo.ID {
+		t.Errorf("invalid run ID: %s != %s",
+			g.Provenance.RunInfo.ID, n.Statistics.RunInfo.ID)
+	}
+}
+
+func TestNEATExportGenomeJSON(t *testing.T) {
+	config := NewDefaultConfig(3, 1)
+	config.NumGenerations = 2
+	config.PopulationSize = 20
+	config.TrackLineage = true
+
+	n := New(config, XORTest(), WithRand(rand.New(rand.NewSource(0))))
+	n.Run()
+
+	filename := filepath.Join(t.TempDir(), "best.json")
+	f, err := os.Create(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = n.ExportGenomeJSON(f, n.Best, true); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	g, err := NewGenomeJSON(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := g.Provenance
+	if p == nil {
+		t.Fatal("provenance is missing")
+	}
+	if p.Generation != config.NumGenerations-1 {
+		t.Errorf("invalid generation: %d != %d",
+			p.Generation, config.NumGenerations-1)
+	}
+	if p.RunInfo.ID == "" || p.RunInfo.ID != n.Statistics.RunInfo.ID {
+		t.Errorf("invalid run ID: %q", p.RunInfo.ID)
+	}
+	if p.Config.PopulationSize != config.PopulationSize {
+		t.Errorf("invalid config: %d != %d",
+			p.Config.PopulationSize, config.PopulationSize)
+	}
+	if p.AncestryHash == "" {
+		t.Error("ancestry hash is missing")
+	}
+	if n.Best.Provenance != nil {
+		t.Error("provenance is attached to the original genome")
+	}
+}
+
+func TestNEATProgress(t *testing.T) {
+	config := NewDefaultConfig(3, 1)
+	config.NumGenerations = 3
+	config.PopulationSize = 20
+
+	buf := &bytes.Buffer{}
+	n := New(config, XORTest(), WithProgress(buf))
+	n.Run()
+
+	reports := strings.Split(buf.String(), "\r")
+	if len(reports) != config.NumGenerations+1 {
+		t.Fatalf("invalid number of reports: %d != %d",
+			len(reports), config.NumGenerations+1)
+	}
+	last := reports[len(reports)-1]
+	if !strings.HasPrefix(last, "Gen.    3/3") || !strings.HasSuffix(last, "\n") {
+		t.Errorf("invalid last report: %q", last)
+	}
+}
+
+func TestProgressReporterRate(t *testing.T) {
+	config := NewDefaultConfig(3, 1)
+	config.PopulationSize = 20
+
+	// 20 genomes are evaluated in 2 seconds of a generation of 10 seconds.
+	n := New(config, XORTest())
+	n.Statistics.UpdateTiming(0, 2*time.Second, time.Second, time.Second,
+		10*time.Second)
+
+	buf := &bytes.Buffer{}
+	NewProgressReporter(buf)(n, 0)
+	if !strings.Contains(buf.String(), " 10.0 evals/s") {
+		t.Errorf("invalid rate of evaluations: %q", buf.String())
+	}
+}
+
+func TestNEATSpeciesSummaries(t *testing.T) {
+	config := NewDefaultConfig(3, 1)
+	config.NumGenerations = 3
+	config.PopulationSize = 20
+	config.Verbose = true
+
+	buf := &bytes.Buffer{}
+	n := New(config, XORTest(), WithSeed(1), WithLogger(log.New(buf, "", 0)),
+		WithCallback(func(n *NEAT, gen int) {
+			size := 0
+			for _, s := range n.SpeciesSummaries() {
+				size += s.Size
+			}
+			if size != config.PopulationSize {
+				t.Errorf("invalid total size of species: %d != %d",
+					size, config.PopulationSize)
+			}
+		}))
+	n.Run()
+
+	if !strings.Contains(buf.String(), "Species    0 | Size:") {
+		t.Errorf("species are not summarized:\n%s", buf.String())
+	}
+}
+
+func TestNEATMutators(t *testing.T) {
+	config := NewDefaultConfig(3, 1)
+	config.NumGenerations = 2
+	config.PopulationSize = 20
+	config.RateMutateChild = 1.0
+
+	count := 0
+	n := New(config, XORTest(), WithSeed(0),
+		WithMutation("noop", func(g *Genome, rng *rand.Rand) bool {
+			count++
+			return count%2 == 0
+		}))
+	n.Run()
+	if count == 0 {
+		t.Fatal("custom mutation is not applied")
+	}
+	recorded := 0
+	for _, counts := range n.Statistics.MutationCounts {
+		recorded += counts["noop"]
+	}
+	if recorded != count/2 {
+		t.Errorf("invalid count of custom mutations: %d != %d", recorded, count/2)
+	}
+}
+
+// weightMutator is a Mutator that only sets the weight of every connection.
+type weightMutator struct {
+	weight float64 // weight of connections
+}
+
+func (m *weightMutator) Mutate(g *Genome, config *Config,
+	rng *rand.Rand) []string {
+	for _, conn := range g.ConnGenes {
+		conn.Weight = m.weight
+	}
+	return []string{"weight"}
+}
+
+func TestNEATMutator(t *testing.T) {
+	config := NewDefaultConfig(3, 1)
+	config.NumGenerations = 3
+	config.PopulationSize = 20
+	config.RateAddNode = 1.0
+	config.RateMutateChild = 1.0
+
+	n := New(config, XORTest(), WithSeed(0),
+		WithMutator(&weightMutator{weight: 0.5}),
+		WithMutation("noop", func(g *Genome, rng *rand.Rand) bool {
+			return true
+		}))
+	n.Run()
+
+	for _, genome := range n.Population {
+		if len(genome.NodeGenes) != 4 {
+			t.Fatalf("built-in mutation is applied: %d nodes",
+				len(genome.NodeGenes))
+		}
+		for _, conn := range genome.ConnGenes {
+			if conn.Weight != 0.5 {
+				t.Fatalf("custom mutator is not applied: %f", conn.Weight)
+			}
+		}
+	}
+	counts := n.Statistics.MutationCounts[0]
+	if counts["weight"] == 0 || counts["noop"] != counts["weight"] ||
+		counts["addNode"] != 0 {
+		t.Errorf("invalid mutation counts: %v", counts)
+	}
+
+	if _, ok := New(config, XORTest()).Mutator.(*DefaultMutator); !ok {
+		t.Error("built-in mutations are not the default")
+	}
+}
+
+func TestNEATComparison(t *testing.T) {
+	config := NewDefaultConfig(3, 1)
+	config.NumGenerations = 5
+	config.PopulationSize = 20
+	config.RateAddNode = 0.5
+	config.ArchiveChampions = true
+
+	// prefer the smallest genome, and break ties by fitness.
+	better := NewLexicographicComparisonFunc(SmallerGenome,
+		NewComparisonFunc(false))
+	n := New(config, XORTest(), WithSeed(0), WithComparison(better))
+	n.Run()
+
+	champion := n.Champion()
+	for _, genome := range n.Population {
+		if better(genome, champion) {
+			t.Fatalf("genome %d is better than the champion %d", genome.ID,
+				champion.ID)
+		}
+	}
+	if better(champion, n.Best) {
+		t.Errorf("champion %d is better than the best genome %d", champion.ID,
+			n.Best.ID)
+	}
+	for i, genome := range n.Champions {
+		if better(genome, n.Best) {
+			t.Errorf("champion of generation %d is better than the best genome", i)
+		}
+	}
+}
+
+func TestSpeciesRegister(t *testing.T) {
+	large, small := NewGenome(0, 3, 1, 2.0), NewGenome(1, 1, 1, 1.0)
+	s := NewSpecies(0, large)
+	s.Stagnation = 3
+
+	// the smaller genome is not better by fitness, but it is by the comparison.
+	s.Register(small, NewComparisonFunc(false))
+	if s.Best.ID != large.ID || s.Stagnation != 3 {
+		t.Errorf("worse genome replaced the best genome: %d", s.Best.ID)
+	}
+	s.Register(small, NewLexicographicComparisonFunc(SmallerGenome,
+		NewComparisonFunc(false)))
+	if s.Best.ID != small.ID || s.BestFitness != small.Fitness ||
+		s.Stagnation != 0 {
+		t.Errorf("better genome did not replace the best genome: %d", s.Best.ID)
+	}
+}
+
+func TestNEATBestSnapshot(t *testing.T) {
+	config := NewDefaultConfig(3, 1)
+	config.NumGenerations = 10
+	config.PopulationSize = 20
+
+	n := New(config, XORTest(), WithSeed(0))
+	if p := n.Progress(); p.Generation != 0 || p.NumGenerations != 10 {
+		t.Errorf("invalid initial progress: %+v", p)
+	}
+
+	// the champion is served from another goroutine while the evolution runs.
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		last := 0
+		for last < config.NumGenerations {
+			p := n.Progress()
+			if p.Generation < last {
+				t.Errorf("progress went back: %d < %d", p.Generation, last)
+				return
+			}
+			last = p.Generation
+			net := NewNeuralNetwork(n.BestSnapshot())
+			if _, err := net.FeedForward([]float64{1.0, 0.0, 1.0}); err != nil {
+				t.Error(err)
+				return
+			}
+		}
+	}()
+	n.Run()
+	<-done
+
+	best := n.BestSnapshot()
+	if best.ID != n.Best.ID || best.Fitness != n.Best.Fitness || best == n.Best {
+		t.Errorf("invalid snapshot of the best genome %d", best.ID)
+	}
+	if p := n.Progress(); p.Generation != config.NumGenerations ||
+		p.BestFitness != n.Best.Fitness || p.NumSpecies != len(n.Species) {
+		t.Errorf("invalid final progress: %+v", p)
+	}
+}
+
+func TestNEATTopK(t *testing.T) {
+	config := NewDefaultConfig(3, 1)
+	config.PopulationSize = 20
+	config.DistanceThreshold = 0.5
+
+	n := New(config, XORTest(), WithSeed(0))
+	for i, genome := range n.Population {
+		genome.Fitness = float64((i * 7) % len(n.Population))
+		genome.mutatePerturb(1.0, n.Rand)
+	}
+	n.Speciate()
+
+	top := n.TopK(3)
+	if len(top) != 3 || top[0] != n.Champion() {
+		t.Fatalf("invalid top genomes: %d", len(top))
+	}
+	for i, want := range []float64{19.0, 18.0, 17.0} {
+		if top[i].Fitness != want {
+			t.Errorf("invalid fitness of genome %d: %f != %f", i, top[i].Fitness,
+				want)
+		}
+	}
+	if len(n.TopK(100)) != len(n.Population) || len(n.TopK(-1)) != 0 {
+		t.Error("invalid number of top genomes")
+	}
+
+	champions := n.SpeciesChampions()
+	if len(champions) != len(n.Species) || len(champions) < 2 {
+		t.Fatalf("invalid number of species champions: %d", len(champions))
+	}
+	for i, s := range n.Species {
+		if champions[i].SpeciesID != s.ID {
+			t.Errorf("champion %d is not of species %d", champions[i].ID, s.ID)
+		}
+		for _, genome := range s.Members {
+			if genome.Fitness > champions[i].Fitness {
+				t.Errorf("genome %d is better than the champion of species %d",
+					genome.ID, s.ID)
+			}
+		}
+	}
+}
+
+// steadyStateReproducer is a Reproducer that only replaces the worst genome of
+// the population with a child of the two best genomes.
+type steadyStateReproducer struct {
+	calls int // number of calls of Reproduce
+}
+
+func (r *steadyStateReproducer) Reproduce(n *NEAT) []*Genome {
+	r.calls++
+	population := make([]*Genome, len(n.Population))
+	copy(population, n.Population)
+	sort.Slice(population, func(i, j int) bool {
+		return n.Comparison(population[i], population[j])
+	})
+	child := n.Offspring(population[0], population[1])
+	population[len(population)-1] = child
+	return population
+}
+
+func TestNEATReproducer(t *testing.T) {
+	config := NewDefaultConfig(3, 1)
+	config.NumGenerations = 4
+	config.PopulationSize = 20
+
+	r := &steadyStateReproducer{}
+	n := New(config, XORTest(), WithSeed(0), WithReproducer(r))
+	initial := make(map[int]bool)
+	for _, genome := range n.Population {
+		initial[genome.ID] = true
+	}
+	n.Run()
+	if n.Err() != nil {
+		t.Fatal(n.Err())
+	}
+
+	if r.calls != config.NumGenerations {
+		t.Errorf("invalid number of reproductions: %d", r.calls)
+	}
+	if len(n.Population) != config.PopulationSize {
+		t.Fatalf("invalid population size: %d", len(n.Population))
+	}
+	survived := 0
+	for _, genome := range n.Population {
+		if initial[genome.ID] {
+			survived++
+		}
+	}
+	if survived != config.PopulationSize-r.calls {
+		t.Errorf("invalid number of survivors: %d", survived)
+	}
+
+	if _, ok := New(config, XORTest()).Reproducer.(DefaultReproducer); !ok {
+		t.Error("default reproduction is not the default")
+	}
+}
+
+func TestNEATFallibleEvaluation(t *testing.T) {
+	config := NewDefaultConfig(3, 1)
+	config.NumGenerations = 5
+	config.PopulationSize = 20
+	config.InitFitness = -1.0
+
+	// the simulator crashes in the second generation.
+	calls := 0
+	crashing := func(nn *NeuralNetwork) (float64, error) {
+		calls++
+		if calls > config.PopulationSize {
+			return 0.0, fmt.Errorf("simulator crashed")
+		}
+		return 1.0, nil
+	}
+	n := New(config, nil, WithSeed(0), WithFallibleEvaluation(crashing))
+	n.Run()
+	if n.Generation != 2 {
+		t.Errorf("evolution did not stop after the failure: %d", n.Generation)
+	}
+	err, ok := n.Err().(*EvaluationError)
+	if !ok || err.Generation != 1 || len(err.Errors) == 0 {
+		t.Fatalf("invalid error: %v", n.Err())
+	}
+	failures := n.Statistics.Failures[1]
+	if len(failures) != len(err.Errors) || failures[0].Reason != "error" ||
+		failures[0].Message != "simulator crashed" {
+		t.Errorf("invalid failures: %+v", failures)
+	}
+	if n.Statistics.MinFitness[1] != config.InitFitness {
+		t.Errorf("the fitness of a failed genome is not replaced: %f",
+			n.Statistics.MinFitness[1])
+	}
+
+	// the built-in tasks do not stop the process with an invalid network.
+	if fitness := XORTest()(NewNeuralNetwork(NewGenome(0, 2, 1, 0.0))); !math.IsNaN(fitness) {
+		t.Errorf("invalid fitness of an invalid network: %f", fitness)
+	}
+}
+
+func TestNEATContextEvaluation(t *testing.T) {
+	config := NewDefaultConfig(3, 1)
+	config.NumGenerations = 2
+	config.PopulationSize = 10
+	config.InitFitness = -1.0
+	config.EvaluationTimeout = 0.01
+
+	// every other simulation hangs until it is canceled.
+	var calls int32
+	hanging := func(ctx context.Context, nn *NeuralNetwork) (float64, error) {
+		if atomic.AddInt32(&calls, 1)%2 == 0 {
+			<-ctx.Done()
+			return 0.0, ctx.Err()
+		}
+		return 1.0, nil
+	}
+	n := New(config, nil, WithSeed(0), WithContextEvaluation(hanging))
+	n.Run()
+	if n.Generation != config.NumGenerations || n.Err() != nil {
+		t.Fatalf("timeouts stopped the evolution: %d, %v", n.Generation, n.Err())
+	}
+	failures := n.Statistics.Failures[0]
+	if len(failures) != config.PopulationSize/2 || failures[0].Reason != "timeout" {
+		t.Errorf("invalid failures: %+v", failures)
+	}
+	if n.Statistics.MinFitness[0] != config.InitFitness {
+		t.Errorf("the fitness of a runaway genome is not replaced: %f",
+			n.Statistics.MinFitness[0])
+	}
+
+	// a canceled run stops after the current generation.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	n = New(config, XORTest(), WithSeed(0))
+	n.RunContext(ctx)
+	if n.Generation != 1 || n.Err() != context.Canceled {
+		t.Errorf("canceled run did not stop: %d, %v", n.Generation, n.Err())
+	}
+}
+
+// sizeEvaluator is a population evaluator that ranks genomes by their number of
+// connection genes, except the first genome, which it fails to evaluate.
+type sizeEvaluator struct {
+	calls int
+}
+
+func (e *sizeEvaluator) Evaluate(genomes []*Genome, nets []*NeuralNetwork) {
+	e.calls++
+	for i, genome := range genomes {
+		genome.Fitness = float64(nets[i].NumSynapses())
+	}
+	genomes[0].Fitness = math.NaN()
+}
+
+func TestNEATPopulationEvaluator(t *testing.T) {
+	config := NewDefaultConfig(3, 1)
+	config.NumGenerations = 3
+	config.PopulationSize = 10
+	config.InitFitness = -1.0
+
+	evaluator := &sizeEvaluator{}
+	n := New(config, nil, WithSeed(0), WithPopulationEvaluator(evaluator))
+	n.Run()
+	if evaluator.calls != config.NumGenerations {
+		t.Errorf("invalid number of evaluations: %d", evaluator.calls)
+	}
+	for gen, failures := range n.Statistics.Failures {
+		if len(failures) != 1 || failures[0].Reason != "nan" {
+			t.Errorf("invalid failures in generation %d: %+v", gen, failures)
+		}
+	}
+	if n.Best.Fitness < 3.0 {
+		t.Errorf("invalid best fitness: %f", n.Best.Fitness)
+	}
+}
+
+func TestNEATValidation(t *testing.T) {
+	config := NewDefaultConfig(3, 1)
+	config.NumGenerations = 3
+	config.PopulationSize = 10
+
+	var calls int
+	validation := func(n *NeuralNetwork) float64 {
+		calls++
+		return float64(calls)
+	}
+	n := New(config, XORTest(), WithSeed(0), WithValidation(validation, 2))
+	n.Run()
+	if calls != 2*config.NumGenerations {
+		t.Errorf("invalid number of validations: %d", calls)
+	}
+	for gen, fitness := range n.Statistics.ValidationFitness {
+		if fitness != float64(2*gen+2) {
+			t.Errorf("invalid validation fitness in generation %d: %f",
+				gen, fitness)
+		}
+	}
+
+	// the best validation score is chosen by the comparison function.
+	calls = 0
+	n = New(config, XORTest(), WithSeed(0), WithValidation(validation, 2),
+		WithComparison(NewComparisonFunc(true)))
+	n.Run()
+	for gen, fitness := range n.Statistics.ValidationFitness {
+		if fitness != float64(2*gen+1) {
+			t.Errorf("invalid minimized validation fitness in generation %d: %f",
+				gen, fitness)
+		}
+	}
+}
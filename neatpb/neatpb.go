@@ -0,0 +1,324 @@
+// neatpb.go implementation of protocol buffers encoding of genomes,
+// populations, and checkpoints.
+//
+// Copyright (C) 2017  Jin Yeom
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+// Package neatpb encodes genomes, populations, and checkpoints of the neat
+// package in the protocol buffers wire format of the schema in neat.proto, so
+// that other tools and languages can read and write them with code generated
+// from the schema. The encoding is implemented without dependencies on a
+// protocol buffers runtime; unknown fields are skipped, such that messages of
+// newer versions of the schema can be read.
+package neatpb
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/jinyeom/neat"
+)
+
+// MarshalGenome returns the encoding of the argument genome as a Genome
+// message.
+func MarshalGenome(g *neat.Genome) []byte {
+	e := &encoder{}
+	encodeGenome(e, g)
+	return e.buf
+}
+
+// UnmarshalGenome returns the genome of the argument Genome message, whose
+// activation functions are restored from neat.ActivationSet by their names.
+// It returns an error if the genome is invalid or a function is unknown.
+func UnmarshalGenome(b []byte) (*neat.Genome, error) {
+	g := &neat.Genome{}
+	if err := decodeMessage(b, genomeField(g)); err != nil {
+		return nil, err
+	}
+	if err := neat.RestoreGenome(g); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+// MarshalPopulation returns the encoding of the argument genomes as a
+// Population message.
+func MarshalPopulation(genomes []*neat.Genome) []byte {
+	e := &encoder{}
+	for _, g := range genomes {
+		e.message(1, func(m *encoder) { encodeGenome(m, g) })
+	}
+	return e.buf
+}
+
+// UnmarshalPopulation returns the genomes of the argument Population message.
+func UnmarshalPopulation(b []byte) ([]*neat.Genome, error) {
+	var genomes []*neat.Genome
+	err := decodeMessage(b, func(d *decoder) error {
+		if d.field != 1 {
+			return d.skip()
+		}
+		g := &neat.Genome{}
+		genomes = append(genomes, g)
+		return d.message(genomeField(g))
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err = restoreGenomes(genomes); err != nil {
+		return nil, err
+	}
+	return genomes, nil
+}
+
+// MarshalCheckpoint returns the encoding of the argument checkpoint as a
+// Checkpoint message, whose configuration is encoded in JSON.
+func MarshalCheckpoint(c *neat.Checkpoint) ([]byte, error) {
+	config, err := json.Marshal(c.Config)
+	if err != nil {
+		return nil, err
+	}
+
+	e := &encoder{}
+	e.bytes(1, config)
+	e.int32(2, c.Generation)
+	for _, g := range c.Population {
+		e.message(3, func(m *encoder) { encodeGenome(m, g) })
+	}
+	for _, s := range c.Species {
+		e.message(4, func(m *encoder) {
+			m.int32(1, s.ID)
+			m.int32(2, s.Age)
+			m.int32(3, s.Stagnation)
+			if s.Representative != nil {
+				m.message(4, func(r *encoder) { encodeGenome(r, s.Representative) })
+			}
+			m.double(5, s.BestFitness)
+			if s.Best != nil {
+				m.message(6, func(b *encoder) { encodeGenome(b, s.Best) })
+			}
+		})
+	}
+	if c.Best != nil {
+		e.message(5, func(m *encoder) { encodeGenome(m, c.Best) })
+	}
+	e.int32(6, c.NextGenomeID)
+	e.int32(7, c.NextSpeciesID)
+	for _, pair := range c.Innovations {
+		e.message(8, func(m *encoder) {
+			m.int32(1, pair[0])
+			m.int32(2, pair[1])
+		})
+	}
+	return e.buf, nil
+}
+
+// UnmarshalCheckpoint returns the checkpoint of the argument Checkpoint
+// message, from which a run can be resumed with NEAT.Restore.
+func UnmarshalCheckpoint(b []byte) (*neat.Checkpoint, error) {
+	c := &neat.Checkpoint{}
+	err := decodeMessage(b, func(d *decoder) (err error) {
+		switch d.field {
+		case 1:
+			var config []byte
+			if config, err = d.bytes(); err == nil {
+				c.Config = &neat.Config{}
+				err = json.Unmarshal(config, c.Config)
+			}
+		case 2:
+			c.Generation, err = d.int32()
+		case 3:
+			g := &neat.Genome{}
+			c.Population = append(c.Population, g)
+			err = d.message(genomeField(g))
+		case 4:
+			s := &neat.Species{Members: []*neat.Genome{}}
+			c.Species = append(c.Species, s)
+			err = d.message(speciesField(s))
+		case 5:
+			c.Best = &neat.Genome{}
+			err = d.message(genomeField(c.Best))
+		case 6:
+			c.NextGenomeID, err = d.int32()
+		case 7:
+			c.NextSpeciesID, err = d.int32()
+		case 8:
+			var pair [2]int
+			err = d.message(func(m *decoder) (err error) {
+				switch m.field {
+				case 1:
+					pair[0], err = m.int32()
+				case 2:
+					pair[1], err = m.int32()
+				default:
+					err = m.skip()
+				}
+				return err
+			})
+			c.Innovations = append(c.Innovations, pair)
+		default:
+			err = d.skip()
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if c.Config == nil || len(c.Population) == 0 || c.Best == nil {
+		return nil, errors.New("neatpb: incomplete checkpoint")
+	}
+	genomes := append([]*neat.Genome{c.Best}, c.Population...)
+	for _, s := range c.Species {
+		if s.Representative != nil {
+			genomes = append(genomes, s.Representative)
+		}
+		if s.Best != nil {
+			genomes = append(genomes, s.Best)
+		}
+	}
+	if err = restoreGenomes(genomes); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// encodeGenome encodes the fields of the argument genome.
+func encodeGenome(e *encoder, g *neat.Genome) {
+	e.int32(1, g.ID)
+	e.int32(2, g.SpeciesID)
+	for _, node := range g.NodeGenes {
+		e.message(3, func(m *encoder) {
+			m.int32(1, node.ID)
+			m.string(2, node.Type)
+			if node.Activation != nil {
+				m.string(3, node.Activation.Name)
+			}
+		})
+	}
+	for _, conn := range g.ConnGenes {
+		e.message(4, func(m *encoder) {
+			m.int32(1, conn.From)
+			m.int32(2, conn.To)
+			m.double(3, conn.Weight)
+			m.bool(4, conn.Disabled)
+		})
+	}
+	e.double(5, g.Fitness)
+	e.doubles(6, g.Objectives)
+	e.doubles(7, g.Behavior)
+	e.doubleMap(8, g.Metrics)
+}
+
+// genomeField returns a function that decodes a field of a Genome message into
+// the argument genome.
+func genomeField(g *neat.Genome) func(d *decoder) error {
+	return func(d *decoder) (err error) {
+		switch d.field {
+		case 1:
+			g.ID, err = d.int32()
+		case 2:
+			g.SpeciesID, err = d.int32()
+		case 3:
+			node := &neat.NodeGene{}
+			g.NodeGenes = append(g.NodeGenes, node)
+			err = d.message(func(m *decoder) (err error) {
+				switch m.field {
+				case 1:
+					node.ID, err = m.int32()
+				case 2:
+					node.Type, err = m.string()
+				case 3:
+					var name string
+					if name, err = m.string(); err == nil {
+						node.Activation = &neat.ActivationFunc{Name: name}
+					}
+				default:
+					err = m.skip()
+				}
+				return err
+			})
+		case 4:
+			conn := &neat.ConnGene{}
+			g.ConnGenes = append(g.ConnGenes, conn)
+			err = d.message(func(m *decoder) (err error) {
+				switch m.field {
+				case 1:
+					conn.From, err = m.int32()
+				case 2:
+					conn.To, err = m.int32()
+				case 3:
+					conn.Weight, err = m.double()
+				case 4:
+					conn.Disabled, err = m.bool()
+				default:
+					err = m.skip()
+				}
+				return err
+			})
+		case 5:
+			g.Fitness, err = d.double()
+		case 6:
+			g.Objectives, err = d.doubles(g.Objectives)
+		case 7:
+			g.Behavior, err = d.doubles(g.Behavior)
+		case 8:
+			if g.Metrics == nil {
+				g.Metrics = make(map[string]float64)
+			}
+			err = d.doubleMap(g.Metrics)
+		default:
+			err = d.skip()
+		}
+		return err
+	}
+}
+
+// speciesField returns a function that decodes a field of a Species message
+// into the argument species.
+func speciesField(s *neat.Species) func(d *decoder) error {
+	return func(d *decoder) (err error) {
+		switch d.field {
+		case 1:
+			s.ID, err = d.int32()
+		case 2:
+			s.Age, err = d.int32()
+		case 3:
+			s.Stagnation, err = d.int32()
+		case 4:
+			s.Representative = &neat.Genome{}
+			err = d.message(genomeField(s.Representative))
+		case 5:
+			s.BestFitness, err = d.double()
+		case 6:
+			s.Best = &neat.Genome{}
+			err = d.message(genomeField(s.Best))
+		default:
+			err = d.skip()
+		}
+		return err
+	}
+}
+
+// restoreGenomes validates the argument decoded genomes and restores their
+// activation functions with neat.RestoreGenome, such that neither an unknown
+// function nor a dangling connection survives decoding.
+func restoreGenomes(genomes []*neat.Genome) error {
+	for _, g := range genomes {
+		if err := neat.RestoreGenome(g); err != nil {
+			return err
+		}
+	}
+	return nil
+}
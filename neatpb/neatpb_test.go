@@ -0,0 +1,123 @@
+package neatpb
+
+import (
+	"bytes"
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/jinyeom/neat"
+)
+
+func TestMarshalGenome(t *testing.T) {
+	g := &neat.Genome{
+		ID:        3,
+		SpeciesID: -1,
+		NodeGenes: []*neat.NodeGene{
+			neat.NewNodeGene(0, "input", neat.ActivationSet["identity"]),
+			neat.NewNodeGene(1, "output", neat.ActivationSet["sigmoid"]),
+		},
+		ConnGenes:  []*neat.ConnGene{neat.NewConnGene(0, 1, 0.5)},
+		Fitness:    math.NaN(),
+		Objectives: []float64{1.0, -2.0},
+		Metrics:    map[string]float64{"steps": 10.0, "solved": 0.0},
+	}
+
+	// the connection gene is encoded as in the schema: from is omitted as 0,
+	// to is 1, and the weight is 0.5.
+	conn := []byte{0x22, 0x0b, 0x10, 0x01, 0x19, 0, 0, 0, 0, 0, 0, 0xe0, 0x3f}
+	b := MarshalGenome(g)
+	if !bytes.Contains(b, conn) {
+		t.Errorf("connection gene is not encoded as in the schema: %x", b)
+	}
+
+	decoded, err := UnmarshalGenome(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decoded.ID != 3 || decoded.SpeciesID != -1 || !math.IsNaN(decoded.Fitness) {
+		t.Errorf("invalid genome: %+v", decoded)
+	}
+	if len(decoded.NodeGenes) != 2 ||
+		decoded.NodeGenes[1].Activation != neat.ActivationSet["sigmoid"] {
+		t.Errorf("invalid node genes: %v", decoded.NodeGenes)
+	}
+	if len(decoded.ConnGenes) != 1 || *decoded.ConnGenes[0] != *g.ConnGenes[0] {
+		t.Errorf("invalid connection genes: %v", decoded.ConnGenes)
+	}
+	if !reflect.DeepEqual(decoded.Objectives, g.Objectives) ||
+		!reflect.DeepEqual(decoded.Metrics, g.Metrics) {
+		t.Errorf("invalid results: %v, %v", decoded.Objectives, decoded.Metrics)
+	}
+
+	// unknown fields of a newer schema are skipped.
+	e := &encoder{buf: b}
+	e.string(100, "unknown")
+	if _, err := UnmarshalGenome(e.buf); err != nil {
+		t.Errorf("unknown field is not skipped: %v", err)
+	}
+	if _, err := UnmarshalGenome(b[:len(b)-1]); err == nil {
+		t.Error("truncated message is not rejected")
+	}
+}
+
+func TestUnmarshalInvalidGenome(t *testing.T) {
+	g := &neat.Genome{
+		NodeGenes: []*neat.NodeGene{
+			neat.NewNodeGene(0, "input", neat.ActivationSet["identity"]),
+			neat.NewNodeGene(1, "output", &neat.ActivationFunc{Name: "unknown"}),
+		},
+		ConnGenes: []*neat.ConnGene{neat.NewConnGene(0, 1, 0.5)},
+	}
+	if _, err := UnmarshalGenome(MarshalGenome(g)); err == nil {
+		t.Error("unknown activation function is not rejected")
+	}
+
+	g.NodeGenes[1].Activation = neat.ActivationSet["sigmoid"]
+	g.ConnGenes = append(g.ConnGenes, neat.NewConnGene(0, 2, 1.0))
+	b := MarshalPopulation([]*neat.Genome{g})
+	if _, err := UnmarshalPopulation(b); err == nil {
+		t.Error("dangling connection is not rejected")
+	}
+}
+
+func TestMarshalCheckpoint(t *testing.T) {
+	config := neat.NewDefaultConfig(3, 1)
+	config.NumGenerations = 3
+	config.PopulationSize = 30
+
+	n := neat.New(config, neat.XORTest(), neat.WithSeed(0))
+	n.Run()
+
+	genomes, err := UnmarshalPopulation(MarshalPopulation(n.Population))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(genomes) != len(n.Population) {
+		t.Errorf("invalid number of genomes: %d", len(genomes))
+	}
+
+	b, err := MarshalCheckpoint(n.Checkpoint())
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := UnmarshalCheckpoint(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Generation != n.Generation || len(c.Species) != len(n.Species) ||
+		c.Config.PopulationSize != config.PopulationSize {
+		t.Errorf("checkpoint is not decoded as encoded")
+	}
+
+	resumed := *c.Config
+	resumed.NumGenerations = 5
+	m := neat.New(&resumed, neat.XORTest(), neat.WithSeed(1))
+	if err := m.Restore(c); err != nil {
+		t.Fatal(err)
+	}
+	m.Run()
+	if m.Err() != nil || m.Generation != resumed.NumGenerations {
+		t.Errorf("run is not resumed: generation %d, %v", m.Generation, m.Err())
+	}
+}
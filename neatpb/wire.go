@@ -0,0 +1,315 @@
+// wire.go implementation of the protocol buffers wire format.
+//
+// Copyright (C) 2017  Jin Yeom
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package neatpb
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// wire types of fields.
+const (
+	wireVarint  = 0
+	wireFixed64 = 1
+	wireBytes   = 2
+	wireFixed32 = 5
+)
+
+// errTruncated is returned if a message ends in the middle of a field.
+var errTruncated = errors.New("neatpb: truncated message")
+
+// encoder appends fields to a message; as in proto3, fields of zero values are
+// omitted.
+type encoder struct {
+	buf []byte // encoded message
+}
+
+// tag appends the tag of a field of the argument number and wire type.
+func (e *encoder) tag(field int, wire int) {
+	e.buf = binary.AppendUvarint(e.buf, uint64(field)<<3|uint64(wire))
+}
+
+// int32 appends an int32 field; negative values are sign-extended to 64 bits.
+func (e *encoder) int32(field int, v int) {
+	if v != 0 {
+		e.tag(field, wireVarint)
+		e.buf = binary.AppendUvarint(e.buf, uint64(int64(int32(v))))
+	}
+}
+
+// bool appends a bool field.
+func (e *encoder) bool(field int, v bool) {
+	if v {
+		e.tag(field, wireVarint)
+		e.buf = append(e.buf, 1)
+	}
+}
+
+// double appends a double field.
+func (e *encoder) double(field int, v float64) {
+	if v != 0.0 || math.Signbit(v) {
+		e.tag(field, wireFixed64)
+		e.buf = binary.LittleEndian.AppendUint64(e.buf, math.Float64bits(v))
+	}
+}
+
+// doubles appends a packed repeated double field.
+func (e *encoder) doubles(field int, vs []float64) {
+	if len(vs) == 0 {
+		return
+	}
+	e.tag(field, wireBytes)
+	e.buf = binary.AppendUvarint(e.buf, uint64(8*len(vs)))
+	for _, v := range vs {
+		e.buf = binary.LittleEndian.AppendUint64(e.buf, math.Float64bits(v))
+	}
+}
+
+// string appends a string field.
+func (e *encoder) string(field int, v string) {
+	if v != "" {
+		e.bytes(field, []byte(v))
+	}
+}
+
+// bytes appends a bytes field, or an embedded message of the argument
+// encoding, which is appended even if it is empty.
+func (e *encoder) bytes(field int, v []byte) {
+	e.tag(field, wireBytes)
+	e.buf = binary.AppendUvarint(e.buf, uint64(len(v)))
+	e.buf = append(e.buf, v...)
+}
+
+// message appends an embedded message, encoded by the argument function.
+func (e *encoder) message(field int, encode func(e *encoder)) {
+	m := &encoder{}
+	encode(m)
+	e.bytes(field, m.buf)
+}
+
+// doubleMap appends a map<string, double> field in the order of its keys.
+func (e *encoder) doubleMap(field int, m map[string]float64) {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		e.message(field, func(entry *encoder) {
+			entry.string(1, key)
+			entry.double(2, m[key])
+		})
+	}
+}
+
+// decoder reads the fields of a message.
+type decoder struct {
+	buf   []byte // rest of the message
+	field int    // number of the current field
+	wire  int    // wire type of the current field
+}
+
+// next reads the tag of the next field, and returns false at the end of the
+// message.
+func (d *decoder) next() (bool, error) {
+	if len(d.buf) == 0 {
+		return false, nil
+	}
+	tag, err := d.uvarint()
+	if err != nil {
+		return false, err
+	}
+	d.field, d.wire = int(tag>>3), int(tag&7)
+	if d.field <= 0 {
+		return false, fmt.Errorf("neatpb: invalid field number %d", d.field)
+	}
+	return true, nil
+}
+
+// uvarint reads a varint.
+func (d *decoder) uvarint() (uint64, error) {
+	v, n := binary.Uvarint(d.buf)
+	if n <= 0 {
+		return 0, errTruncated
+	}
+	d.buf = d.buf[n:]
+	return v, nil
+}
+
+// expect returns an error if the current field is not of the argument wire
+// type.
+func (d *decoder) expect(wire int) error {
+	if d.wire != wire {
+		return fmt.Errorf("neatpb: field %d has wire type %d, not %d",
+			d.field, d.wire, wire)
+	}
+	return nil
+}
+
+// int32 reads the current int32 field.
+func (d *decoder) int32() (int, error) {
+	if err := d.expect(wireVarint); err != nil {
+		return 0, err
+	}
+	v, err := d.uvarint()
+	return int(int32(v)), err
+}
+
+// bool reads the current bool field.
+func (d *decoder) bool() (bool, error) {
+	if err := d.expect(wireVarint); err != nil {
+		return false, err
+	}
+	v, err := d.uvarint()
+	return v != 0, err
+}
+
+// fixed64 reads a double.
+func (d *decoder) fixed64() (float64, error) {
+	if len(d.buf) < 8 {
+		return 0.0, errTruncated
+	}
+	v := math.Float64frombits(binary.LittleEndian.Uint64(d.buf))
+	d.buf = d.buf[8:]
+	return v, nil
+}
+
+// double reads the current double field.
+func (d *decoder) double() (float64, error) {
+	if err := d.expect(wireFixed64); err != nil {
+		return 0.0, err
+	}
+	return d.fixed64()
+}
+
+// doubles appends the values of the current repeated double field, either
+// packed or not, to the argument values.
+func (d *decoder) doubles(vs []float64) ([]float64, error) {
+	if d.wire == wireFixed64 {
+		v, err := d.fixed64()
+		return append(vs, v), err
+	}
+	b, err := d.bytes()
+	if err != nil {
+		return nil, err
+	}
+	if len(b)%8 != 0 {
+		return nil, errTruncated
+	}
+	packed := &decoder{buf: b}
+	for len(packed.buf) != 0 {
+		v, _ := packed.fixed64()
+		vs = append(vs, v)
+	}
+	return vs, nil
+}
+
+// bytes reads the current bytes, string, or embedded message field.
+func (d *decoder) bytes() ([]byte, error) {
+	if err := d.expect(wireBytes); err != nil {
+		return nil, err
+	}
+	n, err := d.uvarint()
+	if err != nil {
+		return nil, err
+	}
+	if n > uint64(len(d.buf)) {
+		return nil, errTruncated
+	}
+	b := d.buf[:n]
+	d.buf = d.buf[n:]
+	return b, nil
+}
+
+// string reads the current string field.
+func (d *decoder) string() (string, error) {
+	b, err := d.bytes()
+	return string(b), err
+}
+
+// message reads the current embedded message field with the argument function
+// of its fields, which is called for each field of the message.
+func (d *decoder) message(decode func(m *decoder) error) error {
+	b, err := d.bytes()
+	if err != nil {
+		return err
+	}
+	return decodeMessage(b, decode)
+}
+
+// doubleMap reads an entry of the current map<string, double> field into the
+// argument map.
+func (d *decoder) doubleMap(m map[string]float64) error {
+	var key string
+	var value float64
+	err := d.message(func(entry *decoder) (err error) {
+		switch entry.field {
+		case 1:
+			key, err = entry.string()
+		case 2:
+			value, err = entry.double()
+		default:
+			err = entry.skip()
+		}
+		return err
+	})
+	m[key] = value
+	return err
+}
+
+// skip skips the current field, e.g., of a newer version of the schema.
+func (d *decoder) skip() error {
+	var err error
+	switch d.wire {
+	case wireVarint:
+		_, err = d.uvarint()
+	case wireFixed64:
+		_, err = d.fixed64()
+	case wireBytes:
+		_, err = d.bytes()
+	case wireFixed32:
+		if len(d.buf) < 4 {
+			return errTruncated
+		}
+		d.buf = d.buf[4:]
+	default:
+		err = fmt.Errorf("neatpb: unsupported wire type %d", d.wire)
+	}
+	return err
+}
+
+// decodeMessage decodes the argument message with the argument function of its
+// fields, which is called for each field of the message.
+func decodeMessage(b []byte, decode func(d *decoder) error) error {
+	d := &decoder{buf: b}
+	for {
+		ok, err := d.next()
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return nil
+		}
+		if err := decode(d); err != nil {
+			return err
+		}
+	}
+}
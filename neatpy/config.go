@@ -0,0 +1,249 @@
+// config.go implementation of conversion of configurations from and to
+// neat-python.
+//
+// Copyright (C) 2017  Jin Yeom
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package neatpy
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/jinyeom/neat"
+)
+
+// WriteConfig writes the argument configuration to the argument writer as a
+// configuration file of neat-python, with its DefaultGenome, DefaultSpeciesSet,
+// DefaultStagnation, and DefaultReproduction. Settings that do not exist in
+// this package, e.g., mutation of biases, are set to those of networks that
+// can be converted (see ToGenome). If biasInput is true, the first input is
+// taken as the bias input, which is excluded from the inputs of neat-python.
+// Since neat-python always maximizes fitness, a minimized fitness must be
+// negated by its evaluation function.
+func WriteConfig(w io.Writer, c *neat.Config, biasInput bool) error {
+	numInputs := c.NumInputs
+	biasMutateRate := 0.0
+	if biasInput {
+		numInputs--
+		biasMutateRate = c.RatePerturb
+	}
+	initialConnection := "unconnected"
+	if c.FullyConnected {
+		initialConnection = "full_direct"
+	}
+	activationOptions := []string{}
+	added := make(map[string]bool)
+	for _, name := range append([]string{"sigmoid"}, c.HiddenActivations...) {
+		if afunc, ok := neat.ActivationSet[name]; ok {
+			if a, ok := activations[afunc.Name]; ok && !added[a.name] {
+				activationOptions = append(activationOptions, a.name)
+				added[a.name] = true
+			}
+		}
+	}
+
+	sections := []struct {
+		name     string
+		settings [][2]string
+	}{
+		{"NEAT", [][2]string{
+			{"fitness_criterion", "max"},
+			{"fitness_threshold", "inf"},
+			{"no_fitness_termination", "True"},
+			{"pop_size", strconv.Itoa(c.PopulationSize)},
+			{"reset_on_extinction", "True"},
+		}},
+		{"DefaultGenome", [][2]string{
+			{"num_inputs", strconv.Itoa(numInputs)},
+			{"num_outputs", strconv.Itoa(c.NumOutputs)},
+			{"num_hidden", "0"},
+			{"feed_forward", pyBool(!c.SelfConnections)},
+			{"initial_connection", initialConnection},
+
+			{"activation_default", "sigmoid"},
+			{"activation_mutate_rate", formatFloat(c.RateMutateActivation)},
+			{"activation_options", strings.Join(activationOptions, " ")},
+			{"aggregation_default", "sum"},
+			{"aggregation_mutate_rate", "0.0"},
+			{"aggregation_options", "sum"},
+
+			{"bias_init_mean", "0.0"},
+			{"bias_init_stdev", "0.0"},
+			{"bias_max_value", "30.0"},
+			{"bias_min_value", "-30.0"},
+			{"bias_mutate_power", "0.5"},
+			{"bias_mutate_rate", formatFloat(biasMutateRate)},
+			{"bias_replace_rate", "0.0"},
+
+			{"response_init_mean", "0.2"},
+			{"response_init_stdev", "0.0"},
+			{"response_max_value", "30.0"},
+			{"response_min_value", "-30.0"},
+			{"response_mutate_power", "0.0"},
+			{"response_mutate_rate", "0.0"},
+			{"response_replace_rate", "0.0"},
+
+			{"weight_init_mean", "0.0"},
+			{"weight_init_stdev", "1.0"},
+			{"weight_max_value", "30.0"},
+			{"weight_min_value", "-30.0"},
+			{"weight_mutate_power", "0.5"},
+			{"weight_mutate_rate", formatFloat(c.RatePerturb)},
+			{"weight_replace_rate", "0.0"},
+
+			{"enabled_default", "True"},
+			{"enabled_mutate_rate", "0.0"},
+
+			{"conn_add_prob", formatFloat(c.RateAddConn)},
+			{"conn_delete_prob", "0.0"},
+			{"node_add_prob", formatFloat(c.RateAddNode)},
+			{"node_delete_prob", "0.0"},
+
+			{"compatibility_disjoint_coefficient", formatFloat(c.CoeffUnmatching)},
+			{"compatibility_weight_coefficient", formatFloat(c.CoeffMatching)},
+		}},
+		{"DefaultSpeciesSet", [][2]string{
+			{"compatibility_threshold", formatFloat(c.DistanceThreshold)},
+		}},
+		{"DefaultStagnation", [][2]string{
+			{"species_fitness_func", "max"},
+			{"max_stagnation", strconv.Itoa(c.StagnationLimit)},
+			{"species_elitism", "1"},
+		}},
+		{"DefaultReproduction", [][2]string{
+			{"elitism", "1"},
+			{"survival_threshold", formatFloat(c.SurvivalRate)},
+			{"min_species_size", "2"},
+		}},
+	}
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "# %s, converted from github.com/jinyeom/neat\n",
+		c.ExperimentName)
+	for _, section := range sections {
+		fmt.Fprintf(bw, "\n[%s]\n", section.name)
+		for _, setting := range section.settings {
+			fmt.Fprintf(bw, "%s = %s\n", setting[0], setting[1])
+		}
+	}
+	return bw.Flush()
+}
+
+// ReadConfig reads a configuration file of neat-python from the argument
+// reader, and returns a default configuration (see neat.NewDefaultConfig)
+// whose settings that exist in neat-python are overridden. If biasInput is
+// true, a bias input is added to the inputs of neat-python.
+func ReadConfig(r io.Reader, biasInput bool) (*neat.Config, error) {
+	settings := make(map[string]string)
+	section := ""
+	scanner := bufio.NewScanner(r)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "" || line[0] == '#' || line[0] == ';':
+		case line[0] == '[' && line[len(line)-1] == ']':
+			section = line[1 : len(line)-1]
+		default:
+			i := strings.IndexAny(line, "=:")
+			if i < 0 {
+				return nil, fmt.Errorf("neatpy: invalid line %d: %q", lineNum, line)
+			}
+			key := strings.TrimSpace(line[:i])
+			settings[section+"."+key] = strings.TrimSpace(line[i+1:])
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	var errs []string
+	getInt := func(key string, v *int) {
+		if s, ok := settings[key]; ok {
+			n, err := strconv.Atoi(s)
+			if err != nil {
+				errs = append(errs, fmt.Sprintf("%s: %v", key, err))
+			}
+			*v = n
+		}
+	}
+	getFloat := func(key string, v *float64) {
+		if s, ok := settings[key]; ok {
+			f, err := strconv.ParseFloat(s, 64)
+			if err != nil {
+				errs = append(errs, fmt.Sprintf("%s: %v", key, err))
+			}
+			*v = f
+		}
+	}
+
+	c := neat.NewDefaultConfig(0, 0)
+	getInt("NEAT.pop_size", &c.PopulationSize)
+	getInt("DefaultGenome.num_inputs", &c.NumInputs)
+	getInt("DefaultGenome.num_outputs", &c.NumOutputs)
+	getFloat("DefaultGenome.activation_mutate_rate", &c.RateMutateActivation)
+	getFloat("DefaultGenome.weight_mutate_rate", &c.RatePerturb)
+	getFloat("DefaultGenome.conn_add_prob", &c.RateAddConn)
+	getFloat("DefaultGenome.node_add_prob", &c.RateAddNode)
+	getFloat("DefaultGenome.compatibility_disjoint_coefficient",
+		&c.CoeffUnmatching)
+	getFloat("DefaultGenome.compatibility_weight_coefficient", &c.CoeffMatching)
+	getFloat("DefaultSpeciesSet.compatibility_threshold", &c.DistanceThreshold)
+	getInt("DefaultStagnation.max_stagnation", &c.StagnationLimit)
+	getFloat("DefaultReproduction.survival_threshold", &c.SurvivalRate)
+	if len(errs) != 0 {
+		return nil, fmt.Errorf("neatpy: invalid settings: %s",
+			strings.Join(errs, "; "))
+	}
+
+	if biasInput {
+		c.NumInputs++
+	}
+	if s, ok := settings["DefaultGenome.initial_connection"]; ok {
+		c.FullyConnected = strings.HasPrefix(s, "full")
+	}
+	if s, ok := settings["DefaultGenome.feed_forward"]; ok {
+		c.SelfConnections = !strings.EqualFold(s, "true")
+	}
+	if s, ok := settings["DefaultGenome.activation_options"]; ok {
+		c.HiddenActivations = []string{}
+		for _, name := range strings.Fields(s) {
+			for key, afunc := range neat.ActivationSet {
+				if a, ok := activations[afunc.Name]; ok && a.name == name {
+					c.HiddenActivations = append(c.HiddenActivations, key)
+					break
+				}
+			}
+		}
+	}
+	return c, nil
+}
+
+// pyBool returns the argument boolean in Python.
+func pyBool(b bool) string {
+	if b {
+		return "True"
+	}
+	return "False"
+}
+
+// formatFloat returns the argument value in the shortest representation that
+// is read back exactly.
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
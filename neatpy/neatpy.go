@@ -0,0 +1,289 @@
+// neatpy.go implementation of conversion of genomes from and to neat-python.
+//
+// Copyright (C) 2017  Jin Yeom
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+// Package neatpy converts genomes and configurations from and to those of
+// neat-python, so that populations and champions can be exchanged with it,
+// e.g., to cross-validate results.
+//
+// Genomes are exchanged in JSON of the attributes of neat-python's
+// DefaultGenome, which can be loaded with a few lines of Python:
+//
+//	genome = neat.DefaultGenome(data["key"])
+//	for n in data["nodes"]:
+//		node = neat.genes.DefaultNodeGene(n["key"])
+//		node.bias, node.response = n["bias"], n["response"]
+//		node.activation, node.aggregation = n["activation"], n["aggregation"]
+//		genome.nodes[node.key] = node
+//	for c in data["connections"]:
+//		conn = neat.genes.DefaultConnectionGene(tuple(c["key"]))
+//		conn.weight, conn.enabled = c["weight"], c["enabled"]
+//		genome.connections[conn.key] = conn
+//
+// Activation functions of neat-python are scaled, e.g., its sigmoid is
+// 1 / (1 + exp(-5z)), and each node has a bias and a response, i.e., a node
+// computes f(bias + response * sum); conversions account for both, such that
+// the converted networks compute the same function, except where neat-python
+// clamps its inputs of activation functions. Since genomes of this package
+// have no bias, biases are converted from and to the connections from a bias
+// input node, i.e., an input that is always 1.
+package neatpy
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/jinyeom/neat"
+)
+
+// Genome is a genome of neat-python, in which input nodes have negative keys
+// from -1, output nodes have keys from 0, and hidden nodes follow them.
+type Genome struct {
+	Key         int          `json:"key"`         // genome ID
+	Fitness     float64      `json:"fitness"`     // fitness score
+	Nodes       []Node       `json:"nodes"`       // output and hidden nodes
+	Connections []Connection `json:"connections"` // connections
+}
+
+// Node is a node gene of neat-python.
+type Node struct {
+	Key         int     `json:"key"`         // node key
+	Bias        float64 `json:"bias"`        // bias of the node
+	Response    float64 `json:"response"`    // multiplier of the input sum
+	Activation  string  `json:"activation"`  // name of the activation function
+	Aggregation string  `json:"aggregation"` // name of the aggregation function
+}
+
+// Connection is a connection gene of neat-python.
+type Connection struct {
+	Key     [2]int  `json:"key"`     // keys of the input and output nodes
+	Weight  float64 `json:"weight"`  // connection weight
+	Enabled bool    `json:"enabled"` // true if enabled
+}
+
+// activation is an activation function that exists in both packages, where
+// that of neat-python f(z) is that of this package f'(scale * z).
+type activation struct {
+	name  string  // name in neat-python
+	scale float64 // scale of the input of the function
+}
+
+// activations maps the names of activation functions of this package to
+// those of neat-python.
+var activations = map[string]activation{
+	"Identity": {"identity", 1.0},
+	"Linear":   {"identity", 1.0},
+	"Sigmoid":  {"sigmoid", 5.0},
+	"Tanh":     {"tanh", 2.5},
+	"Sine":     {"sin", 5.0},
+	"ReLU":     {"relu", 1.0},
+	"Log":      {"log", 1.0},
+	"Exp":      {"exp", 1.0},
+	"Abs":      {"abs", 1.0},
+	"Square":   {"square", 1.0},
+	"Cube":     {"cube", 1.0},
+}
+
+// FromGenome converts the argument genome to a genome of neat-python. If
+// biasInput is not negative, the input node of that index is taken as the bias
+// input, whose connections become the biases of their output nodes.
+func FromGenome(g *neat.Genome, biasInput int) (*Genome, error) {
+	// input keys are negative from -1, skipping the bias input; output keys
+	// are from 0, followed by hidden keys.
+	keys := make(map[int]int)
+	inputIndex, numInputs, numOutputs := 0, 0, 0
+	biasID := -1
+	for _, node := range g.NodeGenes {
+		switch node.Type {
+		case "input":
+			if inputIndex == biasInput {
+				biasID = node.ID
+			} else {
+				numInputs++
+				keys[node.ID] = -numInputs
+			}
+			inputIndex++
+		case "output":
+			keys[node.ID] = numOutputs
+			numOutputs++
+		}
+	}
+	if biasInput >= 0 && biasID < 0 {
+		return nil, fmt.Errorf("neatpy: no bias input %d", biasInput)
+	}
+
+	nodes := make(map[int]*Node)
+	scales := make(map[int]float64)
+	next := numOutputs
+	for _, node := range g.NodeGenes {
+		if node.Type == "input" {
+			continue
+		}
+		if node.Type != "output" {
+			keys[node.ID] = next
+			next++
+		}
+		name := "Identity"
+		if node.Activation != nil {
+			name = node.Activation.Name
+		}
+		a, ok := activations[name]
+		if !ok {
+			return nil, fmt.Errorf("neatpy: activation %s of node %d does not "+
+				"exist in neat-python", name, node.ID)
+		}
+		nodes[node.ID] = &Node{
+			Key:         keys[node.ID],
+			Response:    1.0 / a.scale,
+			Activation:  a.name,
+			Aggregation: "sum",
+		}
+		scales[node.ID] = a.scale
+	}
+
+	p := &Genome{Key: g.ID, Fitness: g.Fitness}
+	for _, conn := range g.ConnGenes {
+		if _, ok := nodes[conn.To]; !ok {
+			return nil, fmt.Errorf("neatpy: connection %s into a missing node", conn)
+		}
+		if conn.From == biasID {
+			if !conn.Disabled {
+				nodes[conn.To].Bias += conn.Weight / scales[conn.To]
+			}
+			continue
+		}
+		from, ok := keys[conn.From]
+		if !ok {
+			return nil, fmt.Errorf("neatpy: connection %s from a missing node", conn)
+		}
+		p.Connections = append(p.Connections, Connection{
+			Key:     [2]int{from, keys[conn.To]},
+			Weight:  conn.Weight,
+			Enabled: !conn.Disabled,
+		})
+	}
+	for _, node := range nodes {
+		p.Nodes = append(p.Nodes, *node)
+	}
+	sort.Slice(p.Nodes, func(i, j int) bool {
+		return p.Nodes[i].Key < p.Nodes[j].Key
+	})
+	return p, nil
+}
+
+// ToGenome converts this genome of neat-python to a genome of this package,
+// given the numbers of inputs and outputs of neat-python. If biasInput is not
+// negative, an input node is inserted at that index as the bias input, from
+// which the biases of nodes are connected; otherwise, every bias must be 0.
+func (p *Genome) ToGenome(numInputs, numOutputs,
+	biasInput int) (*neat.Genome, error) {
+	if biasInput > numInputs {
+		return nil, fmt.Errorf("neatpy: bias input %d out of %d inputs",
+			biasInput, numInputs)
+	}
+
+	g := &neat.Genome{ID: p.Key, SpeciesID: -1, Fitness: p.Fitness}
+	ids := make(map[int]int)
+	biasID := -1
+	for i := 0; i < numInputs+boolToInt(biasInput >= 0); i++ {
+		id := len(g.NodeGenes)
+		if i == biasInput {
+			biasID = id
+		} else {
+			ids[-(len(ids) + 1)] = id
+		}
+		g.NodeGenes = append(g.NodeGenes, neat.NewNodeGene(id, "input",
+			neat.ActivationSet["identity"]))
+	}
+
+	// output nodes come first, then hidden nodes in order of their keys.
+	nodes := append([]Node(nil), p.Nodes...)
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].Key < nodes[j].Key })
+	scales := make(map[int]float64)
+	for _, node := range nodes {
+		if node.Key < 0 {
+			return nil, fmt.Errorf("neatpy: node of an input key %d", node.Key)
+		}
+		if node.Aggregation != "" && node.Aggregation != "sum" {
+			return nil, fmt.Errorf("neatpy: aggregation %s of node %d is not "+
+				"supported", node.Aggregation, node.Key)
+		}
+		afunc, scale, err := fromActivation(node.Activation)
+		if err != nil {
+			return nil, err
+		}
+		ntype := "hidden"
+		if node.Key < numOutputs {
+			ntype = "output"
+		}
+		id := len(g.NodeGenes)
+		ids[node.Key] = id
+		scales[node.Key] = scale * node.Response
+		g.NodeGenes = append(g.NodeGenes, neat.NewNodeGene(id, ntype, afunc))
+
+		if node.Bias != 0.0 {
+			if biasID < 0 {
+				return nil, fmt.Errorf("neatpy: bias of node %d without a bias "+
+					"input", node.Key)
+			}
+			g.ConnGenes = append(g.ConnGenes, neat.NewConnGene(biasID, id,
+				scale*node.Bias))
+		}
+	}
+
+	for key := 0; key < numOutputs; key++ {
+		if _, ok := ids[key]; !ok {
+			return nil, fmt.Errorf("neatpy: missing output node %d", key)
+		}
+	}
+
+	for _, conn := range p.Connections {
+		from, okFrom := ids[conn.Key[0]]
+		to, okTo := ids[conn.Key[1]]
+		if !okFrom || !okTo {
+			return nil, fmt.Errorf("neatpy: connection %v of a missing node",
+				conn.Key)
+		}
+		c := neat.NewConnGene(from, to, scales[conn.Key[1]]*conn.Weight)
+		c.Disabled = !conn.Enabled
+		g.ConnGenes = append(g.ConnGenes, c)
+	}
+	return g, nil
+}
+
+// fromActivation returns the activation function of this package of the
+// argument name of neat-python, and the scale of its input.
+func fromActivation(name string) (*neat.ActivationFunc, float64, error) {
+	for afuncName, a := range activations {
+		if a.name != name || afuncName == "Identity" {
+			continue
+		}
+		for _, afunc := range neat.ActivationSet {
+			if afunc.Name == afuncName {
+				return afunc, a.scale, nil
+			}
+		}
+	}
+	return nil, 0.0, fmt.Errorf("neatpy: activation %s is not supported", name)
+}
+
+// boolToInt returns 1 if the argument is true, and 0 otherwise.
+func boolToInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,125 @@
+package neatpy
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/jinyeom/neat"
+)
+
+func TestGenome(t *testing.T) {
+	// a genome with a bias input (0), two inputs, a hidden tanh node (4), and
+	// an output sigmoid node (3).
+	g := neat.NewGenome(7, 3, 1, 0.0)
+	g.NodeGenes[3].Activation = neat.ActivationSet["sigmoid"]
+	g.NodeGenes = append(g.NodeGenes,
+		neat.NewNodeGene(4, "hidden", neat.ActivationSet["tanh"]))
+	g.ConnGenes = []*neat.ConnGene{
+		neat.NewConnGene(0, 3, 0.5),
+		neat.NewConnGene(1, 4, -1.0),
+		neat.NewConnGene(0, 4, 0.25),
+		neat.NewConnGene(4, 3, 2.0),
+		neat.NewConnGene(2, 3, 1.5),
+	}
+
+	p, err := FromGenome(g, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(p.Nodes) != 2 || p.Nodes[0].Key != 0 || p.Nodes[1].Key != 1 {
+		t.Fatalf("invalid nodes: %+v", p.Nodes)
+	}
+	if p.Nodes[0].Activation != "sigmoid" || p.Nodes[0].Bias != 0.1 ||
+		p.Nodes[1].Activation != "tanh" || p.Nodes[1].Bias != 0.1 {
+		t.Errorf("invalid nodes: %+v", p.Nodes)
+	}
+	if len(p.Connections) != 3 || p.Connections[0].Key != [2]int{-1, 1} {
+		t.Errorf("invalid connections: %+v", p.Connections)
+	}
+
+	// the network of neat-python computes the same function.
+	inputs := []float64{0.3, -0.7}
+	hidden := math.Tanh(2.5 * (p.Nodes[1].Bias + p.Nodes[1].Response*
+		(-1.0*inputs[0])))
+	output := 1.0 / (1.0 + math.Exp(-5.0*(p.Nodes[0].Bias+
+		p.Nodes[0].Response*(2.0*hidden+1.5*inputs[1]))))
+	nn := neat.NewNeuralNetwork(g)
+	outputs, err := nn.FeedForward([]float64{1.0, inputs[0], inputs[1]})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(outputs[0]-output) > 1e-9 {
+		t.Errorf("invalid output of neat-python: %f != %f", output, outputs[0])
+	}
+
+	// the genome is converted back through JSON.
+	data, err := json.Marshal(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded := &Genome{}
+	if err := json.Unmarshal(data, decoded); err != nil {
+		t.Fatal(err)
+	}
+	back, err := decoded.ToGenome(2, 1, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if back.ID != g.ID || len(back.NodeGenes) != 5 || len(back.ConnGenes) != 5 {
+		t.Errorf("invalid genome: %+v", back)
+	}
+	outputsBack, err := neat.NewNeuralNetwork(back).FeedForward(
+		[]float64{1.0, inputs[0], inputs[1]})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(outputsBack[0]-outputs[0]) > 1e-9 {
+		t.Errorf("converted genome computes another function: %f != %f",
+			outputsBack[0], outputs[0])
+	}
+
+	if _, err := decoded.ToGenome(2, 1, -1); err == nil {
+		t.Error("bias without a bias input is not rejected")
+	}
+	g.NodeGenes[4].Activation = neat.ActivationSet["cos"]
+	if _, err := FromGenome(g, 0); err == nil {
+		t.Error("activation that does not exist in neat-python is not rejected")
+	}
+}
+
+func TestConfig(t *testing.T) {
+	c := neat.NewDefaultConfig(3, 1)
+	c.PopulationSize = 50
+	c.RateAddNode = 0.2
+
+	buf := &bytes.Buffer{}
+	if err := WriteConfig(buf, c, true); err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range []string{"[DefaultGenome]", "num_inputs = 2",
+		"pop_size = 50", "node_add_prob = 0.2", "initial_connection = full_direct"} {
+		if !strings.Contains(buf.String(), line) {
+			t.Errorf("%q is not written", line)
+		}
+	}
+
+	read, err := ReadConfig(buf, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if read.NumInputs != 3 || read.NumOutputs != 1 || read.PopulationSize != 50 ||
+		read.RateAddNode != 0.2 || !read.FullyConnected {
+		t.Errorf("invalid configuration: %+v", read)
+	}
+	if err := read.Validate(); err != nil {
+		t.Error(err)
+	}
+
+	if _, err := ReadConfig(strings.NewReader("[NEAT]\npop_size = many\n"),
+		false); err == nil {
+		t.Error("invalid setting is not rejected")
+	}
+}
@@ -18,6 +18,7 @@
 package neat
 
 import (
+	"errors"
 	"fmt"
 	"sort"
 )
@@ -48,12 +49,12 @@ func NewNeuron(nodeGene *NodeGene) *Neuron {
 // String returns the string representation of Neuron.
 func (n *Neuron) String() string {
 	if len(n.Synapses) == 0 {
-		return fmt.Sprintf("[%s(%d, %s)]", n.Type, n.ID, n.Activation.Name)
+		return fmt.Sprintf("[%s(%d, %s)]", n.Type, n.ID, n.Activation.name())
 	}
-	str := fmt.Sprintf("[%s(%d, %s)] (\n", n.Type, n.ID, n.Activation.Name)
+	str := fmt.Sprintf("[%s(%d, %s)] (\n", n.Type, n.ID, n.Activation.name())
 	for neuron, weight := range n.Synapses {
 		str += fmt.Sprintf("  <--{%.3f}--[%s(%d, %s)]\n",
-			weight, neuron.Type, neuron.ID, neuron.Activation.Name)
+			weight, neuron.Type, neuron.ID, neuron.Activation.name())
 	}
 	return str + ")"
 }
@@ -78,13 +79,27 @@ func (n *Neuron) Activate() float64 {
 
 // NeuralNetwork is an implementation of the phenotype neural network that is
 // decoded from a genome.
+//
+// If the network is recurrent, i.e., a neuron is connected to itself, signals
+// of neurons are kept between calls of FeedForward, such that a self-connection
+// carries the neuron's signal of the previous step; call Reset to clear them,
+// e.g., at the start of an episode.
 type NeuralNetwork struct {
-	Neurons []*Neuron // all neurons in the network
+	Neurons   []*Neuron // all neurons in the network
+	Recurrent bool      // true if signals are kept between steps
 
 	inputNeurons  []*Neuron // input neurons
 	outputNeurons []*Neuron // output neurons
 }
 
+// Predictor is an interface of networks that map inputs to outputs, regardless
+// of the decoder that produced them, e.g., NeuralNetwork, a CPPN, or an adaptive
+// network of HyperNEAT, such that application code can swap them.
+type Predictor interface {
+	// Predict returns the outputs of the network given the argument inputs.
+	Predict(inputs []float64) ([]float64, error)
+}
+
 // NewNeuralNetwork returns a new instance of NeuralNetwork given a genome to
 // decode from.
 func NewNeuralNetwork(g *Genome) *NeuralNetwork {
@@ -122,7 +137,12 @@ func NewNeuralNetwork(g *Genome) *NeuralNetwork {
 			}
 		}
 	}
-	return &NeuralNetwork{neurons, inputNeurons, outputNeurons}
+	return &NeuralNetwork{
+		Neurons:       neurons,
+		Recurrent:     g.SelfConnected(),
+		inputNeurons:  inputNeurons,
+		outputNeurons: outputNeurons,
+	}
 }
 
 // String returns the string representation of NeuralNetwork.
@@ -138,6 +158,19 @@ func (n *NeuralNetwork) String() string {
 // FeedForward propagates inputs signals from input neurons to output neurons,
 // and return output signals.
 func (n *NeuralNetwork) FeedForward(inputs []float64) ([]float64, error) {
+	return n.FeedForwardTo(make([]float64, 0, len(n.outputNeurons)), inputs)
+}
+
+// Predict is FeedForward, which implements Predictor.
+func (n *NeuralNetwork) Predict(inputs []float64) ([]float64, error) {
+	return n.FeedForward(inputs)
+}
+
+// FeedForwardTo is FeedForward that appends the output signals to the argument
+// outputs, e.g., outputs[:0] to reuse a buffer between calls, and returns the
+// extended slice.
+func (n *NeuralNetwork) FeedForwardTo(outputs, inputs []float64) ([]float64,
+	error) {
 	if len(inputs) != len(n.inputNeurons) {
 		errStr := "Invalid number of inputs: %d != %d"
 		return nil, fmt.Errorf(errStr, len(n.inputNeurons), len(inputs))
@@ -149,16 +182,127 @@ func (n *NeuralNetwork) FeedForward(inputs []float64) ([]float64, error) {
 	}
 
 	// recursively propagate from input neurons to output neurons
-	outputs := make([]float64, 0, len(n.outputNeurons))
 	for _, neuron := range n.outputNeurons {
 		outputs = append(outputs, neuron.Activate())
 	}
 
-	// reset all neurons
+	// reset all neurons; in recurrent mode, their signals are kept for the
+	// next step.
 	for _, neuron := range n.Neurons {
-		neuron.Signal = 0.0
+		if !n.Recurrent {
+			neuron.Signal = 0.0
+		}
 		neuron.activated = false
 	}
 
 	return outputs, nil
 }
+
+// NumInputs returns the number of input neurons.
+func (n *NeuralNetwork) NumInputs() int {
+	return len(n.inputNeurons)
+}
+
+// NumOutputs returns the number of output neurons.
+func (n *NeuralNetwork) NumOutputs() int {
+	return len(n.outputNeurons)
+}
+
+// NumSynapses returns the number of synapses, i.e., the enabled connections.
+func (n *NeuralNetwork) NumSynapses() int {
+	num := 0
+	for _, neuron := range n.Neurons {
+		num += len(neuron.Synapses)
+	}
+	return num
+}
+
+// Reset clears signals of all neurons, which are kept between steps in
+// recurrent mode.
+func (n *NeuralNetwork) Reset() {
+	for _, neuron := range n.Neurons {
+		neuron.Signal = 0.0
+		neuron.activated = false
+	}
+}
+
+// errRecurrent is the error of a recurrent network, where a feedforward one is
+// required.
+var errRecurrent = errors.New("neat: network is recurrent")
+
+// graphNode is a neuron of a feedforward network, in topological order.
+type graphNode struct {
+	id         int             // neuron ID
+	input      int             // index of the input, or -1 if not an input neuron
+	sources    []int           // IDs of the neurons of the synapses, in order
+	weights    []float64       // weights of the synapses
+	activation *ActivationFunc // activation function
+}
+
+// activationName returns the name of the activation function of this neuron,
+// which is Identity if it has none.
+func (g *graphNode) activationName() string {
+	return g.activation.name()
+}
+
+// graph returns the neurons that the outputs depend on in topological order,
+// and the IDs of the output neurons; it returns errRecurrent if the network is
+// recurrent.
+func (n *NeuralNetwork) graph() ([]*graphNode, []int, error) {
+	inputs := make(map[*Neuron]int)
+	for i, neuron := range n.inputNeurons {
+		inputs[neuron] = i
+	}
+
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make(map[*Neuron]int)
+	var nodes []*graphNode
+	var visit func(neuron *Neuron) error
+	visit = func(neuron *Neuron) error {
+		switch state[neuron] {
+		case visiting:
+			return errRecurrent
+		case visited:
+			return nil
+		}
+		state[neuron] = visiting
+
+		node := &graphNode{id: neuron.ID, input: -1}
+		if i, ok := inputs[neuron]; ok {
+			node.input = i
+		} else {
+			sources := make([]*Neuron, 0, len(neuron.Synapses))
+			for source := range neuron.Synapses {
+				sources = append(sources, source)
+			}
+			sort.Slice(sources, func(i, j int) bool {
+				return sources[i].ID < sources[j].ID
+			})
+			for _, source := range sources {
+				if err := visit(source); err != nil {
+					return err
+				}
+				node.sources = append(node.sources, source.ID)
+				node.weights = append(node.weights, neuron.Synapses[source])
+			}
+			node.activation = neuron.Activation
+		}
+
+		state[neuron] = visited
+		nodes = append(nodes, node)
+		return nil
+	}
+
+	outputs := make([]int, len(n.outputNeurons))
+	for i, neuron := range n.outputNeurons {
+		if err := visit(neuron); err != nil {
+			return nil, nil, err
+		}
+		outputs[i] = neuron.ID
+	}
+	return nodes, outputs, nil
+}
@@ -1,8 +1,10 @@
 package neat
 
 import (
+	"bytes"
 	"fmt"
 	"math/rand"
+	"strings"
 	"testing"
 )
 
@@ -36,3 +38,79 @@ func TestNeuralNetwork(t *testing.T) {
 	rand.Seed(0)
 	NeuralNetworkUnitTest()
 }
+
+func TestNeuralNetworkRecurrent(t *testing.T) {
+	g := NewGenome(0, 1, 1, 0.0)
+	g.NodeGenes[0].Activation = Linear()
+	g.NodeGenes[1].Activation = Linear()
+	g.ConnGenes = append(g.ConnGenes, NewConnGene(0, 1, 1.0),
+		NewConnGene(1, 1, 1.0))
+
+	n := NewNeuralNetwork(g)
+	if !n.Recurrent {
+		t.Fatal("self-connected network is not recurrent")
+	}
+
+	// the output accumulates its inputs through the self-connection.
+	for i, expected := range []float64{1.0, 2.0, 3.0} {
+		outputs, err := n.FeedForward([]float64{1.0})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if outputs[0] != expected {
+			t.Errorf("step %d: invalid output %f != %f", i, outputs[0], expected)
+		}
+	}
+
+	n.Reset()
+	if outputs, _ := n.FeedForward([]float64{1.0}); outputs[0] != 1.0 {
+		t.Errorf("signals are not reset: %f != 1.0", outputs[0])
+	}
+}
+
+func TestNeuralNetworkExportPython(t *testing.T) {
+	g := NewGenome(0, 3, 1, 0.0)
+	g.NodeGenes[3].Activation = ActivationSet["sigmoid"]
+	g.NodeGenes = append(g.NodeGenes,
+		NewNodeGene(4, "hidden", ActivationSet["tanh"]))
+	g.ConnGenes = []*ConnGene{
+		NewConnGene(1, 4, 0.5),
+		NewConnGene(2, 4, -1.0),
+		NewConnGene(4, 3, 2.0),
+		NewConnGene(0, 3, 0.25),
+	}
+	nn := NewNeuralNetwork(g)
+
+	buf := &bytes.Buffer{}
+	if err := nn.ExportPyTorch(buf); err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range []string{
+		"self.w4 = nn.Parameter(torch.tensor([0.5, -1.0]))",
+		"n4 = torch.tanh(torch.stack([n1, n2], dim=-1) @ self.w4)",
+		"n3 = torch.sigmoid(torch.stack([n0, n4], dim=-1) @ self.w3)",
+		"return torch.stack([n3], dim=-1)",
+	} {
+		if !strings.Contains(buf.String(), line) {
+			t.Errorf("%q is not in the PyTorch script:\n%s", line, buf)
+		}
+	}
+
+	buf.Reset()
+	if err := nn.ExportKeras(buf); err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range []string{
+		"x = keras.Input(shape=(3,))",
+		"model.get_layer(\"node_3\").set_weights([np.array([[0.25, 2.0]]).T])",
+	} {
+		if !strings.Contains(buf.String(), line) {
+			t.Errorf("%q is not in the Keras script:\n%s", line, buf)
+		}
+	}
+
+	g.ConnGenes = append(g.ConnGenes, NewConnGene(3, 4, 1.0))
+	if err := NewNeuralNetwork(g).ExportPyTorch(buf); err == nil {
+		t.Error("recurrent network is not rejected")
+	}
+}
@@ -0,0 +1,222 @@
+// option.go implementation of options for creating a new instance of NEAT.
+//
+// Copyright (C) 2017  Jin Yeom
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package neat
+
+import (
+	"io"
+	"log"
+	"math/rand"
+)
+
+// Option is a type of function that overrides a default behavior of NEAT. It is
+// applied by New before the initial population is created.
+type Option func(n *NEAT)
+
+// WithComparison returns an option that replaces the comparison function, which
+// is otherwise determined by Config.MinimizeFitness. It ranks genomes in
+// selection and validation, and determines n.Best and the champion of each
+// generation, e.g., n.Champions; species stagnate by fitness regardless.
+func WithComparison(comparison ComparisonFunc) Option {
+	return func(n *NEAT) {
+		n.Comparison = comparison
+	}
+}
+
+// WithPopulationEvaluation returns an option that evaluates the whole
+// population at once with the argument function, instead of each genome with
+// the evaluation function, e.g., for coevolution, in which the fitness of a
+// genome depends on the others. Since the fitness is relative, every genome is
+// evaluated again in each generation.
+func WithPopulationEvaluation(evaluation PopulationEvaluationFunc) Option {
+	return WithPopulationEvaluator(evaluation)
+}
+
+// WithPopulationEvaluator returns an option that evaluates the whole population
+// at once with the argument evaluator, instead of each genome with the
+// evaluation function; as with WithPopulationEvaluation, every genome is
+// evaluated again in each generation.
+func WithPopulationEvaluator(evaluator PopulationEvaluator) Option {
+	return func(n *NEAT) {
+		n.PopulationEvaluation = evaluator
+	}
+}
+
+// WithFallibleEvaluation returns an option that evaluates each genome with the
+// argument function that may fail, instead of the evaluation function; a
+// genome whose evaluation returns an error is assigned the initial fitness,
+// and Run stops at the end of the generation (see NEAT.Err).
+func WithFallibleEvaluation(evaluation FallibleEvaluationFunc) Option {
+	return func(n *NEAT) {
+		n.FallibleEvaluation = evaluation
+	}
+}
+
+// WithContextEvaluation returns an option that evaluates each genome with the
+// argument function under a context, instead of the evaluation function; the
+// context is canceled when the evaluation exceeds Config.EvaluationTimeout,
+// after which the genome is assigned the initial fitness (see
+// NEAT.EvaluateContext).
+func WithContextEvaluation(evaluation ContextEvaluationFunc) Option {
+	return func(n *NEAT) {
+		n.ContextEvaluation = evaluation
+	}
+}
+
+// WithResultEvaluation returns an option that evaluates each genome with the
+// argument function of detailed results under a context, instead of the
+// evaluation function; e.g., the values of multiple objectives and the behavior
+// descriptor are recorded in each genome (see Genome.Objectives and
+// Genome.Behavior).
+func WithResultEvaluation(evaluation ResultEvaluationFunc) Option {
+	return func(n *NEAT) {
+		n.ResultEvaluation = evaluation
+	}
+}
+
+// WithValidation returns an option that evaluates the top k genomes (at least
+// the champion) of each generation by fitness with the argument held-out
+// evaluation function, and records the best of their scores in
+// Statistics.ValidationFitness, such that overfitting to the evaluation
+// function can be detected during the run; the scores do not affect the
+// evolution.
+func WithValidation(validation EvaluationFunc, k int) Option {
+	return func(n *NEAT) {
+		n.Validation = validation
+		n.ValidationTopK = k
+	}
+}
+
+// WithSelection returns an option that replaces the selection function of
+// surviving genomes in each species.
+func WithSelection(selection SelectionFunc) Option {
+	return func(n *NEAT) {
+		n.Selection = selection
+	}
+}
+
+// WithRand returns an option that replaces the random number generator used
+// during the evolution. Since its seed is unknown, the seed in the metadata of
+// the run is recorded as 0.
+func WithRand(rng *rand.Rand) Option {
+	return func(n *NEAT) {
+		n.Rand = rng
+		n.seed = 0
+	}
+}
+
+// WithSeed returns an option that replaces the random number generator with a
+// new one seeded with the argument seed, which is recorded in the metadata of
+// the run.
+func WithSeed(seed int64) Option {
+	return func(n *NEAT) {
+		n.Rand = rand.New(rand.NewSource(seed))
+		n.seed = seed
+	}
+}
+
+// WithWorkerRand returns an option that replaces the constructor of the random
+// number generators of reproduction workers (see NEAT.Reproduce), given a
+// seed. Since a generator is reseeded for each child, its source should be
+// cheap to seed.
+func WithWorkerRand(newRand func(seed int64) *rand.Rand) Option {
+	return func(n *NEAT) {
+		n.WorkerRand = newRand
+	}
+}
+
+// WithDistance returns an option that replaces the distance function of
+// speciation, which is otherwise the compatibility distance with
+// Config.CoeffUnmatching and Config.CoeffMatching.
+func WithDistance(distance DistanceFunc) Option {
+	return func(n *NEAT) {
+		n.Distance = distance
+	}
+}
+
+// WithIDGenerator returns an option that replaces the generator of IDs of
+// genomes and species, e.g., with a partition of IDs of a distributed run.
+func WithIDGenerator(ids IDGenerator) Option {
+	return func(n *NEAT) {
+		n.IDs = ids
+	}
+}
+
+// WithReproducer returns an option that replaces the reproduction policy.
+func WithReproducer(reproducer Reproducer) Option {
+	return func(n *NEAT) {
+		n.Reproducer = reproducer
+	}
+}
+
+// WithSpeciation returns an option that replaces the speciation function.
+func WithSpeciation(speciation SpeciationFunc) Option {
+	return func(n *NEAT) {
+		n.Speciation = speciation
+	}
+}
+
+// WithLogger returns an option that replaces the logger of verbose messages.
+func WithLogger(logger *log.Logger) Option {
+	return func(n *NEAT) {
+		n.Logger = logger
+	}
+}
+
+// WithCallback returns an option that adds a callback function, which is called
+// at the end of each generation.
+func WithCallback(callback Callback) Option {
+	return func(n *NEAT) {
+		n.Callbacks = append(n.Callbacks, callback)
+	}
+}
+
+// WithProgress returns an option that adds a progress reporter, which writes
+// the progress of the evolution to the argument writer (see
+// NewProgressReporter).
+func WithProgress(w io.Writer) Option {
+	return WithCallback(NewProgressReporter(w))
+}
+
+// WithMutation returns an option that adds a custom mutation operator of the
+// argument name, which is applied after the built-in mutations whenever a
+// genome is mutated; the name is recorded in the statistics and the genealogy
+// when the operator mutates a genome.
+func WithMutation(name string, mutate MutationFunc) Option {
+	return func(n *NEAT) {
+		n.Mutators = append(n.Mutators, &namedMutation{name: name, mutate: mutate})
+	}
+}
+
+// WithMutator returns an option that replaces the built-in mutation operators
+// (DefaultMutator) with the argument Mutator, e.g., of domain-specific
+// structural mutations, or one that wraps a DefaultMutator; custom mutation
+// operators of WithMutation are still applied after it.
+func WithMutator(mutator Mutator) Option {
+	return func(n *NEAT) {
+		n.Mutator = mutator
+	}
+}
+
+// SpeciationFunc is a type of function that assigns every genome in the
+// population of the argument NEAT to a species. (*NEAT).Speciate is the default
+// speciation function.
+type SpeciationFunc func(n *NEAT)
+
+// Callback is a type of function that is called at the end of each generation,
+// given the NEAT and the index of the generation.
+type Callback func(n *NEAT, gen int)
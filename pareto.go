@@ -0,0 +1,149 @@
+// pareto.go implementation of Pareto-based selection of multiple objectives.
+//
+// Copyright (C) 2017  Jin Yeom
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package neat
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+)
+
+// Dominates returns true if the first argument values of objectives Pareto
+// dominate the second, i.e., none of them is worse, and at least one of them
+// is better; objectives are maximized. Values of different numbers of
+// objectives never dominate each other.
+func Dominates(a, b []float64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	better := false
+	for i := range a {
+		if a[i] < b[i] {
+			return false
+		}
+		better = better || a[i] > b[i]
+	}
+	return better
+}
+
+// ParetoFronts sorts the argument genomes into Pareto fronts by their
+// objectives (Deb et al., 2002): the first front consists of the genomes that
+// no genome dominates, the second of those that only genomes of the first
+// front dominate, and so on.
+func ParetoFronts(genomes []*Genome) [][]*Genome {
+	dominated := make([][]int, len(genomes)) // genomes that each dominates
+	counts := make([]int, len(genomes))      // number of dominating genomes
+	var front []int
+	for i := range genomes {
+		for j := range genomes {
+			if Dominates(genomes[i].Objectives, genomes[j].Objectives) {
+				dominated[i] = append(dominated[i], j)
+			} else if Dominates(genomes[j].Objectives, genomes[i].Objectives) {
+				counts[i]++
+			}
+		}
+		if counts[i] == 0 {
+			front = append(front, i)
+		}
+	}
+
+	var fronts [][]*Genome
+	for len(front) > 0 {
+		members := make([]*Genome, len(front))
+		var next []int
+		for k, i := range front {
+			members[k] = genomes[i]
+			for _, j := range dominated[i] {
+				if counts[j]--; counts[j] == 0 {
+					next = append(next, j)
+				}
+			}
+		}
+		fronts = append(fronts, members)
+		front = next
+	}
+	return fronts
+}
+
+// CrowdingDistances returns the crowding distance of each of the argument
+// genomes of a front, i.e., the sum over objectives of the normalized distance
+// between its neighbors; the extremes of each objective have infinite
+// distances, such that the front remains diverse.
+func CrowdingDistances(front []*Genome) []float64 {
+	distances := make([]float64, len(front))
+	if len(front) == 0 {
+		return distances
+	}
+	order := make([]int, len(front))
+	for m := range front[0].Objectives {
+		for i := range order {
+			order[i] = i
+		}
+		sort.SliceStable(order, func(i, j int) bool {
+			return front[order[i]].Objectives[m] < front[order[j]].Objectives[m]
+		})
+
+		lo := front[order[0]].Objectives[m]
+		hi := front[order[len(order)-1]].Objectives[m]
+		distances[order[0]] = math.Inf(1)
+		distances[order[len(order)-1]] = math.Inf(1)
+		if hi == lo {
+			continue
+		}
+		for k := 1; k < len(order)-1; k++ {
+			distances[order[k]] += (front[order[k+1]].Objectives[m] -
+				front[order[k-1]].Objectives[m]) / (hi - lo)
+		}
+	}
+	return distances
+}
+
+// ParetoSelection is a selection function of NSGA-II, which keeps the members
+// of the best Pareto fronts of objectives, and, within the last front that
+// partially survives, the members of the largest crowding distances. If any of
+// the members are not evaluated with the same number of objectives, it falls
+// back to TruncationSelection.
+func ParetoSelection(members []*Genome, numSurvived int,
+	comparison ComparisonFunc, rng *rand.Rand) []*Genome {
+	for _, genome := range members {
+		if len(genome.Objectives) == 0 ||
+			len(genome.Objectives) != len(members[0].Objectives) {
+			return TruncationSelection(members, numSurvived, comparison, rng)
+		}
+	}
+
+	survivors := make([]*Genome, 0, numSurvived)
+	for _, front := range ParetoFronts(members) {
+		if len(survivors)+len(front) > numSurvived {
+			distances := CrowdingDistances(front)
+			order := make([]int, len(front))
+			for i := range order {
+				order[i] = i
+			}
+			sort.SliceStable(order, func(i, j int) bool {
+				return distances[order[i]] > distances[order[j]]
+			})
+			for _, i := range order[:numSurvived-len(survivors)] {
+				survivors = append(survivors, front[i])
+			}
+			break
+		}
+		survivors = append(survivors, front...)
+	}
+	return survivors
+}
@@ -0,0 +1,86 @@
+package neat
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestParetoFronts(t *testing.T) {
+	if !Dominates([]float64{1, 2}, []float64{1, 1}) ||
+		Dominates([]float64{1, 1}, []float64{1, 1}) ||
+		Dominates([]float64{2, 0}, []float64{1, 1}) ||
+		Dominates([]float64{2, 2}, []float64{1}) {
+		t.Error("invalid dominance")
+	}
+
+	objectives := [][]float64{{0, 3}, {1, 1}, {3, 0}, {2, 2}, {0, 0}, {1, 0}}
+	genomes := make([]*Genome, len(objectives))
+	for i := range genomes {
+		genomes[i] = NewGenome(i, 3, 1, 0.0)
+		genomes[i].Objectives = objectives[i]
+	}
+	fronts := ParetoFronts(genomes)
+	want := [][]int{{0, 2, 3}, {1}, {5}, {4}}
+	if len(fronts) != len(want) {
+		t.Fatalf("invalid number of fronts: %d != %d", len(fronts), len(want))
+	}
+	for i := range want {
+		if len(fronts[i]) != len(want[i]) {
+			t.Fatalf("invalid front %d: %v", i, fronts[i])
+		}
+		for j, id := range want[i] {
+			if fronts[i][j].ID != id {
+				t.Errorf("invalid member of front %d: %d != %d", i,
+					fronts[i][j].ID, id)
+			}
+		}
+	}
+
+	// the extremes of the first front are kept over the member in between.
+	distances := CrowdingDistances(fronts[0])
+	if !math.IsInf(distances[0], 1) || !math.IsInf(distances[1], 1) ||
+		distances[2] != 2.0 {
+		t.Errorf("invalid crowding distances: %v", distances)
+	}
+	survivors := ParetoSelection(genomes, 2, NewComparisonFunc(false),
+		rand.New(rand.NewSource(0)))
+	if len(survivors) != 2 || survivors[0].ID != 0 || survivors[1].ID != 2 {
+		t.Errorf("invalid survivors: %v, %v", survivors[0].ID, survivors[1].ID)
+	}
+}
+
+func TestNEATObjectives(t *testing.T) {
+	config := NewDefaultConfig(3, 1)
+	config.NumGenerations = 3
+	config.PopulationSize = 20
+	config.SelectionStrategy = "pareto"
+
+	// the objectives are the accuracy of XOR and the simplicity of the network.
+	xor := XORTest()
+	n := New(config, nil, WithSeed(0), WithResultEvaluation(
+		func(ctx context.Context, nn *NeuralNetwork) (EvaluationResult, error) {
+			accuracy := 4.0 - xor(nn)
+			simplicity := -float64(nn.NumSynapses())
+			return EvaluationResult{
+				Fitness:    accuracy,
+				Objectives: []float64{accuracy, simplicity},
+			}, nil
+		}))
+	n.Run()
+	for _, genome := range n.Population {
+		if genome.evaluated && len(genome.Objectives) != 2 {
+			t.Fatalf("objectives are not recorded: %v", genome.Objectives)
+		}
+	}
+	if len(n.Best.Objectives) != 2 {
+		t.Errorf("objectives of the best genome are not copied")
+	}
+	for gen := 0; gen < config.NumGenerations; gen++ {
+		avg, best := n.Statistics.AvgObjectives[gen], n.Statistics.MaxObjectives[gen]
+		if len(avg) != 2 || len(best) != 2 || avg[1] > best[1] || best[1] > 0.0 {
+			t.Errorf("invalid statistics of objectives: %v, %v", avg, best)
+		}
+	}
+}
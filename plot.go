@@ -0,0 +1,241 @@
+// plot.go implementation of plots of the statistics of the evolution.
+//
+// Copyright (C) 2017  Jin Yeom
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+//go:build !js
+
+package neat
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const (
+	plotScale       = 3   // pixels per point, i.e., 216 DPI at 72 points/inch
+	plotWidth       = 800 // width of a chart in points
+	plotPanelHeight = 300 // height of each panel of a chart in points
+	plotMarginLeft  = 70  // margin at the left of the axes, for tick values
+	plotMarginRight = 20  // margin at the right of the axes
+	plotMarginTop   = 30  // margin above the axes, for the title and legend
+	plotMarginBelow = 40  // margin below the axes, for tick values and label
+	plotGridLines   = 5   // number of horizontal grid lines in a panel
+	plotTicks       = 5   // maximum number of ticks on the x-axis
+)
+
+var (
+	plotAxisColor = color.RGBA{0x33, 0x33, 0x33, 0xff}
+	plotGridColor = color.RGBA{0xdd, 0xdd, 0xdd, 0xff}
+	plotMaxColor  = color.RGBA{0xcc, 0x33, 0x33, 0xff}
+	plotAvgColor  = color.RGBA{0x33, 0xaa, 0x33, 0xff}
+	plotMinColor  = color.RGBA{0x33, 0x33, 0xcc, 0xff}
+	plotSpcColor  = color.RGBA{0xaa, 0x33, 0xaa, 0xff}
+)
+
+// plotSeries is a series of values drawn as a line in a panel of a chart.
+type plotSeries struct {
+	Name   string      // name of the series in the legend
+	Values []float64   // value of each recorded generation
+	Color  color.Color // color of the line
+}
+
+// PlotPNG renders figures of the statistics of the recorded generations to a
+// PNG file at the argument path. The figure has two panels: the maximum,
+// average, and minimum fitness on top, and the number of species at the
+// bottom, each with a title, a legend, tick values on both axes, and the
+// generation as the label of the x-axis. It is rendered at plotScale pixels
+// per point (2400x1800 pixels), such that it stays sharp when printed at the
+// width of a column. It is drawn with the standard library only, as is the
+// rest of this package.
+func (s *Statistics) PlotPNG(path string) error {
+	img := image.NewRGBA(image.Rect(0, 0, plotWidth*plotScale,
+		2*plotPanelHeight*plotScale))
+	draw.Draw(img, img.Bounds(), image.White, image.Point{}, draw.Src)
+
+	generations := s.Generations[:s.numRecorded]
+	numSpecies := make([]float64, s.numRecorded)
+	for i := range numSpecies {
+		numSpecies[i] = float64(s.NumSpecies[i])
+	}
+
+	drawPanel(img, image.Rect(0, 0, plotWidth, plotPanelHeight), "Fitness",
+		generations, []plotSeries{
+			{"Max", s.MaxFitness[:s.numRecorded], plotMaxColor},
+			{"Avg", s.AvgFitness[:s.numRecorded], plotAvgColor},
+			{"Min", s.MinFitness[:s.numRecorded], plotMinColor},
+		})
+	drawPanel(img, image.Rect(0, plotPanelHeight, plotWidth,
+		2*plotPanelHeight), "Species", generations, []plotSeries{
+		{"Species", numSpecies, plotSpcColor},
+	})
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err = png.Encode(f, img); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// drawPanel draws the title, the legend, the axes with their tick values, the
+// grid lines, and the argument series within the argument rectangle of the
+// image, which is in points; the i-th value of each series is of the i-th
+// argument generation.
+func drawPanel(img *image.RGBA, rect image.Rectangle, title string,
+	generations []int, series []plotSeries) {
+	const u = plotScale // pixels per point
+	rect = image.Rect(rect.Min.X*u, rect.Min.Y*u, rect.Max.X*u, rect.Max.Y*u)
+
+	// origin, and the top right corner of the axes
+	x0, y0 := rect.Min.X+plotMarginLeft*u, rect.Max.Y-plotMarginBelow*u
+	x1, y1 := rect.Max.X-plotMarginRight*u, rect.Min.Y+plotMarginTop*u
+
+	// range of values, which is widened if all values are the same.
+	lo, hi := math.Inf(1), math.Inf(-1)
+	length := 0
+	for _, s := range series {
+		for _, v := range s.Values {
+			lo, hi = math.Min(lo, v), math.Max(hi, v)
+		}
+		if len(s.Values) > length {
+			length = len(s.Values)
+		}
+	}
+	if length == 0 {
+		lo, hi = 0.0, 1.0
+	}
+	if hi == lo {
+		lo, hi = lo-0.5, hi+0.5
+	}
+
+	// horizontal grid lines, labeled with their values.
+	for i := 0; i <= plotGridLines; i++ {
+		y := y0 - (y0-y1)*i/plotGridLines
+		drawLine(img, x0, y, x1, y, u, plotGridColor)
+		label := formatTick(lo + (hi-lo)*float64(i)/plotGridLines)
+		drawText(img, x0-(6+textWidth(label))*u, y-glyphHeight*u/2, u, label,
+			plotAxisColor)
+	}
+	drawLine(img, x0, y0, x1, y0, u, plotAxisColor)
+	drawLine(img, x0, y0, x0, y1, u, plotAxisColor)
+
+	// position of the i-th value of a series in the panel
+	xOf := func(i int) int {
+		if length > 1 {
+			return x0 + (x1-x0)*i/(length-1)
+		}
+		return x0
+	}
+	point := func(i int, v float64) (int, int) {
+		return xOf(i), y0 - int(float64(y0-y1)*(v-lo)/(hi-lo))
+	}
+
+	// ticks on the x-axis, labeled with the generations of evenly spaced
+	// records, and the label of the axis.
+	numTicks := plotTicks
+	if length < numTicks {
+		numTicks = length
+	}
+	for k := 0; k < numTicks; k++ {
+		i := 0
+		if numTicks > 1 {
+			i = (length - 1) * k / (numTicks - 1)
+		}
+		x := xOf(i)
+		drawLine(img, x, y0, x, y0+4*u, u, plotAxisColor)
+		label := strconv.Itoa(generations[i])
+		drawText(img, x-textWidth(label)*u/2, y0+8*u, u, label, plotAxisColor)
+	}
+	drawText(img, (x0+x1-textWidth("Generation")*u)/2, y0+24*u, u,
+		"Generation", plotAxisColor)
+
+	// title at the top left, and legend at the top right.
+	drawText(img, x0, rect.Min.Y+10*u, u, title, plotAxisColor)
+	lx := x1
+	for i := len(series) - 1; i >= 0; i-- {
+		lx -= textWidth(series[i].Name) * u
+		drawText(img, lx, rect.Min.Y+10*u, u, series[i].Name, plotAxisColor)
+		lx -= 20 * u
+		drawLine(img, lx, rect.Min.Y+13*u, lx+14*u, rect.Min.Y+13*u, 2*u,
+			series[i].Color)
+		lx -= 12 * u
+	}
+
+	for _, s := range series {
+		for i := 1; i < len(s.Values); i++ {
+			px, py := point(i-1, s.Values[i-1])
+			qx, qy := point(i, s.Values[i])
+			drawLine(img, px, py, qx, qy, 2*u, s.Color)
+		}
+		if len(s.Values) == 1 {
+			px, py := point(0, s.Values[0])
+			drawLine(img, px, py, px, py, 2*u, s.Color)
+		}
+	}
+}
+
+// formatTick returns the argument value of a tick with at most 4 significant
+// digits.
+func formatTick(v float64) string {
+	return strings.ToUpper(strconv.FormatFloat(v, 'g', 4, 64))
+}
+
+// drawLine draws a line of the argument width in pixels between two points
+// with Bresenham's algorithm.
+func drawLine(img *image.RGBA, x0, y0, x1, y1, width int, c color.Color) {
+	dx, dy := x1-x0, y1-y0
+	if dx < 0 {
+		dx = -dx
+	}
+	if dy < 0 {
+		dy = -dy
+	}
+	sx, sy := 1, 1
+	if x0 > x1 {
+		sx = -1
+	}
+	if y0 > y1 {
+		sy = -1
+	}
+
+	err := dx - dy
+	for {
+		pen := image.Rect(x0, y0, x0+width, y0+width).Sub(
+			image.Pt(width/2, width/2))
+		draw.Draw(img, pen, image.NewUniform(c), image.Point{}, draw.Src)
+		if x0 == x1 && y0 == y1 {
+			return
+		}
+		e2 := 2 * err
+		if e2 > -dy {
+			err -= dy
+			x0 += sx
+		}
+		if e2 < dx {
+			err += dx
+			y0 += sy
+		}
+	}
+}
@@ -0,0 +1,80 @@
+// progress.go implementation of progress reporting of the evolution.
+//
+// Copyright (C) 2017  Jin Yeom
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package neat
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// Progress is a snapshot of the progress of the evolution, which is returned by
+// NEAT.Progress.
+type Progress struct {
+	Generation     int           // number of generations done
+	NumGenerations int           // number of generations of the run
+	BestFitness    float64       // fitness of the best genome so far
+	NumSpecies     int           // number of species
+	Elapsed        time.Duration // time since the start of the run
+}
+
+const (
+	// progressBarWidth is the number of characters in a progress bar.
+	progressBarWidth = 30
+)
+
+// NewProgressReporter returns a callback that reports the progress of the
+// evolution to the argument writer at the end of each generation, i.e., the
+// number of generations done, the number of evaluations per second during the
+// evaluation of the generation (see Statistics.EvaluationTime), and the
+// estimated time remaining. The report is rewritten in place with a carriage
+// return, and ends with a newline after the last generation. If the timing of
+// a generation is not recorded (see NewBoundedStatistics), the last rate is
+// reported again.
+func NewProgressReporter(w io.Writer) Callback {
+	evalsPerSec := 0.0
+	return func(n *NEAT, gen int) {
+		now := time.Now()
+		if e, ok := n.Statistics.lookup(gen); ok &&
+			n.Statistics.EvaluationTime[e] > 0 {
+			evalsPerSec = float64(len(n.Population)) /
+				n.Statistics.EvaluationTime[e].Seconds()
+		}
+
+		done := gen + 1
+		total := n.Config.NumGenerations
+		if done > total {
+			total = done
+		}
+
+		// estimate the time remaining with the average time of a generation.
+		perGen := now.Sub(n.Statistics.RunInfo.StartTime) / time.Duration(done)
+		eta := perGen * time.Duration(total-done)
+
+		filled := progressBarWidth * done / total
+		bar := strings.Repeat("=", filled) +
+			strings.Repeat(" ", progressBarWidth-filled)
+		fmt.Fprintf(w, "\rGen. %4d/%d [%s] %5.1f%% | %8.1f evals/s | ETA %s",
+			done, total, bar, 100.0*float64(done)/float64(total), evalsPerSec,
+			eta.Round(time.Second))
+		if done == total {
+			fmt.Fprintln(w)
+		}
+	}
+}
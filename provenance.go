@@ -0,0 +1,74 @@
+// provenance.go implementation of the provenance of exported genomes.
+//
+// Copyright (C) 2017  Jin Yeom
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package neat
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+)
+
+// Provenance is the origin of an exported genome, with which a champion file
+// can be traced back to the run that produced it, long after the run.
+type Provenance struct {
+	RunInfo    *RunInfo `json:"runInfo"`    // metadata of the run, e.g., its ID
+	Config     *Config  `json:"config"`     // configuration of the run
+	Generation int      `json:"generation"` // last generation evaluated
+
+	// SHA-256 hash of the lineage of the genome, if the run tracks it (see
+	// Genealogy), which identifies its evolutionary history
+	AncestryHash string `json:"ancestryHash,omitempty"`
+}
+
+// Provenance returns the provenance of the argument genome of this run, as of
+// the last generation evaluated (-1 if none).
+func (n *NEAT) Provenance(g *Genome) *Provenance {
+	return n.provenance(g, n.Generation-1)
+}
+
+// provenance returns the provenance of the argument genome of this run, as of
+// the argument generation.
+func (n *NEAT) provenance(g *Genome, gen int) *Provenance {
+	runInfo := *n.Statistics.RunInfo
+	p := &Provenance{
+		RunInfo:    &runInfo,
+		Config:     n.Config.Copy(),
+		Generation: gen,
+	}
+	if n.Genealogy != nil {
+		if data, err := json.Marshal(n.Genealogy.Lineage(g.ID)); err == nil {
+			sum := sha256.Sum256(data)
+			p.AncestryHash = hex.EncodeToString(sum[:])
+		}
+	}
+	return p
+}
+
+// ExportGenomeJSON writes the argument genome to the argument writer in JSON,
+// which can be read as a file with NewGenomeJSON, along with its provenance in
+// this run if provenance is true.
+func (n *NEAT) ExportGenomeJSON(w io.Writer, g *Genome, provenance bool) error {
+	exported := g.Copy()
+	if provenance {
+		exported.Provenance = n.Provenance(g)
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "\t")
+	return encoder.Encode(exported)
+}
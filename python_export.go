@@ -0,0 +1,207 @@
+// python_export.go implementation of exporting networks as Python scripts.
+//
+// Copyright (C) 2017  Jin Yeom
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package neat
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// pythonActivations are the expressions of activation functions in PyTorch and
+// Keras, where %s is the argument.
+var pythonActivations = map[string][2]string{
+	"Identity": {"%s", "%s"},
+	"Linear":   {"%s", "%s"},
+	"Sigmoid":  {"torch.sigmoid(%s)", "tf.math.sigmoid(%s)"},
+	"Tanh":     {"torch.tanh(%s)", "tf.math.tanh(%s)"},
+	"Sine":     {"torch.sin(%s)", "tf.math.sin(%s)"},
+	"Cosine":   {"torch.cos(%s)", "tf.math.cos(%s)"},
+	"ReLU":     {"torch.relu(%s)", "tf.nn.relu(%s)"},
+	"Log":      {"torch.log(%s)", "tf.math.log(%s)"},
+	"Exp":      {"torch.exp(%s)", "tf.math.exp(%s)"},
+	"Abs":      {"torch.abs(%s)", "tf.math.abs(%s)"},
+	"Square":   {"torch.square(%s)", "tf.math.square(%s)"},
+	"Cube":     {"torch.pow(%s, 3)", "tf.math.pow(%s, 3.0)"},
+	"Gaussian": {"torch.exp(-torch.square(%s) / 2) / math.sqrt(2 * math.pi)",
+		"tf.math.exp(-tf.math.square(%s) / 2) / math.sqrt(2 * math.pi)"},
+}
+
+// pythonGraph returns the feedforward graph of this network (see graph); it
+// returns an error if the network is recurrent, or if an activation function
+// does not exist in Python.
+func (n *NeuralNetwork) pythonGraph() ([]*graphNode, []int, error) {
+	nodes, outputs, err := n.graph()
+	if err == errRecurrent {
+		return nil, nil, errors.New("neat: recurrent networks cannot be exported")
+	} else if err != nil {
+		return nil, nil, err
+	}
+	for _, node := range nodes {
+		if node.input >= 0 || len(node.sources) == 0 {
+			continue
+		}
+		if _, ok := pythonActivations[node.activationName()]; !ok {
+			return nil, nil, fmt.Errorf(
+				"neat: activation function %s cannot be exported",
+				node.activationName())
+		}
+	}
+	return nodes, outputs, nil
+}
+
+// ExportPyTorch writes a Python script that defines this feedforward network
+// as a PyTorch module, Network, whose synapse weights are parameters, such that
+// it can be fine-tuned with gradient descent. The module maps a batch of
+// inputs of shape (batch, inputs) to outputs of shape (batch, outputs); as in
+// this network, a neuron without synapses outputs 0.
+func (n *NeuralNetwork) ExportPyTorch(w io.Writer) error {
+	nodes, outputs, err := n.pythonGraph()
+	if err != nil {
+		return err
+	}
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "# PyTorch network exported from github.com/jinyeom/neat "+
+		"(inputs: %d, outputs: %d).\n", len(n.inputNeurons), len(outputs))
+	fmt.Fprint(bw, "import math\n\nimport torch\nfrom torch import nn\n\n\n")
+	fmt.Fprint(bw, "class Network(nn.Module):\n")
+	fmt.Fprint(bw, "    def __init__(self):\n        super().__init__()\n")
+	for _, node := range nodes {
+		if len(node.sources) != 0 {
+			fmt.Fprintf(bw, "        self.w%d = nn.Parameter(torch.tensor([%s]))\n",
+				node.id, pythonFloats(node.weights))
+		}
+	}
+	fmt.Fprint(bw, "\n    def forward(self, x):\n")
+	for _, node := range nodes {
+		switch {
+		case node.input >= 0:
+			fmt.Fprintf(bw, "        n%d = x[:, %d]\n", node.id, node.input)
+		case len(node.sources) == 0:
+			fmt.Fprintf(bw, "        n%d = torch.zeros_like(x[:, 0])\n", node.id)
+		default:
+			sum := fmt.Sprintf("torch.stack([%s], dim=-1) @ self.w%d",
+				pythonNames(node.sources), node.id)
+			fmt.Fprintf(bw, "        n%d = %s\n", node.id,
+				fmt.Sprintf(pythonActivations[node.activationName()][0], sum))
+		}
+	}
+	fmt.Fprintf(bw, "        return torch.stack([%s], dim=-1)\n\n\n",
+		pythonNames(outputs))
+	fmt.Fprint(bw, "if __name__ == \"__main__\":\n")
+	fmt.Fprintf(bw, "    print(Network()(torch.zeros(1, %d)))\n",
+		len(n.inputNeurons))
+	return bw.Flush()
+}
+
+// ExportKeras writes a Python script that defines this feedforward network as
+// a Keras model, built by build_model, in which each neuron with synapses is a
+// Dense layer of a single unit without bias, named "node_<ID>", whose kernel
+// is set to the synapse weights, such that it can be fine-tuned with gradient
+// descent. As in this network, a neuron without synapses outputs 0.
+func (n *NeuralNetwork) ExportKeras(w io.Writer) error {
+	nodes, outputs, err := n.pythonGraph()
+	if err != nil {
+		return err
+	}
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "# Keras network exported from github.com/jinyeom/neat "+
+		"(inputs: %d, outputs: %d).\n", len(n.inputNeurons), len(outputs))
+	fmt.Fprint(bw, "import math\n\nimport numpy as np\nimport tensorflow as tf\n"+
+		"from tensorflow import keras\n\n\n")
+	fmt.Fprint(bw, "def build_model():\n")
+	fmt.Fprintf(bw, "    x = keras.Input(shape=(%d,))\n", len(n.inputNeurons))
+	for _, node := range nodes {
+		switch {
+		case node.input >= 0:
+			fmt.Fprintf(bw, "    n%d = keras.layers.Lambda(lambda t: t[:, %d:%d])(x)\n",
+				node.id, node.input, node.input+1)
+		case len(node.sources) == 0:
+			fmt.Fprintf(bw, "    n%d = keras.layers.Lambda("+
+				"lambda t: tf.zeros_like(t[:, 0:1]))(x)\n", node.id)
+		default:
+			sum := pythonConcat(node.sources)
+			activation := pythonActivations[node.activationName()][1]
+			if activation == "%s" {
+				activation = "None"
+			} else {
+				activation = "lambda z: " + fmt.Sprintf(activation, "z")
+			}
+			fmt.Fprintf(bw, "    n%d = keras.layers.Dense(1, use_bias=False, "+
+				"activation=%s, name=\"node_%d\")(%s)\n",
+				node.id, activation, node.id, sum)
+		}
+	}
+	fmt.Fprintf(bw, "    model = keras.Model(inputs=x, outputs=%s)\n",
+		pythonConcat(outputs))
+	for _, node := range nodes {
+		if len(node.sources) != 0 {
+			fmt.Fprintf(bw, "    model.get_layer(\"node_%d\").set_weights("+
+				"[np.array([[%s]]).T])\n", node.id, pythonFloats(node.weights))
+		}
+	}
+	fmt.Fprint(bw, "    return model\n\n\n")
+	fmt.Fprint(bw, "if __name__ == \"__main__\":\n")
+	fmt.Fprintf(bw, "    print(build_model()(np.zeros((1, %d))))\n",
+		len(n.inputNeurons))
+	return bw.Flush()
+}
+
+// pythonFloats returns the argument values as a Python list without brackets,
+// in the shortest representation that is read back exactly.
+func pythonFloats(values []float64) string {
+	strs := make([]string, len(values))
+	for i, v := range values {
+		switch {
+		case math.IsNaN(v):
+			strs[i] = "float(\"nan\")"
+		case math.IsInf(v, 0):
+			strs[i] = "float(\"" + strconv.FormatFloat(v, 'g', -1, 64) + "\")"
+		default:
+			strs[i] = strconv.FormatFloat(v, 'g', -1, 64)
+			if !strings.ContainsAny(strs[i], ".e") {
+				strs[i] += ".0"
+			}
+		}
+	}
+	return strings.Join(strs, ", ")
+}
+
+// pythonNames returns the names of the variables of the argument neurons.
+func pythonNames(ids []int) string {
+	names := make([]string, len(ids))
+	for i, id := range ids {
+		names[i] = "n" + strconv.Itoa(id)
+	}
+	return strings.Join(names, ", ")
+}
+
+// pythonConcat returns the Keras expression of the concatenation of the
+// argument neurons, or the neuron itself if there is only one.
+func pythonConcat(ids []int) string {
+	if len(ids) == 1 {
+		return pythonNames(ids)
+	}
+	return fmt.Sprintf("keras.layers.Concatenate()([%s])", pythonNames(ids))
+}
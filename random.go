@@ -0,0 +1,73 @@
+// random.go implementation of random number generation used in evolution.
+//
+// Copyright (C) 2017  Jin Yeom
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package neat
+
+import (
+	"math/rand"
+)
+
+// globalRand is a random number generator that draws from the global source of
+// package math/rand; it is used wherever no generator is injected, so that
+// seeding with rand.Seed keeps working.
+var globalRand = rand.New(globalSource{})
+
+// globalSource is a rand.Source that delegates to the global source of package
+// math/rand, which is safe for concurrent use.
+type globalSource struct{}
+
+// Int63 returns a non-negative pseudo-random 63-bit integer.
+func (globalSource) Int63() int64 {
+	return rand.Int63()
+}
+
+// Seed seeds the global source of package math/rand.
+func (globalSource) Seed(seed int64) {
+	rand.Seed(seed)
+}
+
+// newWorkerRand returns a new random number generator of a reproduction worker
+// seeded with the argument seed. Since the generator of a worker is reseeded
+// for each child, its source is SplitMix64, which is seeded in constant time,
+// unlike the default source of package math/rand.
+func newWorkerRand(seed int64) *rand.Rand {
+	return rand.New(&splitMix{state: uint64(seed)})
+}
+
+// splitMix is a rand.Source64 of the SplitMix64 algorithm.
+type splitMix struct {
+	state uint64 // state of the generator
+}
+
+// Seed seeds the generator with the argument seed.
+func (s *splitMix) Seed(seed int64) {
+	s.state = uint64(seed)
+}
+
+// Uint64 returns a pseudo-random 64-bit integer.
+func (s *splitMix) Uint64() uint64 {
+	s.state += 0x9e3779b97f4a7c15
+	z := s.state
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+	return z ^ (z >> 31)
+}
+
+// Int63 returns a non-negative pseudo-random 63-bit integer.
+func (s *splitMix) Int63() int64 {
+	return int64(s.Uint64() >> 1)
+}
@@ -0,0 +1,57 @@
+// report.go implementation of the report of a run.
+//
+// Copyright (C) 2017  Jin Yeom
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package neat
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// Report is a self-contained bundle of the artifacts of a run: its
+// configuration, metadata, statistics, best genome, and hall of fame.
+type Report struct {
+	Config     *Config     `json:"config"`     // configuration of the run
+	RunInfo    *RunInfo    `json:"runInfo"`    // metadata of the run
+	Statistics *Statistics `json:"statistics"` // statistics of each generation
+	Best       *Genome     `json:"best"`       // best genome found
+	HallOfFame []*Genome   `json:"hallOfFame"` // champion of each generation
+}
+
+// Report returns the report of the run so far. The hall of fame consists of
+// the champions of the generations, which are only archived if
+// Config.ArchiveChampions is true.
+func (n *NEAT) Report() *Report {
+	return &Report{
+		Config:     n.Config,
+		RunInfo:    n.Statistics.RunInfo,
+		Statistics: n.Statistics.recorded(),
+		Best:       n.Best,
+		HallOfFame: n.Champions,
+	}
+}
+
+// WriteTo writes the report to the argument writer as a JSON document, and
+// returns the number of bytes written.
+func (r *Report) WriteTo(w io.Writer) (int64, error) {
+	data, err := json.MarshalIndent(r, "", "\t")
+	if err != nil {
+		return 0, err
+	}
+	written, err := w.Write(append(data, '\n'))
+	return int64(written), err
+}
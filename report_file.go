@@ -0,0 +1,64 @@
+// report_file.go implementation of report files.
+//
+// Copyright (C) 2017  Jin Yeom
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+//go:build !js
+
+package neat
+
+import (
+	"encoding/json"
+	"os"
+)
+
+// Save writes the report to a JSON file of the argument name.
+func (r *Report) Save(filename string) error {
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	if _, err = r.WriteTo(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// NewReportJSON reads a report from a JSON file of the argument name, which is
+// written by Report.Save. Activation functions of the genomes are restored by
+// their names.
+func NewReportJSON(filename string) (*Report, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	r := &Report{}
+	if err = json.NewDecoder(f).Decode(r); err != nil {
+		return nil, err
+	}
+	genomes := r.HallOfFame
+	if r.Best != nil {
+		genomes = append([]*Genome{r.Best}, genomes...)
+	}
+	for _, genome := range genomes {
+		if err = RestoreGenome(genome); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
@@ -0,0 +1,39 @@
+//go:build !js
+
+package neat
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestReport(t *testing.T) {
+	config := NewDefaultConfig(3, 1)
+	config.NumGenerations = 3
+	config.PopulationSize = 20
+	config.ArchiveChampions = true
+
+	n := New(config, XORTest(), WithSeed(1))
+	n.Run()
+
+	filename := filepath.Join(t.TempDir(), "report.json")
+	if err := n.Report().Save(filename); err != nil {
+		t.Fatal(err)
+	}
+	r, err := NewReportJSON(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Config.PopulationSize != 20 || r.RunInfo.Seed != 1 {
+		t.Errorf("invalid config or run info: %+v, %+v", r.Config, r.RunInfo)
+	}
+	if r.Best.ID != n.Best.ID || len(r.HallOfFame) != config.NumGenerations {
+		t.Errorf("invalid best genome or hall of fame: %d, %d",
+			r.Best.ID, len(r.HallOfFame))
+	}
+	if len(r.Statistics.MaxFitness) != config.NumGenerations {
+		t.Errorf("invalid statistics: %d generations",
+			len(r.Statistics.MaxFitness))
+	}
+	NewNeuralNetwork(r.Best).FeedForward([]float64{1.0, 0.0, 1.0})
+}
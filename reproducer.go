@@ -0,0 +1,180 @@
+// reproducer.go implementation of reproduction policies.
+//
+// Copyright (C) 2017  Jin Yeom
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package neat
+
+import (
+	"math"
+	"math/rand"
+)
+
+// Reproducer is an interface of reproduction policies, i.e., the selection of
+// survivors, the allocation of offspring, and the pairing of parents, which
+// replace the population with the next generation, given the species of the
+// current one; e.g., a steady-state or an elitist scheme. Reproduce returns
+// the next generation of the argument NEAT, whose genomes are speciated and
+// evaluated; NEAT.Offspring and NEAT.Mutate create and mutate genomes with its
+// crossover, mutation operators, and bookkeeping.
+type Reproducer interface {
+	Reproduce(n *NEAT) []*Genome
+}
+
+// DefaultReproducer is the default Reproducer of NEAT, which eliminates the
+// worst genomes of each species, and replaces them with children of the
+// survivors.
+type DefaultReproducer struct{}
+
+// Reproduce returns the next generation of the genomes in each species of the
+// argument NEAT. The number of eliminated genomes in each species is determined
+// by rate of elimination specified in n.Config; after some number of genomes
+// are eliminated, the empty space is filled with resulting genomes of crossover
+// among surviving genomes. If the number of eliminated genomes is 0 or less
+// then 2 genomes survive, every member survives and mutates.
+//
+// If n.Config.NumWorkers is greater than 1, as many children are created, and
+// genomes are mutated, concurrently, each with a generator of n.WorkerRand
+// seeded from n.Rand; thus, custom mutation operators must be safe for
+// concurrent use, and the selection function must not choose a genome twice.
+func (DefaultReproducer) Reproduce(n *NEAT) []*Genome {
+	if n.Config.NumWorkers > 1 {
+		return reproduceParallel(n)
+	}
+
+	nextGeneration := make([]*Genome, 0, n.Config.PopulationSize)
+	for _, s := range n.Species {
+		// genomes in this species can inherit to the next generation, if two or
+		// more genomes survive in this species, and there is room for more
+		// children, i.e., at least one genome must be eliminated.
+		numSurvived := int(math.Ceil(float64(len(s.Members)) *
+			n.Config.SurvivalRate))
+		numEliminated := len(s.Members) - numSurvived
+
+		// reproduction of this species is only executed, if there is enough room.
+		if numSurvived > 2 && numEliminated > 0 {
+			// adjust the fitness of each member genome of this species.
+			//s.ExplicitFitnessSharing()
+
+			survivors := n.Selection(s.Members, numSurvived, n.Comparison, n.Rand)
+
+			// fill the spaces that are made by eliminated genomes, by creating
+			// children.
+			for i := 0; i < numEliminated; i++ {
+				i0, i1 := randomPair(numSurvived, n.Rand)
+
+				// create a child from two chosen parents as a result of crossover;
+				// mutate the child given the rate of mutation of children.
+				child := n.Offspring(survivors[i0], survivors[i1])
+				nextGeneration = append(nextGeneration, child)
+			}
+
+			// mutate all the genomes that survived.
+			for _, genome := range survivors {
+				n.mutate(genome)
+				nextGeneration = append(nextGeneration, genome)
+			}
+		} else {
+			// otherwise, they all survive, and mutate.
+			for _, genome := range s.Members {
+				n.mutate(genome)
+				nextGeneration = append(nextGeneration, genome)
+			}
+		}
+	}
+
+	return nextGeneration
+}
+
+// randomPair returns two distinct random indices in [0, num), where num is at
+// least 2, in constant time; drawing them from a permutation would make
+// reproduction quadratic in the size of a species.
+func randomPair(num int, rng *rand.Rand) (int, int) {
+	i := rng.Intn(num)
+	j := rng.Intn(num - 1)
+	if j >= i {
+		j++
+	}
+	return i, j
+}
+
+// reproduceParallel is DefaultReproducer.Reproduce with concurrent workers.
+// Parents are chosen, and IDs and seeds are assigned, sequentially in the same
+// order as Reproduce; then children are created and mutated, followed by the
+// survivors, which may be their parents; finally, the mutations are recorded
+// in order.
+func reproduceParallel(n *NEAT) []*Genome {
+	type offspring struct {
+		genome   *Genome   // child, or surviving genome
+		p0, p1   *Genome   // parents of a child (nil for a survivor)
+		id       int       // ID of a child
+		mutation *mutation // mutations applied, if any
+	}
+
+	var children, survivors []*offspring
+	var childSeeds, survivorSeeds []int64
+	order := make([]*offspring, 0, n.Config.PopulationSize)
+	for _, s := range n.Species {
+		numSurvived := int(math.Ceil(float64(len(s.Members)) *
+			n.Config.SurvivalRate))
+		numEliminated := len(s.Members) - numSurvived
+
+		members := s.Members
+		if numSurvived > 2 && numEliminated > 0 {
+			members = n.Selection(s.Members, numSurvived, n.Comparison, n.Rand)
+			for i := 0; i < numEliminated; i++ {
+				i0, i1 := randomPair(numSurvived, n.Rand)
+				child := &offspring{
+					p0: members[i0],
+					p1: members[i1],
+					id: n.newGenomeID(),
+				}
+				children = append(children, child)
+				childSeeds = append(childSeeds, n.Rand.Int63())
+				order = append(order, child)
+			}
+		}
+		for _, genome := range members {
+			survivor := &offspring{genome: genome}
+			survivors = append(survivors, survivor)
+			survivorSeeds = append(survivorSeeds, n.Rand.Int63())
+			order = append(order, survivor)
+		}
+	}
+
+	n.parallelRand(childSeeds, func(i int, rng *rand.Rand) {
+		c := children[i]
+		c.genome = crossover(c.id, c.p0, c.p1, n.Config.InitFitness, rng)
+		// if the two parents are identical, definitely mutate the child.
+		if rng.Float64() < n.Config.RateMutateChild || c.p0.ID == c.p1.ID {
+			c.mutation = n.mutateWith(c.genome, rng)
+		}
+	})
+	n.parallelRand(survivorSeeds, func(i int, rng *rand.Rand) {
+		survivors[i].mutation = n.mutateWith(survivors[i].genome, rng)
+	})
+
+	nextGeneration := make([]*Genome, 0, len(order))
+	for _, o := range order {
+		if o.p0 != nil && n.Genealogy != nil {
+			n.Genealogy.Record(o.id, n.Generation+1, o.p0.ID, o.p1.ID)
+		}
+		if o.mutation != nil {
+			n.recordMutation(o.mutation)
+		}
+		nextGeneration = append(nextGeneration, o.genome)
+	}
+	return nextGeneration
+}
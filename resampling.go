@@ -0,0 +1,160 @@
+// resampling.go implementation of adaptive resampling of noisy evaluations.
+//
+// Copyright (C) 2017  Jin Yeom
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package neat
+
+import (
+	"math"
+	"sort"
+)
+
+// Resampling is a population evaluator for noisy evaluation functions, which
+// evaluates each genome repeatedly and assigns the mean of its samples as its
+// fitness. Rather than a fixed number of samples for every genome, it first
+// takes the minimum number of samples of each genome, then spends the rest of
+// its budget on the genomes whose ranking is ambiguous, i.e., whose confidence
+// interval of the mean overlaps with that of a neighbor in the ranking, widest
+// interval first, until every interval is tight enough or separated, or the
+// budget is spent. A genome whose sample is NaN fails to be evaluated.
+type Resampling struct {
+	Evaluation EvaluationFunc // noisy evaluation function
+	MinSamples int            // number of samples of every genome (at least 2)
+	MaxSamples int            // maximum number of samples of a genome
+	Budget     int            // maximum number of samples per generation
+	Z          float64        // z-score of the confidence level of intervals
+	Tolerance  float64        // half-width of intervals that are tight enough
+
+	// number of samples of each genome in the last evaluation
+	Samples []int
+}
+
+// NewResampling returns a new instance of Resampling, given a noisy evaluation
+// function and the minimum and maximum numbers of samples of a genome, with
+// 95% confidence intervals, and without a budget per generation other than
+// the maximum number of samples of each genome.
+func NewResampling(evaluation EvaluationFunc, minSamples,
+	maxSamples int) *Resampling {
+	return &Resampling{
+		Evaluation: evaluation,
+		MinSamples: minSamples,
+		MaxSamples: maxSamples,
+		Budget:     0,
+		Z:          1.96,
+		Tolerance:  0.0,
+	}
+}
+
+// sampleStats is the running statistics of the samples of a genome.
+type sampleStats struct {
+	n      int     // number of samples
+	sum    float64 // sum of samples
+	sumSq  float64 // sum of squared samples
+	failed bool    // true if a sample is NaN
+}
+
+// mean returns the mean of the samples.
+func (s *sampleStats) mean() float64 {
+	return s.sum / float64(s.n)
+}
+
+// halfWidth returns the half-width of the confidence interval of the mean of
+// the argument z-score (infinity if there are less than two samples).
+func (s *sampleStats) halfWidth(z float64) float64 {
+	if s.n < 2 {
+		return math.Inf(1)
+	}
+	mean := s.mean()
+	variance := (s.sumSq - float64(s.n)*mean*mean) / float64(s.n-1)
+	return z * math.Sqrt(math.Max(variance, 0.0)/float64(s.n))
+}
+
+// Evaluate assigns the mean of the samples of each network to its genome,
+// which makes Resampling a PopulationEvaluator.
+func (r *Resampling) Evaluate(genomes []*Genome, nets []*NeuralNetwork) {
+	minSamples := r.MinSamples
+	if minSamples < 2 {
+		minSamples = 2
+	}
+	maxSamples := r.MaxSamples
+	if maxSamples < minSamples {
+		maxSamples = minSamples
+	}
+	budget := r.Budget
+	if budget <= 0 {
+		budget = maxSamples * len(nets)
+	}
+
+	stats := make([]sampleStats, len(nets))
+	sample := func(i int) {
+		score := r.Evaluation(nets[i])
+		budget--
+		if math.IsNaN(score) {
+			stats[i].failed = true
+			return
+		}
+		stats[i].n++
+		stats[i].sum += score
+		stats[i].sumSq += score * score
+	}
+	for i := range nets {
+		for j := 0; j < minSamples && !stats[i].failed; j++ {
+			sample(i)
+		}
+	}
+
+	order := make([]int, 0, len(nets))
+	overlap := func(a, b int) bool {
+		return math.Abs(stats[a].mean()-stats[b].mean()) <=
+			stats[a].halfWidth(r.Z)+stats[b].halfWidth(r.Z)
+	}
+	for budget > 0 {
+		order = order[:0]
+		for i := range stats {
+			if !stats[i].failed {
+				order = append(order, i)
+			}
+		}
+		sort.Slice(order, func(a, b int) bool {
+			return stats[order[a]].mean() < stats[order[b]].mean()
+		})
+
+		pick, widest := -1, 0.0
+		for rank, i := range order {
+			width := stats[i].halfWidth(r.Z)
+			if stats[i].n >= maxSamples || width <= r.Tolerance || width <= widest {
+				continue
+			}
+			if (rank > 0 && overlap(order[rank-1], i)) ||
+				(rank+1 < len(order) && overlap(i, order[rank+1])) {
+				pick, widest = i, width
+			}
+		}
+		if pick < 0 {
+			break
+		}
+		sample(pick)
+	}
+
+	r.Samples = make([]int, len(nets))
+	for i, genome := range genomes {
+		r.Samples[i] = stats[i].n
+		genome.Fitness = math.NaN()
+		if !stats[i].failed {
+			genome.Fitness = stats[i].mean()
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package neat
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestResampling(t *testing.T) {
+	genomes := make([]*Genome, 4)
+	nets := make([]*NeuralNetwork, 4)
+	means := make(map[*NeuralNetwork]float64)
+	for i := range genomes {
+		genomes[i] = NewGenome(i, 3, 1, 0.0)
+		nets[i] = NewNeuralNetwork(genomes[i])
+	}
+	means[nets[0]] = 0.0
+	means[nets[1]] = 0.01
+	means[nets[2]] = 10.0
+	means[nets[3]] = math.NaN()
+
+	rng := rand.New(rand.NewSource(0))
+	r := NewResampling(func(n *NeuralNetwork) float64 {
+		return means[n] + rng.NormFloat64()
+	}, 5, 50)
+	r.Evaluate(genomes, nets)
+
+	// ambiguous genomes are sampled until their budget is spent, while the
+	// separated genome is not resampled.
+	if r.Samples[0] != 50 || r.Samples[1] != 50 || r.Samples[2] != 5 {
+		t.Errorf("invalid numbers of samples: %v", r.Samples)
+	}
+	if math.Abs(genomes[2].Fitness-10.0) > 2.0 {
+		t.Errorf("invalid fitness of the separated genome: %f",
+			genomes[2].Fitness)
+	}
+	if !math.IsNaN(genomes[3].Fitness) {
+		t.Errorf("invalid fitness of the failed genome: %f", genomes[3].Fitness)
+	}
+
+	r.Budget = 20
+	r.Evaluate(genomes, nets)
+	if total := r.Samples[0] + r.Samples[1] + r.Samples[2]; total != 19 {
+		t.Errorf("invalid number of samples within the budget: %v", r.Samples)
+	}
+}
@@ -0,0 +1,84 @@
+// run_info.go implementation of metadata of an evolution run.
+//
+// Copyright (C) 2017  Jin Yeom
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package neat
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+// Version is the version of this package, recorded in the metadata of each run.
+// It is empty unless injected at build time, e.g.,
+//
+//	go build -ldflags "-X github.com/jinyeom/neat.Version=v1.0.0"
+var Version string
+
+// RunInfo is the metadata of an evolution run, with which results can be traced
+// back to the exact settings that produced them.
+type RunInfo struct {
+	ID         string    `json:"id"`         // unique ID of the run
+	ConfigHash string    `json:"configHash"` // SHA-256 hash of the config
+	Seed       int64     `json:"seed"`       // seed of the RNG (0 if unknown)
+	Version    string    `json:"version"`    // version of this package
+	StartTime  time.Time `json:"startTime"`  // time the run started
+	EndTime    time.Time `json:"endTime"`    // time of the last generation
+	Host       string    `json:"host"`       // name of the host machine
+}
+
+// NewRunInfo returns a new instance of RunInfo that starts now, given the
+// configuration and the seed of the random number generator of the run.
+func NewRunInfo(config *Config, seed int64) *RunInfo {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unknown"
+	}
+	now := time.Now()
+	configHash := ConfigHash(config)
+	id := sha256.Sum256([]byte(fmt.Sprintf("%s/%d/%s/%d", configHash, seed,
+		host, now.UnixNano())))
+	return &RunInfo{
+		ID:         hex.EncodeToString(id[:8]),
+		ConfigHash: configHash,
+		Seed:       seed,
+		Version:    Version,
+		StartTime:  now,
+		EndTime:    now,
+		Host:       host,
+	}
+}
+
+// ConfigHash returns the hexadecimal SHA-256 hash of the argument
+// configuration's JSON encoding; two runs with the same hash were configured
+// identically.
+func ConfigHash(config *Config) string {
+	data, err := json.Marshal(config)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// Duration returns the elapsed time of the run until its last generation.
+func (r *RunInfo) Duration() time.Duration {
+	return r.EndTime.Sub(r.StartTime)
+}
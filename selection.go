@@ -0,0 +1,136 @@
+// selection.go implementation of selection of surviving genomes.
+//
+// Copyright (C) 2017  Jin Yeom
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package neat
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+)
+
+var (
+	// SelectionStrategies is a set of names of selection strategies that can be
+	// specified in Config.SelectionStrategy.
+	SelectionStrategies = []string{"truncation", "tournament", "stochastic",
+		"pareto"}
+)
+
+// SelectionFunc is a type of function that selects the argument number of
+// surviving genomes among the argument members of a species, given the
+// comparison function and a random number generator.
+type SelectionFunc func(members []*Genome, numSurvived int,
+	comparison ComparisonFunc, rng *rand.Rand) []*Genome
+
+// NewSelectionFunc returns a new selection function, given the name of a
+// selection strategy ("truncation" if empty) and the size of a tournament,
+// which is only used by tournament selection. It returns an error if the
+// strategy is not one of SelectionStrategies.
+func NewSelectionFunc(strategy string,
+	tournamentSize int) (SelectionFunc, error) {
+	switch strategy {
+	case "", "truncation":
+		return TruncationSelection, nil
+	case "tournament":
+		return NewTournamentSelection(tournamentSize), nil
+	case "stochastic":
+		return StochasticSelection, nil
+	case "pareto":
+		return ParetoSelection, nil
+	}
+	return nil, fmt.Errorf("unknown selection strategy %q (one of %v)",
+		strategy, SelectionStrategies)
+}
+
+// TruncationSelection is a selection function that sorts the members by their
+// fitness and keeps the best ones. It is the default selection function.
+func TruncationSelection(members []*Genome, numSurvived int,
+	comparison ComparisonFunc, rng *rand.Rand) []*Genome {
+	sort.Slice(members, func(i, j int) bool {
+		return comparison(members[i], members[j])
+	})
+	return members[:numSurvived]
+}
+
+// NewTournamentSelection returns a selection function that repeatedly holds a
+// tournament among the argument number of randomly chosen members (2 if not
+// positive), and keeps the winner, until enough members survive. Each member
+// survives at most once.
+func NewTournamentSelection(size int) SelectionFunc {
+	if size <= 0 {
+		size = 2
+	}
+	return func(members []*Genome, numSurvived int,
+		comparison ComparisonFunc, rng *rand.Rand) []*Genome {
+		candidates := make([]*Genome, len(members))
+		copy(candidates, members)
+
+		survivors := make([]*Genome, 0, numSurvived)
+		for len(survivors) < numSurvived {
+			winner := rng.Intn(len(candidates))
+			for i := 1; i < size && i < len(candidates); i++ {
+				challenger := rng.Intn(len(candidates))
+				if comparison(candidates[challenger], candidates[winner]) {
+					winner = challenger
+				}
+			}
+			survivors = append(survivors, candidates[winner])
+			candidates[winner] = candidates[len(candidates)-1]
+			candidates = candidates[:len(candidates)-1]
+		}
+		return survivors
+	}
+}
+
+// StochasticSelection is a selection function that randomly chooses surviving
+// members with probabilities proportional to their ranks, i.e., the best member
+// is the most likely to survive, yet even the worst member may survive. Since
+// it is based on ranks, fitness scores may be negative or minimized.
+func StochasticSelection(members []*Genome, numSurvived int,
+	comparison ComparisonFunc, rng *rand.Rand) []*Genome {
+	candidates := make([]*Genome, len(members))
+	copy(candidates, members)
+	sort.Slice(candidates, func(i, j int) bool {
+		return comparison(candidates[i], candidates[j])
+	})
+
+	// the i-th best candidate has a weight of len(candidates) - i.
+	weights := make([]float64, len(candidates))
+	total := 0.0
+	for i := range weights {
+		weights[i] = float64(len(candidates) - i)
+		total += weights[i]
+	}
+
+	survivors := make([]*Genome, 0, numSurvived)
+	for len(survivors) < numSurvived {
+		r := rng.Float64() * total
+		chosen := len(candidates) - 1
+		for i, w := range weights {
+			if r < w {
+				chosen = i
+				break
+			}
+			r -= w
+		}
+		survivors = append(survivors, candidates[chosen])
+		total -= weights[chosen]
+		candidates = append(candidates[:chosen], candidates[chosen+1:]...)
+		weights = append(weights[:chosen], weights[chosen+1:]...)
+	}
+	return survivors
+}
@@ -0,0 +1,53 @@
+package neat
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestSelection(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	comparison := NewComparisonFunc(false)
+
+	for _, strategy := range SelectionStrategies {
+		members := make([]*Genome, 10)
+		for i := range members {
+			members[i] = NewGenome(i, 3, 1, float64(i))
+		}
+
+		selection, err := NewSelectionFunc(strategy, 3)
+		if err != nil {
+			t.Fatal(err)
+		}
+		survivors := selection(members, 4, comparison, rng)
+		if len(survivors) != 4 {
+			t.Errorf("%s: invalid number of survivors: %d != 4",
+				strategy, len(survivors))
+		}
+		survived := make(map[int]bool)
+		for _, genome := range survivors {
+			if survived[genome.ID] {
+				t.Errorf("%s: genome %d survived twice", strategy, genome.ID)
+			}
+			survived[genome.ID] = true
+		}
+		if strategy == "truncation" && !survived[9] {
+			t.Errorf("%s: the best genome did not survive", strategy)
+		}
+	}
+}
+
+func TestNewSelectionFunc(t *testing.T) {
+	if _, err := NewSelectionFunc("", 0); err != nil {
+		t.Errorf("empty strategy is not truncation: %v", err)
+	}
+	if _, err := NewSelectionFunc("tournment", 3); err == nil {
+		t.Error("unknown strategy is not reported")
+	}
+
+	config := NewDefaultConfig(3, 1)
+	config.SelectionStrategy = "tournment"
+	if err := config.Validate(); err == nil {
+		t.Error("unknown strategy is not reported by Validate")
+	}
+}
@@ -0,0 +1,209 @@
+// sharpneat.go implementation of a reader of genomes of SharpNEAT.
+//
+// Copyright (C) 2017  Jin Yeom
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+// Package sharpneat reads genomes in the XML format of SharpNEAT (version 2),
+// such that published champions of that ecosystem can be evaluated, and
+// further evolved with neat.NEAT.Transfer. A genome file looks like
+//
+//	<Root>
+//		<ActivationFunctions>
+//			<Fn id="0" name="SteepenedSigmoid" prob="1" />
+//		</ActivationFunctions>
+//		<Networks>
+//			<Network id="42" birthGen="7" fitness="3.9">
+//				<Nodes>
+//					<Node type="bias" id="0" />
+//					<Node type="in" id="1" />
+//					<Node type="out" id="2" />
+//					<Node type="hid" id="5" />
+//				</Nodes>
+//				<Connections>
+//					<Con id="3" src="1" tgt="2" wght="0.5" />
+//				</Connections>
+//			</Network>
+//		</Networks>
+//	</Root>
+//
+// The bias node of SharpNEAT becomes the first input node, which must be fed 1
+// as in neat.XORTest. Activation functions of SharpNEAT are scaled versions of
+// those of this package, e.g., its steepened sigmoid is 1 / (1 + exp(-4.9x)),
+// hence the weights of the connections into a node are scaled accordingly.
+package sharpneat
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/jinyeom/neat"
+)
+
+// activation is an activation function of SharpNEAT, which is the function of
+// this package of the name, whose input is multiplied by the scale.
+type activation struct {
+	name  string  // name of the function in neat.ActivationSet
+	scale float64 // scale of the input of the function
+}
+
+// activations maps the names of activation functions of SharpNEAT to those of
+// this package; the approximations of the sigmoid are read as the sigmoid.
+var activations = map[string]activation{
+	"PlainSigmoid":                  {"sigmoid", 1.0},
+	"SteepenedSigmoid":              {"sigmoid", 4.9},
+	"SteepenedSigmoidApproximation": {"sigmoid", 4.9},
+	"SigmoidApproximation":          {"sigmoid", 4.9},
+	"BipolarSigmoid":                {"tanh", 2.45},
+	"TanH":                          {"tanh", 1.0},
+	"Linear":                        {"linear", 1.0},
+	"Sine":                          {"sin", 2.0},
+	"ReLU":                          {"relu", 1.0},
+}
+
+// document is the XML document of genomes.
+type document struct {
+	Functions []struct {
+		ID   int    `xml:"id,attr"`
+		Name string `xml:"name,attr"`
+	} `xml:"ActivationFunctions>Fn"`
+	Networks []network `xml:"Networks>Network"`
+}
+
+// network is the XML element of a genome.
+type network struct {
+	ID      int     `xml:"id,attr"`
+	Fitness float64 `xml:"fitness,attr"`
+	Nodes   []struct {
+		Type string `xml:"type,attr"`
+		ID   int    `xml:"id,attr"`
+		FnID *int   `xml:"fnId,attr"`
+	} `xml:"Nodes>Node"`
+	Connections []struct {
+		Src    int     `xml:"src,attr"`
+		Tgt    int     `xml:"tgt,attr"`
+		Weight float64 `xml:"wght,attr"`
+	} `xml:"Connections>Con"`
+}
+
+// ReadGenomesFile reads the genomes of a SharpNEAT XML file of the argument
+// name.
+func ReadGenomesFile(filename string) ([]*neat.Genome, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ReadGenomes(f)
+}
+
+// ReadGenomes reads the genomes of SharpNEAT XML data from the argument
+// reader, in the order of the document. Nodes are assigned new IDs, such that
+// the bias and input nodes come first, then output nodes, then hidden nodes,
+// as in a genome created by this package. Nodes without a function ID are
+// assigned the first activation function of the document.
+func ReadGenomes(r io.Reader) ([]*neat.Genome, error) {
+	doc := &document{}
+	if err := xml.NewDecoder(r).Decode(doc); err != nil {
+		return nil, err
+	}
+	if len(doc.Functions) == 0 {
+		return nil, fmt.Errorf("sharpneat: no activation functions")
+	}
+
+	functions := make(map[int]activation)
+	for _, fn := range doc.Functions {
+		a, ok := activations[fn.Name]
+		if !ok {
+			return nil, fmt.Errorf("sharpneat: activation function %s is not "+
+				"supported", fn.Name)
+		}
+		functions[fn.ID] = a
+	}
+
+	genomes := make([]*neat.Genome, len(doc.Networks))
+	for i, net := range doc.Networks {
+		g, err := net.genome(functions, doc.Functions[0].ID)
+		if err != nil {
+			return nil, fmt.Errorf("sharpneat: network %d: %v", net.ID, err)
+		}
+		genomes[i] = g
+	}
+	return genomes, nil
+}
+
+// genome returns the genome of this network, given the activation functions
+// by their IDs and the ID of the default function.
+func (net *network) genome(functions map[int]activation,
+	defaultFn int) (*neat.Genome, error) {
+	order := map[string]int{"bias": 0, "in": 1, "out": 2, "hid": 3}
+	types := map[string]string{
+		"bias": "input", "in": "input", "out": "output", "hid": "hidden"}
+	nodes := net.Nodes
+	for _, node := range nodes {
+		if _, ok := order[node.Type]; !ok {
+			return nil, fmt.Errorf("unknown type %q of node %d", node.Type, node.ID)
+		}
+	}
+	sort.SliceStable(nodes, func(i, j int) bool {
+		return order[nodes[i].Type] < order[nodes[j].Type]
+	})
+
+	g := &neat.Genome{ID: net.ID, SpeciesID: -1, Fitness: net.Fitness}
+	ids := make(map[int]int)
+	scales := make(map[int]float64)
+	for _, node := range nodes {
+		if _, ok := ids[node.ID]; ok {
+			return nil, fmt.Errorf("duplicate node %d", node.ID)
+		}
+		id := len(g.NodeGenes)
+		ids[node.ID] = id
+
+		ntype := types[node.Type]
+		afunc := neat.ActivationSet["identity"]
+		if ntype != "input" {
+			fnID := defaultFn
+			if node.FnID != nil {
+				fnID = *node.FnID
+			}
+			a, ok := functions[fnID]
+			if !ok {
+				return nil, fmt.Errorf("unknown function %d of node %d",
+					fnID, node.ID)
+			}
+			afunc = neat.ActivationSet[a.name]
+			scales[id] = a.scale
+		}
+		g.NodeGenes = append(g.NodeGenes, neat.NewNodeGene(id, ntype, afunc))
+	}
+
+	for _, conn := range net.Connections {
+		from, okFrom := ids[conn.Src]
+		to, okTo := ids[conn.Tgt]
+		if !okFrom || !okTo {
+			return nil, fmt.Errorf("connection %d->%d of a missing node",
+				conn.Src, conn.Tgt)
+		}
+		if g.NodeGenes[to].Type == "input" {
+			return nil, fmt.Errorf("connection %d->%d into an input node",
+				conn.Src, conn.Tgt)
+		}
+		g.ConnGenes = append(g.ConnGenes, neat.NewConnGene(from, to,
+			scales[to]*conn.Weight))
+	}
+	return g, nil
+}
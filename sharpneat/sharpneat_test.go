@@ -0,0 +1,73 @@
+package sharpneat
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/jinyeom/neat"
+)
+
+const genomesXML = `<Root>
+	<ActivationFunctions>
+		<Fn id="0" name="SteepenedSigmoid" prob="1" />
+	</ActivationFunctions>
+	<Networks>
+		<Network id="42" birthGen="7" fitness="3.9">
+			<Nodes>
+				<Node type="bias" id="0" />
+				<Node type="in" id="1" />
+				<Node type="in" id="2" />
+				<Node type="hid" id="9" />
+				<Node type="out" id="3" />
+			</Nodes>
+			<Connections>
+				<Con id="4" src="1" tgt="9" wght="1.0" />
+				<Con id="5" src="2" tgt="9" wght="-1.0" />
+				<Con id="6" src="9" tgt="3" wght="2.0" />
+				<Con id="7" src="0" tgt="3" wght="-0.5" />
+			</Connections>
+		</Network>
+	</Networks>
+</Root>`
+
+func TestReadGenomes(t *testing.T) {
+	genomes, err := ReadGenomes(strings.NewReader(genomesXML))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(genomes) != 1 {
+		t.Fatalf("invalid number of genomes: %d", len(genomes))
+	}
+	g := genomes[0]
+	if g.ID != 42 || g.Fitness != 3.9 {
+		t.Errorf("invalid genome: %d, %f", g.ID, g.Fitness)
+	}
+	types := []string{"input", "input", "input", "output", "hidden"}
+	for i, node := range g.NodeGenes {
+		if node.ID != i || node.Type != types[i] {
+			t.Errorf("invalid node %d: %s", i, node)
+		}
+	}
+
+	// the network computes the function of SharpNEAT.
+	sigmoid := func(x float64) float64 { return 1.0 / (1.0 + math.Exp(-4.9*x)) }
+	hidden := sigmoid(0.3 - 0.8)
+	expected := sigmoid(2.0*hidden - 0.5)
+	outputs, err := neat.NewNeuralNetwork(g).FeedForward([]float64{1.0, 0.3, 0.8})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(outputs[0]-expected) > 1e-9 {
+		t.Errorf("invalid output: %f != %f", outputs[0], expected)
+	}
+
+	unsupported := strings.Replace(genomesXML, "SteepenedSigmoid", "RbfGaussian", 1)
+	if _, err := ReadGenomes(strings.NewReader(unsupported)); err == nil {
+		t.Error("unsupported activation function is not rejected")
+	}
+	dangling := strings.Replace(genomesXML, `tgt="3" wght="2.0"`, `tgt="8" wght="2.0"`, 1)
+	if _, err := ReadGenomes(strings.NewReader(dangling)); err == nil {
+		t.Error("connection of a missing node is not rejected")
+	}
+}
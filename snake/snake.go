@@ -0,0 +1,335 @@
+// snake.go implementation of the Snake game environment.
+//
+// Copyright (C) 2017  Jin Yeom
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+// Package snake provides the grid-based game of Snake as an environment, in
+// which a network steers a snake to eat food without running into the walls
+// or its own body.
+//
+// A network takes a bias followed by the sensors of an encoding, and outputs
+// 3 scores of turning left, going straight, and turning right; the snake takes
+// the action of the highest score. With the "relative" encoding, the snake
+// only senses its immediate surroundings, thus recurrent controllers, which
+// remember what they have sensed, have an advantage.
+package snake
+
+import (
+	"fmt"
+	"math/rand"
+
+	"github.com/jinyeom/neat"
+)
+
+var (
+	// Encodings are the names of the sensor encodings:
+	//
+	//	"relative": danger ahead, left, and right, and the direction of the food
+	//	            (ahead, left, right, or behind) relative to the heading
+	//	"rays":     for each of 8 directions relative to the heading, the inverse
+	//	            distance to the wall, and whether food or the body is seen
+	//	"grid":     every cell of the board, i.e., 1 for food, -1 for the body,
+	//	            0.5 for the head, and 0 for empty cells, in row-major order
+	Encodings = []string{"relative", "rays", "grid"}
+
+	// directions are the unit vectors of the headings: up, right, down, and
+	// left, in clockwise order.
+	directions = [4][2]int{{0, -1}, {1, 0}, {0, 1}, {-1, 0}}
+)
+
+// NumOutputs is the number of outputs of a network, i.e., the scores of
+// turning left, going straight, and turning right.
+const NumOutputs = 3
+
+// Env is an environment of Snake.
+type Env struct {
+	Width       int    // width of the board
+	Height      int    // height of the board
+	Encoding    string // sensor encoding
+	MaxSteps    int    // maximum number of steps of a game
+	HungerLimit int    // maximum number of steps without food (0 if none)
+	NumGames    int    // number of games of an evaluation
+	Seed        int64  // seed of the placement of food
+}
+
+// NewEnv returns a new environment of a board of the argument width and
+// height, with the argument sensor encoding, in which a game lasts up to 1000
+// steps, or 100 steps without food, and an evaluation consists of 3 games.
+func NewEnv(width, height int, encoding string) (*Env, error) {
+	e := &Env{
+		Width:       width,
+		Height:      height,
+		Encoding:    encoding,
+		MaxSteps:    1000,
+		HungerLimit: 100,
+		NumGames:    3,
+		Seed:        1,
+	}
+	if width < 4 || height < 4 {
+		return nil, fmt.Errorf("snake: board must be at least 4x4 (%dx%d)",
+			width, height)
+	}
+	if e.NumInputs() == 0 {
+		return nil, fmt.Errorf("snake: unknown encoding %q (available: %v)",
+			encoding, Encodings)
+	}
+	return e, nil
+}
+
+// NumInputs returns the number of inputs of a network, including the bias, or
+// 0 if the encoding is unknown.
+func (e *Env) NumInputs() int {
+	switch e.Encoding {
+	case "relative":
+		return 1 + 3 + 4
+	case "rays":
+		return 1 + 8*3
+	case "grid":
+		return 1 + e.Width*e.Height
+	}
+	return 0
+}
+
+// NewConfig returns a new default configuration of NEAT for controllers of
+// this environment, which may be recurrent.
+func (e *Env) NewConfig() *neat.Config {
+	config := neat.NewDefaultConfig(e.NumInputs(), NumOutputs)
+	config.ExperimentName = "Snake"
+	config.SelfConnections = true
+	return config
+}
+
+// Result is the result of a game.
+type Result struct {
+	Food    int  // number of food eaten
+	Steps   int  // number of steps survived
+	Crashed bool // true if the snake crashed into a wall or its body
+}
+
+// Fitness returns the fitness of the result: the number of food eaten, plus a
+// small reward for survival that never exceeds one food.
+func (r Result) Fitness(maxSteps int) float64 {
+	return float64(r.Food) + float64(r.Steps)/float64(maxSteps+1)
+}
+
+// game is a state of a game.
+type game struct {
+	env     *Env       // environment
+	body    [][2]int   // positions of the body, from the head
+	heading int        // index of the direction of the heading
+	food    [2]int     // position of the food
+	rng     *rand.Rand // random number generator of food
+}
+
+// Play plays a game with the snake controlled by the argument network, whose
+// food is placed by the argument random number generator, and returns the
+// result.
+func (e *Env) Play(nn *neat.NeuralNetwork, rng *rand.Rand) Result {
+	nn.Reset()
+	g := &game{
+		env:     e,
+		body:    [][2]int{{e.Width / 2, e.Height / 2}, {e.Width / 2, e.Height/2 + 1}},
+		heading: 0,
+		rng:     rng,
+	}
+	g.placeFood()
+
+	result := Result{}
+	inputs := make([]float64, e.NumInputs())
+	hunger := 0
+	for result.Steps < e.MaxSteps {
+		g.sense(inputs)
+		outputs, err := nn.FeedForward(inputs)
+		if err != nil || len(outputs) < NumOutputs {
+			result.Crashed = true
+			break
+		}
+		turn := 0
+		for i := 1; i < NumOutputs; i++ {
+			if outputs[i] > outputs[turn] {
+				turn = i
+			}
+		}
+		g.heading = (g.heading + turn - 1 + 4) % 4
+
+		ate, crashed := g.step()
+		if crashed {
+			result.Crashed = true
+			break
+		}
+		result.Steps++
+		hunger++
+		if ate {
+			result.Food++
+			hunger = 0
+			if !g.placeFood() {
+				break
+			}
+		}
+		if e.HungerLimit > 0 && hunger >= e.HungerLimit {
+			break
+		}
+	}
+	return result
+}
+
+// Evaluation returns an evaluation function of the average fitness of
+// NumGames games; the games of every network are played with the same
+// placement of food, given by the seed.
+func (e *Env) Evaluation() neat.EvaluationFunc {
+	return func(nn *neat.NeuralNetwork) float64 {
+		rng := rand.New(rand.NewSource(e.Seed))
+		total := 0.0
+		for i := 0; i < e.NumGames; i++ {
+			total += e.Play(nn, rng).Fitness(e.MaxSteps)
+		}
+		return total / float64(e.NumGames)
+	}
+}
+
+// placeFood places the food on a random empty cell, and returns false if the
+// board is full.
+func (g *game) placeFood() bool {
+	empty := make([][2]int, 0, g.env.Width*g.env.Height)
+	for y := 0; y < g.env.Height; y++ {
+		for x := 0; x < g.env.Width; x++ {
+			if !g.occupied([2]int{x, y}) {
+				empty = append(empty, [2]int{x, y})
+			}
+		}
+	}
+	if len(empty) == 0 {
+		return false
+	}
+	g.food = empty[g.rng.Intn(len(empty))]
+	return true
+}
+
+// occupied returns true if the argument cell is a part of the body.
+func (g *game) occupied(p [2]int) bool {
+	for _, b := range g.body {
+		if b == p {
+			return true
+		}
+	}
+	return false
+}
+
+// inside returns true if the argument cell is on the board.
+func (g *game) inside(p [2]int) bool {
+	return p[0] >= 0 && p[0] < g.env.Width && p[1] >= 0 && p[1] < g.env.Height
+}
+
+// dangerous returns true if moving to the argument cell would crash, i.e., it
+// is a wall or a part of the body other than the tail, which moves away.
+func (g *game) dangerous(p [2]int) bool {
+	if !g.inside(p) {
+		return true
+	}
+	for _, b := range g.body[:len(g.body)-1] {
+		if b == p {
+			return true
+		}
+	}
+	return false
+}
+
+// step moves the snake in its heading, and returns whether it ate the food,
+// and whether it crashed.
+func (g *game) step() (bool, bool) {
+	dir := directions[g.heading]
+	head := [2]int{g.body[0][0] + dir[0], g.body[0][1] + dir[1]}
+	if g.dangerous(head) {
+		return false, true
+	}
+	ate := head == g.food
+	g.body = append([][2]int{head}, g.body...)
+	if !ate {
+		g.body = g.body[:len(g.body)-1]
+	}
+	return ate, false
+}
+
+// relative returns the direction of the argument offset from the heading in
+// clockwise order, e.g., 1 for right and -1 for left.
+func (g *game) relative(offset int) [2]int {
+	return directions[(g.heading+offset+4)%4]
+}
+
+// sense writes the bias and the sensors of the encoding to the argument
+// inputs.
+func (g *game) sense(inputs []float64) {
+	for i := range inputs {
+		inputs[i] = 0.0
+	}
+	inputs[0] = 1.0
+	head := g.body[0]
+
+	switch g.env.Encoding {
+	case "relative":
+		for i, offset := range []int{0, -1, 1} {
+			dir := g.relative(offset)
+			if g.dangerous([2]int{head[0] + dir[0], head[1] + dir[1]}) {
+				inputs[1+i] = 1.0
+			}
+		}
+		// project the offset of the food onto the heading and its right.
+		fx, fy := g.food[0]-head[0], g.food[1]-head[1]
+		ahead, right := g.relative(0), g.relative(1)
+		forward := fx*ahead[0] + fy*ahead[1]
+		side := fx*right[0] + fy*right[1]
+		if forward > 0 {
+			inputs[4] = 1.0
+		} else if forward < 0 {
+			inputs[7] = 1.0
+		}
+		if side < 0 {
+			inputs[5] = 1.0
+		} else if side > 0 {
+			inputs[6] = 1.0
+		}
+
+	case "rays":
+		for i := 0; i < 8; i++ {
+			// 8 directions in clockwise order from the heading, including the
+			// diagonals between the 4 directions.
+			d0, d1 := g.relative(i/2), g.relative((i+1)/2)
+			dir := [2]int{d0[0], d0[1]}
+			if i%2 == 1 {
+				dir = [2]int{d0[0] + d1[0], d0[1] + d1[1]}
+			}
+			p := [2]int{head[0] + dir[0], head[1] + dir[1]}
+			dist := 1
+			for g.inside(p) {
+				if p == g.food {
+					inputs[1+3*i+1] = 1.0
+				}
+				if g.occupied(p) {
+					inputs[1+3*i+2] = 1.0
+				}
+				p = [2]int{p[0] + dir[0], p[1] + dir[1]}
+				dist++
+			}
+			inputs[1+3*i] = 1.0 / float64(dist)
+		}
+
+	case "grid":
+		for _, b := range g.body {
+			inputs[1+b[1]*g.env.Width+b[0]] = -1.0
+		}
+		inputs[1+head[1]*g.env.Width+head[0]] = 0.5
+		inputs[1+g.food[1]*g.env.Width+g.food[0]] = 1.0
+	}
+}
@@ -0,0 +1,89 @@
+package snake
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/jinyeom/neat"
+)
+
+func TestNewEnv(t *testing.T) {
+	for _, encoding := range Encodings {
+		e, err := NewEnv(8, 6, encoding)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if e.NumInputs() != e.NewConfig().NumInputs {
+			t.Errorf("invalid number of inputs of %q", encoding)
+		}
+	}
+	if _, err := NewEnv(8, 6, "pixels"); err == nil {
+		t.Error("unknown encoding is accepted")
+	}
+	if _, err := NewEnv(2, 6, "grid"); err == nil {
+		t.Error("small board is accepted")
+	}
+}
+
+func TestSense(t *testing.T) {
+	e, _ := NewEnv(6, 6, "relative")
+	g := &game{
+		env:     e,
+		body:    [][2]int{{0, 3}, {1, 3}},
+		heading: 0,
+		food:    [2]int{5, 0},
+	}
+	inputs := make([]float64, e.NumInputs())
+	g.sense(inputs)
+
+	// the wall is on the left, and the food is ahead and to the right.
+	want := []float64{1, 0, 1, 0, 1, 0, 1, 0}
+	for i := range want {
+		if inputs[i] != want[i] {
+			t.Fatalf("invalid inputs: %v", inputs)
+		}
+	}
+
+	e.Encoding = "grid"
+	inputs = make([]float64, e.NumInputs())
+	g.sense(inputs)
+	if inputs[1+3*6] != 0.5 || inputs[1+3*6+1] != -1.0 || inputs[1+5] != 1.0 {
+		t.Errorf("invalid grid: %v", inputs)
+	}
+}
+
+func TestPlay(t *testing.T) {
+	e, _ := NewEnv(8, 8, "rays")
+
+	// a network without connections always turns left, thus circles until it
+	// starves, unless it runs into the food.
+	g := neat.NewGenome(0, e.NumInputs(), NumOutputs, 0.0)
+	result := e.Play(neat.NewNeuralNetwork(g), rand.New(rand.NewSource(0)))
+	if result.Crashed || (result.Food == 0 && result.Steps != e.HungerLimit) {
+		t.Errorf("invalid result of circling: %+v", result)
+	}
+
+	// a network that goes straight crashes into the wall.
+	g.ConnGenes = append(g.ConnGenes, neat.NewConnGene(0, e.NumInputs()+1, 1.0))
+	result = e.Play(neat.NewNeuralNetwork(g), rand.New(rand.NewSource(0)))
+	if !result.Crashed || result.Steps > e.Height {
+		t.Errorf("invalid result of going straight: %+v", result)
+	}
+	if f := result.Fitness(e.MaxSteps); f < float64(result.Food) ||
+		f >= float64(result.Food+1) {
+		t.Errorf("invalid fitness: %f", f)
+	}
+}
+
+func TestSnakeEvolution(t *testing.T) {
+	e, _ := NewEnv(8, 8, "relative")
+	config := e.NewConfig()
+	config.NumGenerations = 3
+	config.PopulationSize = 20
+	best := neat.New(config, e.Evaluation(), neat.WithSeed(0)).Run()
+	evaluate := e.Evaluation()
+	nn := neat.NewNeuralNetwork(best)
+	if a, b := evaluate(nn), evaluate(nn); a != b {
+		t.Errorf("evaluation is not deterministic: %f != %f", a, b)
+	}
+}
@@ -24,9 +24,11 @@ package neat
 // compatible with any other species.
 type Species struct {
 	ID             int       // species ID
+	Age            int       // number of generations since its creation
 	Stagnation     int       // number of generations of stagnation
 	Representative *Genome   // genome that represents this species (permanent)
-	BestFitness    float64   // best fitness score in this species
+	Best           *Genome   // best genome in this species' history
+	BestFitness    float64   // fitness score of its best genome
 	Members        []*Genome // member genomes
 }
 
@@ -36,29 +38,31 @@ func NewSpecies(id int, g *Genome) *Species {
 	g.SpeciesID = id
 	return &Species{
 		ID:             id,
+		Age:            0,
 		Stagnation:     0,
 		Representative: g.Copy(),
+		Best:           g.Copy(),
 		BestFitness:    g.Fitness,
 		Members:        []*Genome{g},
 	}
 }
 
 // Register adds an argument genome as a new member of this species; in
-// addition, if the new member genome outperforms this species' best genome, it
-// replaces the best genome in this species.
-func (s *Species) Register(g *Genome, minimizeFitness bool) {
+// addition, if the new member genome outperforms this species' best genome by
+// the argument comparison function (see NEAT.Comparison), it replaces the best
+// genome in this species. A species without its best genome, e.g., one decoded
+// from an older checkpoint, is compared by its best fitness score.
+func (s *Species) Register(g *Genome, comparison ComparisonFunc) {
 	s.Members = append(s.Members, g)
 	g.SpeciesID = s.ID
-	if minimizeFitness {
-		if g.Fitness < s.BestFitness {
-			s.BestFitness = g.Fitness
-			s.Stagnation = 0
-		}
-	} else {
-		if g.Fitness > s.BestFitness {
-			s.BestFitness = g.Fitness
-			s.Stagnation = 0
-		}
+	best := s.Best
+	if best == nil {
+		best = &Genome{Fitness: s.BestFitness}
+	}
+	if comparison(g, best) {
+		s.Best = g.Copy()
+		s.BestFitness = g.Fitness
+		s.Stagnation = 0
 	}
 }
 
@@ -74,7 +78,42 @@ func (s *Species) ExplicitFitnessSharing() {
 	}
 }
 
-// Flush empties the species membership, except for its representative.
+// Flush empties the species membership, except for its representative. The
+// new membership is preallocated with the size of the previous one, since it is
+// likely to be similar.
 func (s *Species) Flush() {
-	s.Members = []*Genome{}
+	s.Members = make([]*Genome, 0, len(s.Members))
+}
+
+// SpeciesSummary is a summary of the current state of a species.
+type SpeciesSummary struct {
+	ID          int     `json:"id"`          // species ID
+	Size        int     `json:"size"`        // number of members
+	BestFitness float64 `json:"bestFitness"` // best fitness in its history
+	AvgFitness  float64 `json:"avgFitness"`  // average fitness of members
+	Age         int     `json:"age"`         // number of generations since birth
+	Stagnation  int     `json:"stagnation"`  // generations of stagnation
+	NumNodes    int     `json:"numNodes"`    // number of nodes of representative
+	NumConns    int     `json:"numConns"`    // number of connections of representative
+}
+
+// Summary returns the summary of the current state of this species.
+func (s *Species) Summary() *SpeciesSummary {
+	avg := 0.0
+	for _, genome := range s.Members {
+		avg += genome.Fitness
+	}
+	if len(s.Members) != 0 {
+		avg /= float64(len(s.Members))
+	}
+	return &SpeciesSummary{
+		ID:          s.ID,
+		Size:        len(s.Members),
+		BestFitness: s.BestFitness,
+		AvgFitness:  avg,
+		Age:         s.Age,
+		Stagnation:  s.Stagnation,
+		NumNodes:    len(s.Representative.NodeGenes),
+		NumConns:    len(s.Representative.ConnGenes),
+	}
 }
@@ -18,46 +18,1001 @@
 package neat
 
 import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
 	"math"
+	"sort"
+	"strconv"
+	"time"
+)
+
+var (
+	// statisticsColumns is a list of columns of the statistics exported as CSV,
+	// each of which consists of its header and a function that formats its
+	// value in a recorded generation.
+	statisticsColumns = []struct {
+		Header string
+		Value  func(s *Statistics, i int) string // given the index of an entry
+	}{
+		{"generation", func(s *Statistics, i int) string {
+			return strconv.Itoa(s.Generations[i])
+		}},
+		{"num_species", func(s *Statistics, i int) string {
+			return strconv.Itoa(s.NumSpecies[i])
+		}},
+		{"min_fitness", func(s *Statistics, i int) string {
+			return formatFloat(s.MinFitness[i])
+		}},
+		{"max_fitness", func(s *Statistics, i int) string {
+			return formatFloat(s.MaxFitness[i])
+		}},
+		{"avg_fitness", func(s *Statistics, i int) string {
+			return formatFloat(s.AvgFitness[i])
+		}},
+		{"std_fitness", func(s *Statistics, i int) string {
+			return formatFloat(s.StdFitness[i])
+		}},
+		{"lower_quartile_fitness", func(s *Statistics, i int) string {
+			return formatFloat(s.LowerQuartileFitness[i])
+		}},
+		{"median_fitness", func(s *Statistics, i int) string {
+			return formatFloat(s.MedianFitness[i])
+		}},
+		{"upper_quartile_fitness", func(s *Statistics, i int) string {
+			return formatFloat(s.UpperQuartileFitness[i])
+		}},
+		{"validation_fitness", func(s *Statistics, i int) string {
+			return formatFloat(s.ValidationFitness[i])
+		}},
+		{"avg_num_nodes", func(s *Statistics, i int) string {
+			return formatFloat(s.AvgNumNodes[i])
+		}},
+		{"avg_num_conns", func(s *Statistics, i int) string {
+			return formatFloat(s.AvgNumConns[i])
+		}},
+		{"best_num_nodes", func(s *Statistics, i int) string {
+			return strconv.Itoa(s.BestNumNodes[i])
+		}},
+		{"best_num_conns", func(s *Statistics, i int) string {
+			return strconv.Itoa(s.BestNumConns[i])
+		}},
+		{"perturb_count", func(s *Statistics, i int) string {
+			return strconv.Itoa(s.MutationCounts[i]["perturb"])
+		}},
+		{"add_node_count", func(s *Statistics, i int) string {
+			return strconv.Itoa(s.MutationCounts[i]["addNode"])
+		}},
+		{"add_conn_count", func(s *Statistics, i int) string {
+			return strconv.Itoa(s.MutationCounts[i]["addConn"])
+		}},
+		{"activation_count", func(s *Statistics, i int) string {
+			return strconv.Itoa(s.MutationCounts[i]["activation"])
+		}},
+		{"num_innovations", func(s *Statistics, i int) string {
+			return strconv.Itoa(s.NumInnovations[i])
+		}},
+		{"num_failures", func(s *Statistics, i int) string {
+			return strconv.Itoa(len(s.Failures[i]))
+		}},
+		{"evaluation_seconds", func(s *Statistics, i int) string {
+			return formatFloat(s.EvaluationTime[i].Seconds())
+		}},
+		{"speciation_seconds", func(s *Statistics, i int) string {
+			return formatFloat(s.SpeciationTime[i].Seconds())
+		}},
+		{"reproduction_seconds", func(s *Statistics, i int) string {
+			return formatFloat(s.ReproductionTime[i].Seconds())
+		}},
+		{"generation_seconds", func(s *Statistics, i int) string {
+			return formatFloat(s.GenerationTime[i].Seconds())
+		}},
+	}
 )
 
 // Statistics is a data structure that records statistical information of each
 // generation during the evolutionary process.
+//
+// By default, every generation is recorded, and the series are indexed by
+// generation. Bounded statistics (see NewBoundedStatistics) only record some
+// of the generations, whose indices are in Generations.
 type Statistics struct {
-	NumSpecies []int     // number of species in each generation
-	MinFitness []float64 // minimum fitness in each generation
-	MaxFitness []float64 // maximum fitness in each generation
-	AvgFitness []float64 // average fitness in each generation
+	Generations []int `json:"generations"` // index of each recorded generation
+
+	NumSpecies []int     `json:"numSpecies"` // number of species in each generation
+	MinFitness []float64 `json:"minFitness"` // minimum fitness in each generation
+	MaxFitness []float64 `json:"maxFitness"` // maximum fitness in each generation
+	AvgFitness []float64 `json:"avgFitness"` // average fitness in each generation
+	StdFitness []float64 `json:"stdFitness"` // standard deviation of fitness
+	RunInfo    *RunInfo  `json:"-"`          // metadata of the run
+
+	// quartiles of fitness in each generation
+	LowerQuartileFitness []float64 `json:"lowerQuartileFitness"`
+	MedianFitness        []float64 `json:"medianFitness"`
+	UpperQuartileFitness []float64 `json:"upperQuartileFitness"`
+
+	// best held-out validation score among the top genomes of each generation
+	// by fitness (0 without a validation function; see WithValidation)
+	ValidationFitness []float64 `json:"validationFitness"`
+
+	// complexity of genomes in each generation, in terms of the number of node
+	// genes and connection genes (including disabled ones)
+	AvgNumNodes  []float64 `json:"avgNumNodes"`  // average number of nodes
+	AvgNumConns  []float64 `json:"avgNumConns"`  // average number of connections
+	BestNumNodes []int     `json:"bestNumNodes"` // number of nodes of the best
+	BestNumConns []int     `json:"bestNumConns"` // number of connections of the best
+
+	// average and maximum value of each objective in each generation, if
+	// genomes are evaluated with multiple objectives (nil otherwise)
+	AvgObjectives [][]float64 `json:"avgObjectives"`
+	MaxObjectives [][]float64 `json:"maxObjectives"`
+
+	// average and maximum value of each auxiliary metric in each generation,
+	// among the genomes that report it (nil if none of them does)
+	AvgMetrics []map[string]float64 `json:"avgMetrics"`
+	MaxMetrics []map[string]float64 `json:"maxMetrics"`
+
+	// number of mutations of each type (see Mutation) applied during the
+	// reproduction of each generation, and the number of connections between
+	// pairs of nodes that never appeared before in the run
+	MutationCounts []map[string]int `json:"mutationCounts"`
+	NumInnovations []int            `json:"numInnovations"`
+
+	// failed evaluations in each generation
+	Failures [][]EvaluationFailure `json:"failures"`
+
+	// distribution of fitness in each generation (see Config.HistogramBins)
+	FitnessHistograms []*Histogram `json:"fitnessHistograms"`
+
+	// wall-clock time of each phase of each generation (in nanoseconds in
+	// JSON); the time of a generation also includes the bookkeeping
+	EvaluationTime   []time.Duration `json:"evaluationTime"`
+	SpeciationTime   []time.Duration `json:"speciationTime"`
+	ReproductionTime []time.Duration `json:"reproductionTime"`
+	GenerationTime   []time.Duration `json:"generationTime"`
+
+	// compositions of species in each generation, right after speciation
+	SpeciesHistory [][]SpeciesRecord `json:"speciesHistory"`
+
+	numRecorded int                   // number of generations recorded so far
+	window      int                   // number of recent generations (0: all)
+	stride      int                   // interval of recorded generations
+	stream      chan *GenerationStats // channel of each generation (optional)
+	fitness     []float64             // buffer of fitness scores of a generation
+}
+
+// GenerationStats is a snapshot of the statistics of a generation, which is
+// pushed to the stream of the statistics at the end of the generation.
+type GenerationStats struct {
+	Generation int     `json:"generation"` // index of the generation
+	NumSpecies int     `json:"numSpecies"` // number of species
+	MinFitness float64 `json:"minFitness"` // minimum fitness
+	MaxFitness float64 `json:"maxFitness"` // maximum fitness
+	AvgFitness float64 `json:"avgFitness"` // average fitness
+	StdFitness float64 `json:"stdFitness"` // standard deviation of fitness
+
+	// quartiles of fitness
+	LowerQuartileFitness float64 `json:"lowerQuartileFitness"`
+	MedianFitness        float64 `json:"medianFitness"`
+	UpperQuartileFitness float64 `json:"upperQuartileFitness"`
+
+	// best held-out validation score of the top genomes
+	ValidationFitness float64 `json:"validationFitness"`
+
+	// complexity of genomes
+	AvgNumNodes  float64 `json:"avgNumNodes"`  // average number of nodes
+	AvgNumConns  float64 `json:"avgNumConns"`  // average number of connections
+	BestNumNodes int     `json:"bestNumNodes"` // number of nodes of the best
+	BestNumConns int     `json:"bestNumConns"` // number of connections of the best
+
+	// objectives of genomes, if any
+	AvgObjectives []float64 `json:"avgObjectives,omitempty"` // average values
+	MaxObjectives []float64 `json:"maxObjectives,omitempty"` // maximum values
+
+	// auxiliary metrics of genomes, if any
+	AvgMetrics map[string]float64 `json:"avgMetrics,omitempty"` // average values
+	MaxMetrics map[string]float64 `json:"maxMetrics,omitempty"` // maximum values
+
+	// mutations and innovations during reproduction
+	MutationCounts map[string]int `json:"mutationCounts"`
+	NumInnovations int            `json:"numInnovations"`
+
+	Failures []EvaluationFailure `json:"failures"` // failed evaluations
+
+	FitnessHistogram *Histogram `json:"fitnessHistogram"` // distribution of fitness
+
+	// wall-clock time of each phase
+	EvaluationTime   time.Duration `json:"evaluationTime"`
+	SpeciationTime   time.Duration `json:"speciationTime"`
+	ReproductionTime time.Duration `json:"reproductionTime"`
+	GenerationTime   time.Duration `json:"generationTime"`
+
+	Species []SpeciesRecord `json:"species"` // compositions of species
+}
+
+// SpeciesRecord is a snapshot of a species in a generation, from which the
+// birth, growth, and extinction of species can be reconstructed.
+type SpeciesRecord struct {
+	ID          int     `json:"id"`          // species ID
+	Size        int     `json:"size"`        // number of members
+	BestFitness float64 `json:"bestFitness"` // best fitness in the species
+	Age         int     `json:"age"`         // number of generations since birth
+	Stagnation  int     `json:"stagnation"`  // generations of stagnation
+}
+
+// Histogram is a distribution of values over bins of equal width between the
+// minimum and the maximum value.
+type Histogram struct {
+	Min    float64 `json:"min"`    // lower bound of the first bin
+	Max    float64 `json:"max"`    // upper bound of the last bin
+	Counts []int   `json:"counts"` // number of values in each bin
+}
+
+// NewHistogram returns a new histogram of the argument values with the
+// argument number of bins; the last bin includes the maximum value.
+func NewHistogram(values []float64, numBins int) *Histogram {
+	h := &Histogram{Counts: make([]int, numBins)}
+	if len(values) == 0 || numBins == 0 {
+		return h
+	}
+	h.Min, h.Max = values[0], values[0]
+	for _, v := range values {
+		h.Min, h.Max = math.Min(h.Min, v), math.Max(h.Max, v)
+	}
+
+	width := (h.Max - h.Min) / float64(numBins)
+	for _, v := range values {
+		bin := 0
+		if width > 0.0 {
+			bin = int((v - h.Min) / width)
+		}
+		if bin >= numBins {
+			bin = numBins - 1
+		}
+		h.Counts[bin]++
+	}
+	return h
+}
+
+// EvaluationFailure is a record of a failed evaluation of a genome, whose
+// fitness is replaced with the initial fitness. Its reason is "timeout", "nan"
+// or "inf" (for a non-finite fitness), or "error".
+type EvaluationFailure struct {
+	GenomeID int    `json:"genomeID"`          // ID of the genome
+	Reason   string `json:"reason"`            // reason of the failure
+	Message  string `json:"message,omitempty"` // message of the error
+}
+
+// Results is a document of the results of a run, which bundles the statistics
+// of each recorded generation with the configuration and the metadata of the
+// run; it is written by Statistics.ExportJSON.
+type Results struct {
+	Config     *Config     `json:"config"`     // configuration of the run
+	RunInfo    *RunInfo    `json:"runInfo"`    // metadata of the run
+	Statistics *Statistics `json:"statistics"` // statistics of each generation
 }
 
-// NewStatistics returns a new instance of Statistics.
+// NewStatistics returns a new instance of Statistics, given the expected
+// number of generations; series grow beyond it as more generations are
+// recorded, e.g., in open-ended runs driven by Step.
 func NewStatistics(numGenerations int) *Statistics {
+	generations := make([]int, numGenerations)
+	for i := range generations {
+		generations[i] = i
+	}
 	return &Statistics{
-		NumSpecies: make([]int, numGenerations),
-		MinFitness: make([]float64, numGenerations),
-		MaxFitness: make([]float64, numGenerations),
-		AvgFitness: make([]float64, numGenerations),
+		Generations: generations,
+		NumSpecies:  make([]int, numGenerations),
+		MinFitness:  make([]float64, numGenerations),
+		MaxFitness:  make([]float64, numGenerations),
+		AvgFitness:  make([]float64, numGenerations),
+		StdFitness:  make([]float64, numGenerations),
+
+		LowerQuartileFitness: make([]float64, numGenerations),
+		MedianFitness:        make([]float64, numGenerations),
+		UpperQuartileFitness: make([]float64, numGenerations),
+
+		ValidationFitness: make([]float64, numGenerations),
+
+		AvgNumNodes:  make([]float64, numGenerations),
+		AvgNumConns:  make([]float64, numGenerations),
+		BestNumNodes: make([]int, numGenerations),
+		BestNumConns: make([]int, numGenerations),
+
+		AvgObjectives: make([][]float64, numGenerations),
+		MaxObjectives: make([][]float64, numGenerations),
+
+		AvgMetrics: make([]map[string]float64, numGenerations),
+		MaxMetrics: make([]map[string]float64, numGenerations),
+
+		MutationCounts: make([]map[string]int, numGenerations),
+		NumInnovations: make([]int, numGenerations),
+
+		Failures: make([][]EvaluationFailure, numGenerations),
+
+		FitnessHistograms: make([]*Histogram, numGenerations),
+
+		EvaluationTime:   make([]time.Duration, numGenerations),
+		SpeciationTime:   make([]time.Duration, numGenerations),
+		ReproductionTime: make([]time.Duration, numGenerations),
+		GenerationTime:   make([]time.Duration, numGenerations),
+
+		SpeciesHistory: make([][]SpeciesRecord, numGenerations),
 	}
 }
 
 // Update the statistics of current generation
 func (s *Statistics) Update(currGen int, n *NEAT) {
-	s.NumSpecies[currGen] = len(n.Species)
+	e, ok := s.entry(currGen)
+	if !ok {
+		return
+	}
 
-	// mininum and maximum
-	s.MinFitness[currGen] = n.Population[0].Fitness
-	s.MaxFitness[currGen] = n.Population[0].Fitness
+	// minimum, maximum, and average fitness, and the complexity of genomes are
+	// accumulated in a single pass over the population, which also gathers the
+	// fitness scores in a buffer that is reused between generations.
+	fitness := s.fitness[:0]
+	sum, numNodes, numConns := 0.0, 0, 0
+	s.MinFitness[e] = n.Population[0].Fitness
+	s.MaxFitness[e] = n.Population[0].Fitness
 	for _, genome := range n.Population {
-		s.MinFitness[currGen] = math.Min(genome.Fitness, s.MinFitness[currGen])
-		s.MaxFitness[currGen] = math.Max(genome.Fitness, s.MinFitness[currGen])
+		s.MinFitness[e] = math.Min(genome.Fitness, s.MinFitness[e])
+		s.MaxFitness[e] = math.Max(genome.Fitness, s.MaxFitness[e])
+		sum += genome.Fitness
+		numNodes += len(genome.NodeGenes)
+		numConns += len(genome.ConnGenes)
+		fitness = append(fitness, genome.Fitness)
+	}
+	s.fitness = fitness
+	s.AvgFitness[e] = sum / float64(len(fitness))
+
+	// standard deviation of fitness
+	variance := 0.0
+	for _, f := range fitness {
+		diff := f - s.AvgFitness[e]
+		variance += diff * diff
 	}
+	s.StdFitness[e] = math.Sqrt(variance / float64(len(fitness)))
 
-	// average fitness
-	s.AvgFitness[currGen] = func() float64 {
-		avg := 0.0
-		for _, genome := range n.Population {
-			avg += genome.Fitness
+	// quartiles
+	sort.Float64s(fitness)
+	s.LowerQuartileFitness[e] = quantile(fitness, 0.25)
+	s.MedianFitness[e] = quantile(fitness, 0.5)
+	s.UpperQuartileFitness[e] = quantile(fitness, 0.75)
+
+	// distribution of fitness
+	if n.Config.HistogramBins > 0 {
+		s.FitnessHistograms[e] = NewHistogram(fitness, n.Config.HistogramBins)
+	}
+
+	// complexity of genomes on average, and of the best in this generation
+	best := n.Champion()
+	s.AvgNumNodes[e] = float64(numNodes) / float64(len(n.Population))
+	s.AvgNumConns[e] = float64(numConns) / float64(len(n.Population))
+	s.BestNumNodes[e] = len(best.NodeGenes)
+	s.BestNumConns[e] = len(best.ConnGenes)
+
+	s.AvgObjectives[e], s.MaxObjectives[e] = objectiveStats(n.Population)
+	s.AvgMetrics[e], s.MaxMetrics[e] = metricStats(n.Population)
+}
+
+// objectiveStats returns the average and the maximum value of each objective
+// among the argument genomes that are evaluated with the most objectives, or
+// nil if none of them is.
+func objectiveStats(genomes []*Genome) ([]float64, []float64) {
+	numObjectives := 0
+	for _, genome := range genomes {
+		if len(genome.Objectives) > numObjectives {
+			numObjectives = len(genome.Objectives)
+		}
+	}
+	if numObjectives == 0 {
+		return nil, nil
+	}
+
+	avg := make([]float64, numObjectives)
+	maxs := make([]float64, numObjectives)
+	for i := range maxs {
+		maxs[i] = math.Inf(-1)
+	}
+	count := 0
+	for _, genome := range genomes {
+		if len(genome.Objectives) != numObjectives {
+			continue
+		}
+		count++
+		for i, v := range genome.Objectives {
+			avg[i] += v
+			maxs[i] = math.Max(maxs[i], v)
+		}
+	}
+	for i := range avg {
+		avg[i] /= float64(count)
+	}
+	return avg, maxs
+}
+
+// metricStats returns the average and the maximum value of each auxiliary
+// metric among the argument genomes that report it, ignoring NaN and infinite
+// values, or nil if none of them does.
+func metricStats(genomes []*Genome) (map[string]float64, map[string]float64) {
+	var avg, maxs map[string]float64
+	counts := make(map[string]int)
+	for _, genome := range genomes {
+		for name, v := range genome.Metrics {
+			if math.IsNaN(v) || math.IsInf(v, 0) {
+				continue
+			}
+			if avg == nil {
+				avg = make(map[string]float64)
+				maxs = make(map[string]float64)
+			}
+			if counts[name] == 0 || v > maxs[name] {
+				maxs[name] = v
+			}
+			avg[name] += v
+			counts[name]++
+		}
+	}
+	for name, count := range counts {
+		avg[name] /= float64(count)
+	}
+	return avg, maxs
+}
+
+// RecordValidation records the argument held-out validation score of the top
+// genomes of the current generation.
+func (s *Statistics) RecordValidation(currGen int, fitness float64) {
+	e, ok := s.entry(currGen)
+	if !ok {
+		return
+	}
+	s.ValidationFitness[e] = fitness
+}
+
+// UpdateSpecies records the number and the compositions of the argument species
+// in the current generation; it is called right after speciation, so that the
+// records reflect the members of the current generation.
+func (s *Statistics) UpdateSpecies(currGen int, species []*Species) {
+	e, ok := s.entry(currGen)
+	if !ok {
+		return
+	}
+	s.NumSpecies[e] = len(species)
+	records := make([]SpeciesRecord, len(species))
+	for i, sp := range species {
+		records[i] = SpeciesRecord{
+			ID:          sp.ID,
+			Size:        len(sp.Members),
+			BestFitness: sp.BestFitness,
+			Age:         sp.Age,
+			Stagnation:  sp.Stagnation,
+		}
+	}
+	s.SpeciesHistory[e] = records
+}
+
+// UpdateTiming records the wall-clock time of each phase of the current
+// generation, and of the whole generation.
+func (s *Statistics) UpdateTiming(currGen int, evaluation, speciation,
+	reproduction, generation time.Duration) {
+	e, ok := s.entry(currGen)
+	if !ok {
+		return
+	}
+	s.EvaluationTime[e] = evaluation
+	s.SpeciationTime[e] = speciation
+	s.ReproductionTime[e] = reproduction
+	s.GenerationTime[e] = generation
+}
+
+// CountMutations counts the mutations of the argument names applied during the
+// reproduction of the current generation.
+func (s *Statistics) CountMutations(currGen int, names ...string) {
+	e, ok := s.entry(currGen)
+	if !ok {
+		return
+	}
+	if s.MutationCounts[e] == nil {
+		s.MutationCounts[e] = make(map[string]int)
+	}
+	for _, name := range names {
+		s.MutationCounts[e][name]++
+	}
+}
+
+// CountInnovations counts the argument number of innovations that appeared
+// during the reproduction of the current generation.
+func (s *Statistics) CountInnovations(currGen, numInnovations int) {
+	e, ok := s.entry(currGen)
+	if !ok {
+		return
+	}
+	s.NumInnovations[e] += numInnovations
+}
+
+// RecordFailure records a failed evaluation of the genome of the argument ID in
+// the current generation, given the reason of the failure.
+func (s *Statistics) RecordFailure(currGen, genomeID int, reason string) {
+	e, ok := s.entry(currGen)
+	if !ok {
+		return
+	}
+	s.Failures[e] = append(s.Failures[e],
+		EvaluationFailure{GenomeID: genomeID, Reason: reason})
+}
+
+// RecordError records a failed evaluation of the argument genome in the
+// argument generation, whose evaluation function returned the argument error.
+func (s *Statistics) RecordError(currGen, genomeID int, err error) {
+	e, ok := s.entry(currGen)
+	if !ok {
+		return
+	}
+	s.Failures[e] = append(s.Failures[e], EvaluationFailure{
+		GenomeID: genomeID, Reason: "error", Message: err.Error()})
+}
+
+// NewBoundedStatistics returns a new instance of Statistics whose memory does
+// not grow with the number of generations, for open-ended runs; only every
+// stride-th generation is recorded (every generation if stride is not greater
+// than 1), and only the argument number of most recent records are kept (all
+// of them if window is 0).
+func NewBoundedStatistics(window, stride int) *Statistics {
+	if stride < 1 {
+		stride = 1
+	}
+	s := NewStatistics(0)
+	s.window = window
+	s.stride = stride
+	return s
+}
+
+// entry returns the index of the entry of the argument generation in every
+// series, and creates it if needed; it returns false if the generation is not
+// recorded, i.e., it is skipped by downsampling, or it is older than the
+// window of recent generations.
+func (s *Statistics) entry(gen int) (int, bool) {
+	if !s.bounded() {
+		for len(s.Generations) <= gen {
+			s.appendEntry(len(s.Generations))
+		}
+		if gen >= s.numRecorded {
+			s.numRecorded = gen + 1
 		}
-		return avg / float64(n.Config.PopulationSize)
-	}()
+		return gen, true
+	}
+
+	if e, ok := s.lookup(gen); ok {
+		return e, true
+	}
+	if gen%s.stride != 0 ||
+		(s.numRecorded > 0 && gen < s.Generations[s.numRecorded-1]) {
+		return 0, false
+	}
+	s.appendEntry(gen)
+	s.numRecorded++
+	if s.window > 0 && s.numRecorded > s.window {
+		s.dropOldest()
+		s.numRecorded--
+	}
+	return s.numRecorded - 1, true
+}
+
+// lookup returns the index of the entry of the argument generation in every
+// series, if it is recorded.
+func (s *Statistics) lookup(gen int) (int, bool) {
+	if !s.bounded() {
+		return gen, gen >= 0 && gen < s.numRecorded
+	}
+	e := sort.SearchInts(s.Generations[:s.numRecorded], gen)
+	return e, e < s.numRecorded && s.Generations[e] == gen
+}
+
+// bounded returns true if only a part of the generations is recorded.
+func (s *Statistics) bounded() bool {
+	return s.window > 0 || s.stride > 1
+}
+
+// appendEntry appends an empty entry of the argument generation to every
+// series.
+func (s *Statistics) appendEntry(gen int) {
+	s.Generations = append(s.Generations, gen)
+	s.NumSpecies = append(s.NumSpecies, 0)
+	s.MinFitness = append(s.MinFitness, 0.0)
+	s.MaxFitness = append(s.MaxFitness, 0.0)
+	s.AvgFitness = append(s.AvgFitness, 0.0)
+	s.StdFitness = append(s.StdFitness, 0.0)
+
+	s.LowerQuartileFitness = append(s.LowerQuartileFitness, 0.0)
+	s.MedianFitness = append(s.MedianFitness, 0.0)
+	s.UpperQuartileFitness = append(s.UpperQuartileFitness, 0.0)
+
+	s.ValidationFitness = append(s.ValidationFitness, 0.0)
+
+	s.AvgNumNodes = append(s.AvgNumNodes, 0.0)
+	s.AvgNumConns = append(s.AvgNumConns, 0.0)
+	s.BestNumNodes = append(s.BestNumNodes, 0)
+	s.BestNumConns = append(s.BestNumConns, 0)
+
+	s.AvgObjectives = append(s.AvgObjectives, nil)
+	s.MaxObjectives = append(s.MaxObjectives, nil)
+	s.AvgMetrics = append(s.AvgMetrics, nil)
+	s.MaxMetrics = append(s.MaxMetrics, nil)
+
+	s.MutationCounts = append(s.MutationCounts, nil)
+	s.NumInnovations = append(s.NumInnovations, 0)
+
+	s.Failures = append(s.Failures, nil)
+
+	s.FitnessHistograms = append(s.FitnessHistograms, nil)
+
+	s.EvaluationTime = append(s.EvaluationTime, 0)
+	s.SpeciationTime = append(s.SpeciationTime, 0)
+	s.ReproductionTime = append(s.ReproductionTime, 0)
+	s.GenerationTime = append(s.GenerationTime, 0)
+
+	s.SpeciesHistory = append(s.SpeciesHistory, nil)
+}
+
+// dropOldest removes the oldest entry from every series.
+func (s *Statistics) dropOldest() {
+	s.Generations = s.Generations[1:]
+	s.NumSpecies = s.NumSpecies[1:]
+	s.MinFitness = s.MinFitness[1:]
+	s.MaxFitness = s.MaxFitness[1:]
+	s.AvgFitness = s.AvgFitness[1:]
+	s.StdFitness = s.StdFitness[1:]
+
+	s.LowerQuartileFitness = s.LowerQuartileFitness[1:]
+	s.MedianFitness = s.MedianFitness[1:]
+	s.UpperQuartileFitness = s.UpperQuartileFitness[1:]
+
+	s.ValidationFitness = s.ValidationFitness[1:]
+
+	s.AvgNumNodes = s.AvgNumNodes[1:]
+	s.AvgNumConns = s.AvgNumConns[1:]
+	s.BestNumNodes = s.BestNumNodes[1:]
+	s.BestNumConns = s.BestNumConns[1:]
+
+	s.AvgObjectives = s.AvgObjectives[1:]
+	s.MaxObjectives = s.MaxObjectives[1:]
+	s.AvgMetrics = s.AvgMetrics[1:]
+	s.MaxMetrics = s.MaxMetrics[1:]
+
+	s.MutationCounts = s.MutationCounts[1:]
+	s.NumInnovations = s.NumInnovations[1:]
+
+	s.Failures = s.Failures[1:]
+
+	s.FitnessHistograms = s.FitnessHistograms[1:]
+
+	s.EvaluationTime = s.EvaluationTime[1:]
+	s.SpeciationTime = s.SpeciationTime[1:]
+	s.ReproductionTime = s.ReproductionTime[1:]
+	s.GenerationTime = s.GenerationTime[1:]
+
+	s.SpeciesHistory = s.SpeciesHistory[1:]
+}
+
+// At returns a snapshot of the statistics of the argument generation, or nil
+// if the generation is not recorded.
+func (s *Statistics) At(gen int) *GenerationStats {
+	e, ok := s.lookup(gen)
+	if !ok {
+		return nil
+	}
+	return &GenerationStats{
+		Generation: gen,
+		NumSpecies: s.NumSpecies[e],
+		MinFitness: s.MinFitness[e],
+		MaxFitness: s.MaxFitness[e],
+		AvgFitness: s.AvgFitness[e],
+		StdFitness: s.StdFitness[e],
+
+		LowerQuartileFitness: s.LowerQuartileFitness[e],
+		MedianFitness:        s.MedianFitness[e],
+		UpperQuartileFitness: s.UpperQuartileFitness[e],
+
+		ValidationFitness: s.ValidationFitness[e],
+
+		AvgNumNodes:  s.AvgNumNodes[e],
+		AvgNumConns:  s.AvgNumConns[e],
+		BestNumNodes: s.BestNumNodes[e],
+		BestNumConns: s.BestNumConns[e],
+
+		AvgObjectives: s.AvgObjectives[e],
+		MaxObjectives: s.MaxObjectives[e],
+		AvgMetrics:    s.AvgMetrics[e],
+		MaxMetrics:    s.MaxMetrics[e],
+
+		MutationCounts: s.MutationCounts[e],
+		NumInnovations: s.NumInnovations[e],
+
+		Failures: s.Failures[e],
+
+		FitnessHistogram: s.FitnessHistograms[e],
+
+		EvaluationTime:   s.EvaluationTime[e],
+		SpeciationTime:   s.SpeciationTime[e],
+		ReproductionTime: s.ReproductionTime[e],
+		GenerationTime:   s.GenerationTime[e],
+
+		Species: s.SpeciesHistory[e],
+	}
+}
+
+// Stream returns a channel, with the argument size of buffer, to which a
+// snapshot of each generation is pushed at the end of the generation, so that
+// external consumers can process the statistics incrementally. Pushing blocks
+// while the buffer is full, thus the channel must be drained. It is closed at
+// the end of Run, or by CloseStream.
+func (s *Statistics) Stream(buffer int) <-chan *GenerationStats {
+	if s.stream == nil {
+		s.stream = make(chan *GenerationStats, buffer)
+	}
+	return s.stream
+}
+
+// CloseStream closes the stream of the statistics, if any.
+func (s *Statistics) CloseStream() {
+	if s.stream != nil {
+		close(s.stream)
+		s.stream = nil
+	}
+}
+
+// publish pushes a snapshot of the argument generation to the stream, if any.
+func (s *Statistics) publish(gen int) {
+	if s.stream == nil {
+		return
+	}
+	if stats := s.At(gen); stats != nil {
+		s.stream <- stats
+	}
+}
+
+// NumRecorded returns the number of generations recorded so far, i.e., the
+// length of each series.
+func (s *Statistics) NumRecorded() int {
+	return s.numRecorded
+}
+
+// ExportCSV writes the statistics to the argument writer in CSV format, with a
+// header row followed by a row for each recorded generation.
+func (s *Statistics) ExportCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+
+	record := make([]string, len(statisticsColumns))
+	for i, column := range statisticsColumns {
+		record[i] = column.Header
+	}
+	if err := cw.Write(record); err != nil {
+		return err
+	}
+
+	for e := 0; e < s.numRecorded; e++ {
+		for i, column := range statisticsColumns {
+			record[i] = column.Value(s, e)
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// ExportJSON writes the statistics of the recorded generations to the argument
+// writer as a JSON document of Results, along with the argument configuration
+// and the metadata of the run.
+func (s *Statistics) ExportJSON(w io.Writer, config *Config) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "\t")
+	return encoder.Encode(&Results{
+		Config:     config,
+		RunInfo:    s.RunInfo,
+		Statistics: s.recorded(),
+	})
+}
+
+// recorded returns a shallow copy of the statistics, whose series are trimmed
+// to the recorded generations.
+func (s *Statistics) recorded() *Statistics {
+	return &Statistics{
+		Generations: s.Generations[:s.numRecorded],
+		NumSpecies:  s.NumSpecies[:s.numRecorded],
+		MinFitness:  s.MinFitness[:s.numRecorded],
+		MaxFitness:  s.MaxFitness[:s.numRecorded],
+		AvgFitness:  s.AvgFitness[:s.numRecorded],
+		StdFitness:  s.StdFitness[:s.numRecorded],
+		RunInfo:     s.RunInfo,
+		numRecorded: s.numRecorded,
+		window:      s.window,
+		stride:      s.stride,
+
+		LowerQuartileFitness: s.LowerQuartileFitness[:s.numRecorded],
+		MedianFitness:        s.MedianFitness[:s.numRecorded],
+		UpperQuartileFitness: s.UpperQuartileFitness[:s.numRecorded],
+
+		ValidationFitness: s.ValidationFitness[:s.numRecorded],
+
+		AvgNumNodes:  s.AvgNumNodes[:s.numRecorded],
+		AvgNumConns:  s.AvgNumConns[:s.numRecorded],
+		BestNumNodes: s.BestNumNodes[:s.numRecorded],
+		BestNumConns: s.BestNumConns[:s.numRecorded],
+
+		AvgObjectives: s.AvgObjectives[:s.numRecorded],
+		MaxObjectives: s.MaxObjectives[:s.numRecorded],
+		AvgMetrics:    s.AvgMetrics[:s.numRecorded],
+		MaxMetrics:    s.MaxMetrics[:s.numRecorded],
+
+		MutationCounts: s.MutationCounts[:s.numRecorded],
+		NumInnovations: s.NumInnovations[:s.numRecorded],
+
+		Failures: s.Failures[:s.numRecorded],
+
+		FitnessHistograms: s.FitnessHistograms[:s.numRecorded],
+
+		EvaluationTime:   s.EvaluationTime[:s.numRecorded],
+		SpeciationTime:   s.SpeciationTime[:s.numRecorded],
+		ReproductionTime: s.ReproductionTime[:s.numRecorded],
+		GenerationTime:   s.GenerationTime[:s.numRecorded],
+
+		SpeciesHistory: s.SpeciesHistory[:s.numRecorded],
+	}
+}
+
+// Copy returns a deep copy of the statistics, except for the stream, which is
+// not copied, and the immutable records of generations, e.g., histograms,
+// which are shared.
+func (s *Statistics) Copy() *Statistics {
+	c := &Statistics{
+		Generations: append([]int(nil), s.Generations...),
+		NumSpecies:  append([]int(nil), s.NumSpecies...),
+		MinFitness:  append([]float64(nil), s.MinFitness...),
+		MaxFitness:  append([]float64(nil), s.MaxFitness...),
+		AvgFitness:  append([]float64(nil), s.AvgFitness...),
+		StdFitness:  append([]float64(nil), s.StdFitness...),
+		numRecorded: s.numRecorded,
+		window:      s.window,
+		stride:      s.stride,
+
+		LowerQuartileFitness: append([]float64(nil), s.LowerQuartileFitness...),
+		MedianFitness:        append([]float64(nil), s.MedianFitness...),
+		UpperQuartileFitness: append([]float64(nil), s.UpperQuartileFitness...),
+
+		ValidationFitness: append([]float64(nil), s.ValidationFitness...),
+
+		AvgNumNodes:  append([]float64(nil), s.AvgNumNodes...),
+		AvgNumConns:  append([]float64(nil), s.AvgNumConns...),
+		BestNumNodes: append([]int(nil), s.BestNumNodes...),
+		BestNumConns: append([]int(nil), s.BestNumConns...),
+
+		AvgObjectives: append([][]float64(nil), s.AvgObjectives...),
+		MaxObjectives: append([][]float64(nil), s.MaxObjectives...),
+		AvgMetrics:    append([]map[string]float64(nil), s.AvgMetrics...),
+		MaxMetrics:    append([]map[string]float64(nil), s.MaxMetrics...),
+
+		MutationCounts: make([]map[string]int, len(s.MutationCounts)),
+		NumInnovations: append([]int(nil), s.NumInnovations...),
+
+		Failures: make([][]EvaluationFailure, len(s.Failures)),
+
+		FitnessHistograms: append([]*Histogram(nil), s.FitnessHistograms...),
+
+		EvaluationTime:   append([]time.Duration(nil), s.EvaluationTime...),
+		SpeciationTime:   append([]time.Duration(nil), s.SpeciationTime...),
+		ReproductionTime: append([]time.Duration(nil), s.ReproductionTime...),
+		GenerationTime:   append([]time.Duration(nil), s.GenerationTime...),
+
+		SpeciesHistory: append([][]SpeciesRecord(nil), s.SpeciesHistory...),
+	}
+	if s.RunInfo != nil {
+		runInfo := *s.RunInfo
+		c.RunInfo = &runInfo
+	}
+
+	// mutations are counted, and failures are appended, in place.
+	for e, counts := range s.MutationCounts {
+		if counts == nil {
+			continue
+		}
+		c.MutationCounts[e] = make(map[string]int, len(counts))
+		for name, count := range counts {
+			c.MutationCounts[e][name] = count
+		}
+	}
+	for e, failures := range s.Failures {
+		c.Failures[e] = failures[:len(failures):len(failures)]
+	}
+	return c
+}
+
+// quantile returns the q-quantile of the argument sorted values, linearly
+// interpolated between the two closest ranks.
+func quantile(sorted []float64, q float64) float64 {
+	if len(sorted) == 0 {
+		return 0.0
+	}
+	pos := q * float64(len(sorted)-1)
+	lower := int(math.Floor(pos))
+	upper := int(math.Ceil(pos))
+	frac := pos - float64(lower)
+	return sorted[lower]*(1.0-frac) + sorted[upper]*frac
+}
+
+// SpeciesSize is the number of members of a species in a generation.
+type SpeciesSize struct {
+	SpeciesID  int `json:"speciesID"`  // species ID
+	Generation int `json:"generation"` // index of the generation
+	Size       int `json:"size"`       // number of members
+}
+
+// SpeciesSizes returns the size of every species in every recorded generation,
+// sorted by generation and species ID, as a dataset in long format.
+func (s *Statistics) SpeciesSizes() []SpeciesSize {
+	var sizes []SpeciesSize
+	for e := 0; e < s.numRecorded; e++ {
+		records := make([]SpeciesRecord, len(s.SpeciesHistory[e]))
+		copy(records, s.SpeciesHistory[e])
+		sort.Slice(records, func(i, j int) bool {
+			return records[i].ID < records[j].ID
+		})
+		for _, record := range records {
+			sizes = append(sizes,
+				SpeciesSize{record.ID, s.Generations[e], record.Size})
+		}
+	}
+	return sizes
+}
+
+// ExportSpeciesCSV writes the sizes of species over generations to the
+// argument writer in CSV format, with a row for each recorded generation and a
+// column for each species that ever existed (0 if it did not exist in the
+// generation), which can be plotted as a stacked area chart of speciation.
+func (s *Statistics) ExportSpeciesCSV(w io.Writer) error {
+	sizes := s.SpeciesSizes()
+
+	// column of each species, in the order of their IDs
+	var ids []int
+	columns := make(map[int]int)
+	for _, size := range sizes {
+		if _, ok := columns[size.SpeciesID]; !ok {
+			columns[size.SpeciesID] = 0
+			ids = append(ids, size.SpeciesID)
+		}
+	}
+	sort.Ints(ids)
+	header := []string{"generation"}
+	for i, id := range ids {
+		columns[id] = i + 1
+		header = append(header, "species_"+strconv.Itoa(id))
+	}
+
+	cw := csv.NewWriter(w)
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	rows := make([][]string, s.numRecorded)
+	for e := range rows {
+		rows[e] = make([]string, len(header))
+		rows[e][0] = strconv.Itoa(s.Generations[e])
+		for i := 1; i < len(header); i++ {
+			rows[e][i] = "0"
+		}
+	}
+	for _, size := range sizes {
+		e, _ := s.lookup(size.Generation)
+		rows[e][columns[size.SpeciesID]] = strconv.Itoa(size.Size)
+	}
+	if err := cw.WriteAll(rows); err != nil {
+		return err
+	}
+	return cw.Error()
+}
+
+// formatFloat formats a floating point number for exported statistics.
+func formatFloat(x float64) string {
+	return strconv.FormatFloat(x, 'g', -1, 64)
 }
@@ -0,0 +1,467 @@
+//go:build !js
+
+package neat
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"image"
+	"image/color"
+	"image/png"
+	"math"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+)
+
+func TestStatisticsExportCSV(t *testing.T) {
+	config := NewDefaultConfig(3, 1)
+	config.NumGenerations = 3
+	config.PopulationSize = 20
+
+	n := New(config, XORTest(), WithRand(rand.New(rand.NewSource(0))))
+	n.Run()
+
+	buf := &bytes.Buffer{}
+	if err := n.Statistics.ExportCSV(buf); err != nil {
+		t.Fatal(err)
+	}
+	records, err := csv.NewReader(buf).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != config.NumGenerations+1 {
+		t.Fatalf("invalid number of rows: %d != %d",
+			len(records), config.NumGenerations+1)
+	}
+	if records[0][0] != "generation" || records[3][0] != "2" {
+		t.Errorf("invalid rows: %v", records)
+	}
+}
+
+func TestStatisticsExportJSON(t *testing.T) {
+	config := NewDefaultConfig(3, 1)
+	config.NumGenerations = 5
+	config.PopulationSize = 20
+
+	n := New(config, XORTest(), WithRand(rand.New(rand.NewSource(0))))
+	for i := 0; i < 2; i++ {
+		n.Step()
+	}
+
+	buf := &bytes.Buffer{}
+	if err := n.Statistics.ExportJSON(buf, config); err != nil {
+		t.Fatal(err)
+	}
+	results := &Results{}
+	if err := json.Unmarshal(buf.Bytes(), results); err != nil {
+		t.Fatal(err)
+	}
+	if results.Config.PopulationSize != 20 {
+		t.Errorf("invalid config: %+v", results.Config)
+	}
+	if results.RunInfo.ConfigHash != ConfigHash(config) {
+		t.Errorf("invalid run info: %+v", results.RunInfo)
+	}
+	if len(results.Statistics.AvgFitness) != 2 {
+		t.Errorf("invalid number of generations: %d != 2",
+			len(results.Statistics.AvgFitness))
+	}
+}
+
+func TestStatisticsUpdate(t *testing.T) {
+	config := NewDefaultConfig(3, 1)
+	n := New(config, XORTest())
+	n.Population = n.Population[:5]
+	for i, genome := range n.Population {
+		genome.Fitness = float64(i + 1)
+	}
+
+	s := NewStatistics(1)
+	s.Update(0, n)
+	if s.MinFitness[0] != 1.0 || s.MaxFitness[0] != 5.0 {
+		t.Errorf("invalid min/max: %f, %f", s.MinFitness[0], s.MaxFitness[0])
+	}
+	if s.AvgFitness[0] != 3.0 {
+		t.Errorf("invalid average: %f != 3.0", s.AvgFitness[0])
+	}
+	if math.Abs(s.StdFitness[0]-math.Sqrt(2.0)) > 1e-9 {
+		t.Errorf("invalid standard deviation: %f", s.StdFitness[0])
+	}
+	if s.LowerQuartileFitness[0] != 2.0 || s.MedianFitness[0] != 3.0 ||
+		s.UpperQuartileFitness[0] != 4.0 {
+		t.Errorf("invalid quartiles: %f, %f, %f", s.LowerQuartileFitness[0],
+			s.MedianFitness[0], s.UpperQuartileFitness[0])
+	}
+
+	// the initial population is fully connected with 3 inputs and an output.
+	if s.AvgNumNodes[0] != 4.0 || s.AvgNumConns[0] != 3.0 {
+		t.Errorf("invalid average complexity: %f, %f",
+			s.AvgNumNodes[0], s.AvgNumConns[0])
+	}
+	if s.BestNumNodes[0] != 4 || s.BestNumConns[0] != 3 {
+		t.Errorf("invalid complexity of the best: %d, %d",
+			s.BestNumNodes[0], s.BestNumConns[0])
+	}
+}
+
+func TestStatisticsSpeciesHistory(t *testing.T) {
+	config := NewDefaultConfig(3, 1)
+	config.NumGenerations = 3
+	config.PopulationSize = 20
+
+	n := New(config, XORTest(), WithRand(rand.New(rand.NewSource(0))))
+	n.Run()
+
+	for gen, records := range n.Statistics.SpeciesHistory {
+		size := 0
+		for _, record := range records {
+			size += record.Size
+			if record.Age > gen {
+				t.Errorf("species %d is older than the run: %d > %d",
+					record.ID, record.Age, gen)
+			}
+		}
+		if size != config.PopulationSize {
+			t.Errorf("invalid total size of species in generation %d: %d != %d",
+				gen, size, config.PopulationSize)
+		}
+	}
+}
+
+func TestStatisticsPlotPNG(t *testing.T) {
+	config := NewDefaultConfig(3, 1)
+	config.NumGenerations = 5
+	config.PopulationSize = 20
+
+	n := New(config, XORTest(), WithRand(rand.New(rand.NewSource(0))))
+	n.Run()
+
+	path := filepath.Join(t.TempDir(), "statistics.png")
+	if err := n.Statistics.PlotPNG(path); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	img, err := png.Decode(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if img.Bounds().Dx() != plotWidth*plotScale ||
+		img.Bounds().Dy() != 2*plotPanelHeight*plotScale {
+		t.Errorf("invalid size of the plot: %v", img.Bounds())
+	}
+
+	// the title, the legend, and the tick values are drawn in the margins.
+	drawn := func(rect image.Rectangle, c color.Color) bool {
+		rect = image.Rect(rect.Min.X*plotScale, rect.Min.Y*plotScale,
+			rect.Max.X*plotScale, rect.Max.Y*plotScale)
+		r0, g0, b0, _ := c.RGBA()
+		for y := rect.Min.Y; y < rect.Max.Y; y++ {
+			for x := rect.Min.X; x < rect.Max.X; x++ {
+				r, g, b, _ := img.At(x, y).RGBA()
+				if r == r0 && g == g0 && b == b0 {
+					return true
+				}
+			}
+		}
+		return false
+	}
+	margins := map[string]image.Rectangle{
+		"title":         image.Rect(0, 0, plotWidth/2, plotMarginTop),
+		"y tick values": image.Rect(0, 0, plotMarginLeft, plotPanelHeight),
+		"x tick values": image.Rect(0, plotPanelHeight-plotMarginBelow,
+			plotWidth, plotPanelHeight),
+	}
+	for name, rect := range margins {
+		if !drawn(rect, plotAxisColor) {
+			t.Errorf("%s are not drawn", name)
+		}
+	}
+	legend := image.Rect(plotWidth/2, 0, plotWidth, plotMarginTop)
+	for _, c := range []color.Color{plotMaxColor, plotAvgColor, plotMinColor} {
+		if !drawn(legend, c) {
+			t.Errorf("legend of %v is not drawn", c)
+		}
+	}
+}
+
+func TestDrawText(t *testing.T) {
+	if w := textWidth("Max"); w != 3*glyphWidth+2*glyphSpacing {
+		t.Errorf("invalid width of text: %d", w)
+	}
+	img := image.NewRGBA(image.Rect(0, 0, 20, 10))
+	drawText(img, 0, 0, 1, "1", color.Black)
+	if _, _, _, a := img.At(2, 0).RGBA(); a == 0 {
+		t.Error("glyph is not drawn")
+	}
+	if _, _, _, a := img.At(0, 0).RGBA(); a != 0 {
+		t.Error("glyph is drawn outside of its bitmap")
+	}
+
+	img = image.NewRGBA(image.Rect(0, 0, 40, 20))
+	drawText(img, 0, 0, 2, "1", color.Black)
+	if _, _, _, a := img.At(5, 1).RGBA(); a == 0 {
+		t.Error("glyph is not scaled")
+	}
+}
+
+func TestStatisticsTiming(t *testing.T) {
+	config := NewDefaultConfig(3, 1)
+	config.NumGenerations = 3
+	config.PopulationSize = 20
+
+	n := New(config, XORTest(), WithRand(rand.New(rand.NewSource(0))))
+	n.Run()
+
+	s := n.Statistics
+	for gen := 0; gen < config.NumGenerations; gen++ {
+		phases := s.EvaluationTime[gen] + s.SpeciationTime[gen] +
+			s.ReproductionTime[gen]
+		if s.GenerationTime[gen] <= 0 || phases > s.GenerationTime[gen] {
+			t.Errorf("invalid timing of generation %d: %v > %v",
+				gen, phases, s.GenerationTime[gen])
+		}
+	}
+}
+
+func TestStatisticsStream(t *testing.T) {
+	config := NewDefaultConfig(3, 1)
+	config.NumGenerations = 3
+	config.PopulationSize = 20
+
+	n := New(config, XORTest(), WithRand(rand.New(rand.NewSource(0))))
+	stream := n.Statistics.Stream(1)
+	done := make(chan []*GenerationStats)
+	go func() {
+		var received []*GenerationStats
+		for stats := range stream {
+			received = append(received, stats)
+		}
+		done <- received
+	}()
+
+	// run beyond the configured number of generations.
+	for i := 0; i < 5; i++ {
+		n.Step()
+	}
+	n.Statistics.CloseStream()
+
+	received := <-done
+	if len(received) != 5 {
+		t.Fatalf("invalid number of snapshots: %d != 5", len(received))
+	}
+	if received[4].Generation != 4 ||
+		received[4].AvgFitness != n.Statistics.AvgFitness[4] {
+		t.Errorf("invalid snapshot: %+v", received[4])
+	}
+	if n.Statistics.NumRecorded() != 5 {
+		t.Errorf("invalid number of recorded generations: %d != 5",
+			n.Statistics.NumRecorded())
+	}
+}
+
+func TestStatisticsMutations(t *testing.T) {
+	config := NewDefaultConfig(3, 1)
+	config.NumGenerations = 5
+	config.PopulationSize = 30
+	config.RateAddNode = 0.5
+	config.RateAddConn = 0.5
+	config.RateMutateActivation = 0.5
+	config.CPPNActivations = []string{"sin", "gaussian"}
+
+	n := New(config, XORTest(), WithSeed(1))
+	n.Run()
+
+	s := n.Statistics
+	total := map[string]int{}
+	innovations := 0
+	for gen := 0; gen < config.NumGenerations; gen++ {
+		for name, count := range s.MutationCounts[gen] {
+			total[name] += count
+		}
+		innovations += s.NumInnovations[gen]
+	}
+	if total["perturb"] == 0 || total["addNode"] == 0 || total["addConn"] == 0 ||
+		total["activation"] == 0 {
+		t.Errorf("mutations are not counted: %v", total)
+	}
+	if innovations == 0 {
+		t.Error("innovations are not counted")
+	}
+
+	// every type of mutation is exported.
+	buf := &bytes.Buffer{}
+	if err := s.ExportCSV(buf); err != nil {
+		t.Fatal(err)
+	}
+	records, err := csv.NewReader(buf).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	exported := 0
+	for i, column := range records[0] {
+		if column != "activation_count" {
+			continue
+		}
+		for _, record := range records[1:] {
+			count, _ := strconv.Atoi(record[i])
+			exported += count
+		}
+	}
+	if exported != total["activation"] {
+		t.Errorf("invalid exported activation mutations: %d != %d", exported,
+			total["activation"])
+	}
+}
+
+func TestStatisticsFailures(t *testing.T) {
+	config := NewDefaultConfig(3, 1)
+	config.NumGenerations = 2
+	config.PopulationSize = 20
+	config.InitFitness = -1.0
+
+	// every other evaluation is numerically unstable, resulting in NaN and
+	// infinity in turn.
+	count := 0
+	unstable := func(n *NeuralNetwork) float64 {
+		count++
+		switch count % 4 {
+		case 2:
+			return math.NaN()
+		case 0:
+			return math.Inf(1)
+		}
+		return 1.0
+	}
+
+	n := New(config, unstable, WithSeed(1))
+	n.Step()
+
+	failures := n.Statistics.Failures[0]
+	if len(failures) != config.PopulationSize/2 {
+		t.Fatalf("invalid number of failures: %d != %d",
+			len(failures), config.PopulationSize/2)
+	}
+	reasons := make(map[string]int)
+	for _, failure := range failures {
+		reasons[failure.Reason]++
+	}
+	if reasons["nan"] != len(failures)/2 || reasons["inf"] != len(failures)/2 {
+		t.Errorf("invalid reasons of failures: %v", reasons)
+	}
+	if n.Statistics.MinFitness[0] != config.InitFitness {
+		t.Errorf("the fitness of a failed genome is not replaced: %f",
+			n.Statistics.MinFitness[0])
+	}
+}
+
+func TestStatisticsExportSpeciesCSV(t *testing.T) {
+	config := NewDefaultConfig(3, 1)
+	config.NumGenerations = 5
+	config.PopulationSize = 30
+	config.DistanceThreshold = 0.5
+
+	n := New(config, XORTest(), WithSeed(1))
+	n.Run()
+
+	buf := &bytes.Buffer{}
+	if err := n.Statistics.ExportSpeciesCSV(buf); err != nil {
+		t.Fatal(err)
+	}
+	records, err := csv.NewReader(buf).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != config.NumGenerations+1 {
+		t.Fatalf("invalid number of rows: %d != %d",
+			len(records), config.NumGenerations+1)
+	}
+	for gen, record := range records[1:] {
+		total := 0
+		for _, field := range record[1:] {
+			size, _ := strconv.Atoi(field)
+			total += size
+		}
+		if total != config.PopulationSize {
+			t.Errorf("invalid total size in generation %d: %d != %d",
+				gen, total, config.PopulationSize)
+		}
+	}
+}
+
+func TestNewHistogram(t *testing.T) {
+	h := NewHistogram([]float64{0.0, 0.1, 0.2, 0.9, 1.0}, 2)
+	if h.Min != 0.0 || h.Max != 1.0 {
+		t.Errorf("invalid range: [%f, %f]", h.Min, h.Max)
+	}
+	if h.Counts[0] != 3 || h.Counts[1] != 2 {
+		t.Errorf("invalid counts: %v", h.Counts)
+	}
+
+	h = NewHistogram([]float64{1.0, 1.0}, 3)
+	if h.Counts[0] != 2 {
+		t.Errorf("invalid counts of identical values: %v", h.Counts)
+	}
+}
+
+func TestBoundedStatistics(t *testing.T) {
+	config := NewDefaultConfig(3, 1)
+	config.NumGenerations = 10
+	config.PopulationSize = 20
+	config.StatisticsWindow = 3
+	config.StatisticsStride = 2
+
+	n := New(config, XORTest(), WithSeed(1))
+	n.Run()
+
+	s := n.Statistics
+	if s.NumRecorded() != 3 || len(s.MaxFitness) != 3 {
+		t.Fatalf("invalid number of records: %d", s.NumRecorded())
+	}
+	for i, gen := range []int{4, 6, 8} {
+		if s.Generations[i] != gen {
+			t.Errorf("invalid recorded generation: %d != %d", s.Generations[i], gen)
+		}
+	}
+	if s.At(2) != nil || s.At(7) != nil || s.At(8) == nil {
+		t.Error("invalid lookup of recorded generations")
+	}
+
+	buf := &bytes.Buffer{}
+	if err := s.ExportCSV(buf); err != nil {
+		t.Fatal(err)
+	}
+	records, err := csv.NewReader(buf).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 4 || records[3][0] != "8" {
+		t.Errorf("invalid rows: %v", records)
+	}
+}
+
+func TestMetricStats(t *testing.T) {
+	genomes := []*Genome{
+		{Metrics: map[string]float64{"steps": 10.0, "distance": 1.0}},
+		{Metrics: map[string]float64{"steps": 20.0, "distance": math.NaN()}},
+		{},
+	}
+	avg, maxs := metricStats(genomes)
+	if avg["steps"] != 15.0 || maxs["steps"] != 20.0 {
+		t.Errorf("invalid stats of steps: %f, %f", avg["steps"], maxs["steps"])
+	}
+	if avg["distance"] != 1.0 || maxs["distance"] != 1.0 {
+		t.Errorf("invalid stats of distance: %f, %f",
+			avg["distance"], maxs["distance"])
+	}
+	if avg, maxs := metricStats(genomes[2:]); avg != nil || maxs != nil {
+		t.Errorf("invalid stats without metrics: %v, %v", avg, maxs)
+	}
+}
@@ -0,0 +1,232 @@
+// transfer.go implementation of transfer of genomes between experiments.
+//
+// Copyright (C) 2017  Jin Yeom
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package neat
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ReadGenomeJSON reads a genome in JSON from the argument reader, as
+// NewGenomeJSON does from a file. Unlike NewGenomeJSON, it does not depend on
+// the file system, so that a genome can be loaded from memory, e.g., in a
+// browser (see package wasm).
+func ReadGenomeJSON(r io.Reader) (*Genome, error) {
+	g := &Genome{}
+	decoder := json.NewDecoder(r)
+	if err := decoder.Decode(g); err != nil {
+		return nil, err
+	}
+
+	if err := RestoreGenome(g); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+// RestoreGenome validates the argument decoded genome, restores its activation
+// functions from ActivationSet by their names, and marks it to be evaluated.
+// It returns an error if the genome is invalid, or if a function is unknown,
+// e.g., if a user-defined function is not registered. Decoders of other
+// formats (see package neatpb) should call it on every genome they decode.
+func RestoreGenome(g *Genome) error {
+	if err := g.Validate(); err != nil {
+		return err
+	}
+	for _, node := range g.NodeGenes {
+		if node.Activation == nil {
+			continue
+		}
+		afunc, ok := lookupActivation(node.Activation.Name)
+		if !ok {
+			return fmt.Errorf("neat: unknown activation function %q of node %d "+
+				"(see RegisterActivation)", node.Activation.Name, node.ID)
+		}
+		node.Activation = afunc
+	}
+	g.evaluated = false
+	return nil
+}
+
+// Adapt returns a copy of the argument genome whose input and output nodes are
+// adapted to the argument number of inputs and outputs, so that a champion of a
+// previous experiment can seed a new experiment with a different interface.
+//
+// Node IDs are remapped such that inputs come first, then outputs, then hidden
+// nodes, as in a newly created genome. Input and output nodes that exist in
+// both experiments keep their order (and therefore their connections); extra
+// nodes are added without connections, and missing nodes are removed along
+// with every connection from or to them. Hidden nodes are kept as they are.
+func Adapt(g *Genome, numInputs, numOutputs int) *Genome {
+	var inputs, outputs, hidden []*NodeGene
+	for _, node := range g.NodeGenes {
+		switch node.Type {
+		case "input":
+			inputs = append(inputs, node)
+		case "output":
+			outputs = append(outputs, node)
+		default:
+			hidden = append(hidden, node)
+		}
+	}
+
+	idMap := make(map[int]int) // old node ID to new node ID
+	nodeGenes := make([]*NodeGene, 0, numInputs+numOutputs+len(hidden))
+
+	for i := 0; i < numInputs; i++ {
+		id := len(nodeGenes)
+		if i < len(inputs) {
+			idMap[inputs[i].ID] = id
+			nodeGenes = append(nodeGenes, NewNodeGene(id, "input",
+				inputs[i].Activation))
+		} else {
+			nodeGenes = append(nodeGenes, NewNodeGene(id, "input",
+				ActivationSet["identity"]))
+		}
+	}
+	for i := 0; i < numOutputs; i++ {
+		id := len(nodeGenes)
+		if i < len(outputs) {
+			idMap[outputs[i].ID] = id
+			nodeGenes = append(nodeGenes, NewNodeGene(id, "output",
+				outputs[i].Activation))
+		} else {
+			nodeGenes = append(nodeGenes, NewNodeGene(id, "output",
+				ActivationSet["sigmoid"]))
+		}
+	}
+	for _, node := range hidden {
+		id := len(nodeGenes)
+		idMap[node.ID] = id
+		nodeGenes = append(nodeGenes, NewNodeGene(id, node.Type,
+			node.Activation))
+	}
+
+	// only keep connections whose both ends survived the adaptation.
+	connGenes := make([]*ConnGene, 0, len(g.ConnGenes))
+	for _, conn := range g.ConnGenes {
+		from, okFrom := idMap[conn.From]
+		to, okTo := idMap[conn.To]
+		if okFrom && okTo {
+			c := conn.Copy()
+			c.From, c.To = from, to
+			connGenes = append(connGenes, c)
+		}
+	}
+
+	return &Genome{
+		ID:        g.ID,
+		SpeciesID: -1,
+		NodeGenes: nodeGenes,
+		ConnGenes: connGenes,
+		Fitness:   g.Fitness,
+		evaluated: false,
+	}
+}
+
+// Transfer seeds the population with the argument genomes (e.g., champions of
+// a previous experiment), after adapting them to this NEAT's number of inputs
+// and outputs. Each genome replaces a member of the initial population and is
+// assigned a new genome ID; if there are more genomes than the population
+// size, the rest are ignored. The population is then speciated from scratch,
+// since representatives of the current species may be replaced genomes.
+func (n *NEAT) Transfer(genomes []*Genome) {
+	if len(genomes) == 0 {
+		return
+	}
+	for i, genome := range genomes {
+		if i >= len(n.Population) {
+			break
+		}
+		adapted := Adapt(genome, n.Config.NumInputs, n.Config.NumOutputs)
+		adapted.ID = n.Population[i].ID
+		adapted.Fitness = n.Config.InitFitness
+		n.Population[i] = adapted
+	}
+	n.Species = nil
+	n.Speciation(n)
+}
+
+// ExportPopulationJSON writes the argument genomes, e.g., the population of a
+// run, to the argument writer as a JSON array, along with their species IDs,
+// which can be read with ImportPopulationJSON for external analysis, manual
+// curation, or another run.
+func ExportPopulationJSON(w io.Writer, genomes []*Genome) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "\t")
+	return encoder.Encode(genomes)
+}
+
+// ImportPopulationJSON reads genomes that were written with
+// ExportPopulationJSON from the argument reader. Since activation functions
+// are exported only by their names, they are restored from ActivationSet.
+func ImportPopulationJSON(r io.Reader) ([]*Genome, error) {
+	var genomes []*Genome
+	if err := json.NewDecoder(r).Decode(&genomes); err != nil {
+		return nil, err
+	}
+	for _, genome := range genomes {
+		if genome == nil {
+			return nil, errors.New("neat: null genome in population")
+		}
+		if err := RestoreGenome(genome); err != nil {
+			return nil, err
+		}
+	}
+	return genomes, nil
+}
+
+// ImportPopulation replaces the population with the argument genomes, e.g.,
+// read with ImportPopulationJSON, which are evaluated again in the next
+// generation. The genomes are regrouped into species by their species IDs,
+// represented by their first members; genomes without a species (negative ID)
+// are assigned to a species at the next speciation. Genome and species IDs
+// that are assigned afterwards do not collide with theirs.
+func (n *NEAT) ImportPopulation(genomes []*Genome) error {
+	if len(genomes) == 0 {
+		return errors.New("neat: empty population")
+	}
+
+	var species []*Species
+	bySpecies := make(map[int]*Species)
+	for _, genome := range genomes {
+		n.reserveIDs(genome.ID, genome.SpeciesID)
+		if genome.SpeciesID < 0 {
+			continue
+		}
+		if s, ok := bySpecies[genome.SpeciesID]; ok {
+			s.Members = append(s.Members, genome)
+			continue
+		}
+		s := NewSpecies(genome.SpeciesID, genome)
+		bySpecies[genome.SpeciesID] = s
+		species = append(species, s)
+	}
+	if len(species) == 0 {
+		species = append(species, NewSpecies(n.newSpeciesID(), genomes[0]))
+	}
+
+	for _, genome := range genomes {
+		n.countInnovations(genome.ConnGenes)
+	}
+	n.Population = genomes
+	n.Species = species
+	return nil
+}
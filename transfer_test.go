@@ -0,0 +1,219 @@
+package neat
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+func TestAdapt(t *testing.T) {
+	rand.Seed(0)
+	g := NewFCGenome(0, 3, 2, 0.0)
+	g.MutateAddNode(1.0, ActivationSet["sigmoid"])
+
+	adapted := Adapt(g, 2, 3)
+	if len(adapted.NodeGenes) != len(g.NodeGenes) {
+		t.Errorf("invalid number of nodes: %d != %d",
+			len(adapted.NodeGenes), len(g.NodeGenes))
+	}
+	for i, node := range adapted.NodeGenes {
+		if node.ID != i {
+			t.Errorf("node ID %d at index %d", node.ID, i)
+		}
+	}
+	for _, conn := range adapted.ConnGenes {
+		if conn.From >= len(adapted.NodeGenes) || conn.To >= len(adapted.NodeGenes) {
+			t.Errorf("dangling connection %s", conn)
+		}
+		if adapted.NodeGenes[conn.To].Type == "input" {
+			t.Errorf("connection into an input node %s", conn)
+		}
+	}
+}
+
+func TestNEATTransfer(t *testing.T) {
+	config := NewDefaultConfig(3, 1)
+	config.NumGenerations = 5
+	config.PopulationSize = 20
+	config.RateAddNode = 0.5
+
+	source := New(config, XORTest(), WithSeed(0))
+	source.Run()
+
+	n := New(config, XORTest(), WithSeed(1))
+	n.Transfer(source.Population[:10])
+
+	// genome IDs are kept, so representatives are compared by structure.
+	byID := make(map[int]*Genome)
+	for _, genome := range n.Population {
+		byID[genome.ID] = genome
+	}
+	species := make(map[int]bool)
+	numMembers := 0
+	for _, s := range n.Species {
+		species[s.ID] = true
+		numMembers += len(s.Members)
+		genome, ok := byID[s.Representative.ID]
+		if !ok || n.Distance(s.Representative, genome) != 0.0 {
+			t.Errorf("species %d is represented by genome %d, which is not in "+
+				"the population", s.ID, s.Representative.ID)
+		}
+	}
+	if numMembers != config.PopulationSize {
+		t.Errorf("invalid number of members of species: %d != %d", numMembers,
+			config.PopulationSize)
+	}
+	for _, genome := range n.Population {
+		if !species[genome.SpeciesID] {
+			t.Errorf("genome %d is of unknown species %d", genome.ID,
+				genome.SpeciesID)
+		}
+	}
+
+	n.Step()
+	if n.Err() != nil {
+		t.Fatal(n.Err())
+	}
+}
+
+func TestImportPopulation(t *testing.T) {
+	config := NewDefaultConfig(3, 1)
+	config.NumGenerations = 3
+	config.PopulationSize = 20
+
+	n := New(config, XORTest(), WithSeed(0))
+	n.Run()
+
+	buf := &bytes.Buffer{}
+	if err := ExportPopulationJSON(buf, n.Population); err != nil {
+		t.Fatal(err)
+	}
+	genomes, err := ImportPopulationJSON(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(genomes) != len(n.Population) {
+		t.Fatalf("invalid number of genomes: %d != %d",
+			len(genomes), len(n.Population))
+	}
+	for i, genome := range genomes {
+		if genome.ID != n.Population[i].ID ||
+			genome.SpeciesID != n.Population[i].SpeciesID ||
+			len(genome.ConnGenes) != len(n.Population[i].ConnGenes) {
+			t.Errorf("genome %d is not imported as exported", i)
+		}
+	}
+
+	m := New(config, XORTest(), WithSeed(1))
+	if err := m.ImportPopulation(genomes); err != nil {
+		t.Fatal(err)
+	}
+	if len(m.Species) == 0 || m.Species[0].ID != genomes[0].SpeciesID {
+		t.Errorf("species are not restored: %d species", len(m.Species))
+	}
+	for _, genome := range genomes {
+		if genome.ID > m.lastGenomeID {
+			t.Errorf("genome ID %d may collide with new genomes", genome.ID)
+		}
+	}
+	m.Step()
+	if m.Err() != nil {
+		t.Fatal(m.Err())
+	}
+
+	if err := m.ImportPopulation(nil); err == nil {
+		t.Error("empty population is not rejected")
+	}
+}
+
+func TestReadGenomeJSON(t *testing.T) {
+	g := NewFCGenome(0, 3, 2, 0.5)
+	buf := &bytes.Buffer{}
+	if err := json.NewEncoder(buf).Encode(g); err != nil {
+		t.Fatal(err)
+	}
+
+	h, err := ReadGenomeJSON(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	inputs := []float64{0.1, 0.2, 0.3}
+	want, _ := NewNeuralNetwork(g).FeedForward(inputs)
+	got, err := NewNeuralNetwork(h).FeedForward(inputs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("invalid output %d: %f != %f", i, got[i], want[i])
+		}
+	}
+
+	if _, err := ReadGenomeJSON(strings.NewReader("{")); err == nil {
+		t.Error("invalid JSON is not rejected")
+	}
+}
+
+func TestRegisterActivation(t *testing.T) {
+	swish := RegisterActivation("swish", func(x float64) float64 {
+		return x / (1.0 + math.Exp(-x))
+	})
+	defer delete(ActivationSet, "swish")
+
+	g := NewGenome(0, 1, 1, 0.0)
+	g.NodeGenes = append(g.NodeGenes, NewNodeGene(2, "hidden", swish))
+	buf := &bytes.Buffer{}
+	if err := ExportPopulationJSON(buf, []*Genome{g}); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.String()
+
+	genomes, err := ImportPopulationJSON(strings.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	afunc := genomes[0].NodeGenes[2].Activation
+	if afunc != swish || afunc.Fn(0.0) != 0.0 {
+		t.Errorf("activation function is not restored: %+v", afunc)
+	}
+
+	delete(ActivationSet, "swish")
+	if _, err := ImportPopulationJSON(strings.NewReader(data)); err == nil {
+		t.Error("unknown activation function is not rejected")
+	}
+}
+
+func TestGenomeValidate(t *testing.T) {
+	g := NewGenome(0, 2, 1, 0.0)
+	g.NodeGenes[2].Activation = ActivationSet["sigmoid"]
+	g.ConnGenes = append(g.ConnGenes, NewConnGene(0, 2, 1.0))
+	if err := g.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	// a hand-edited file with a dangling connection, a connection into an
+	// input node, and an output node without an activation function.
+	data := `[{"id": 3, "fitness": 0, "connGenes": [
+		{"from": 0, "to": 5, "weight": 1, "disabled": false},
+		{"from": 2, "to": 1, "weight": 1, "disabled": false}],
+		"nodeGenes": [
+		{"id": 0, "type": "input", "activation": null},
+		{"id": 1, "type": "input", "activation": null},
+		{"id": 2, "type": "output", "activation": null}]}]`
+	_, err := ImportPopulationJSON(strings.NewReader(data))
+	genomeErr, ok := err.(*GenomeError)
+	if !ok {
+		t.Fatalf("invalid error: %v", err)
+	}
+	if genomeErr.ID != 3 || len(genomeErr.Violations) != 3 {
+		t.Errorf("invalid violations: %v", genomeErr)
+	}
+
+	if !strings.Contains(GenomeSchema, `"nodeGenes"`) ||
+		!strings.Contains(PopulationSchema, "genome.schema.json") {
+		t.Error("schemas are not embedded")
+	}
+}
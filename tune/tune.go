@@ -0,0 +1,310 @@
+// tune.go implementation of hyperparameter search for NEAT.
+//
+// Copyright (C) 2017  Jin Yeom
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+// Package tune provides hyperparameter search for NEAT. Given a base
+// configuration and ranges of hyperparameters, it runs multiple instances of
+// NEAT, optionally in parallel, and reports the best-performing configuration.
+package tune
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/jinyeom/neat"
+)
+
+// Param is a range of a hyperparameter to search over, given the setting's JSON
+// name in the configuration, e.g., "rateAddNode".
+type Param struct {
+	Name    string    // JSON name of the setting
+	Values  []float64 // values to try in grid search
+	Min     float64   // lower bound in random search
+	Max     float64   // upper bound in random search
+	Integer bool      // true if the setting is an integer
+}
+
+// NewGridParam returns a new instance of Param for grid search, given the
+// setting's JSON name and the values to try.
+func NewGridParam(name string, values ...float64) *Param {
+	return &Param{
+		Name:   name,
+		Values: values,
+	}
+}
+
+// NewRandomParam returns a new instance of Param for random search, given the
+// setting's JSON name and its range [min, max).
+func NewRandomParam(name string, min, max float64) *Param {
+	return &Param{
+		Name: name,
+		Min:  min,
+		Max:  max,
+	}
+}
+
+// format is a helper function that converts the argument value of this
+// hyperparameter into a string that can be passed to neat.Config.Set.
+func (p *Param) format(value float64) string {
+	if p.Integer {
+		return strconv.Itoa(int(math.Round(value)))
+	}
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
+
+// Trial is the result of running NEAT with a configuration.
+type Trial struct {
+	Config      *neat.Config // configuration of this trial
+	Best        *neat.Genome // best genome found
+	Generations int          // number of generations run
+	Err         error        // error in the configuration, if any
+}
+
+// Fitness returns the fitness score of the best genome of this trial.
+func (t *Trial) Fitness() float64 {
+	return t.Best.Fitness
+}
+
+// Tuner searches for the best configuration of NEAT, given a base configuration
+// and an evaluation function. If trials run in parallel, the evaluation function
+// must be safe for concurrent use.
+type Tuner struct {
+	Base       *neat.Config        // base configuration
+	Evaluation neat.EvaluationFunc // evaluation function
+	Comparison neat.ComparisonFunc // comparison function (nil by fitness)
+	Budget     int                 // maximum number of trials
+	Parallel   int                 // number of trials that run concurrently
+	Seed       int64               // seed of random number generators
+}
+
+// NewTuner returns a new instance of Tuner, given a base configuration, an
+// evaluation function, and the maximum number of trials. By default, trials
+// run sequentially.
+func NewTuner(base *neat.Config, evaluation neat.EvaluationFunc,
+	budget int) *Tuner {
+	return &Tuner{
+		Base:       base,
+		Evaluation: evaluation,
+		Budget:     budget,
+		Parallel:   1,
+		Seed:       0,
+	}
+}
+
+// GridSearch runs a trial for each combination of the values of the argument
+// hyperparameters, up to the budget, and returns the best trial along with
+// every trial in the order of the combinations.
+func (t *Tuner) GridSearch(params ...*Param) (*Trial, []*Trial, error) {
+	configs := []*neat.Config{t.Base.Copy()}
+	for _, p := range params {
+		next := make([]*neat.Config, 0, len(configs)*len(p.Values))
+		for _, config := range configs {
+			for _, value := range p.Values {
+				c := config.Copy()
+				if err := c.Set(p.Name, p.format(value)); err != nil {
+					return nil, nil, err
+				}
+				next = append(next, c)
+			}
+		}
+		configs = next
+	}
+	if len(configs) > t.Budget {
+		configs = configs[:t.Budget]
+	}
+	best, trials := t.run(configs)
+	return best, trials, nil
+}
+
+// RandomSearch runs trials of configurations whose hyperparameters are sampled
+// uniformly from the argument ranges, as many as the budget, and returns the
+// best trial along with every trial.
+func (t *Tuner) RandomSearch(params ...*Param) (*Trial, []*Trial, error) {
+	configs, err := t.sample(params)
+	if err != nil {
+		return nil, nil, err
+	}
+	best, trials := t.run(configs)
+	return best, trials, nil
+}
+
+// SuccessiveHalving runs trials of sampled configurations (as in RandomSearch)
+// with a budget-aware schedule: every trial first runs for the argument minimum
+// number of generations, then only the best 1/eta of the trials continue for
+// eta times as many generations, and so on, until a single trial remains or the
+// number of generations of the base configuration is reached. Promising
+// configurations are therefore given more generations, while bad ones are
+// pruned early. It returns the best trial along with every trial; each trial
+// records the number of generations it ran before it was pruned.
+func (t *Tuner) SuccessiveHalving(minGenerations, eta int,
+	params ...*Param) (*Trial, []*Trial, error) {
+	if minGenerations < 1 || eta < 2 {
+		return nil, nil, fmt.Errorf("invalid schedule: minGenerations %d, eta %d",
+			minGenerations, eta)
+	}
+	configs, err := t.sample(params)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	trials := make([]*Trial, len(configs))
+	instances := make([]*neat.NEAT, len(configs))
+	for i, config := range configs {
+		config.Verbose = false
+		trials[i] = &Trial{Config: config}
+		if trials[i].Err = config.Validate(); trials[i].Err == nil {
+			instances[i] = neat.New(config, t.Evaluation,
+				t.options(t.Seed+int64(i))...)
+		}
+	}
+
+	compare := t.compare()
+	alive := make([]int, 0, len(configs))
+	for i := range trials {
+		if trials[i].Err == nil {
+			alive = append(alive, i)
+		}
+	}
+
+	generations := minGenerations
+	for len(alive) > 0 {
+		if generations > t.Base.NumGenerations {
+			generations = t.Base.NumGenerations
+		}
+		t.parallel(len(alive), func(j int) {
+			n := instances[alive[j]]
+			for n.Generation < generations {
+				n.Step()
+			}
+			trials[alive[j]].Best = n.Best
+			trials[alive[j]].Generations = n.Generation
+		})
+		if len(alive) == 1 || generations == t.Base.NumGenerations {
+			break
+		}
+
+		// keep the best 1/eta of the trials for the next rung.
+		sort.SliceStable(alive, func(i, j int) bool {
+			return compare(trials[alive[i]].Best, trials[alive[j]].Best)
+		})
+		alive = alive[:int(math.Ceil(float64(len(alive))/float64(eta)))]
+		generations *= eta
+	}
+
+	if len(alive) == 0 {
+		return nil, trials, nil
+	}
+	return trials[alive[0]], trials, nil
+}
+
+// sample is a helper method that returns as many configurations as the budget,
+// whose hyperparameters are sampled from the argument parameters; a parameter
+// with values is sampled among them, otherwise uniformly from its range.
+func (t *Tuner) sample(params []*Param) ([]*neat.Config, error) {
+	rng := rand.New(rand.NewSource(t.Seed))
+	configs := make([]*neat.Config, t.Budget)
+	for i := range configs {
+		configs[i] = t.Base.Copy()
+		for _, p := range params {
+			value := p.Min + rng.Float64()*(p.Max-p.Min)
+			if len(p.Values) != 0 {
+				value = p.Values[rng.Intn(len(p.Values))]
+			}
+			if err := configs[i].Set(p.Name, p.format(value)); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return configs, nil
+}
+
+// run is a helper method that runs NEAT with each of the argument
+// configurations, with at most t.Parallel trials at once, and returns the best
+// trial along with every trial.
+func (t *Tuner) run(configs []*neat.Config) (*Trial, []*Trial) {
+	trials := make([]*Trial, len(configs))
+	t.parallel(len(configs), func(i int) {
+		trials[i] = t.trial(configs[i], t.Seed+int64(i))
+	})
+
+	var best *Trial
+	compare := t.compare()
+	for _, trial := range trials {
+		if trial.Err != nil {
+			continue
+		}
+		if best == nil || compare(trial.Best, best.Best) {
+			best = trial
+		}
+	}
+	return best, trials
+}
+
+// parallel is a helper method that calls the argument function with each index
+// in [0, num), with at most t.Parallel calls at once.
+func (t *Tuner) parallel(num int, fn func(i int)) {
+	parallel := t.Parallel
+	if parallel < 1 {
+		parallel = 1
+	}
+	sem := make(chan struct{}, parallel)
+	var wg sync.WaitGroup
+	for i := 0; i < num; i++ {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			fn(i)
+		}(i)
+	}
+	wg.Wait()
+}
+
+// trial is a helper method that runs NEAT with the argument configuration and
+// seed of its random number generator.
+func (t *Tuner) trial(config *neat.Config, seed int64) *Trial {
+	config.Verbose = false
+	if err := config.Validate(); err != nil {
+		return &Trial{Config: config, Err: err}
+	}
+	n := neat.New(config, t.Evaluation, t.options(seed)...)
+	return &Trial{Config: config, Best: n.Run(), Generations: n.Generation}
+}
+
+// options returns the options of NEAT of a trial with the argument seed, which
+// compare genomes with t.Comparison, if any.
+func (t *Tuner) options(seed int64) []neat.Option {
+	opts := []neat.Option{neat.WithRand(rand.New(rand.NewSource(seed)))}
+	if t.Comparison != nil {
+		opts = append(opts, neat.WithComparison(t.Comparison))
+	}
+	return opts
+}
+
+// compare returns t.Comparison, or the comparison function determined by
+// t.Base.MinimizeFitness if it is nil, with which the best genomes of trials
+// are compared.
+func (t *Tuner) compare() neat.ComparisonFunc {
+	if t.Comparison != nil {
+		return t.Comparison
+	}
+	return neat.NewComparisonFunc(t.Base.MinimizeFitness)
+}
@@ -0,0 +1,64 @@
+package tune
+
+import (
+	"testing"
+
+	"github.com/jinyeom/neat"
+)
+
+func TestGridSearch(t *testing.T) {
+	base := neat.NewDefaultConfig(3, 1)
+	base.NumGenerations = 2
+	base.PopulationSize = 10
+	base.MinimizeFitness = true
+	base.InitFitness = 9999.0
+
+	tuner := NewTuner(base, neat.XORTest(), 3)
+	tuner.Parallel = 2
+	best, trials, err := tuner.GridSearch(
+		NewGridParam("rateAddNode", 0.1, 0.2),
+		NewGridParam("rateAddConn", 0.1, 0.2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(trials) != 3 {
+		t.Errorf("invalid number of trials: %d != 3", len(trials))
+	}
+	for _, trial := range trials {
+		if trial.Fitness() < best.Fitness() {
+			t.Errorf("trial %.4f is better than the best %.4f",
+				trial.Fitness(), best.Fitness())
+		}
+	}
+
+	if _, _, err = tuner.GridSearch(NewGridParam("rateAddNodes", 0.1)); err == nil {
+		t.Error("unknown setting was not reported")
+	}
+}
+
+func TestSuccessiveHalving(t *testing.T) {
+	base := neat.NewDefaultConfig(3, 1)
+	base.NumGenerations = 4
+	base.PopulationSize = 10
+	base.MinimizeFitness = true
+	base.InitFitness = 9999.0
+
+	tuner := NewTuner(base, neat.XORTest(), 4)
+	best, trials, err := tuner.SuccessiveHalving(1, 2,
+		NewRandomParam("ratePerturb", 0.1, 0.9))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if best.Generations != base.NumGenerations {
+		t.Errorf("best trial ran for %d generations", best.Generations)
+	}
+
+	// 4 trials run 1 generation, 2 run 2 generations, and 1 runs 4 generations.
+	total := 0
+	for _, trial := range trials {
+		total += trial.Generations
+	}
+	if total != 1+1+2+4 {
+		t.Errorf("invalid total number of generations: %d != 8", total)
+	}
+}
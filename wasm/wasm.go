@@ -0,0 +1,121 @@
+// wasm.go implementation of a JavaScript wrapper of evolved networks.
+//
+// Copyright (C) 2017  Jin Yeom
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+//go:build js && wasm
+
+// Command wasm exposes evolved networks to JavaScript, so that they can drive
+// browser demos, e.g., of the cartpole task. Build it with
+//
+//	GOOS=js GOARCH=wasm go build -o neat.wasm github.com/jinyeom/neat/wasm
+//
+// and load it with wasm_exec.js of the Go distribution; it defines a global
+// object neat, whose function load takes a genome in JSON (e.g., a champion
+// file) and returns a network:
+//
+//	const net = neat.load(json);
+//	if (net instanceof Error) throw net;
+//	const outputs = net.activate([x, dx, th, dth]);
+//	const push = outputs[0] <= outputs[1] ? -1 : 1;
+//
+// A network also has reset, which clears the signals kept between steps of a
+// recurrent network, and the properties numInputs, numOutputs and recurrent.
+// Errors, e.g., of an invalid genome or a wrong number of inputs, are returned
+// as instances of Error, since Go functions cannot throw in JavaScript.
+//
+// Files of package neat that read or write files, e.g., checkpoints, plots, or
+// command-line flags, are excluded from JavaScript builds (//go:build !js), so
+// that only the inference path is linked; os is still linked indirectly by fmt
+// and encoding/json, but no file is ever opened.
+package main
+
+import (
+	"strings"
+	"syscall/js"
+
+	"github.com/jinyeom/neat"
+)
+
+func main() {
+	js.Global().Set("neat", js.ValueOf(map[string]interface{}{
+		"load": js.FuncOf(load),
+	}))
+
+	// keep the functions alive for the page
+	select {}
+}
+
+// load decodes the genome in JSON of the first argument, and returns its
+// network as a JavaScript object.
+func load(this js.Value, args []js.Value) interface{} {
+	if len(args) != 1 || args[0].Type() != js.TypeString {
+		return jsError("load takes a genome in JSON")
+	}
+	g, err := neat.ReadGenomeJSON(strings.NewReader(args[0].String()))
+	if err != nil {
+		return jsError(err.Error())
+	}
+	return wrap(neat.NewNeuralNetwork(g))
+}
+
+// wrap returns a JavaScript object of the argument network.
+func wrap(n *neat.NeuralNetwork) js.Value {
+	inputs := make([]float64, n.NumInputs())
+	outputs := make([]float64, 0, n.NumOutputs())
+
+	activate := js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		// types are checked before Length and Float, which panic on other
+		// types and would end the program; arrays and typed arrays pass.
+		if len(args) != 1 || args[0].Type() != js.TypeObject ||
+			args[0].Get("length").Type() != js.TypeNumber ||
+			args[0].Length() != len(inputs) {
+			return jsError("activate takes an array of inputs")
+		}
+		for i := range inputs {
+			input := args[0].Index(i)
+			if input.Type() != js.TypeNumber {
+				return jsError("activate takes an array of numbers")
+			}
+			inputs[i] = input.Float()
+		}
+		var err error
+		if outputs, err = n.FeedForwardTo(outputs[:0], inputs); err != nil {
+			return jsError(err.Error())
+		}
+		array := make([]interface{}, len(outputs))
+		for i, output := range outputs {
+			array[i] = output
+		}
+		return js.ValueOf(array)
+	})
+	reset := js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		n.Reset()
+		return nil
+	})
+
+	return js.ValueOf(map[string]interface{}{
+		"activate":   activate,
+		"reset":      reset,
+		"numInputs":  n.NumInputs(),
+		"numOutputs": n.NumOutputs(),
+		"recurrent":  n.Recurrent,
+	})
+}
+
+// jsError returns a JavaScript Error of the argument message.
+func jsError(message string) js.Value {
+	return js.Global().Get("Error").New(message)
+}